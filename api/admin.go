@@ -0,0 +1,234 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"tabungan-api/authtoken"
+	"tabungan-api/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// authPetugas guards the back-office routes. Every request must carry a
+// petugas token in X-Petugas-Token, signed with the deployment's secret; the
+// petugas ID and role come only from the token, so maker-checker cannot be
+// bypassed by claiming another identity.
+func (t *TabunganRESTAPI) authPetugas(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	claims, err := authtoken.Verify(t.rahasiaPetugas, c.Get("X-Petugas-Token", ""), time.Now())
+	petugas := models.Petugas{ID: claims.Subject, Role: claims.Role}
+	if err == nil && petugas.Role != models.RoleTeller && petugas.Role != models.RoleSupervisor && petugas.Role != models.RoleAdmin {
+		err = fmt.Errorf("invalid petugas role")
+	}
+	if err != nil {
+		t.log.WithContext(c.UserContext()).WithFields(logrus.Fields{
+			"petugas": petugas.ID,
+			"role":    petugas.Role,
+			"path":    c.Path(),
+		}).Warn(err.Error())
+		response["remark"] = err.Error()
+		c.Status(http.StatusUnauthorized)
+		return c.JSON(response)
+	}
+	c.Locals("petugas", petugas)
+	return c.Next()
+}
+
+func getPetugas(c *fiber.Ctx) (petugas models.Petugas) {
+	petugas, _ = c.Locals("petugas").(models.Petugas)
+	return
+}
+
+// wajibRole lets through only petugas holding one of roles. It must run
+// after authPetugas.
+func (t *TabunganRESTAPI) wajibRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		petugas := getPetugas(c)
+		for _, role := range roles {
+			if petugas.Role == role {
+				return c.Next()
+			}
+		}
+		err = fmt.Errorf("petugas tidak berwenang")
+		t.log.WithContext(c.UserContext()).WithFields(logrus.Fields{
+			"petugas": petugas.ID,
+			"role":    petugas.Role,
+			"path":    c.Path(),
+		}).Warn(err.Error())
+		c.Status(http.StatusForbidden)
+		return c.JSON(map[string]interface{}{"remark": err.Error()})
+	}
+}
+
+// mask applies the response masking policy of the calling petugas' role.
+func (t *TabunganRESTAPI) mask(c *fiber.Ctx, data interface{}) interface{} {
+	return t.masking[getPetugas(c).Role].Apply(data)
+}
+
+func (t *TabunganRESTAPI) ajukanPersetujuan(c *fiber.Ctx, maker string, request models.RequestOperasi) (err error) {
+	response := make(map[string]interface{})
+	role := models.RoleNasabah
+	if petugas := getPetugas(c); petugas.ID == maker {
+		role = petugas.Role
+	}
+	operasi, err := t.appSebagai(c, maker, role).AjukanOperasi(c.UserContext(), maker, request)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["remark"] = "operasi menunggu persetujuan petugas"
+	response["data"] = t.mask(c, operasi)
+	c.Status(http.StatusAccepted)
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) ajukanOperasi(c *fiber.Ctx) (err error) {
+	var request models.RequestOperasi
+	response := make(map[string]interface{})
+	err = c.BodyParser(&request)
+	if err != nil {
+		t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("parse request body to JSON error")
+		response["remark"] = "Failed to parse request body"
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	return t.ajukanPersetujuan(c, getPetugas(c).ID, request)
+}
+
+func (t *TabunganRESTAPI) getDaftarOperasi(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	operasi, err := t.app.GetDaftarOperasi(c.UserContext(), c.Query("status", ""))
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = t.mask(c, operasi)
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) getOperasi(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	operasi, riwayat, err := t.app.GetOperasi(c.UserContext(), c.Params("operasi", ""))
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = t.mask(c, operasi)
+	response["riwayat"] = t.mask(c, riwayat)
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) setujuiOperasi(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	petugas := getPetugas(c)
+	operasi, err := t.appSebagai(c, petugas.ID, petugas.Role).SetujuiOperasi(c.UserContext(), c.Params("operasi", ""), petugas)
+	if err != nil {
+		response["remark"] = err.Error()
+		response["data"] = t.mask(c, operasi)
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = t.mask(c, operasi)
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) tolakOperasi(c *fiber.Ctx) (err error) {
+	var request models.RequestKeputusanOperasi
+	response := make(map[string]interface{})
+	err = c.BodyParser(&request)
+	if err != nil {
+		t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("parse request body to JSON error")
+		response["remark"] = "Failed to parse request body"
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	petugas := getPetugas(c)
+	operasi, err := t.appSebagai(c, petugas.ID, petugas.Role).TolakOperasi(c.UserContext(), c.Params("operasi", ""), petugas, request.Remark)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = t.mask(c, operasi)
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) getDaftarAudit(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	if petugas := getPetugas(c); petugas.Role != models.RoleAdmin {
+		err = fmt.Errorf("audit hanya dapat diakses admin")
+		t.log.WithContext(c.UserContext()).WithField("petugas", petugas.ID).Warn(err.Error())
+		response["remark"] = err.Error()
+		c.Status(http.StatusForbidden)
+		return c.JSON(response)
+	}
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page < 1 {
+		response["remark"] = "parsing page query parameter to int failed"
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	show, err := strconv.Atoi(c.Query("show", "50"))
+	if err != nil || show < 1 {
+		response["remark"] = "parsing show query parameter to int failed"
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	audit, err := t.app.GetDaftarAudit(c.UserContext(), models.FilterAudit{
+		Aktor:  c.Query("aktor", ""),
+		Aksi:   c.Query("aksi", ""),
+		Target: c.Query("target", ""),
+		Dari:   c.Query("dari", ""),
+		Sampai: c.Query("sampai", ""),
+		Limit:  show,
+		Offset: (page - 1) * show,
+	})
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = t.mask(c, audit)
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) getNasabahAdmin(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	nik := c.Params("nik", "")
+	if asOf := c.Query("as_of", ""); asOf != "" {
+		nasabah, err := t.app.GetNasabahPerWaktu(c.UserContext(), nik, asOf)
+		if err != nil {
+			response["remark"] = err.Error()
+			c.Status(http.StatusBadRequest)
+			return c.JSON(response)
+		}
+		response["data"] = t.mask(c, nasabah)
+		return c.JSON(response)
+	}
+	nasabah, err := t.app.GetNasabah(c.UserContext(), nik)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = t.mask(c, nasabah)
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) getRiwayatNasabah(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	riwayat, err := t.app.GetRiwayatNasabah(c.UserContext(), c.Params("nik", ""))
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = t.mask(c, riwayat)
+	return c.JSON(response)
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"tabungan-api/app"
+	"tabungan-api/authtoken"
+	"tabungan-api/encryption"
+	"tabungan-api/repository"
+	"tabungan-api/storage"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	rahasiaUji = "rahasia-petugas"
+	// nikUji is the nasabah registrasiUji registers.
+	nikUji = "3171012345678901"
+)
+
+func apiUji(t *testing.T) (*TabunganRESTAPI, *repository.TabunganRepo) {
+	dir := t.TempDir()
+	return apiUjiStorage(t, storage.NewLocalStorage(filepath.Join(dir, "photo")),
+		storage.NewLocalStorage(filepath.Join(dir, "document")))
+}
+
+// apiUjiStorage is apiUji with photos and documents kept in foto and dokumen.
+func apiUjiStorage(t *testing.T, foto, dokumen storage.Storage) (*TabunganRESTAPI, *repository.TabunganRepo) {
+	dir := t.TempDir()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	keys, err := encryption.LoadKeyFile(filepath.Join(dir, "keys.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := repository.InitDatabase(filepath.Join(dir, "tabungan.db"), keys, logger)
+	t.Cleanup(func() { repo.Close() })
+	tabungan := app.NewTabunganApp(foto, dokumen, repo, logger)
+	return NewRESTAPI("", 0, rahasiaUji, time.Time{}, "", tabungan, logger), repo
+}
+
+func tokenUji(t *testing.T, rahasia, id, role string) string {
+	token, err := authtoken.Sign([]byte(rahasia), authtoken.Claims{Subject: id, Role: role, Expires: time.Now().Add(time.Hour).Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func kirim(t *testing.T, api *TabunganRESTAPI, method, path, body string, header map[string]string) (status int, data map[string]interface{}, remark string) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := api.server.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var envelope struct {
+		Data   map[string]interface{}
+		Remark string
+	}
+	json.NewDecoder(resp.Body).Decode(&envelope)
+	return resp.StatusCode, envelope.Data, envelope.Remark
+}
+
+// registrasiUji registers the nasabah the tests act as and returns their
+// rekening and the header that identifies them.
+func registrasiUji(t *testing.T, api *TabunganRESTAPI) (noRekening string, nasabah map[string]string) {
+	status, rekening, _ := kirim(t, api, "POST", "/v1/registrasi", `{"nik":"`+nikUji+`","nama":"Budi Santoso",
+		"alamat_ktp":"Jl A","alamat_domisili":"Jl A","jenis_kelamin":"L","tanggal_lahir":"1990-01-01"}`, nil)
+	if status != http.StatusOK {
+		t.Fatalf("registrasi status = %d", status)
+	}
+	return rekening["no_rekening"].(string), map[string]string{"Authorization": nikUji}
+}
+
+// TestMakerChecker checks that petugas identity and role are taken from the
+// signed token only, so nobody can approve their own operation by claiming
+// another identity in a header.
+func TestMakerChecker(t *testing.T) {
+	api, _ := apiUji(t)
+	registrasiUji(t, api)
+
+	teller := map[string]string{"X-Petugas-Token": tokenUji(t, rahasiaUji, "petugas-1", "teller")}
+	status, operasi, _ := kirim(t, api, "POST", "/v1/admin/operasi", `{"jenis_operasi":"update_nasabah",
+		"nik":"`+nikUji+`","nama":"Budi S","alamat_ktp":"Jl A","alamat_domisili":"Jl A"}`, teller)
+	if status != http.StatusAccepted {
+		t.Fatalf("ajukan operasi status = %d", status)
+	}
+	approve := "/v1/admin/operasi/" + operasi["operasi_id"].(string) + "/approve"
+
+	cases := []struct {
+		nama   string
+		header map[string]string
+		status int
+		remark string
+	}{
+		{"no token", map[string]string{"X-Petugas-ID": "petugas-2", "X-Petugas-Role": "supervisor"},
+			http.StatusUnauthorized, "invalid token"},
+		{"token from another secret", map[string]string{"X-Petugas-Token": tokenUji(t, "lain", "petugas-2", "supervisor")},
+			http.StatusUnauthorized, "invalid token"},
+		{"teller claiming supervisor in headers", map[string]string{"X-Petugas-Token": teller["X-Petugas-Token"],
+			"X-Petugas-ID": "petugas-2", "X-Petugas-Role": "supervisor"}, http.StatusBadRequest, "tidak berwenang"},
+		{"maker as checker", map[string]string{"X-Petugas-Token": tokenUji(t, rahasiaUji, "petugas-1", "supervisor")},
+			http.StatusBadRequest, "maker dan checker tidak boleh sama"},
+		{"another supervisor", map[string]string{"X-Petugas-Token": tokenUji(t, rahasiaUji, "petugas-2", "supervisor")},
+			http.StatusOK, ""},
+	}
+	for _, c := range cases {
+		status, _, remark := kirim(t, api, "POST", approve, "", c.header)
+		if status != c.status || !strings.Contains(remark, c.remark) {
+			t.Errorf("%s: status = %d %q, want %d %q", c.nama, status, remark, c.status, c.remark)
+		}
+	}
+}
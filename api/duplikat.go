@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"tabungan-api/app"
+	"tabungan-api/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (t *TabunganRESTAPI) getDaftarDuplikat(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	daftar, err := t.app.GetDaftarDuplikat(c.UserContext(), c.Query("status", models.DuplikatPending), c.Query("nik", ""))
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = t.mask(c, daftar)
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) getDuplikatNasabah(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	daftar, err := t.app.GetDaftarDuplikat(c.UserContext(), c.Query("status", ""), c.Params("nik", ""))
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = t.mask(c, daftar)
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) konfirmasiDuplikat(c *fiber.Ctx) (err error) {
+	return t.putuskanDuplikat(c, app.TabunganAppInterface.KonfirmasiDuplikat)
+}
+
+func (t *TabunganRESTAPI) abaikanDuplikat(c *fiber.Ctx) (err error) {
+	return t.putuskanDuplikat(c, app.TabunganAppInterface.AbaikanDuplikat)
+}
+
+type fungsiKeputusanDuplikat func(view app.TabunganAppInterface, ctx context.Context, duplikatID string, petugas models.Petugas, request models.RequestKeputusanDuplikat) (models.Duplikat, error)
+
+func (t *TabunganRESTAPI) putuskanDuplikat(c *fiber.Ctx, putuskan fungsiKeputusanDuplikat) (err error) {
+	var request models.RequestKeputusanDuplikat
+	response := make(map[string]interface{})
+	if len(c.Body()) > 0 {
+		err = c.BodyParser(&request)
+		if err != nil {
+			t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("parse request body to JSON error")
+			response["remark"] = "Failed to parse request body"
+			c.Status(http.StatusBadRequest)
+			return c.JSON(response)
+		}
+	}
+	petugas := getPetugas(c)
+	duplikat, err := putuskan(t.appSebagai(c, petugas.ID, petugas.Role), c.UserContext(), c.Params("duplikat", ""), petugas, request)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = t.mask(c, duplikat)
+	return c.JSON(response)
+}
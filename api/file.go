@@ -0,0 +1,235 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"tabungan-api/app"
+	"tabungan-api/models"
+	"tabungan-api/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+func (t *TabunganRESTAPI) getFoto(c *fiber.Ctx) (err error) {
+	return t.unduhFile(c, c.Get("Authorization", ""), t.app.GetFoto)
+}
+
+func (t *TabunganRESTAPI) getDokumen(c *fiber.Ctx) (err error) {
+	return t.unduhFile(c, c.Get("Authorization", ""), t.app.GetDokumen)
+}
+
+func (t *TabunganRESTAPI) getThumbnail(c *fiber.Ctx) (err error) {
+	return t.unduhFile(c, c.Get("Authorization", ""), t.app.GetThumbnail)
+}
+
+func (t *TabunganRESTAPI) getThumbnailAdmin(c *fiber.Ctx) (err error) {
+	return t.unduhFile(c, c.Params("nik", ""), t.app.GetThumbnail)
+}
+
+func (t *TabunganRESTAPI) getFotoAdmin(c *fiber.Ctx) (err error) {
+	return t.unduhFile(c, c.Params("nik", ""), t.app.GetFoto)
+}
+
+func (t *TabunganRESTAPI) getDokumenAdmin(c *fiber.Ctx) (err error) {
+	return t.unduhFile(c, c.Params("nik", ""), t.app.GetDokumen)
+}
+
+func (t *TabunganRESTAPI) getFileVersi(c *fiber.Ctx) (err error) {
+	fileID := c.Params("file", "")
+	return t.unduhFile(c, c.Get("Authorization", ""), func(ctx context.Context, nik string) (storage.Blob, error) {
+		return t.app.GetFileVersi(ctx, nik, fileID)
+	})
+}
+
+func (t *TabunganRESTAPI) getFileVersiAdmin(c *fiber.Ctx) (err error) {
+	fileID := c.Params("file", "")
+	return t.unduhFile(c, c.Params("nik", ""), func(ctx context.Context, nik string) (storage.Blob, error) {
+		return t.app.GetFileVersi(ctx, nik, fileID)
+	})
+}
+
+func (t *TabunganRESTAPI) getRiwayatFile(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	nik := c.Get("Authorization", "")
+	if nik == "" {
+		err = fmt.Errorf("missing NIK in authorization header")
+		t.log.WithContext(c.UserContext()).Warn(err.Error())
+		response["remark"] = err.Error()
+		c.Status(http.StatusUnauthorized)
+		return c.JSON(response)
+	}
+	file, err := t.app.GetRiwayatFile(c.UserContext(), nik, c.Query("jenis", ""))
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = file
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) getRiwayatFileAdmin(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	file, err := t.app.GetRiwayatFile(c.UserContext(), c.Params("nik", ""), c.Query("jenis", ""))
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = t.mask(c, file)
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) uploadFoto(c *fiber.Ctx) (err error) {
+	return t.uploadSatuFile(c, "photo", func(app app.TabunganAppInterface) simpanFile { return app.SavePhoto })
+}
+
+func (t *TabunganRESTAPI) uploadDokumen(c *fiber.Ctx) (err error) {
+	return t.uploadSatuFile(c, "doc", func(app app.TabunganAppInterface) simpanFile { return app.SaveDoc })
+}
+
+type simpanFile func(ctx context.Context, file io.Reader, filename, nik string) error
+
+func (t *TabunganRESTAPI) uploadSatuFile(c *fiber.Ctx, field string, pilih func(app.TabunganAppInterface) simpanFile) (err error) {
+	response := make(map[string]interface{})
+	nik := c.Get("Authorization", "")
+	if nik == "" {
+		err = fmt.Errorf("missing NIK in authorization header")
+		t.log.WithContext(c.UserContext()).Warn(err.Error())
+		response["remark"] = err.Error()
+		c.Status(http.StatusUnauthorized)
+		return c.JSON(response)
+	}
+	header, err := c.FormFile(field)
+	if err != nil {
+		t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Errorf("parse %s in multiform error", field)
+		response["remark"] = fmt.Sprintf("Failed to read %s file in multiform", field)
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	if remark := t.simpanUpload(c.UserContext(), header, field, nik, pilih(t.appSebagai(c, nik, models.RoleNasabah))); remark != "" {
+		response["remark"] = remark
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	return c.SendStatus(http.StatusOK)
+}
+
+// simpanUpload hands one multipart file to simpan and returns the remark to
+// answer with when it fails.
+func (t *TabunganRESTAPI) simpanUpload(ctx context.Context, header *multipart.FileHeader, field, nik string, simpan simpanFile) (remark string) {
+	file, err := header.Open()
+	if err != nil {
+		t.log.WithContext(ctx).WithField("error", err.Error()).Errorf("parse %s in multiform error", field)
+		return fmt.Sprintf("Failed to read %s file in multiform", field)
+	}
+	defer file.Close()
+	err = simpan(ctx, file, header.Filename, nik)
+	if err != nil {
+		t.log.WithContext(ctx).WithField("error", err.Error()).Errorf("save %s error", field)
+		return err.Error()
+	}
+	return
+}
+
+func (t *TabunganRESTAPI) unduhFile(c *fiber.Ctx, nik string, buka func(ctx context.Context, nik string) (storage.Blob, error)) (err error) {
+	response := make(map[string]interface{})
+	if nik == "" {
+		err = fmt.Errorf("missing NIK in authorization header")
+		t.log.WithContext(c.UserContext()).Warn(err.Error())
+		response["remark"] = err.Error()
+		c.Status(http.StatusUnauthorized)
+		return c.JSON(response)
+	}
+	blob, err := buka(c.UserContext(), nik)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		if errors.Is(err, app.ErrFileTidakAda) {
+			c.Status(http.StatusNotFound)
+		}
+		return c.JSON(response)
+	}
+	return t.kirimBlob(c, blob)
+}
+
+// kirimBlob streams blob honouring If-None-Match and a single byte range.
+// Multi-range requests are answered with the whole file, which RFC 7233
+// allows.
+func (t *TabunganRESTAPI) kirimBlob(c *fiber.Ctx, blob storage.Blob) (err error) {
+	c.Set(fiber.HeaderETag, blob.ETag)
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+	if !blob.ModTime.IsZero() {
+		c.Set(fiber.HeaderLastModified, blob.ModTime.UTC().Format(http.TimeFormat))
+	}
+	if match := c.Get(fiber.HeaderIfNoneMatch, ""); match != "" && match == blob.ETag {
+		return c.SendStatus(http.StatusNotModified)
+	}
+	offset, length := int64(0), blob.Size
+	if rangeHeader := c.Get(fiber.HeaderRange, ""); rangeHeader != "" {
+		ifRange := c.Get(fiber.HeaderIfRange, "")
+		if ifRange == "" || ifRange == blob.ETag {
+			var ok bool
+			offset, length, ok = parseRange(rangeHeader, blob.Size)
+			if !ok {
+				c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes */%d", blob.Size))
+				return c.SendStatus(http.StatusRequestedRangeNotSatisfiable)
+			}
+			if length != blob.Size {
+				c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, blob.Size))
+				c.Status(http.StatusPartialContent)
+			}
+		}
+	}
+	c.Set(fiber.HeaderContentType, blob.ContentType)
+	if length == 0 {
+		return c.Send(nil)
+	}
+	reader, err := blob.Open(offset, length)
+	if err != nil {
+		t.log.WithContext(c.UserContext()).WithFields(logrus.Fields{
+			"id":    blob.Key,
+			"error": err.Error(),
+		}).Error("read blob error")
+		c.Status(http.StatusInternalServerError)
+		return c.JSON(map[string]interface{}{"remark": "gagal membaca file"})
+	}
+	return c.SendStream(reader, int(length))
+}
+
+// parseRange understands a single "bytes=start-end", "bytes=start-" or
+// "bytes=-suffix" range. Anything else yields the whole file.
+func parseRange(header string, size int64) (offset, length int64, ok bool) {
+	spec := strings.TrimPrefix(header, "bytes=")
+	if spec == header || strings.Contains(spec, ",") {
+		return 0, size, true
+	}
+	parts := strings.SplitN(spec, "-", 2)
+	if len(parts) != 2 {
+		return 0, size, true
+	}
+	start, errStart := strconv.ParseInt(parts[0], 10, 64)
+	end, errEnd := strconv.ParseInt(parts[1], 10, 64)
+	switch {
+	case parts[0] == "" && errEnd == nil && end > 0:
+		if end > size {
+			end = size
+		}
+		return size - end, end, size > 0
+	case errStart == nil && parts[1] == "":
+		return start, size - start, start < size
+	case errStart == nil && errEnd == nil && start <= end:
+		if end >= size {
+			end = size - 1
+		}
+		return start, end - start + 1, start < size
+	}
+	return 0, 0, false
+}
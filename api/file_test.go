@@ -0,0 +1,172 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"tabungan-api/storage"
+	"testing"
+)
+
+const pdfUji = "%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\ntrailer << /Root 1 0 R >>\n%%EOF\n"
+
+// storageGagal is a store that refuses every new blob.
+type storageGagal struct {
+	storage.Storage
+}
+
+func (storageGagal) Put(key string, data []byte, contentType string) error {
+	return errors.New("disk penuh")
+}
+
+// unggahUji registers a nasabah and sends a valid photo with doc to
+// /v1/file, returning the status of the upload.
+func unggahUji(t *testing.T, api *TabunganRESTAPI, doc string) int {
+	registrasiUji(t, api)
+
+	var foto bytes.Buffer
+	if err := png.Encode(&foto, image.NewGray(image.Rect(0, 0, 300, 300))); err != nil {
+		t.Fatal(err)
+	}
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("photo", "foto.png")
+	part.Write(foto.Bytes())
+	part, _ = form.CreateFormFile("doc", "ktp.pdf")
+	part.Write([]byte(doc))
+	form.Close()
+
+	req := httptest.NewRequest("POST", "/v1/file", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", nikUji)
+	resp, err := api.server.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode
+}
+
+func TestUploadFile(t *testing.T) {
+	api, repo := apiUji(t)
+	if status := unggahUji(t, api, pdfUji); status != http.StatusOK {
+		t.Fatalf("upload status = %d, want 200", status)
+	}
+	header := map[string]string{"Authorization": nikUji}
+	for _, path := range []string{"/v1/nasabah/foto", "/v1/nasabah/dokumen"} {
+		if status, _, _ := kirim(t, api, "GET", path, "", header); status != http.StatusOK {
+			t.Errorf("GET %s after the upload: status = %d, want 200", path, status)
+		}
+	}
+	file, err := repo.GetRiwayatFile(context.Background(), nikUji, "")
+	if err != nil || len(file) != 2 {
+		t.Errorf("riwayat file = %+v, %v; want the photo and the document", file, err)
+	}
+}
+
+// TestUploadFileValidatesBoth checks that a rejected document keeps the
+// photo sent with it from being stored.
+func TestUploadFileValidatesBoth(t *testing.T) {
+	api, _ := apiUji(t)
+	if status := unggahUji(t, api, "bukan pdf"); status != http.StatusBadRequest {
+		t.Errorf("upload with a bad document: status = %d, want 400", status)
+	}
+	if status, _, _ := kirim(t, api, "GET", "/v1/nasabah/foto", "", map[string]string{"Authorization": nikUji}); status != http.StatusNotFound {
+		t.Errorf("photo after the rejected upload: status = %d, want 404", status)
+	}
+}
+
+// TestUploadFileDokumenGagal checks that a document that cannot be saved
+// takes the photo stored before it back out, blob and history alike.
+func TestUploadFileDokumenGagal(t *testing.T) {
+	foto := storage.NewLocalStorage(t.TempDir())
+	api, repo := apiUjiStorage(t, foto, storageGagal{storage.NewLocalStorage(t.TempDir())})
+	if status := unggahUji(t, api, pdfUji); status != http.StatusBadRequest {
+		t.Errorf("upload with a failing document store: status = %d, want 400", status)
+	}
+	if status, _, _ := kirim(t, api, "GET", "/v1/nasabah/foto", "", map[string]string{"Authorization": nikUji}); status != http.StatusNotFound {
+		t.Errorf("photo after the failed upload: status = %d, want 404", status)
+	}
+	if blobs, err := foto.List(); err != nil || len(blobs) != 0 {
+		t.Errorf("photo store after the failed upload = %+v, %v; want empty", blobs, err)
+	}
+	if file, err := repo.GetRiwayatFile(context.Background(), nikUji, ""); err != nil || len(file) != 0 {
+		t.Errorf("riwayat file after the failed upload = %+v, %v; want empty", file, err)
+	}
+}
+
+// TestUnduhFile checks the conditional and range requests a download
+// answers.
+func TestUnduhFile(t *testing.T) {
+	api, _ := apiUji(t)
+	if status := unggahUji(t, api, pdfUji); status != http.StatusOK {
+		t.Fatalf("upload status = %d, want 200", status)
+	}
+	unduh := func(header map[string]string) (*http.Response, string) {
+		req := httptest.NewRequest("GET", "/v1/nasabah/dokumen", nil)
+		req.Header.Set("Authorization", nikUji)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		resp, err := api.server.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, string(body)
+	}
+	resp, body := unduh(nil)
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || body != pdfUji || etag == "" ||
+		resp.Header.Get("Content-Type") != "application/pdf" || resp.Header.Get("Accept-Ranges") != "bytes" {
+		t.Fatalf("download: status %d, headers %v, body %q", resp.StatusCode, resp.Header, body)
+	}
+	ukuran := len(pdfUji)
+
+	cases := []struct {
+		nama   string
+		header map[string]string
+		status int
+		// body and contentRange are empty when the response has none.
+		body, contentRange string
+	}{
+		{"matching If-None-Match", map[string]string{"If-None-Match": etag}, http.StatusNotModified, "", ""},
+		{"stale If-None-Match", map[string]string{"If-None-Match": `"lama"`}, http.StatusOK, pdfUji, ""},
+		{"first bytes", map[string]string{"Range": "bytes=0-3"}, http.StatusPartialContent, "%PDF",
+			fmt.Sprintf("bytes 0-3/%d", ukuran)},
+		{"open-ended range", map[string]string{"Range": fmt.Sprintf("bytes=%d-", ukuran-6)}, http.StatusPartialContent,
+			"%%EOF\n", fmt.Sprintf("bytes %d-%d/%d", ukuran-6, ukuran-1, ukuran)},
+		{"suffix range", map[string]string{"Range": "bytes=-6"}, http.StatusPartialContent, "%%EOF\n",
+			fmt.Sprintf("bytes %d-%d/%d", ukuran-6, ukuran-1, ukuran)},
+		{"range past the end is cut short", map[string]string{"Range": fmt.Sprintf("bytes=%d-%d", ukuran-1, ukuran+100)},
+			http.StatusPartialContent, "\n", fmt.Sprintf("bytes %d-%d/%d", ukuran-1, ukuran-1, ukuran)},
+		{"whole file as a range", map[string]string{"Range": "bytes=0-"}, http.StatusOK, pdfUji, ""},
+		{"range starting at the end", map[string]string{"Range": fmt.Sprintf("bytes=%d-", ukuran)},
+			http.StatusRequestedRangeNotSatisfiable, "Requested Range Not Satisfiable", fmt.Sprintf("bytes */%d", ukuran)},
+		{"reversed range", map[string]string{"Range": "bytes=5-3"}, http.StatusRequestedRangeNotSatisfiable,
+			"Requested Range Not Satisfiable", fmt.Sprintf("bytes */%d", ukuran)},
+		{"multiple ranges", map[string]string{"Range": "bytes=0-1,4-5"}, http.StatusOK, pdfUji, ""},
+		{"matching If-Range", map[string]string{"Range": "bytes=0-3", "If-Range": etag}, http.StatusPartialContent, "%PDF",
+			fmt.Sprintf("bytes 0-3/%d", ukuran)},
+		{"stale If-Range", map[string]string{"Range": "bytes=0-3", "If-Range": `"lama"`}, http.StatusOK, pdfUji, ""},
+	}
+	for _, c := range cases {
+		resp, body := unduh(c.header)
+		if resp.StatusCode != c.status || body != c.body || resp.Header.Get("Content-Range") != c.contentRange {
+			t.Errorf("%s: status %d, Content-Range %q, body %q; want %d, %q, %q", c.nama, resp.StatusCode,
+				resp.Header.Get("Content-Range"), body, c.status, c.contentRange, c.body)
+		}
+		if c.status != http.StatusRequestedRangeNotSatisfiable && resp.Header.Get("ETag") != etag {
+			t.Errorf("%s: ETag = %q, want %q", c.nama, resp.Header.Get("ETag"), etag)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"tabungan-api/tracing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("tabungan-api/api")
+
+// lacakRequest starts the span of every request, continuing the caller's
+// trace when it sent a traceparent header, and passes it on to the handler
+// as the user context. Like ukurRequest it names the span after the route
+// template, so that NIKs in paths stay out of traces.
+func (t *TabunganRESTAPI) lacakRequest(c *fiber.Ctx) (err error) {
+	method := utils.CopyString(c.Method())
+	ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headerFiber{c})
+	ctx, span := tracer.Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("http.method", method)))
+	c.SetUserContext(ctx)
+	err = c.Next()
+	route, status := routeDanStatus(c, err)
+	span.SetName(method + " " + route)
+	span.SetAttributes(attribute.String("http.route", route), attribute.Int("http.status_code", status))
+	if err == nil && status >= 500 {
+		span.SetStatus(codes.Error, "")
+	}
+	tracing.End(span, err)
+	return
+}
+
+// headerFiber reads and writes the request headers of c for propagators.
+type headerFiber struct {
+	c *fiber.Ctx
+}
+
+func (h headerFiber) Get(key string) string {
+	return utils.CopyString(h.c.Get(key))
+}
+
+func (h headerFiber) Set(key, value string) {
+	h.c.Request().Header.Set(key, value)
+}
+
+func (h headerFiber) Keys() (keys []string) {
+	h.c.Request().Header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"tabungan-api/app"
+	"tabungan-api/encryption"
+	"tabungan-api/repository"
+	"tabungan-api/storage"
+	"tabungan-api/tracing"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type spanBerkas struct {
+	Name        string
+	SpanContext struct{ TraceID, SpanID string }
+	Parent      struct{ TraceID, SpanID string }
+}
+
+func bacaSpan(t *testing.T, berkas string) (daftar []spanBerkas) {
+	f, err := os.Open(berkas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var span spanBerkas
+		if err := json.Unmarshal(scanner.Bytes(), &span); err != nil {
+			t.Fatalf("span is not valid JSON: %v", err)
+		}
+		daftar = append(daftar, span)
+	}
+	return
+}
+
+// TestTracePerRequest checks that a request is traced through the handler,
+// the app operation and its SQL statements, continuing the caller's trace,
+// and that log entries carry the trace ID.
+func TestTracePerRequest(t *testing.T) {
+	dir := t.TempDir()
+	berkasTrace := filepath.Join(dir, "trace.jsonl")
+	tutup, err := tracing.Setup(tracing.Config{ServiceName: "tabungan-api-test", File: berkasTrace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&log)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.AddHook(tracing.LogHook{})
+	keys, err := encryption.LoadKeyFile(filepath.Join(dir, "keys.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := repository.InitDatabase(filepath.Join(dir, "tabungan.db"), keys, logger)
+	defer repo.Close()
+	tabungan := app.NewTabunganApp(storage.NewLocalStorage(filepath.Join(dir, "photo")),
+		storage.NewLocalStorage(filepath.Join(dir, "document")), repo, logger)
+	api := NewRESTAPI("", 0, "", time.Time{}, "", tabungan, logger)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest("POST", "/v1/registrasi", strings.NewReader(`{"nik":"`+nikUji+`","nama":"Budi Santoso",
+		"alamat_ktp":"Jl A","alamat_domisili":"Jl A","jenis_kelamin":"L","tanggal_lahir":"1990-01-01"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	if resp, err := api.server.Test(req); err != nil || resp.StatusCode != 200 {
+		t.Fatalf("registrasi failed: %v %v", resp, err)
+	}
+	req = httptest.NewRequest("GET", "/v1/nasabah", nil)
+	req.Header.Set("Authorization", "0000000000000000")
+	if _, err := api.server.Test(req); err != nil {
+		t.Fatal(err)
+	}
+	if err := tutup(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	span := make(map[string]spanBerkas)
+	for _, s := range bacaSpan(t, berkasTrace) {
+		if _, ada := span[s.Name]; !ada {
+			span[s.Name] = s
+		}
+	}
+	handler, ok := span["POST /v1/registrasi"]
+	if !ok {
+		t.Fatalf("no span for the handler, got %v", span)
+	}
+	if handler.SpanContext.TraceID != traceID || handler.Parent.SpanID != "00f067aa0ba902b7" {
+		t.Errorf("handler span does not continue the caller's trace: %+v", handler)
+	}
+	operasi, ok := span["app.RegistrasiNasabah"]
+	if !ok || operasi.Parent.SpanID != handler.SpanContext.SpanID {
+		t.Errorf("app span is not a child of the handler span: %+v", operasi)
+	}
+	query, ok := span["sql.InsertNasabah"]
+	if !ok || query.SpanContext.TraceID != traceID {
+		t.Errorf("SQL span is not in the request's trace: %+v", query)
+	}
+
+	var ditemukan bool
+	for _, baris := range strings.Split(strings.TrimSpace(log.String()), "\n") {
+		var entry map[string]interface{}
+		if json.Unmarshal([]byte(baris), &entry) != nil || entry["msg"] != "nasabah tidak ditemukan" {
+			continue
+		}
+		ditemukan = true
+		if id, _ := entry["trace_id"].(string); len(id) != 32 || id == traceID {
+			t.Errorf("log entry has no trace ID of its own request: %v", entry)
+		}
+	}
+	if !ditemukan {
+		t.Errorf("expected a log entry for the unknown nasabah, got %s", log.String())
+	}
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// getHealthz reports whether the database and storage work.
+func (t *TabunganRESTAPI) getHealthz(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	hasil, err := t.app.CekKesehatan(c.UserContext())
+	response["data"] = hasil
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusServiceUnavailable)
+	}
+	return c.JSON(response)
+}
+
+// getReadyz is getHealthz that also fails once shutdown has started, so
+// that load balancers stop sending new requests while in-flight ones drain.
+func (t *TabunganRESTAPI) getReadyz(c *fiber.Ctx) (err error) {
+	if atomic.LoadInt32(&t.berhenti) == 1 {
+		c.Status(http.StatusServiceUnavailable)
+		return c.JSON(map[string]interface{}{"remark": "server sedang berhenti"})
+	}
+	return t.getHealthz(c)
+}
+
+// Shutdown fails readiness first and keeps serving for jedaSiap, so that
+// load balancers stop routing here before connections are refused. It then
+// stops accepting connections and waits up to the rest of timeout for the
+// requests in flight to finish.
+func (t *TabunganRESTAPI) Shutdown(jedaSiap, timeout time.Duration) (err error) {
+	atomic.StoreInt32(&t.berhenti, 1)
+	time.Sleep(jedaSiap)
+	timeout -= jedaSiap
+	selesai := make(chan error, 1)
+	go func() {
+		selesai <- t.server.Shutdown()
+	}()
+	select {
+	case err = <-selesai:
+	case <-time.After(timeout):
+		err = fmt.Errorf("request belum selesai setelah %s", timeout)
+	}
+	return
+}
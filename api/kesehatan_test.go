@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestShutdownReadiness checks that /readyz fails as soon as shutdown starts,
+// while requests are still served during the readiness delay.
+func TestShutdownReadiness(t *testing.T) {
+	api, _ := apiUji(t)
+	if status, _, _ := kirim(t, api, "GET", "/readyz", "", nil); status != http.StatusOK {
+		t.Fatalf("readyz before shutdown = %d", status)
+	}
+	mulai := time.Now()
+	selesai := make(chan struct{})
+	go func() {
+		api.Shutdown(200*time.Millisecond, time.Second)
+		close(selesai)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if status, _, _ := kirim(t, api, "GET", "/readyz", "", nil); status != http.StatusServiceUnavailable {
+		t.Errorf("readyz during the delay = %d, want 503", status)
+	}
+	if status, _, _ := kirim(t, api, "GET", "/healthz", "", nil); status != http.StatusOK {
+		t.Errorf("healthz during the delay = %d, want it still served", status)
+	}
+	<-selesai
+	if waktu := time.Since(mulai); waktu < 200*time.Millisecond {
+		t.Errorf("Shutdown returned after %s, before the readiness delay", waktu)
+	}
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"tabungan-api/app"
+	"tabungan-api/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (t *TabunganRESTAPI) getKYC(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	nik := c.Get("Authorization", "")
+	if nik == "" {
+		err = fmt.Errorf("missing NIK in authorization header")
+		t.log.WithContext(c.UserContext()).Warn(err.Error())
+		response["remark"] = err.Error()
+		c.Status(http.StatusUnauthorized)
+		return c.JSON(response)
+	}
+	kyc, err := t.app.GetKYC(c.UserContext(), nik)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = kyc
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) getDaftarKYC(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	daftar, err := t.app.GetDaftarKYC(c.UserContext(), c.Query("status", models.KYCSubmitted))
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = t.mask(c, daftar)
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) getKYCAdmin(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	nik := c.Params("nik", "")
+	kyc, err := t.app.GetKYC(c.UserContext(), nik)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	riwayat, err := t.app.GetRiwayatKYC(c.UserContext(), nik)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = t.mask(c, kyc)
+	response["riwayat"] = t.mask(c, riwayat)
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) verifikasiKYC(c *fiber.Ctx) (err error) {
+	return t.reviewKYC(c, app.TabunganAppInterface.VerifikasiKYC)
+}
+
+func (t *TabunganRESTAPI) tolakKYC(c *fiber.Ctx) (err error) {
+	return t.reviewKYC(c, app.TabunganAppInterface.TolakKYC)
+}
+
+type fungsiReviewKYC func(view app.TabunganAppInterface, ctx context.Context, nik string, petugas models.Petugas, request models.RequestReviewKYC) (models.KYC, error)
+
+func (t *TabunganRESTAPI) reviewKYC(c *fiber.Ctx, review fungsiReviewKYC) (err error) {
+	var request models.RequestReviewKYC
+	response := make(map[string]interface{})
+	if len(c.Body()) > 0 {
+		err = c.BodyParser(&request)
+		if err != nil {
+			t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("parse request body to JSON error")
+			response["remark"] = "Failed to parse request body"
+			c.Status(http.StatusBadRequest)
+			return c.JSON(response)
+		}
+	}
+	petugas := getPetugas(c)
+	kyc, err := review(t.appSebagai(c, petugas.ID, petugas.Role), c.UserContext(), c.Params("nik", ""), petugas, request)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = t.mask(c, kyc)
+	return c.JSON(response)
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"tabungan-api/app"
+	"tabungan-api/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (t *TabunganRESTAPI) buatLaporanTunai(c *fiber.Ctx) (err error) {
+	var request models.RequestLaporanTunai
+	response := make(map[string]interface{})
+	err = c.BodyParser(&request)
+	if err != nil {
+		t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("parse request body to JSON error")
+		response["remark"] = "Failed to parse request body"
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	petugas := getPetugas(c)
+	laporan, err := t.appSebagai(c, petugas.ID, petugas.Role).BuatLaporanTunai(c.UserContext(), petugas, request)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = laporan
+	c.Status(http.StatusCreated)
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) getDaftarLaporanTunai(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	daftar, err := t.app.GetDaftarLaporanTunai(c.UserContext(), c.Query("status", ""), c.Query("dari", ""), c.Query("sampai", ""))
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = daftar
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) getLaporanTunai(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	laporan, err := t.app.GetLaporanTunai(c.UserContext(), c.Params("laporan", ""))
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusNotFound)
+		return c.JSON(response)
+	}
+	response["data"] = laporan
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) getFileLaporanTunai(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	laporanID := c.Params("laporan", "")
+	blob, err := t.app.GetFileLaporanTunai(c.UserContext(), laporanID)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		if errors.Is(err, app.ErrFileTidakAda) {
+			c.Status(http.StatusNotFound)
+		}
+		return c.JSON(response)
+	}
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", blob.Key))
+	return t.kirimBlob(c, blob)
+}
+
+func (t *TabunganRESTAPI) kirimLaporanTunai(c *fiber.Ctx) (err error) {
+	return t.ubahStatusLaporan(c, app.TabunganAppInterface.KirimLaporanTunai)
+}
+
+func (t *TabunganRESTAPI) terimaLaporanTunai(c *fiber.Ctx) (err error) {
+	return t.ubahStatusLaporan(c, app.TabunganAppInterface.TerimaLaporanTunai)
+}
+
+func (t *TabunganRESTAPI) tolakLaporanTunai(c *fiber.Ctx) (err error) {
+	return t.ubahStatusLaporan(c, app.TabunganAppInterface.TolakLaporanTunai)
+}
+
+type fungsiStatusLaporan func(view app.TabunganAppInterface, ctx context.Context, laporanID string, petugas models.Petugas, request models.RequestStatusLaporan) (models.LaporanTunai, error)
+
+func (t *TabunganRESTAPI) ubahStatusLaporan(c *fiber.Ctx, ubah fungsiStatusLaporan) (err error) {
+	var request models.RequestStatusLaporan
+	response := make(map[string]interface{})
+	if len(c.Body()) > 0 {
+		err = c.BodyParser(&request)
+		if err != nil {
+			t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("parse request body to JSON error")
+			response["remark"] = "Failed to parse request body"
+			c.Status(http.StatusBadRequest)
+			return c.JSON(response)
+		}
+	}
+	petugas := getPetugas(c)
+	laporan, err := ubah(t.appSebagai(c, petugas.ID, petugas.Role), c.UserContext(), c.Params("laporan", ""), petugas, request)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = laporan
+	return c.JSON(response)
+}
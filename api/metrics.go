@@ -0,0 +1,64 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"tabungan-api/app"
+	"tabungan-api/metrics"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+var (
+	metrikRequest = metrics.NewCounter("tabungan_http_requests_total",
+		"HTTP requests, by method, route and status.", "method", "route", "status")
+	metrikDurasiRequest = metrics.NewHistogram("tabungan_http_request_duration_seconds",
+		"Time to serve HTTP requests, by method, route and status.", metrics.DefaultBuckets, "method", "route", "status")
+)
+
+// ukurRequest counts every request and its latency, and the error the app
+// answered it with. Requests are labelled with the route template rather
+// than the path, so that NIKs and IDs in the path do not each become a
+// series.
+func (t *TabunganRESTAPI) ukurRequest(c *fiber.Ctx) (err error) {
+	mulai := time.Now()
+	ctx, hitungError := app.HitungError(c.UserContext())
+	c.SetUserContext(ctx)
+	err = c.Next()
+	hitungError()
+	route, status := routeDanStatus(c, err)
+	// fiber reuses the buffer behind c.Method once the request is done.
+	label := []string{utils.CopyString(c.Method()), route, strconv.Itoa(status)}
+	metrikRequest.Inc(label...)
+	metrikDurasiRequest.Observe(time.Since(mulai).Seconds(), label...)
+	return
+}
+
+// routeDanStatus is the route template c was served by and the status it
+// was answered with, once err came back from the handler.
+func routeDanStatus(c *fiber.Ctx, err error) (route string, status int) {
+	route = c.Route().Path
+	if route == "/" && c.Path() != "/" {
+		// Only middleware matched.
+		route = "unmatched"
+	}
+	status = c.Response().StatusCode()
+	if err != nil {
+		status = http.StatusInternalServerError
+		var errFiber *fiber.Error
+		if errors.As(err, &errFiber) {
+			status = errFiber.Code
+		}
+	}
+	return
+}
+
+// getMetrics serves every metric in the Prometheus text format.
+func (t *TabunganRESTAPI) getMetrics(c *fiber.Ctx) (err error) {
+	c.Set(fiber.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+	_, err = metrics.Default.WriteTo(c)
+	return
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// totalError sums tabungan_error_domain_total over its kinds, as served by
+// /metrics.
+func totalError(t *testing.T, api *TabunganRESTAPI) (total float64, perJenis map[string]float64) {
+	resp, err := api.server.Test(httptest.NewRequest("GET", "/metrics", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	perJenis = make(map[string]float64)
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		series, nilai, ok := strings.Cut(scanner.Text(), " ")
+		if !ok || !strings.HasPrefix(series, "tabungan_error_domain_total{") {
+			continue
+		}
+		n, err := strconv.ParseFloat(nilai, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += n
+		perJenis[series] = n
+	}
+	return
+}
+
+// TestErrorDihitungSekali checks that an error the app answers a request
+// with is counted once, by the kind the client sees.
+func TestErrorDihitungSekali(t *testing.T) {
+	api, _ := apiUji(t)
+	noRekening, nasabah := registrasiUji(t, api)
+
+	cases := []struct {
+		nama, method, path, body, jenis string
+	}{
+		{"saldo tidak cukup", "POST", "/v1/tarik", `{"no_rekening":"` + noRekening + `","nominal":1000}`, "saldo_tidak_cukup"},
+		{"rekening tidak ada", "GET", "/v1/rekening/000000000000", "", "tidak_ditemukan"},
+	}
+	for _, c := range cases {
+		sebelum, perJenisSebelum := totalError(t, api)
+		if status, _, _ := kirim(t, api, c.method, c.path, c.body, nasabah); status != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400", c.nama, status)
+		}
+		sesudah, perJenis := totalError(t, api)
+		series := `tabungan_error_domain_total{jenis="` + c.jenis + `"}`
+		if sesudah-sebelum != 1 || perJenis[series]-perJenisSebelum[series] != 1 {
+			t.Errorf("%s: errors counted %v, %s counted %v; want 1 and 1", c.nama,
+				sesudah-sebelum, series, perJenis[series]-perJenisSebelum[series])
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"tabungan-api/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (t *TabunganRESTAPI) getPreferensiNotifikasi(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	nik := c.Get("Authorization", "")
+	if nik == "" {
+		err = fmt.Errorf("missing NIK in authorization header")
+		t.log.WithContext(c.UserContext()).Warn(err.Error())
+		response["remark"] = err.Error()
+		c.Status(http.StatusUnauthorized)
+		return c.JSON(response)
+	}
+	preferensi, err := t.app.GetPreferensiNotifikasi(c.UserContext(), nik)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = preferensi
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) ubahPreferensiNotifikasi(c *fiber.Ctx) (err error) {
+	var request models.RequestPreferensiNotifikasi
+	response := make(map[string]interface{})
+	nik := c.Get("Authorization", "")
+	if nik == "" {
+		err = fmt.Errorf("missing NIK in authorization header")
+		t.log.WithContext(c.UserContext()).Warn(err.Error())
+		response["remark"] = err.Error()
+		c.Status(http.StatusUnauthorized)
+		return c.JSON(response)
+	}
+	err = c.BodyParser(&request)
+	if err != nil {
+		t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("parse request body to JSON error")
+		response["remark"] = "Failed to parse request body"
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	preferensi, err := t.appSebagai(c, nik, models.RoleNasabah).UbahPreferensiNotifikasi(c.UserContext(), nik, request)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = preferensi
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) getDaftarNotifikasi(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	nik := c.Get("Authorization", "")
+	if nik == "" {
+		err = fmt.Errorf("missing NIK in authorization header")
+		t.log.WithContext(c.UserContext()).Warn(err.Error())
+		response["remark"] = err.Error()
+		c.Status(http.StatusUnauthorized)
+		return c.JSON(response)
+	}
+	daftar, err := t.app.GetDaftarNotifikasi(c.UserContext(), nik)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = daftar
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) getNotifikasiAdmin(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	nik := c.Params("nik", "")
+	preferensi, err := t.app.GetPreferensiNotifikasi(c.UserContext(), nik)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	daftar, err := t.app.GetDaftarNotifikasi(c.UserContext(), nik)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["preferensi"] = t.mask(c, preferensi)
+	response["data"] = t.mask(c, daftar)
+	return c.JSON(response)
+}
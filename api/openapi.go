@@ -0,0 +1,440 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"tabungan-api/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	authNasabah = "nasabah"
+	authPetugas = "petugas"
+
+	kontenJSON   = ""
+	kontenBinary = "binary"
+	kontenStream = "stream"
+)
+
+// dokumenRoute describes one route registered in routeV1. Path is relative
+// to /v1 and uses the fiber syntax; Body and Data are zero values of the request model and of
+// the "data" field of the response envelope.
+type dokumenRoute struct {
+	Method    string
+	Path      string
+	Tag       string
+	Ringkasan string
+	Auth      string
+	// Role lists the petugas roles allowed beyond authPetugas.
+	Role      []string
+	Query     []string
+	Body      interface{}
+	Multipart []string
+	Data      interface{}
+	// Tambahan holds envelope fields other than data and remark.
+	Tambahan map[string]interface{}
+	Status   int
+	Konten   string
+	// TanpaVersi marks operational routes served outside /v1.
+	TanpaVersi bool
+	// Persetujuan marks routes that answer 202 with the queued Operasi when
+	// the request needs petugas approval.
+	Persetujuan bool
+}
+
+var dokumentasiRoute = []dokumenRoute{
+	{Method: "GET", Path: "/healthz", Tag: "operasional", Ringkasan: "Whether the database answers and every storage accepts writes", Data: map[string]string{}, TanpaVersi: true},
+	{Method: "GET", Path: "/readyz", Tag: "operasional", Ringkasan: "As healthz, but failing once shutdown has started", Data: map[string]string{}, TanpaVersi: true},
+	{Method: "GET", Path: "/metrics", Tag: "operasional", Ringkasan: "Metrics in the Prometheus text format", Konten: kontenBinary, TanpaVersi: true},
+	{Method: "GET", Path: "/openapi.json", Tag: "docs", Ringkasan: "This OpenAPI document", Konten: kontenBinary},
+	{Method: "GET", Path: "/docs", Tag: "docs", Ringkasan: "Interactive API documentation, when DOCS_ASSETS_DIR is set", Konten: kontenBinary},
+	{Method: "GET", Path: "/docs/:file", Tag: "docs", Ringkasan: "Swagger UI " + swaggerUI + " assets of /docs", Konten: kontenBinary},
+
+	{Method: "POST", Path: "/registrasi", Tag: "nasabah", Ringkasan: "Register a nasabah and open their first rekening", Body: models.RequestRegistrasiNasabah{}, Data: models.Rekening{}},
+	{Method: "POST", Path: "/file", Tag: "file", Ringkasan: "Upload the photo and the identity document together", Auth: authNasabah, Multipart: []string{"photo", "doc"}},
+	{Method: "GET", Path: "/nasabah", Tag: "nasabah", Ringkasan: "Get the caller's data, optionally as it was at as_of", Auth: authNasabah, Query: []string{"as_of"}, Data: models.VersiNasabah{}},
+	{Method: "PUT", Path: "/nasabah", Tag: "nasabah", Ringkasan: "Update the caller's data; identity changes may need approval", Auth: authNasabah, Body: models.RequestUpdateNasabah{}, Persetujuan: true},
+	{Method: "GET", Path: "/nasabah/foto", Tag: "file", Ringkasan: "Download the caller's photo", Auth: authNasabah, Konten: kontenBinary},
+	{Method: "POST", Path: "/nasabah/foto", Tag: "file", Ringkasan: "Upload or replace the caller's photo", Auth: authNasabah, Multipart: []string{"photo"}},
+	{Method: "GET", Path: "/nasabah/foto/thumbnail", Tag: "file", Ringkasan: "Download the thumbnail of the caller's photo", Auth: authNasabah, Konten: kontenBinary},
+	{Method: "GET", Path: "/nasabah/dokumen", Tag: "file", Ringkasan: "Download the caller's identity document", Auth: authNasabah, Konten: kontenBinary},
+	{Method: "POST", Path: "/nasabah/dokumen", Tag: "file", Ringkasan: "Upload or replace the caller's identity document", Auth: authNasabah, Multipart: []string{"doc"}},
+	{Method: "GET", Path: "/nasabah/kyc", Tag: "kyc", Ringkasan: "Get the caller's KYC status", Auth: authNasabah, Data: models.KYC{}},
+	{Method: "GET", Path: "/nasabah/file", Tag: "file", Ringkasan: "List every photo and document the caller uploaded", Auth: authNasabah, Query: []string{"jenis"}, Data: []models.FileNasabah{}},
+	{Method: "GET", Path: "/nasabah/file/:file", Tag: "file", Ringkasan: "Download one uploaded file version", Auth: authNasabah, Konten: kontenBinary},
+	{Method: "GET", Path: "/nasabah/notifikasi", Tag: "notifikasi", Ringkasan: "List notifications sent to the caller", Auth: authNasabah, Data: []models.Notifikasi{}},
+	{Method: "GET", Path: "/nasabah/notifikasi/preferensi", Tag: "notifikasi", Ringkasan: "Get the caller's notification preferences", Auth: authNasabah, Data: models.PreferensiNotifikasi{}},
+	{Method: "PUT", Path: "/nasabah/notifikasi/preferensi", Tag: "notifikasi", Ringkasan: "Replace the caller's notification preferences", Auth: authNasabah, Body: models.RequestPreferensiNotifikasi{}, Data: models.PreferensiNotifikasi{}},
+	{Method: "GET", Path: "/rekening/list", Tag: "rekening", Ringkasan: "List the caller's rekening numbers", Auth: authNasabah, Data: []string{}},
+	{Method: "GET", Path: "/rekening/:rekening", Tag: "rekening", Ringkasan: "Get one of the caller's rekening", Auth: authNasabah, Data: models.Rekening{}},
+	{Method: "POST", Path: "/tarik", Tag: "transaksi", Ringkasan: "Withdraw; amounts above the approval limit are queued for a petugas", Auth: authNasabah, Body: models.RequestTarikSetorDana{}, Tambahan: map[string]interface{}{"saldo_akhir": float64(0)}, Persetujuan: true},
+	{Method: "POST", Path: "/setor", Tag: "transaksi", Ringkasan: "Deposit", Auth: authNasabah, Body: models.RequestTarikSetorDana{}, Tambahan: map[string]interface{}{"saldo_akhir": float64(0)}},
+	{Method: "GET", Path: "/mutasi/:rekening", Tag: "transaksi", Ringkasan: "List transactions of a rekening, newest first", Query: []string{"page", "show"}, Data: []models.Mutasi{}},
+	{Method: "GET", Path: "/stream/saldo", Tag: "transaksi", Ringkasan: "Server-sent events: a saldo event per rekening, then a mutasi event per transaction", Auth: authNasabah, Data: models.PembaruanSaldo{}, Konten: kontenStream},
+
+	{Method: "POST", Path: "/admin/operasi", Tag: "operasi", Ringkasan: "Submit a sensitive operation for approval", Auth: authPetugas, Body: models.RequestOperasi{}, Data: models.Operasi{}, Status: http.StatusAccepted},
+	{Method: "GET", Path: "/admin/operasi", Tag: "operasi", Ringkasan: "List operations", Auth: authPetugas, Query: []string{"status"}, Data: []models.Operasi{}},
+	{Method: "GET", Path: "/admin/operasi/:operasi", Tag: "operasi", Ringkasan: "Get an operation and its history", Auth: authPetugas, Data: models.Operasi{}, Tambahan: map[string]interface{}{"riwayat": []models.RiwayatOperasi{}}},
+	{Method: "POST", Path: "/admin/operasi/:operasi/approve", Tag: "operasi", Ringkasan: "Approve and execute an operation", Auth: authPetugas, Data: models.Operasi{}},
+	{Method: "POST", Path: "/admin/operasi/:operasi/reject", Tag: "operasi", Ringkasan: "Reject an operation", Auth: authPetugas, Body: models.RequestKeputusanOperasi{}, Data: models.Operasi{}},
+	{Method: "GET", Path: "/admin/audit", Tag: "audit", Ringkasan: "Search the audit trail", Auth: authPetugas, Role: []string{models.RoleAdmin}, Query: []string{"aktor", "aksi", "target", "dari", "sampai", "page", "show"}, Data: []models.Audit{}},
+	{Method: "GET", Path: "/admin/nasabah/:nik", Tag: "nasabah", Ringkasan: "Get a nasabah, optionally as it was at as_of", Auth: authPetugas, Query: []string{"as_of"}, Data: models.VersiNasabah{}},
+	{Method: "GET", Path: "/admin/nasabah/:nik/history", Tag: "nasabah", Ringkasan: "List every version of a nasabah", Auth: authPetugas, Data: []models.VersiNasabah{}},
+	{Method: "GET", Path: "/admin/nasabah/:nik/foto", Tag: "file", Ringkasan: "Download a nasabah's photo", Auth: authPetugas, Konten: kontenBinary},
+	{Method: "GET", Path: "/admin/nasabah/:nik/foto/thumbnail", Tag: "file", Ringkasan: "Download the thumbnail of a nasabah's photo", Auth: authPetugas, Konten: kontenBinary},
+	{Method: "GET", Path: "/admin/nasabah/:nik/dokumen", Tag: "file", Ringkasan: "Download a nasabah's identity document", Auth: authPetugas, Konten: kontenBinary},
+	{Method: "GET", Path: "/admin/kyc", Tag: "kyc", Ringkasan: "List KYC by status", Auth: authPetugas, Query: []string{"status"}, Data: []models.KYC{}},
+	{Method: "GET", Path: "/admin/nasabah/:nik/kyc", Tag: "kyc", Ringkasan: "Get a nasabah's KYC and its history", Auth: authPetugas, Data: models.KYC{}, Tambahan: map[string]interface{}{"riwayat": []models.KYC{}}},
+	{Method: "POST", Path: "/admin/nasabah/:nik/kyc/verify", Tag: "kyc", Ringkasan: "Verify the submitted document", Auth: authPetugas, Body: models.RequestReviewKYC{}, Data: models.KYC{}},
+	{Method: "POST", Path: "/admin/nasabah/:nik/kyc/reject", Tag: "kyc", Ringkasan: "Reject the submitted document", Auth: authPetugas, Body: models.RequestReviewKYC{}, Data: models.KYC{}},
+	{Method: "GET", Path: "/admin/duplikat", Tag: "duplikat", Ringkasan: "List potential duplicate nasabah", Auth: authPetugas, Query: []string{"status", "nik"}, Data: []models.Duplikat{}},
+	{Method: "POST", Path: "/admin/duplikat/:duplikat/confirm", Tag: "duplikat", Ringkasan: "Confirm a duplicate", Auth: authPetugas, Body: models.RequestKeputusanDuplikat{}, Data: models.Duplikat{}},
+	{Method: "POST", Path: "/admin/duplikat/:duplikat/dismiss", Tag: "duplikat", Ringkasan: "Dismiss a duplicate", Auth: authPetugas, Body: models.RequestKeputusanDuplikat{}, Data: models.Duplikat{}},
+	{Method: "GET", Path: "/admin/nasabah/:nik/duplikat", Tag: "duplikat", Ringkasan: "List duplicates involving a nasabah", Auth: authPetugas, Query: []string{"status"}, Data: []models.Duplikat{}},
+	{Method: "GET", Path: "/admin/screening", Tag: "screening", Ringkasan: "List watchlist hits", Auth: authPetugas, Query: []string{"status", "nik"}, Data: []models.HasilScreening{}},
+	{Method: "POST", Path: "/admin/screening/rescreen", Tag: "screening", Ringkasan: "Screen every nasabah against the current watchlist", Auth: authPetugas, Role: []string{models.RoleAdmin}, Data: map[string]int{}},
+	{Method: "POST", Path: "/admin/screening/:screening/confirm", Tag: "screening", Ringkasan: "Confirm a watchlist hit", Auth: authPetugas, Body: models.RequestKeputusanScreening{}, Data: models.HasilScreening{}},
+	{Method: "POST", Path: "/admin/screening/:screening/dismiss", Tag: "screening", Ringkasan: "Dismiss a watchlist hit", Auth: authPetugas, Body: models.RequestKeputusanScreening{}, Data: models.HasilScreening{}},
+	{Method: "GET", Path: "/admin/nasabah/:nik/screening", Tag: "screening", Ringkasan: "List watchlist hits of a nasabah", Auth: authPetugas, Query: []string{"status"}, Data: []models.HasilScreening{}},
+	{Method: "GET", Path: "/admin/aturan", Tag: "pemantauan", Ringkasan: "List transaction monitoring rules", Auth: authPetugas, Data: []models.AturanPemantauan{}},
+	{Method: "PUT", Path: "/admin/aturan/:aturan", Tag: "pemantauan", Ringkasan: "Change a monitoring rule", Auth: authPetugas, Body: models.RequestAturanPemantauan{}, Data: models.AturanPemantauan{}},
+	{Method: "GET", Path: "/admin/alert", Tag: "pemantauan", Ringkasan: "List transaction alerts", Auth: authPetugas, Query: []string{"status", "nik", "severity", "aturan"}, Data: []models.AlertTransaksi{}},
+	{Method: "GET", Path: "/admin/alert/:alert", Tag: "pemantauan", Ringkasan: "Get an alert", Auth: authPetugas, Data: models.AlertTransaksi{}},
+	{Method: "POST", Path: "/admin/alert/:alert/confirm", Tag: "pemantauan", Ringkasan: "Confirm an alert", Auth: authPetugas, Body: models.RequestKeputusanAlert{}, Data: models.AlertTransaksi{}},
+	{Method: "POST", Path: "/admin/alert/:alert/dismiss", Tag: "pemantauan", Ringkasan: "Dismiss an alert", Auth: authPetugas, Body: models.RequestKeputusanAlert{}, Data: models.AlertTransaksi{}},
+	{Method: "GET", Path: "/admin/nasabah/:nik/alert", Tag: "pemantauan", Ringkasan: "List alerts of a nasabah", Auth: authPetugas, Query: []string{"status", "severity", "aturan"}, Data: []models.AlertTransaksi{}},
+	{Method: "GET", Path: "/admin/laporan/tunai", Tag: "laporan", Ringkasan: "List cash transaction reports", Auth: authPetugas, Role: []string{models.RoleSupervisor, models.RoleAdmin}, Query: []string{"status", "dari", "sampai"}, Data: []models.LaporanTunai{}},
+	{Method: "POST", Path: "/admin/laporan/tunai", Tag: "laporan", Ringkasan: "Generate a cash transaction report", Auth: authPetugas, Role: []string{models.RoleSupervisor, models.RoleAdmin}, Body: models.RequestLaporanTunai{}, Data: models.LaporanTunai{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/admin/laporan/tunai/:laporan", Tag: "laporan", Ringkasan: "Get a report", Auth: authPetugas, Role: []string{models.RoleSupervisor, models.RoleAdmin}, Data: models.LaporanTunai{}},
+	{Method: "GET", Path: "/admin/laporan/tunai/:laporan/file", Tag: "laporan", Ringkasan: "Download a report file", Auth: authPetugas, Role: []string{models.RoleSupervisor, models.RoleAdmin}, Konten: kontenBinary},
+	{Method: "POST", Path: "/admin/laporan/tunai/:laporan/submit", Tag: "laporan", Ringkasan: "Record a report as submitted to the regulator", Auth: authPetugas, Role: []string{models.RoleSupervisor, models.RoleAdmin}, Body: models.RequestStatusLaporan{}, Data: models.LaporanTunai{}},
+	{Method: "POST", Path: "/admin/laporan/tunai/:laporan/accept", Tag: "laporan", Ringkasan: "Record a report as accepted", Auth: authPetugas, Role: []string{models.RoleSupervisor, models.RoleAdmin}, Body: models.RequestStatusLaporan{}, Data: models.LaporanTunai{}},
+	{Method: "POST", Path: "/admin/laporan/tunai/:laporan/reject", Tag: "laporan", Ringkasan: "Record a report as rejected", Auth: authPetugas, Role: []string{models.RoleSupervisor, models.RoleAdmin}, Body: models.RequestStatusLaporan{}, Data: models.LaporanTunai{}},
+	{Method: "GET", Path: "/admin/webhook", Tag: "webhook", Ringkasan: "List webhook subscriptions", Auth: authPetugas, Role: []string{models.RoleAdmin}, Data: []models.LanggananWebhook{}},
+	{Method: "POST", Path: "/admin/webhook", Tag: "webhook", Ringkasan: "Create a webhook subscription; the secret is only returned here", Auth: authPetugas, Role: []string{models.RoleAdmin}, Body: models.RequestLanggananWebhook{}, Data: models.LanggananWebhook{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/admin/webhook/pengiriman", Tag: "webhook", Ringkasan: "List webhook deliveries", Auth: authPetugas, Role: []string{models.RoleAdmin}, Query: []string{"langganan", "status"}, Data: []models.PengirimanWebhook{}},
+	{Method: "POST", Path: "/admin/webhook/pengiriman/:pengiriman/replay", Tag: "webhook", Ringkasan: "Send one delivery again", Auth: authPetugas, Role: []string{models.RoleAdmin}, Data: models.PengirimanWebhook{}},
+	{Method: "GET", Path: "/admin/webhook/:langganan", Tag: "webhook", Ringkasan: "Get a webhook subscription", Auth: authPetugas, Role: []string{models.RoleAdmin}, Data: models.LanggananWebhook{}},
+	{Method: "PUT", Path: "/admin/webhook/:langganan", Tag: "webhook", Ringkasan: "Replace a webhook subscription", Auth: authPetugas, Role: []string{models.RoleAdmin}, Body: models.RequestLanggananWebhook{}, Data: models.LanggananWebhook{}},
+	{Method: "GET", Path: "/admin/webhook/:langganan/pengiriman", Tag: "webhook", Ringkasan: "List deliveries of a subscription", Auth: authPetugas, Role: []string{models.RoleAdmin}, Query: []string{"status"}, Data: []models.PengirimanWebhook{}},
+	{Method: "POST", Path: "/admin/webhook/:langganan/replay", Tag: "webhook", Ringkasan: "Requeue every dead delivery of a subscription", Auth: authPetugas, Role: []string{models.RoleAdmin}, Data: map[string]int{}},
+	{Method: "GET", Path: "/admin/nasabah/:nik/file", Tag: "file", Ringkasan: "List every photo and document a nasabah uploaded", Auth: authPetugas, Query: []string{"jenis"}, Data: []models.FileNasabah{}},
+	{Method: "GET", Path: "/admin/nasabah/:nik/file/:file", Tag: "file", Ringkasan: "Download one uploaded file version of a nasabah", Auth: authPetugas, Konten: kontenBinary},
+	{Method: "GET", Path: "/admin/nasabah/:nik/notifikasi", Tag: "notifikasi", Ringkasan: "Get a nasabah's notification preferences and notifications", Auth: authPetugas, Data: []models.Notifikasi{}, Tambahan: map[string]interface{}{"preferensi": models.PreferensiNotifikasi{}}},
+}
+
+var polaParamRoute = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+// pathOpenAPI turns /rekening/:rekening into /rekening/{rekening}.
+func pathOpenAPI(path string) string {
+	return polaParamRoute.ReplaceAllString(path, "{$1}")
+}
+
+// spesifikasiOpenAPI builds the OpenAPI 3 document from dokumentasiRoute.
+// Schemas are derived from the models types, so they follow their JSON tags.
+func spesifikasiOpenAPI() map[string]interface{} {
+	skema := &registriSkema{skema: make(map[string]interface{})}
+	skema.skema["Error"] = map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"remark": map[string]interface{}{"type": "string"}},
+	}
+	paths := make(map[string]map[string]interface{})
+	for _, route := range dokumentasiRoute {
+		path := pathOpenAPI(route.Path)
+		if paths[path] == nil {
+			paths[path] = make(map[string]interface{})
+		}
+		paths[path][strings.ToLower(route.Method)] = skema.operasi(route)
+		if route.TanpaVersi {
+			paths[path]["servers"] = []interface{}{map[string]interface{}{"url": "/"}}
+		}
+	}
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Tabungan API",
+			"version": "1.0.0",
+			"description": "Every JSON response is an envelope holding the result in data, " +
+				"and a human readable remark on errors and pending approvals. " +
+				"The same paths without the /v1 prefix are deprecated aliases.",
+		},
+		"servers": []interface{}{map[string]interface{}{"url": versiV1}},
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas": skema.skema,
+			"securitySchemes": map[string]interface{}{
+				"nasabah": map[string]interface{}{
+					"type": "apiKey", "in": "header", "name": "Authorization",
+					"description": "The NIK of the calling nasabah.",
+				},
+				"petugasToken": map[string]interface{}{
+					"type": "apiKey", "in": "header", "name": "X-Petugas-Token",
+					"description": "Signed token naming the petugas and their role, issued with -issue-token.",
+				},
+			},
+		},
+	}
+}
+
+type registriSkema struct {
+	skema map[string]interface{}
+}
+
+func (r *registriSkema) operasi(route dokumenRoute) map[string]interface{} {
+	op := map[string]interface{}{
+		"tags":        []string{route.Tag},
+		"summary":     route.Ringkasan,
+		"operationId": strings.ToLower(route.Method) + strings.NewReplacer("/", "_", ":", "", ".", "_").Replace(route.Path),
+	}
+	var parameter []interface{}
+	for _, match := range polaParamRoute.FindAllStringSubmatch(route.Path, -1) {
+		parameter = append(parameter, map[string]interface{}{
+			"name": match[1], "in": "path", "required": true,
+			"schema": map[string]interface{}{"type": "string"},
+		})
+	}
+	for _, query := range route.Query {
+		parameter = append(parameter, map[string]interface{}{
+			"name": query, "in": "query",
+			"schema": map[string]interface{}{"type": "string"},
+		})
+	}
+	respons := map[string]interface{}{}
+	switch route.Auth {
+	case authNasabah:
+		op["security"] = []interface{}{map[string]interface{}{"nasabah": []string{}}}
+		respons["401"] = r.respons("Missing NIK", nil)
+	case authPetugas:
+		op["security"] = []interface{}{map[string]interface{}{"petugasToken": []string{}}}
+		respons["401"] = r.respons("Invalid or expired petugas token", nil)
+		if len(route.Role) > 0 {
+			op["description"] = "Only for petugas with role " + strings.Join(route.Role, " or ") + "."
+			respons["403"] = r.respons("Role not allowed", nil)
+		}
+	}
+	if len(parameter) > 0 {
+		op["parameters"] = parameter
+	}
+	switch {
+	case route.Body != nil:
+		op["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": r.tipe(reflect.TypeOf(route.Body))},
+			},
+		}
+	case len(route.Multipart) > 0:
+		properti := make(map[string]interface{})
+		for _, field := range route.Multipart {
+			properti[field] = map[string]interface{}{"type": "string", "format": "binary"}
+		}
+		op["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"multipart/form-data": map[string]interface{}{"schema": map[string]interface{}{
+					"type": "object", "properties": properti, "required": route.Multipart,
+				}},
+			},
+		}
+	}
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	switch {
+	case route.Konten == kontenBinary:
+		respons[fmt.Sprint(status)] = map[string]interface{}{"description": http.StatusText(status)}
+	case route.Data == nil && route.Tambahan == nil:
+		respons[fmt.Sprint(status)] = map[string]interface{}{"description": "Empty body"}
+	case route.Konten == kontenStream:
+		respons[fmt.Sprint(status)] = map[string]interface{}{
+			"description": "A text/event-stream whose data lines are JSON.",
+			"content": map[string]interface{}{
+				"text/event-stream": map[string]interface{}{"schema": r.tipe(reflect.TypeOf(route.Data))},
+			},
+		}
+	default:
+		respons[fmt.Sprint(status)] = r.respons(http.StatusText(status), r.envelope(route))
+	}
+	if route.Method != http.MethodGet || len(parameter) > 0 {
+		respons["400"] = r.respons("Invalid request or operation failed", nil)
+	}
+	if route.TanpaVersi && route.Konten == kontenJSON {
+		respons["503"] = r.respons("Not ready; data tells which check failed", r.envelope(route))
+	}
+	if route.Persetujuan {
+		respons["202"] = r.respons("Queued for petugas approval", r.envelope(dokumenRoute{Data: models.Operasi{}}))
+		respons["404"] = r.respons("Nasabah not found; nothing was done", nil)
+		respons["500"] = r.respons("Could not tell whether approval is needed; nothing was done", nil)
+	}
+	op["responses"] = respons
+	return op
+}
+
+func (r *registriSkema) envelope(route dokumenRoute) map[string]interface{} {
+	properti := map[string]interface{}{"remark": map[string]interface{}{"type": "string"}}
+	if route.Data != nil {
+		properti["data"] = r.tipe(reflect.TypeOf(route.Data))
+	}
+	for nama, nilai := range route.Tambahan {
+		properti[nama] = r.tipe(reflect.TypeOf(nilai))
+	}
+	return map[string]interface{}{"type": "object", "properties": properti}
+}
+
+// respons describes a JSON response; a nil schema is the error envelope.
+func (r *registriSkema) respons(deskripsi string, schema map[string]interface{}) map[string]interface{} {
+	if schema == nil {
+		schema = map[string]interface{}{"$ref": "#/components/schemas/Error"}
+	}
+	return map[string]interface{}{
+		"description": deskripsi,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": schema},
+		},
+	}
+}
+
+// tipe returns the schema of typ the way encoding/json marshals it. Named
+// structs become components referenced by name.
+func (r *registriSkema) tipe(typ reflect.Type) map[string]interface{} {
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return r.tipe(typ.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": r.tipe(typ.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": r.tipe(typ.Elem())}
+	case reflect.Struct:
+		if typ.Name() == "" {
+			return r.objek(typ)
+		}
+		if _, ok := r.skema[typ.Name()]; !ok {
+			r.skema[typ.Name()] = nil
+			r.skema[typ.Name()] = r.objek(typ)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + typ.Name()}
+	}
+	return map[string]interface{}{}
+}
+
+func (r *registriSkema) objek(typ reflect.Type) map[string]interface{} {
+	properti := make(map[string]interface{})
+	r.field(typ, properti)
+	return map[string]interface{}{"type": "object", "properties": properti}
+}
+
+// field collects the JSON fields of typ, flattening embedded structs.
+func (r *registriSkema) field(typ reflect.Type, properti map[string]interface{}) {
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		nama := strings.Split(tag, ",")[0]
+		if f.Anonymous && nama == "" && f.Type.Kind() == reflect.Struct {
+			r.field(f.Type, properti)
+			continue
+		}
+		if nama == "" {
+			nama = f.Name
+		}
+		properti[nama] = r.tipe(f.Type)
+	}
+}
+
+// routeTerdaftar lists "METHOD path" of every documented route, sorted.
+func routeTerdaftar() (daftar []string) {
+	for _, route := range dokumentasiRoute {
+		daftar = append(daftar, route.Method+" "+route.Path)
+	}
+	sort.Strings(daftar)
+	return
+}
+
+func (t *TabunganRESTAPI) getOpenAPI(c *fiber.Ctx) (err error) {
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(t.openapi)
+}
+
+// swaggerUI is the swagger-ui-dist release whose swagger-ui.css and
+// swagger-ui-bundle.js are expected in the docs assets directory. The page
+// loads nothing from third parties, so a compromised CDN cannot run script
+// next to the petugas token.
+const swaggerUI = "5.17.14"
+
+// asetDocs are the files of the docs assets directory /docs may load.
+var asetDocs = map[string]string{
+	"swagger-ui.css":       "text/css; charset=utf-8",
+	"swagger-ui-bundle.js": "text/javascript; charset=utf-8",
+}
+
+const skripDocs = `SwaggerUIBundle({url: "openapi.json", dom_id: "#swagger-ui"});`
+
+const halamanDocs = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Tabungan API</title>
+<link rel="stylesheet" href="docs/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="docs/swagger-ui-bundle.js"></script>
+<script>` + skripDocs + `</script>
+</body>
+</html>
+`
+
+// cspDocs lets the page run the two scripts above and nothing else.
+var cspDocs = func() string {
+	hash := sha256.Sum256([]byte(skripDocs))
+	return "default-src 'self'; img-src 'self' data:; style-src 'self' 'unsafe-inline'; " +
+		"script-src 'self' 'sha256-" + base64.StdEncoding.EncodeToString(hash[:]) + "'"
+}()
+
+// getDocs serves Swagger UI when DOCS_ASSETS_DIR holds its assets.
+func (t *TabunganRESTAPI) getDocs(c *fiber.Ctx) (err error) {
+	if t.dirDocs == "" {
+		c.Status(http.StatusNotFound)
+		return c.JSON(map[string]interface{}{"remark": "docs UI is not configured; openapi.json is still served"})
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	c.Set(fiber.HeaderContentSecurityPolicy, cspDocs)
+	return c.SendString(halamanDocs)
+}
+
+// getAsetDocs serves one of asetDocs from the docs assets directory.
+func (t *TabunganRESTAPI) getAsetDocs(c *fiber.Ctx) (err error) {
+	contentType, ok := asetDocs[c.Params("file")]
+	if t.dirDocs == "" || !ok {
+		return c.SendStatus(http.StatusNotFound)
+	}
+	data, err := os.ReadFile(filepath.Join(t.dirDocs, c.Params("file")))
+	if err != nil {
+		t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("read docs asset error")
+		return c.SendStatus(http.StatusNotFound)
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderCacheControl, "public, max-age=86400")
+	return c.Send(data)
+}
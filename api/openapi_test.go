@@ -0,0 +1,147 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// routeServer lists "METHOD path" of every handler registered on the fiber
+// app, with paths relative to /v1. Middleware registered with Use or Group
+// is copied into every method's stack, so it is recognised by also being in
+// the TRACE stack, which no handler uses.
+func routeServer(api *TabunganRESTAPI) (daftar []string) {
+	middleware := make(map[string]int)
+	handler := make(map[string]int)
+	for _, stack := range api.server.Stack() {
+		for _, route := range stack {
+			if route.Method == http.MethodTrace {
+				middleware[route.Path]++
+			}
+		}
+	}
+	for _, stack := range api.server.Stack() {
+		for _, route := range stack {
+			switch route.Method {
+			case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodPatch:
+				handler[route.Method+" "+route.Path]++
+			}
+		}
+	}
+	for key, jumlah := range handler {
+		path := key[strings.Index(key, " ")+1:]
+		if jumlah > middleware[path] {
+			daftar = append(daftar, strings.Replace(key, " "+versiV1, " ", 1))
+		}
+	}
+	sort.Strings(daftar)
+	return
+}
+
+func TestOpenAPIMencakupSemuaRoute(t *testing.T) {
+	api := NewRESTAPI("", 0, "", time.Time{}, "", nil, logrus.New())
+	terdokumentasi := make(map[string]bool)
+	for _, route := range routeTerdaftar() {
+		if terdokumentasi[route] {
+			t.Errorf("%s is documented twice", route)
+		}
+		terdokumentasi[route] = true
+	}
+	terdaftar := make(map[string]bool)
+	for _, route := range routeServer(api) {
+		terdaftar[route] = true
+		if !terdokumentasi[route] {
+			t.Errorf("%s is registered in routeV1 but missing from the OpenAPI document", route)
+		}
+	}
+	for route := range terdokumentasi {
+		if !terdaftar[route] {
+			t.Errorf("%s is in the OpenAPI document but not registered in routeV1", route)
+		}
+	}
+}
+
+func TestOpenAPIRefValid(t *testing.T) {
+	api := NewRESTAPI("", 0, "", time.Time{}, "", nil, logrus.New())
+	var spec map[string]interface{}
+	if err := json.Unmarshal(api.openapi, &spec); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+	skema := spec["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	var periksa func(v interface{})
+	periksa = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := v["$ref"].(string); ok {
+				nama := strings.TrimPrefix(ref, "#/components/schemas/")
+				if skema[nama] == nil {
+					t.Errorf("%s does not resolve", ref)
+				}
+			}
+			for _, isi := range v {
+				periksa(isi)
+			}
+		case []interface{}:
+			for _, isi := range v {
+				periksa(isi)
+			}
+		}
+	}
+	periksa(spec)
+}
+
+// TestDocsSameOrigin checks that /docs loads Swagger UI only from this
+// server, and only when its assets are configured.
+func TestDocsSameOrigin(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "swagger-ui.css"), []byte("body{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	api := NewRESTAPI("", 0, "", time.Time{}, dir, nil, logrus.New())
+	cases := []struct {
+		path   string
+		status int
+	}{
+		{"/v1/docs", http.StatusOK},
+		{"/v1/docs/swagger-ui.css", http.StatusOK},
+		{"/v1/docs/swagger-ui-bundle.js", http.StatusNotFound},
+		{"/v1/docs/..%2Fkeys.json", http.StatusNotFound},
+	}
+	for _, c := range cases {
+		resp, err := api.server.Test(httptest.NewRequest("GET", c.path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != c.status {
+			t.Errorf("GET %s = %d, want %d", c.path, resp.StatusCode, c.status)
+		}
+		if c.path != "/v1/docs" {
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if strings.Contains(string(body), "https://") {
+			t.Errorf("docs page loads from another origin:\n%s", body)
+		}
+		if csp := resp.Header.Get("Content-Security-Policy"); !strings.Contains(csp, "script-src 'self' 'sha256-") {
+			t.Errorf("Content-Security-Policy = %q", csp)
+		}
+	}
+
+	api = NewRESTAPI("", 0, "", time.Time{}, "", nil, logrus.New())
+	resp, err := api.server.Test(httptest.NewRequest("GET", "/v1/docs", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /v1/docs without assets = %d, want 404", resp.StatusCode)
+	}
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"tabungan-api/app"
+	"tabungan-api/models"
+	"testing"
+)
+
+// TestReversal checks that an approved reversal publishes the opposite
+// movement of funds like any other transaction.
+func TestReversal(t *testing.T) {
+	api, repo := apiUji(t)
+	noRekening, nasabah := registrasiUji(t, api)
+	if status, _, _ := kirim(t, api, "POST", "/v1/setor", `{"no_rekening":"`+noRekening+`","nominal":250000}`, nasabah); status != http.StatusOK {
+		t.Fatalf("setor status = %d", status)
+	}
+
+	resp, err := api.server.Test(httptest.NewRequest("GET", "/v1/mutasi/"+noRekening, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mutasi struct{ Data []models.Mutasi }
+	json.NewDecoder(resp.Body).Decode(&mutasi)
+	if len(mutasi.Data) != 1 {
+		t.Fatalf("mutasi = %+v", mutasi.Data)
+	}
+
+	teller := map[string]string{"X-Petugas-Token": tokenUji(t, rahasiaUji, "petugas-1", "teller")}
+	status, operasi, _ := kirim(t, api, "POST", "/v1/admin/operasi",
+		`{"jenis_operasi":"reversal","transaksi_id":"`+mutasi.Data[0].TransaksiID+`"}`, teller)
+	if status != http.StatusAccepted {
+		t.Fatalf("ajukan reversal status = %d", status)
+	}
+	supervisor := map[string]string{"X-Petugas-Token": tokenUji(t, rahasiaUji, "petugas-2", "supervisor")}
+	if status, _, _ = kirim(t, api, "POST", "/v1/admin/operasi/"+operasi["operasi_id"].(string)+"/approve", "", supervisor); status != http.StatusOK {
+		t.Fatalf("approve reversal status = %d", status)
+	}
+
+	outbox, err := repo.GetOutboxTertunda(context.Background(), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jenis []string
+	for _, event := range outbox {
+		if event.AggregateID == noRekening && (event.Jenis == models.EventDanaDisetor || event.Jenis == models.EventDanaDitarik) {
+			jenis = append(jenis, event.Jenis)
+		}
+	}
+	if len(jenis) != 2 || jenis[0] != models.EventDanaDisetor || jenis[1] != models.EventDanaDitarik {
+		t.Errorf("events of the rekening = %v, want the deposit and its reversal as %s", jenis, models.EventDanaDitarik)
+	}
+}
+
+// TestEventTanpaNIK checks that events, which are stored and published
+// unencrypted, identify a new nasabah by rekening and never carry the NIK.
+func TestEventTanpaNIK(t *testing.T) {
+	api, repo := apiUji(t)
+	noRekening, _ := registrasiUji(t, api)
+	outbox, err := repo.GetOutboxTertunda(context.Background(), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jenis []string
+	for _, event := range outbox {
+		if strings.Contains(event.AggregateID+event.Payload, nikUji) {
+			t.Errorf("%s carries the NIK: %+v", event.Jenis, event)
+		}
+		if event.AggregateID == noRekening {
+			jenis = append(jenis, event.Jenis)
+		}
+	}
+	if len(jenis) != 2 || jenis[0] != models.EventNasabahRegistered || jenis[1] != models.EventRekeningOpened {
+		t.Errorf("events of the rekening = %v, want %s then %s", jenis, models.EventNasabahRegistered, models.EventRekeningOpened)
+	}
+}
+
+// TestPerluPersetujuanGagal checks that a failed approval check is answered
+// by the kind of its failure rather than always as a server error.
+func TestPerluPersetujuanGagal(t *testing.T) {
+	api, _ := apiUji(t)
+	status, _, _ := kirim(t, api, "PUT", "/v1/nasabah", `{"nama":"Budi S","alamat_ktp":"Jl A","alamat_domisili":"Jl A"}`,
+		map[string]string{"Authorization": "3171019999999999"})
+	if status != http.StatusNotFound {
+		t.Errorf("update of an unknown nasabah: status = %d, want %d", status, http.StatusNotFound)
+	}
+
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{fmt.Errorf("nasabah tidak ditemukan: %w", app.ErrTidakDitemukan), http.StatusNotFound},
+		{fmt.Errorf("jenis operasi tidak dikenal: %w", app.ErrValidasi), http.StatusBadRequest},
+		{fmt.Errorf("query data nasabah gagal: %w", app.ErrInternal), http.StatusInternalServerError},
+		{errors.New("tanpa jenis"), http.StatusInternalServerError},
+	}
+	for _, c := range cases {
+		if got := statusPersetujuan(c.err); got != c.status {
+			t.Errorf("statusPersetujuan(%v) = %d, want %d", c.err, got, c.status)
+		}
+	}
+}
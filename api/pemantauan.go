@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"tabungan-api/app"
+	"tabungan-api/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (t *TabunganRESTAPI) getDaftarAturan(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	aturan, err := t.app.GetDaftarAturan(c.UserContext())
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = aturan
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) ubahAturan(c *fiber.Ctx) (err error) {
+	var request models.RequestAturanPemantauan
+	response := make(map[string]interface{})
+	err = c.BodyParser(&request)
+	if err != nil {
+		t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("parse request body to JSON error")
+		response["remark"] = "Failed to parse request body"
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	petugas := getPetugas(c)
+	aturan, err := t.appSebagai(c, petugas.ID, petugas.Role).UbahAturan(c.UserContext(), c.Params("aturan", ""), petugas, request)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = aturan
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) getDaftarAlert(c *fiber.Ctx) (err error) {
+	return t.daftarAlert(c, c.Query("status", models.AlertPending), c.Query("nik", ""))
+}
+
+func (t *TabunganRESTAPI) getAlertNasabah(c *fiber.Ctx) (err error) {
+	return t.daftarAlert(c, c.Query("status", ""), c.Params("nik", ""))
+}
+
+func (t *TabunganRESTAPI) daftarAlert(c *fiber.Ctx, status, nik string) (err error) {
+	response := make(map[string]interface{})
+	daftar, err := t.app.GetDaftarAlert(c.UserContext(), models.FilterAlert{
+		Status:   status,
+		Severity: c.Query("severity", ""),
+		AturanID: c.Query("aturan", ""),
+		NIK:      nik,
+	})
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = t.mask(c, daftar)
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) getAlert(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	alert, err := t.app.GetAlert(c.UserContext(), c.Params("alert", ""))
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusNotFound)
+		return c.JSON(response)
+	}
+	response["data"] = t.mask(c, alert)
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) konfirmasiAlert(c *fiber.Ctx) (err error) {
+	return t.putuskanAlert(c, app.TabunganAppInterface.KonfirmasiAlert)
+}
+
+func (t *TabunganRESTAPI) abaikanAlert(c *fiber.Ctx) (err error) {
+	return t.putuskanAlert(c, app.TabunganAppInterface.AbaikanAlert)
+}
+
+type fungsiKeputusanAlert func(view app.TabunganAppInterface, ctx context.Context, alertID string, petugas models.Petugas, request models.RequestKeputusanAlert) (models.AlertTransaksi, error)
+
+func (t *TabunganRESTAPI) putuskanAlert(c *fiber.Ctx, putuskan fungsiKeputusanAlert) (err error) {
+	var request models.RequestKeputusanAlert
+	response := make(map[string]interface{})
+	if len(c.Body()) > 0 {
+		err = c.BodyParser(&request)
+		if err != nil {
+			t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("parse request body to JSON error")
+			response["remark"] = "Failed to parse request body"
+			c.Status(http.StatusBadRequest)
+			return c.JSON(response)
+		}
+	}
+	petugas := getPetugas(c)
+	alert, err := putuskan(t.appSebagai(c, petugas.ID, petugas.Role), c.UserContext(), c.Params("alert", ""), petugas, request)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = t.mask(c, alert)
+	return c.JSON(response)
+}
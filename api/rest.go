@@ -1,22 +1,34 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 	"tabungan-api/app"
+	"tabungan-api/masking"
 	"tabungan-api/models"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/sirupsen/logrus"
 )
 
 type TabunganRESTAPI struct {
-	server *fiber.App
-	host   string
-	port   int
-	app    app.TabunganAppInterface
-	log    *logrus.Logger
+	server         *fiber.App
+	host           string
+	port           int
+	rahasiaPetugas []byte
+	masking        map[string]masking.Policy
+	app            app.TabunganAppInterface
+	log            *logrus.Logger
+	openapi        []byte
+	sunset         time.Time
+	// dirDocs holds the Swagger UI assets /docs loads; empty disables /docs.
+	dirDocs string
+	// berhenti is set to 1 once Shutdown is called.
+	berhenti int32
 }
 
 func (t *TabunganRESTAPI) registrasiNasabah(c *fiber.Ctx) (err error) {
@@ -24,12 +36,12 @@ func (t *TabunganRESTAPI) registrasiNasabah(c *fiber.Ctx) (err error) {
 	response := make(map[string]interface{})
 	err = c.BodyParser(&request)
 	if err != nil {
-		t.log.WithField("error", err.Error()).Error("parse request body to JSON error")
+		t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("parse request body to JSON error")
 		response["remark"] = "Failed to parse request body"
 		c.Status(http.StatusBadRequest)
 		return c.JSON(response)
 	}
-	rekening, err := t.app.RegistrasiNasabah(request)
+	rekening, err := t.appSebagai(c, request.NIK, models.RoleNasabah).RegistrasiNasabah(c.UserContext(), request)
 	if err != nil {
 		response["remark"] = err.Error()
 		c.Status(http.StatusBadRequest)
@@ -39,56 +51,53 @@ func (t *TabunganRESTAPI) registrasiNasabah(c *fiber.Ctx) (err error) {
 	return c.JSON(response)
 }
 
+// uploadFile accepts the photo and the document in one request. Both parts
+// must be present and pass validation before either is stored, so that a
+// rejected document does not leave a new photo behind.
 func (t *TabunganRESTAPI) uploadFile(c *fiber.Ctx) (err error) {
 	response := make(map[string]interface{})
 	nik := c.Get("Authorization", "")
 	if nik == "" {
 		err = fmt.Errorf("missing NIK in authorization header")
-		t.log.Warn(err.Error())
+		t.log.WithContext(c.UserContext()).Warn(err.Error())
 		response["remark"] = err.Error()
 		c.Status(http.StatusUnauthorized)
 		return c.JSON(response)
 	}
 	photo, err := c.FormFile("photo")
 	if err != nil {
-		t.log.WithField("error", err.Error()).Error("parse photo in multiform error")
+		t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("parse photo in multiform error")
 		response["remark"] = "Failed to read photo file in multiform"
 		c.Status(http.StatusBadRequest)
 		return c.JSON(response)
 	}
-	file, err := photo.Open()
-	if err != nil {
-		t.log.WithField("error", err.Error()).Error("parse photo in multiform error")
-		response["remark"] = "Failed to read photo file in multiform"
-		c.Status(http.StatusBadRequest)
-		return c.JSON(response)
-	}
-	err = t.app.SavePhoto(file, photo.Filename, nik)
+	doc, err := c.FormFile("doc")
 	if err != nil {
-		t.log.WithField("error", err.Error()).Error("save photo error")
-		response["remark"] = "Failed to save photo"
+		t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("parse doc in multiform error")
+		response["remark"] = "Failed to read doc file in multiform"
 		c.Status(http.StatusBadRequest)
 		return c.JSON(response)
 	}
-
-	doc, err := c.FormFile("doc")
+	foto, err := photo.Open()
 	if err != nil {
-		t.log.WithField("error", err.Error()).Error("parse doc in multiform error")
-		response["remark"] = "Failed to read doc file in multiform"
+		t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("parse photo in multiform error")
+		response["remark"] = "Failed to read photo file in multiform"
 		c.Status(http.StatusBadRequest)
 		return c.JSON(response)
 	}
-	file, err = doc.Open()
+	defer foto.Close()
+	dokumen, err := doc.Open()
 	if err != nil {
-		t.log.WithField("error", err.Error()).Error("parse doc in multiform error")
+		t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("parse doc in multiform error")
 		response["remark"] = "Failed to read doc file in multiform"
 		c.Status(http.StatusBadRequest)
 		return c.JSON(response)
 	}
-	err = t.app.SaveDoc(file, doc.Filename, nik)
+	defer dokumen.Close()
+	err = t.appSebagai(c, nik, models.RoleNasabah).SaveFotoDokumen(c.UserContext(), foto, photo.Filename, dokumen, doc.Filename, nik)
 	if err != nil {
-		t.log.WithField("error", err.Error()).Error("save doc error")
-		response["remark"] = "Failed to save doc"
+		t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("save photo and doc error")
+		response["remark"] = err.Error()
 		c.Status(http.StatusBadRequest)
 		return c.JSON(response)
 	}
@@ -100,12 +109,22 @@ func (t *TabunganRESTAPI) getNasabah(c *fiber.Ctx) (err error) {
 	nik := c.Get("Authorization", "")
 	if nik == "" {
 		err = fmt.Errorf("missing NIK in authorization header")
-		t.log.Warn(err.Error())
+		t.log.WithContext(c.UserContext()).Warn(err.Error())
 		response["remark"] = err.Error()
 		c.Status(http.StatusUnauthorized)
 		return c.JSON(response)
 	}
-	nasabah, err := t.app.GetNasabah(nik)
+	if asOf := c.Query("as_of", ""); asOf != "" {
+		nasabah, err := t.app.GetNasabahPerWaktu(c.UserContext(), nik, asOf)
+		if err != nil {
+			response["remark"] = err.Error()
+			c.Status(http.StatusBadRequest)
+			return c.JSON(response)
+		}
+		response["data"] = nasabah
+		return c.JSON(response)
+	}
+	nasabah, err := t.app.GetNasabah(c.UserContext(), nik)
 	if err != nil {
 		response["remark"] = err.Error()
 		c.Status(http.StatusBadRequest)
@@ -120,12 +139,12 @@ func (t *TabunganRESTAPI) getDaftarRekening(c *fiber.Ctx) (err error) {
 	nik := c.Get("Authorization", "")
 	if nik == "" {
 		err = fmt.Errorf("missing NIK in authorization header")
-		t.log.Warn(err.Error())
+		t.log.WithContext(c.UserContext()).Warn(err.Error())
 		response["remark"] = err.Error()
 		c.Status(http.StatusUnauthorized)
 		return c.JSON(response)
 	}
-	rekening, err := t.app.GetDaftarRekening(nik)
+	rekening, err := t.app.GetDaftarRekening(c.UserContext(), nik)
 	if err != nil {
 		response["remark"] = err.Error()
 		c.Status(http.StatusBadRequest)
@@ -140,7 +159,7 @@ func (t *TabunganRESTAPI) getRekening(c *fiber.Ctx) (err error) {
 	nik := c.Get("Authorization", "")
 	if nik == "" {
 		err = fmt.Errorf("missing NIK in authorization header")
-		t.log.Warn(err.Error())
+		t.log.WithContext(c.UserContext()).Warn(err.Error())
 		response["remark"] = err.Error()
 		c.Status(http.StatusUnauthorized)
 		return c.JSON(response)
@@ -148,12 +167,12 @@ func (t *TabunganRESTAPI) getRekening(c *fiber.Ctx) (err error) {
 	noRekening := c.Params("rekening", "")
 	if noRekening == "" {
 		err = fmt.Errorf("missing no-rekening in path parameter")
-		t.log.Warn(err.Error())
+		t.log.WithContext(c.UserContext()).Warn(err.Error())
 		response["remark"] = err.Error()
 		c.Status(http.StatusBadRequest)
 		return c.JSON(response)
 	}
-	rekening, err := t.app.GetRekening(nik, noRekening)
+	rekening, err := t.app.GetRekening(c.UserContext(), nik, noRekening)
 	if err != nil {
 		response["remark"] = err.Error()
 		c.Status(http.StatusBadRequest)
@@ -169,19 +188,34 @@ func (t *TabunganRESTAPI) tarikDana(c *fiber.Ctx) (err error) {
 	nik := c.Get("Authorization", "")
 	if nik == "" {
 		err = fmt.Errorf("missing NIK in authorization header")
-		t.log.Warn(err.Error())
+		t.log.WithContext(c.UserContext()).Warn(err.Error())
 		response["remark"] = err.Error()
 		c.Status(http.StatusUnauthorized)
 		return c.JSON(response)
 	}
 	err = c.BodyParser(&request)
 	if err != nil {
-		t.log.WithField("error", err.Error()).Error("parse request body to JSON error")
+		t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("parse request body to JSON error")
 		response["remark"] = "Failed to parse request body"
 		c.Status(http.StatusBadRequest)
 		return c.JSON(response)
 	}
-	saldoAkhir, err := t.app.TarikDana(nik, request.NoRekening, request.Nominal)
+	operasi := models.RequestOperasi{
+		JenisOperasi: models.OperasiTarikDana,
+		NIK:          nik,
+		NoRekening:   request.NoRekening,
+		Nominal:      request.Nominal,
+	}
+	perlu, err := t.app.PerluPersetujuan(c.UserContext(), operasi)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(statusPersetujuan(err))
+		return c.JSON(response)
+	}
+	if perlu {
+		return t.ajukanPersetujuan(c, nik, operasi)
+	}
+	saldoAkhir, err := t.appSebagai(c, nik, models.RoleNasabah).TarikDana(c.UserContext(), nik, request.NoRekening, request.Nominal)
 	if err != nil {
 		response["remark"] = err.Error()
 		c.Status(http.StatusBadRequest)
@@ -191,25 +225,39 @@ func (t *TabunganRESTAPI) tarikDana(c *fiber.Ctx) (err error) {
 	return c.JSON(response)
 }
 
+// statusPersetujuan is the status of a request whose PerluPersetujuan check
+// failed. The operation never runs directly then; only an internal failure is
+// reported as the server's fault.
+func statusPersetujuan(err error) int {
+	switch app.JenisError(err) {
+	case app.ErrTidakDitemukan:
+		return http.StatusNotFound
+	case app.ErrInternal:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
+
 func (t *TabunganRESTAPI) setorDana(c *fiber.Ctx) (err error) {
 	var request models.RequestTarikSetorDana
 	response := make(map[string]interface{})
 	nik := c.Get("Authorization", "")
 	if nik == "" {
 		err = fmt.Errorf("missing NIK in authorization header")
-		t.log.Warn(err.Error())
+		t.log.WithContext(c.UserContext()).Warn(err.Error())
 		response["remark"] = err.Error()
 		c.Status(http.StatusUnauthorized)
 		return c.JSON(response)
 	}
 	err = c.BodyParser(&request)
 	if err != nil {
-		t.log.WithField("error", err.Error()).Error("parse request body to JSON error")
+		t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("parse request body to JSON error")
 		response["remark"] = "Failed to parse request body"
 		c.Status(http.StatusBadRequest)
 		return c.JSON(response)
 	}
-	saldoAkhir, err := t.app.SetorDana(nik, request.NoRekening, request.Nominal)
+	saldoAkhir, err := t.appSebagai(c, nik, models.RoleNasabah).SetorDana(c.UserContext(), nik, request.NoRekening, request.Nominal)
 	if err != nil {
 		response["remark"] = err.Error()
 		c.Status(http.StatusBadRequest)
@@ -225,19 +273,35 @@ func (t *TabunganRESTAPI) updateNasabah(c *fiber.Ctx) (err error) {
 	nik := c.Get("Authorization", "")
 	if nik == "" {
 		err = fmt.Errorf("missing NIK in authorization header")
-		t.log.Warn(err.Error())
+		t.log.WithContext(c.UserContext()).Warn(err.Error())
 		response["remark"] = err.Error()
 		c.Status(http.StatusUnauthorized)
 		return c.JSON(response)
 	}
 	err = c.BodyParser(&request)
 	if err != nil {
-		t.log.WithField("error", err.Error()).Error("parse request body to JSON error")
+		t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("parse request body to JSON error")
 		response["remark"] = "Failed to parse request body"
 		c.Status(http.StatusBadRequest)
 		return c.JSON(response)
 	}
-	err = t.app.UpdateNasabah(nik, request)
+	operasi := models.RequestOperasi{
+		JenisOperasi:   models.OperasiUpdateNasabah,
+		NIK:            nik,
+		Nama:           request.Nama,
+		AlamatKTP:      request.AlamatKTP,
+		AlamatDomisili: request.AlamatDomisili,
+	}
+	perlu, err := t.app.PerluPersetujuan(c.UserContext(), operasi)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(statusPersetujuan(err))
+		return c.JSON(response)
+	}
+	if perlu {
+		return t.ajukanPersetujuan(c, nik, operasi)
+	}
+	err = t.appSebagai(c, nik, models.RoleNasabah).UpdateNasabah(c.UserContext(), nik, request)
 	if err != nil {
 		response["remark"] = err.Error()
 		c.Status(http.StatusBadRequest)
@@ -251,7 +315,7 @@ func (t *TabunganRESTAPI) getMutasi(c *fiber.Ctx) (err error) {
 	noRekening := c.Params("rekening", "")
 	if noRekening == "" {
 		err = fmt.Errorf("missing no-rekening in path parameter")
-		t.log.Warn(err.Error())
+		t.log.WithContext(c.UserContext()).Warn(err.Error())
 		response["remark"] = err.Error()
 		c.Status(http.StatusBadRequest)
 		return c.JSON(response)
@@ -259,7 +323,7 @@ func (t *TabunganRESTAPI) getMutasi(c *fiber.Ctx) (err error) {
 	page := c.Query("page", "1")
 	pageInt, err := strconv.Atoi(page)
 	if err != nil {
-		t.log.WithFields(logrus.Fields{
+		t.log.WithContext(c.UserContext()).WithFields(logrus.Fields{
 			"no_rekening": noRekening,
 			"page":        page,
 			"error":       err.Error(),
@@ -271,7 +335,7 @@ func (t *TabunganRESTAPI) getMutasi(c *fiber.Ctx) (err error) {
 	show := c.Query("show", "1")
 	showInt, err := strconv.Atoi(show)
 	if err != nil {
-		t.log.WithFields(logrus.Fields{
+		t.log.WithContext(c.UserContext()).WithFields(logrus.Fields{
 			"no_rekening": noRekening,
 			"show":        show,
 			"error":       err.Error(),
@@ -280,7 +344,7 @@ func (t *TabunganRESTAPI) getMutasi(c *fiber.Ctx) (err error) {
 		c.Status(http.StatusBadRequest)
 		return c.JSON(response)
 	}
-	mutasi, err := t.app.GetMutasi(noRekening, pageInt, showInt)
+	mutasi, err := t.app.GetMutasi(c.UserContext(), noRekening, pageInt, showInt)
 	if err != nil {
 		response["remark"] = err.Error()
 		c.Status(http.StatusBadRequest)
@@ -290,28 +354,135 @@ func (t *TabunganRESTAPI) getMutasi(c *fiber.Ctx) (err error) {
 	return c.JSON(response)
 }
 
-func (t *TabunganRESTAPI) Start() {
+// appSebagai returns the app bound to the caller of this request so that
+// mutations are attributed to them in the audit log.
+func (t *TabunganRESTAPI) appSebagai(c *fiber.Ctx, aktor, role string) app.TabunganAppInterface {
+	return t.app.Sebagai(models.MetadataRequest{
+		Aktor:     aktor,
+		Role:      role,
+		IP:        c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent, ""),
+		RequestID: c.GetRespHeader(fiber.HeaderXRequestID, ""),
+	})
+}
+
+// Start serves until Shutdown is called, which makes it return nil.
+func (t *TabunganRESTAPI) Start() (err error) {
 	addr := fmt.Sprintf("%s:%d", t.host, t.port)
-	t.server.Listen(addr)
+	return t.server.Listen(addr)
 }
 
-func NewRESTAPI(host string, port int, app app.TabunganAppInterface, logger *logrus.Logger) *TabunganRESTAPI {
-	server := fiber.New()
+func NewRESTAPI(host string, port int, rahasiaPetugas string, sunset time.Time, dirDocs string, app app.TabunganAppInterface, logger *logrus.Logger) *TabunganRESTAPI {
+	server := fiber.New(fiber.Config{
+		// Large enough for a photo and a document in one multipart request;
+		// the per-file limits are enforced by the app.
+		BodyLimit: 8 << 20,
+	})
 	api := &TabunganRESTAPI{
-		server: server,
-		host:   host,
-		port:   port,
-		app:    app,
-		log:    logger,
-	}
-	api.server.Post("/registrasi", api.registrasiNasabah)
-	api.server.Post("/file", api.uploadFile)
-	api.server.Get("/nasabah", api.getNasabah)
-	api.server.Put("/nasabah", api.updateNasabah)
-	api.server.Get("/rekening/list", api.getDaftarRekening)
-	api.server.Get("/rekening/:rekening", api.getRekening)
-	api.server.Post("/tarik", api.tarikDana)
-	api.server.Post("/setor", api.setorDana)
-	api.server.Get("/mutasi/:rekening", api.getMutasi)
+		server:         server,
+		host:           host,
+		port:           port,
+		rahasiaPetugas: []byte(rahasiaPetugas),
+		masking:        masking.DefaultResponsePolicies,
+		app:            app,
+		log:            logger,
+		sunset:         sunset,
+		dirDocs:        dirDocs,
+	}
+	api.openapi, _ = json.Marshal(spesifikasiOpenAPI())
+	api.server.Use(requestid.New())
+	api.server.Use(api.lacakRequest)
+	api.server.Use(api.ukurRequest)
+	// aliasTanpaVersi must come before any route: it rewrites the path and
+	// routing continues from its position in the stack.
+	api.server.Use(api.aliasTanpaVersi)
+	api.server.Get("/healthz", api.getHealthz)
+	api.server.Get("/readyz", api.getReadyz)
+	api.server.Get("/metrics", api.getMetrics)
+	// Every version registers its own handlers on its own group; they share
+	// the app, only the request and response shapes differ.
+	api.routeV1(api.server.Group(versiV1))
 	return api
 }
+
+// routeV1 registers the routes of /v1, which kept the shapes of the API from
+// before it was versioned.
+func (t *TabunganRESTAPI) routeV1(v1 fiber.Router) {
+	v1.Get("/openapi.json", t.getOpenAPI)
+	v1.Get("/docs", t.getDocs)
+	v1.Get("/docs/:file", t.getAsetDocs)
+	v1.Post("/registrasi", t.registrasiNasabah)
+	v1.Post("/file", t.uploadFile)
+	v1.Get("/nasabah", t.getNasabah)
+	v1.Put("/nasabah", t.updateNasabah)
+	v1.Get("/nasabah/foto", t.getFoto)
+	v1.Post("/nasabah/foto", t.uploadFoto)
+	v1.Get("/nasabah/foto/thumbnail", t.getThumbnail)
+	v1.Get("/nasabah/dokumen", t.getDokumen)
+	v1.Post("/nasabah/dokumen", t.uploadDokumen)
+	v1.Get("/nasabah/kyc", t.getKYC)
+	v1.Get("/nasabah/file", t.getRiwayatFile)
+	v1.Get("/nasabah/file/:file", t.getFileVersi)
+	v1.Get("/nasabah/notifikasi", t.getDaftarNotifikasi)
+	v1.Get("/nasabah/notifikasi/preferensi", t.getPreferensiNotifikasi)
+	v1.Put("/nasabah/notifikasi/preferensi", t.ubahPreferensiNotifikasi)
+	v1.Get("/rekening/list", t.getDaftarRekening)
+	v1.Get("/rekening/:rekening", t.getRekening)
+	v1.Post("/tarik", t.tarikDana)
+	v1.Post("/setor", t.setorDana)
+	v1.Get("/mutasi/:rekening", t.getMutasi)
+	v1.Get("/stream/saldo", t.streamSaldo)
+
+	admin := v1.Group("/admin", t.authPetugas)
+	admin.Post("/operasi", t.ajukanOperasi)
+	admin.Get("/operasi", t.getDaftarOperasi)
+	admin.Get("/operasi/:operasi", t.getOperasi)
+	admin.Post("/operasi/:operasi/approve", t.setujuiOperasi)
+	admin.Post("/operasi/:operasi/reject", t.tolakOperasi)
+	admin.Get("/audit", t.getDaftarAudit)
+	admin.Get("/nasabah/:nik", t.getNasabahAdmin)
+	admin.Get("/nasabah/:nik/history", t.getRiwayatNasabah)
+	admin.Get("/nasabah/:nik/foto", t.getFotoAdmin)
+	admin.Get("/nasabah/:nik/foto/thumbnail", t.getThumbnailAdmin)
+	admin.Get("/nasabah/:nik/dokumen", t.getDokumenAdmin)
+	admin.Get("/kyc", t.getDaftarKYC)
+	admin.Get("/nasabah/:nik/kyc", t.getKYCAdmin)
+	admin.Post("/nasabah/:nik/kyc/verify", t.verifikasiKYC)
+	admin.Post("/nasabah/:nik/kyc/reject", t.tolakKYC)
+	admin.Get("/duplikat", t.getDaftarDuplikat)
+	admin.Post("/duplikat/:duplikat/confirm", t.konfirmasiDuplikat)
+	admin.Post("/duplikat/:duplikat/dismiss", t.abaikanDuplikat)
+	admin.Get("/nasabah/:nik/duplikat", t.getDuplikatNasabah)
+	admin.Get("/screening", t.getDaftarScreening)
+	admin.Post("/screening/rescreen", t.rescreeningNasabah)
+	admin.Post("/screening/:screening/confirm", t.konfirmasiScreening)
+	admin.Post("/screening/:screening/dismiss", t.abaikanScreening)
+	admin.Get("/nasabah/:nik/screening", t.getScreeningNasabah)
+	admin.Get("/aturan", t.getDaftarAturan)
+	admin.Put("/aturan/:aturan", t.ubahAturan)
+	admin.Get("/alert", t.getDaftarAlert)
+	admin.Get("/alert/:alert", t.getAlert)
+	admin.Post("/alert/:alert/confirm", t.konfirmasiAlert)
+	admin.Post("/alert/:alert/dismiss", t.abaikanAlert)
+	admin.Get("/nasabah/:nik/alert", t.getAlertNasabah)
+	laporan := admin.Group("/laporan/tunai", t.wajibRole(models.RoleSupervisor, models.RoleAdmin))
+	laporan.Get("/", t.getDaftarLaporanTunai)
+	laporan.Post("/", t.buatLaporanTunai)
+	laporan.Get("/:laporan", t.getLaporanTunai)
+	laporan.Get("/:laporan/file", t.getFileLaporanTunai)
+	laporan.Post("/:laporan/submit", t.kirimLaporanTunai)
+	laporan.Post("/:laporan/accept", t.terimaLaporanTunai)
+	laporan.Post("/:laporan/reject", t.tolakLaporanTunai)
+	webhook := admin.Group("/webhook", t.wajibRole(models.RoleAdmin))
+	webhook.Get("/", t.getDaftarLanggananWebhook)
+	webhook.Post("/", t.buatLanggananWebhook)
+	webhook.Get("/pengiriman", t.getDaftarPengirimanWebhook)
+	webhook.Post("/pengiriman/:pengiriman/replay", t.ulangiPengirimanWebhook)
+	webhook.Get("/:langganan", t.getLanggananWebhook)
+	webhook.Put("/:langganan", t.ubahLanggananWebhook)
+	webhook.Get("/:langganan/pengiriman", t.getPengirimanLangganan)
+	webhook.Post("/:langganan/replay", t.ulangiWebhookGagal)
+	admin.Get("/nasabah/:nik/file", t.getRiwayatFileAdmin)
+	admin.Get("/nasabah/:nik/file/:file", t.getFileVersiAdmin)
+	admin.Get("/nasabah/:nik/notifikasi", t.getNotifikasiAdmin)
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"tabungan-api/app"
+	"tabungan-api/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (t *TabunganRESTAPI) getDaftarScreening(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	daftar, err := t.app.GetDaftarScreening(c.UserContext(), c.Query("status", models.ScreeningPending), c.Query("nik", ""))
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = t.mask(c, daftar)
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) getScreeningNasabah(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	daftar, err := t.app.GetDaftarScreening(c.UserContext(), c.Query("status", ""), c.Params("nik", ""))
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = t.mask(c, daftar)
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) konfirmasiScreening(c *fiber.Ctx) (err error) {
+	return t.putuskanScreening(c, app.TabunganAppInterface.KonfirmasiScreening)
+}
+
+func (t *TabunganRESTAPI) abaikanScreening(c *fiber.Ctx) (err error) {
+	return t.putuskanScreening(c, app.TabunganAppInterface.AbaikanScreening)
+}
+
+type fungsiKeputusanScreening func(view app.TabunganAppInterface, ctx context.Context, screeningID string, petugas models.Petugas, request models.RequestKeputusanScreening) (models.HasilScreening, error)
+
+func (t *TabunganRESTAPI) putuskanScreening(c *fiber.Ctx, putuskan fungsiKeputusanScreening) (err error) {
+	var request models.RequestKeputusanScreening
+	response := make(map[string]interface{})
+	if len(c.Body()) > 0 {
+		err = c.BodyParser(&request)
+		if err != nil {
+			t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("parse request body to JSON error")
+			response["remark"] = "Failed to parse request body"
+			c.Status(http.StatusBadRequest)
+			return c.JSON(response)
+		}
+	}
+	petugas := getPetugas(c)
+	hasil, err := putuskan(t.appSebagai(c, petugas.ID, petugas.Role), c.UserContext(), c.Params("screening", ""), petugas, request)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = t.mask(c, hasil)
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) rescreeningNasabah(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	petugas := getPetugas(c)
+	if petugas.Role != models.RoleAdmin {
+		err = fmt.Errorf("rescreening hanya dapat dijalankan admin")
+		t.log.WithContext(c.UserContext()).WithField("petugas", petugas.ID).Warn(err.Error())
+		response["remark"] = err.Error()
+		c.Status(http.StatusForbidden)
+		return c.JSON(response)
+	}
+	jumlahNasabah, jumlahHit, err := t.appSebagai(c, petugas.ID, petugas.Role).RescreeningNasabah(c.UserContext())
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = map[string]int{
+		"jumlah_nasabah": jumlahNasabah,
+		"jumlah_hit":     jumlahHit,
+	}
+	return c.JSON(response)
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"tabungan-api/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// intervalPingStream keeps idle streams open through proxies and notices
+// clients that went away.
+const intervalPingStream = 15 * time.Second
+
+// streamSaldo sends the caller's balances as server-sent events: one "saldo"
+// event per rekening when the stream opens, then a "mutasi" event for every
+// setor or tarik. A client that is dropped should reconnect; it receives a
+// fresh snapshot.
+func (t *TabunganRESTAPI) streamSaldo(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	nik := c.Get("Authorization", "")
+	if nik == "" {
+		err = fmt.Errorf("missing NIK in authorization header")
+		t.log.WithContext(c.UserContext()).Warn(err.Error())
+		response["remark"] = err.Error()
+		c.Status(http.StatusUnauthorized)
+		return c.JSON(response)
+	}
+	snapshot, stream, berhenti, err := t.app.StreamSaldo(c.UserContext(), nik)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+	c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
+		defer berhenti()
+		for _, saldo := range snapshot {
+			if tulisEventStream(w, "saldo", "", saldo) != nil {
+				return
+			}
+		}
+		ping := time.NewTicker(intervalPingStream)
+		defer ping.Stop()
+		for {
+			select {
+			case pembaruan, ok := <-stream:
+				if !ok {
+					return
+				}
+				if tulisEventStream(w, "mutasi", pembaruan.Mutasi.TransaksiID, pembaruan) != nil {
+					return
+				}
+			case <-ping.C:
+				if _, err := w.WriteString(": ping\n\n"); err != nil || w.Flush() != nil {
+					return
+				}
+			}
+		}
+	}))
+	return
+}
+
+func tulisEventStream(w *bufio.Writer, event, id string, data models.PembaruanSaldo) (err error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	if id != "" {
+		fmt.Fprintf(w, "id: %s\n", id)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, body)
+	return w.Flush()
+}
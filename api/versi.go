@@ -0,0 +1,39 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// versiV1 is the version unversioned paths are routed to. Unversioned paths
+// are the routes from before the API was versioned and are deprecated.
+const versiV1 = "/v1"
+
+var polaVersi = regexp.MustCompile(`^/v[0-9]+(/|$)`)
+
+// pathTanpaVersi are operational routes outside the versioned API.
+var pathTanpaVersi = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// aliasTanpaVersi serves a path without a version prefix from /v1 and tells
+// the client, with the Deprecation, Sunset and Link headers, to move to the
+// versioned path.
+func (t *TabunganRESTAPI) aliasTanpaVersi(c *fiber.Ctx) (err error) {
+	path := c.Path()
+	if polaVersi.MatchString(path) || pathTanpaVersi[path] {
+		return c.Next()
+	}
+	c.Set("Deprecation", "true")
+	if !t.sunset.IsZero() {
+		c.Set("Sunset", t.sunset.UTC().Format(http.TimeFormat))
+	}
+	c.Set(fiber.HeaderLink, fmt.Sprintf(`<%s%s>; rel="successor-version"`, versiV1, path))
+	c.Path(versiV1 + path)
+	return c.Next()
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAliasTanpaVersi checks that the paths from before versioning still
+// reach the /v1 handlers, and that only they are marked deprecated.
+func TestAliasTanpaVersi(t *testing.T) {
+	api, _ := apiUji(t)
+	api.sunset = time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	panggil := func(method, path, body string) (*http.Response, map[string]interface{}) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", nikUji)
+		resp, err := api.server.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		isi, _ := io.ReadAll(resp.Body)
+		var response map[string]interface{}
+		json.Unmarshal(isi, &response)
+		return resp, response
+	}
+
+	resp, _ := panggil("POST", "/registrasi", `{"nik":"`+nikUji+`","nama":"Budi Santoso",
+		"alamat_ktp":"Jl A","alamat_domisili":"Jl A","jenis_kelamin":"L","tanggal_lahir":"1990-01-01"}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("POST /registrasi status = %d, want 200", resp.StatusCode)
+	}
+
+	cases := []struct {
+		path   string
+		status int
+		// link is the successor the response points to; empty when the path
+		// is not deprecated.
+		link string
+	}{
+		{"/nasabah", http.StatusOK, `</v1/nasabah>; rel="successor-version"`},
+		{"/nasabah?as_of=2999-01-01", http.StatusOK, `</v1/nasabah>; rel="successor-version"`},
+		{"/rekening/list", http.StatusOK, `</v1/rekening/list>; rel="successor-version"`},
+		{"/v1/nasabah", http.StatusOK, ""},
+		{"/v1/nasabah?as_of=2999-01-01", http.StatusOK, ""},
+		{"/healthz", http.StatusOK, ""},
+		{"/metrics", http.StatusOK, ""},
+		{"/v2/nasabah", http.StatusNotFound, ""},
+		{"/v1", http.StatusNotFound, ""},
+	}
+	for _, c := range cases {
+		resp, response := panggil("GET", c.path, "")
+		if resp.StatusCode != c.status {
+			t.Errorf("GET %s: status = %d, want %d", c.path, resp.StatusCode, c.status)
+		}
+		deprecated := c.link != ""
+		if (resp.Header.Get("Deprecation") == "true") != deprecated || resp.Header.Get("Link") != c.link {
+			t.Errorf("GET %s: Deprecation %q, Link %q; want deprecated %v, Link %q", c.path,
+				resp.Header.Get("Deprecation"), resp.Header.Get("Link"), deprecated, c.link)
+		}
+		if sunset := resp.Header.Get("Sunset"); deprecated != (sunset == "Mon, 30 Jun 2025 00:00:00 GMT") {
+			t.Errorf("GET %s: Sunset = %q", c.path, sunset)
+		}
+		if c.status != http.StatusOK || !strings.Contains(c.path, "nasabah") {
+			continue
+		}
+		data, _ := response["data"].(map[string]interface{})
+		if data["nama"] != "Budi Santoso" {
+			t.Errorf("GET %s: data = %v", c.path, response)
+		}
+		if _, versi := data["versi"]; versi != strings.Contains(c.path, "as_of") {
+			t.Errorf("GET %s: the as_of query was not kept: data = %v", c.path, data)
+		}
+	}
+
+	api.sunset = time.Time{}
+	if resp, _ := panggil("GET", "/nasabah", ""); resp.Header.Get("Deprecation") != "true" || resp.Header.Get("Sunset") != "" {
+		t.Errorf("without a sunset date: Deprecation %q, Sunset %q; want true and no Sunset",
+			resp.Header.Get("Deprecation"), resp.Header.Get("Sunset"))
+	}
+}
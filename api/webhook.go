@@ -0,0 +1,125 @@
+package api
+
+import (
+	"net/http"
+	"tabungan-api/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (t *TabunganRESTAPI) buatLanggananWebhook(c *fiber.Ctx) (err error) {
+	var request models.RequestLanggananWebhook
+	response := make(map[string]interface{})
+	err = c.BodyParser(&request)
+	if err != nil {
+		t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("parse request body to JSON error")
+		response["remark"] = "Failed to parse request body"
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	petugas := getPetugas(c)
+	langganan, err := t.appSebagai(c, petugas.ID, petugas.Role).BuatLanggananWebhook(c.UserContext(), petugas, request)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = langganan
+	c.Status(http.StatusCreated)
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) ubahLanggananWebhook(c *fiber.Ctx) (err error) {
+	var request models.RequestLanggananWebhook
+	response := make(map[string]interface{})
+	err = c.BodyParser(&request)
+	if err != nil {
+		t.log.WithContext(c.UserContext()).WithField("error", err.Error()).Error("parse request body to JSON error")
+		response["remark"] = "Failed to parse request body"
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	petugas := getPetugas(c)
+	langganan, err := t.appSebagai(c, petugas.ID, petugas.Role).UbahLanggananWebhook(c.UserContext(), c.Params("langganan", ""), petugas, request)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = langganan
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) getDaftarLanggananWebhook(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	daftar, err := t.app.GetDaftarLanggananWebhook(c.UserContext())
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = daftar
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) getLanggananWebhook(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	langganan, err := t.app.GetLanggananWebhook(c.UserContext(), c.Params("langganan", ""))
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusNotFound)
+		return c.JSON(response)
+	}
+	response["data"] = langganan
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) getDaftarPengirimanWebhook(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	daftar, err := t.app.GetDaftarPengirimanWebhook(c.UserContext(), c.Query("langganan", ""), c.Query("status", ""))
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = daftar
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) getPengirimanLangganan(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	daftar, err := t.app.GetDaftarPengirimanWebhook(c.UserContext(), c.Params("langganan", ""), c.Query("status", ""))
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = daftar
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) ulangiPengirimanWebhook(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	petugas := getPetugas(c)
+	pengiriman, err := t.appSebagai(c, petugas.ID, petugas.Role).UlangiPengirimanWebhook(c.UserContext(), c.Params("pengiriman", ""), petugas)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = pengiriman
+	return c.JSON(response)
+}
+
+func (t *TabunganRESTAPI) ulangiWebhookGagal(c *fiber.Ctx) (err error) {
+	response := make(map[string]interface{})
+	petugas := getPetugas(c)
+	jumlah, err := t.appSebagai(c, petugas.ID, petugas.Role).UlangiWebhookGagal(c.UserContext(), c.Params("langganan", ""), petugas)
+	if err != nil {
+		response["remark"] = err.Error()
+		c.Status(http.StatusBadRequest)
+		return c.JSON(response)
+	}
+	response["data"] = map[string]int{"jumlah": jumlah}
+	return c.JSON(response)
+}
@@ -1,14 +1,20 @@
 package app
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
-	"os"
-	"path/filepath"
+	"net/http"
 	"strconv"
+	"tabungan-api/events"
 	"tabungan-api/models"
+	"tabungan-api/notification"
 	"tabungan-api/repository"
+	"tabungan-api/screening"
+	"tabungan-api/storage"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,33 +24,123 @@ import (
 )
 
 type TabunganAppInterface interface {
-	RegistrasiNasabah(request models.RequestRegistrasiNasabah) (rekening models.Rekening, err error)
-	UpdateNasabah(nik string, request models.RequestUpdateNasabah) (err error)
-	PembukaanRekening(tx *sqlx.Tx, nik string) (rekening models.Rekening, err error)
-	GetNasabah(nik string) (nasabah models.Nasabah, err error)
-	GetDaftarRekening(nik string) (rekening []string, err error)
-	GetRekening(nik, noRekening string) (rekening models.Rekening, err error)
-	GetMutasi(noRekening string, page, show int) (mutasi []models.Mutasi, err error)
-	TarikDana(nik, noRekening string, nominal float64) (saldoAkhir float64, err error)
-	SetorDana(nik, noRekening string, nominal float64) (saldoAkhir float64, err error)
-	SavePhoto(file io.Reader, filename, nik string) (err error)
-	SaveDoc(file io.Reader, filename, nik string) (err error)
+	RegistrasiNasabah(ctx context.Context, request models.RequestRegistrasiNasabah) (rekening models.Rekening, err error)
+	UpdateNasabah(ctx context.Context, nik string, request models.RequestUpdateNasabah) (err error)
+	PembukaanRekening(ctx context.Context, tx *sqlx.Tx, nik string) (rekening models.Rekening, err error)
+	GetNasabah(ctx context.Context, nik string) (nasabah models.Nasabah, err error)
+	GetDaftarRekening(ctx context.Context, nik string) (rekening []string, err error)
+	GetRekening(ctx context.Context, nik, noRekening string) (rekening models.Rekening, err error)
+	GetMutasi(ctx context.Context, noRekening string, page, show int) (mutasi []models.Mutasi, err error)
+	TarikDana(ctx context.Context, nik, noRekening string, nominal float64) (saldoAkhir float64, err error)
+	SetorDana(ctx context.Context, nik, noRekening string, nominal float64) (saldoAkhir float64, err error)
+	SavePhoto(ctx context.Context, file io.Reader, filename, nik string) (err error)
+	SaveDoc(ctx context.Context, file io.Reader, filename, nik string) (err error)
+	SaveFotoDokumen(ctx context.Context, foto io.Reader, namaFoto string, dokumen io.Reader, namaDokumen, nik string) (err error)
+	GetFoto(ctx context.Context, nik string) (blob storage.Blob, err error)
+	GetDokumen(ctx context.Context, nik string) (blob storage.Blob, err error)
+	GetThumbnail(ctx context.Context, nik string) (blob storage.Blob, err error)
+	GetRiwayatFile(ctx context.Context, nik, jenis string) (file []models.FileNasabah, err error)
+	GetFileVersi(ctx context.Context, nik, fileID string) (blob storage.Blob, err error)
+	BersihkanFileYatim(ctx context.Context, umurMinimal time.Duration) (jumlah int, err error)
+	PerluPersetujuan(ctx context.Context, request models.RequestOperasi) (perlu bool, err error)
+	AjukanOperasi(ctx context.Context, maker string, request models.RequestOperasi) (operasi models.Operasi, err error)
+	GetDaftarOperasi(ctx context.Context, status string) (operasi []models.Operasi, err error)
+	GetOperasi(ctx context.Context, operasiID string) (operasi models.Operasi, riwayat []models.RiwayatOperasi, err error)
+	SetujuiOperasi(ctx context.Context, operasiID string, checker models.Petugas) (operasi models.Operasi, err error)
+	TolakOperasi(ctx context.Context, operasiID string, checker models.Petugas, remark string) (operasi models.Operasi, err error)
+	Sebagai(meta models.MetadataRequest) TabunganAppInterface
+	GetDaftarAudit(ctx context.Context, filter models.FilterAudit) (audit []models.Audit, err error)
+	GetRiwayatNasabah(ctx context.Context, nik string) (riwayat []models.VersiNasabah, err error)
+	GetNasabahPerWaktu(ctx context.Context, nik, asOf string) (nasabah models.VersiNasabah, err error)
+	GetKYC(ctx context.Context, nik string) (kyc models.KYC, err error)
+	GetRiwayatKYC(ctx context.Context, nik string) (riwayat []models.KYC, err error)
+	GetDaftarKYC(ctx context.Context, status string) (daftar []models.KYC, err error)
+	VerifikasiKYC(ctx context.Context, nik string, petugas models.Petugas, request models.RequestReviewKYC) (kyc models.KYC, err error)
+	TolakKYC(ctx context.Context, nik string, petugas models.Petugas, request models.RequestReviewKYC) (kyc models.KYC, err error)
+	GetDaftarDuplikat(ctx context.Context, status, nik string) (daftar []models.Duplikat, err error)
+	KonfirmasiDuplikat(ctx context.Context, duplikatID string, petugas models.Petugas, request models.RequestKeputusanDuplikat) (duplikat models.Duplikat, err error)
+	AbaikanDuplikat(ctx context.Context, duplikatID string, petugas models.Petugas, request models.RequestKeputusanDuplikat) (duplikat models.Duplikat, err error)
+	GetDaftarScreening(ctx context.Context, status, nik string) (daftar []models.HasilScreening, err error)
+	KonfirmasiScreening(ctx context.Context, screeningID string, petugas models.Petugas, request models.RequestKeputusanScreening) (hasil models.HasilScreening, err error)
+	AbaikanScreening(ctx context.Context, screeningID string, petugas models.Petugas, request models.RequestKeputusanScreening) (hasil models.HasilScreening, err error)
+	RescreeningNasabah(ctx context.Context) (jumlahNasabah, jumlahHit int, err error)
+	GetDaftarAturan(ctx context.Context) (aturan []models.AturanPemantauan, err error)
+	UbahAturan(ctx context.Context, aturanID string, petugas models.Petugas, request models.RequestAturanPemantauan) (aturan models.AturanPemantauan, err error)
+	GetDaftarAlert(ctx context.Context, filter models.FilterAlert) (daftar []models.AlertTransaksi, err error)
+	GetAlert(ctx context.Context, alertID string) (alert models.AlertTransaksi, err error)
+	KonfirmasiAlert(ctx context.Context, alertID string, petugas models.Petugas, request models.RequestKeputusanAlert) (alert models.AlertTransaksi, err error)
+	AbaikanAlert(ctx context.Context, alertID string, petugas models.Petugas, request models.RequestKeputusanAlert) (alert models.AlertTransaksi, err error)
+	BuatLaporanTunai(ctx context.Context, petugas models.Petugas, request models.RequestLaporanTunai) (laporan models.LaporanTunai, err error)
+	GetDaftarLaporanTunai(ctx context.Context, status, dari, sampai string) (daftar []models.LaporanTunai, err error)
+	GetLaporanTunai(ctx context.Context, laporanID string) (laporan models.LaporanTunai, err error)
+	GetFileLaporanTunai(ctx context.Context, laporanID string) (blob storage.Blob, err error)
+	KirimLaporanTunai(ctx context.Context, laporanID string, petugas models.Petugas, request models.RequestStatusLaporan) (laporan models.LaporanTunai, err error)
+	TerimaLaporanTunai(ctx context.Context, laporanID string, petugas models.Petugas, request models.RequestStatusLaporan) (laporan models.LaporanTunai, err error)
+	TolakLaporanTunai(ctx context.Context, laporanID string, petugas models.Petugas, request models.RequestStatusLaporan) (laporan models.LaporanTunai, err error)
+	RelayEvent(ctx context.Context) (jumlah int, err error)
+	BuatLanggananWebhook(ctx context.Context, petugas models.Petugas, request models.RequestLanggananWebhook) (langganan models.LanggananWebhook, err error)
+	UbahLanggananWebhook(ctx context.Context, langgananID string, petugas models.Petugas, request models.RequestLanggananWebhook) (langganan models.LanggananWebhook, err error)
+	GetDaftarLanggananWebhook(ctx context.Context) (daftar []models.LanggananWebhook, err error)
+	GetLanggananWebhook(ctx context.Context, langgananID string) (langganan models.LanggananWebhook, err error)
+	KirimWebhook(ctx context.Context) (jumlah int, err error)
+	GetDaftarPengirimanWebhook(ctx context.Context, langgananID, status string) (daftar []models.PengirimanWebhook, err error)
+	UlangiPengirimanWebhook(ctx context.Context, pengirimanID string, petugas models.Petugas) (pengiriman models.PengirimanWebhook, err error)
+	UlangiWebhookGagal(ctx context.Context, langgananID string, petugas models.Petugas) (jumlah int, err error)
+	GetPreferensiNotifikasi(ctx context.Context, nik string) (preferensi models.PreferensiNotifikasi, err error)
+	UbahPreferensiNotifikasi(ctx context.Context, nik string, request models.RequestPreferensiNotifikasi) (preferensi models.PreferensiNotifikasi, err error)
+	GetDaftarNotifikasi(ctx context.Context, nik string) (daftar []models.Notifikasi, err error)
+	KirimNotifikasi(ctx context.Context) (jumlah int, err error)
+	StreamSaldo(ctx context.Context, nik string) (snapshot []models.PembaruanSaldo, stream <-chan models.PembaruanSaldo, berhenti func(), err error)
+	CekKesehatan(ctx context.Context) (hasil map[string]string, err error)
 }
 
 type TabunganApp struct {
-	repo     repository.TabunganRepoInterface
-	log      *logrus.Logger
-	photoDir string
-	docDir   string
+	repo       repository.TabunganRepoInterface
+	log        *logrus.Logger
+	foto       storage.Storage
+	dokumen    storage.Storage
+	batasTarik float64
+	// batasTarikBelumKYC caps withdrawals until the nasabah's KYC is verified.
+	batasTarikBelumKYC float64
+	daftarPantauan     *screening.Watchlist
+	laporan            storage.Storage
+	ambangLaporanTunai float64
+	sinkEvent          []events.Sink
+	webhook            *http.Client
+	// maksPercobaanWebhook attempts are made before a delivery is dead.
+	maksPercobaanWebhook int
+	jedaWebhook          time.Duration
+	templateNotifikasi   *notification.Templates
+	kanalNotifikasi      map[string]notification.Channel
+	// Setor and tarik below ambangNotifikasi are not notified.
+	ambangNotifikasi        float64
+	maksPercobaanNotifikasi int
+	jedaNotifikasi          time.Duration
+	stream                  *streamSaldo
+	antrianFoto             chan struct{}
+	meta                    models.MetadataRequest
 }
 
-func (t *TabunganApp) RegistrasiNasabah(request models.RequestRegistrasiNasabah) (rekening models.Rekening, err error) {
+type Option func(*TabunganApp)
+
+// WithBatasPersetujuan sets the withdrawal amount from which TarikDana has to
+// go through maker-checker approval. Zero disables the limit.
+func WithBatasPersetujuan(nominal float64) Option {
+	return func(t *TabunganApp) {
+		t.batasTarik = nominal
+	}
+}
+
+func (t *TabunganApp) RegistrasiNasabah(ctx context.Context, request models.RequestRegistrasiNasabah) (rekening models.Rekening, err error) {
+	ctx, span := t.mulaiSpan(ctx, "RegistrasiNasabah")
+	defer akhiriSpan(ctx, span, &err)
 	var nasabah models.Nasabah
 	copier.Copy(&nasabah, request)
-	tx, err := t.repo.StartTransaction()
+	rekening = models.Rekening{NIK: nasabah.NIK, NoRekening: genNoRekening()}
+	tx, err := t.repo.StartTransaction(ctx)
 	if err != nil {
-		err = fmt.Errorf("registrasi nasabah error")
-		t.log.WithFields(logrus.Fields{
+		err = errDomain(ErrInternal, "registrasi nasabah error")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"nik":             request.NIK,
 			"nama":            nasabah.Nama,
 			"alamat_ktp":      nasabah.AlamatKTP,
@@ -55,10 +151,15 @@ func (t *TabunganApp) RegistrasiNasabah(request models.RequestRegistrasiNasabah)
 		tx.Rollback()
 		return
 	}
-	err = t.repo.InsertNasabah(tx, nasabah)
+	err = t.repo.InsertNasabah(ctx, tx, nasabah)
+	if err == nil {
+		// The outbox is not encrypted, so the nasabah is identified by the
+		// rekening opened with them rather than by NIK.
+		err = t.tulisEvent(ctx, tx, models.EventNasabahRegistered, rekening.NoRekening, map[string]string{"no_rekening": rekening.NoRekening})
+	}
 	if err != nil {
-		err = fmt.Errorf("registrasi nasabah gagal")
-		t.log.WithFields(logrus.Fields{
+		err = errDomain(ErrInternal, "registrasi nasabah gagal")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"nik":             nasabah.NIK,
 			"nama":            nasabah.Nama,
 			"alamat_ktp":      nasabah.AlamatKTP,
@@ -69,10 +170,10 @@ func (t *TabunganApp) RegistrasiNasabah(request models.RequestRegistrasiNasabah)
 		tx.Rollback()
 		return
 	}
-	rekening, err = t.PembukaanRekening(tx, nasabah.NIK)
+	rekening, err = t.bukaRekening(ctx, tx, rekening)
 	if err != nil {
-		err = fmt.Errorf("registrasi nasabah gagal")
-		t.log.WithFields(logrus.Fields{
+		err = errDomain(ErrInternal, "registrasi nasabah gagal")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"nik":             nasabah.NIK,
 			"nama":            nasabah.Nama,
 			"alamat_ktp":      nasabah.AlamatKTP,
@@ -81,35 +182,85 @@ func (t *TabunganApp) RegistrasiNasabah(request models.RequestRegistrasiNasabah)
 			"tanggal_lahir":   nasabah.TanggalLahir,
 		}).Warn(err.Error())
 		tx.Rollback()
+		return
 	}
 	tx.Commit()
+	t.catatAudit(ctx, aksiRegistrasiNasabah, nasabah.NIK, nil, struct {
+		models.Nasabah
+		NoRekening string `json:"no_rekening"`
+	}{nasabah, rekening.NoRekening})
+	t.periksaDuplikat(ctx, nasabah)
+	t.screeningNasabah(ctx, nasabah, models.PemicuRegistrasi)
 	return
 }
 
-func (t *TabunganApp) UpdateNasabah(nik string, request models.RequestUpdateNasabah) (err error) {
+func (t *TabunganApp) UpdateNasabah(ctx context.Context, nik string, request models.RequestUpdateNasabah) (err error) {
+	ctx, span := t.mulaiSpan(ctx, "UpdateNasabah")
+	defer akhiriSpan(ctx, span, &err)
+	perlu, err := t.PerluPersetujuan(ctx, models.RequestOperasi{
+		JenisOperasi: models.OperasiUpdateNasabah,
+		NIK:          nik,
+		Nama:         request.Nama,
+		AlamatKTP:    request.AlamatKTP,
+	})
+	if err != nil {
+		return
+	}
+	if perlu {
+		err = errDomain(ErrPerluPersetujuan, "perubahan data identitas memerlukan persetujuan petugas")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
+		return
+	}
+	return t.updateNasabah(ctx, nik, request)
+}
+
+func (t *TabunganApp) updateNasabah(ctx context.Context, nik string, request models.RequestUpdateNasabah) (err error) {
 	request.NIK = nik
-	err = t.repo.UpdateNasabah(request)
+	nasabah, err := t.GetNasabah(ctx, nik)
+	if err != nil {
+		return
+	}
+	err = t.repo.UpdateNasabah(ctx, request)
 	if err != nil {
-		err = fmt.Errorf("update nasabah error")
-		t.log.WithFields(logrus.Fields{
+		err = errDomain(ErrInternal, "update nasabah error")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"nik":             nik,
 			"nama":            request.Nama,
 			"alamat_ktp":      request.AlamatKTP,
 			"alamat_domisili": request.AlamatDomisili,
 		}).Warn(err.Error())
+		return
 	}
+	t.catatAudit(ctx, aksiUpdateNasabah, nik, models.RequestUpdateNasabah{
+		NIK:            nik,
+		Nama:           nasabah.Nama,
+		AlamatKTP:      nasabah.AlamatKTP,
+		AlamatDomisili: nasabah.AlamatDomisili,
+	}, request)
+	t.notifikasiUpdateNasabah(ctx, nasabah, request)
+	nasabah.Nama = request.Nama
+	t.screeningNasabah(ctx, nasabah, models.PemicuUpdate)
 	return
 }
 
-func (t *TabunganApp) PembukaanRekening(tx *sqlx.Tx, nik string) (rekening models.Rekening, err error) {
-	rekening.NIK = nik
-	rekening.NoRekening = genNoRekening()
+func (t *TabunganApp) PembukaanRekening(ctx context.Context, tx *sqlx.Tx, nik string) (rekening models.Rekening, err error) {
+	return t.bukaRekening(ctx, tx, models.Rekening{NIK: nik, NoRekening: genNoRekening()})
+}
+
+// bukaRekening opens baru, whose number is already chosen.
+func (t *TabunganApp) bukaRekening(ctx context.Context, tx *sqlx.Tx, baru models.Rekening) (rekening models.Rekening, err error) {
+	ctx, span := t.mulaiSpan(ctx, "PembukaanRekening")
+	defer akhiriSpan(ctx, span, &err)
+	rekening = baru
 	rekening.Saldo = 0.0
-	err = t.repo.InsertRekening(tx, rekening)
+	err = t.repo.InsertRekening(ctx, tx, rekening)
+	if err == nil {
+		err = t.tulisEvent(ctx, tx, models.EventRekeningOpened, rekening.NoRekening, eventRekening{rekening.NoRekening, rekening.Saldo})
+	}
 	if err != nil {
-		err = fmt.Errorf("pembukaan rekening gagal")
-		t.log.WithFields(logrus.Fields{
-			"nik":         nik,
+		err = errDomain(ErrInternal, "pembukaan rekening gagal")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":         rekening.NIK,
 			"no_rekening": rekening.NoRekening,
 			"saldo":       rekening.Saldo,
 		}).Warn(err.Error())
@@ -117,33 +268,48 @@ func (t *TabunganApp) PembukaanRekening(tx *sqlx.Tx, nik string) (rekening model
 	return
 }
 
-func (t *TabunganApp) GetNasabah(nik string) (nasabah models.Nasabah, err error) {
-	nasabah, err = t.repo.GetNasabah(nik)
-	if err != nil {
-		err = fmt.Errorf("query data nasabah gagal")
-		t.log.WithFields(logrus.Fields{
+func (t *TabunganApp) GetNasabah(ctx context.Context, nik string) (nasabah models.Nasabah, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetNasabah")
+	defer akhiriSpan(ctx, span, &err)
+	nasabah, err = t.repo.GetNasabah(ctx, nik)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = errDomain(ErrTidakDitemukan, "nasabah tidak ditemukan")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
+	} else if err != nil {
+		err = errDomain(ErrInternal, "query data nasabah gagal")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"nik": nik,
 		}).Warn(err.Error())
 	}
 	return
 }
 
-func (t *TabunganApp) GetDaftarRekening(nik string) (rekening []string, err error) {
-	rekening, err = t.repo.GetDaftarRekening(nik)
+func (t *TabunganApp) GetDaftarRekening(ctx context.Context, nik string) (rekening []string, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetDaftarRekening")
+	defer akhiriSpan(ctx, span, &err)
+	rekening, err = t.repo.GetDaftarRekening(ctx, nik)
 	if err != nil {
-		err = fmt.Errorf("query daftar rekening gagal")
-		t.log.WithFields(logrus.Fields{
+		err = errDomain(ErrInternal, "query daftar rekening gagal")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"nik": nik,
 		}).Warn(err.Error())
 	}
 	return
 }
 
-func (t *TabunganApp) GetRekening(nik, noRekening string) (rekening models.Rekening, err error) {
-	rekening, err = t.repo.GetRekening(nik, noRekening)
-	if err != nil {
-		err = fmt.Errorf("query data rekening gagal")
-		t.log.WithFields(logrus.Fields{
+func (t *TabunganApp) GetRekening(ctx context.Context, nik, noRekening string) (rekening models.Rekening, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetRekening")
+	defer akhiriSpan(ctx, span, &err)
+	rekening, err = t.repo.GetRekening(ctx, nik, noRekening)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = errDomain(ErrTidakDitemukan, "rekening tidak ditemukan")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":         nik,
+			"no_rekening": noRekening,
+		}).Warn(err.Error())
+	} else if err != nil {
+		err = errDomain(ErrInternal, "query data rekening gagal")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"nik":         nik,
 			"no_rekening": noRekening,
 		}).Warn(err.Error())
@@ -151,12 +317,14 @@ func (t *TabunganApp) GetRekening(nik, noRekening string) (rekening models.Reken
 	return
 }
 
-func (t *TabunganApp) GetMutasi(noRekening string, page, show int) (mutasi []models.Mutasi, err error) {
+func (t *TabunganApp) GetMutasi(ctx context.Context, noRekening string, page, show int) (mutasi []models.Mutasi, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetMutasi")
+	defer akhiriSpan(ctx, span, &err)
 	offset := (page - 1) * show
-	mutasi, err = t.repo.GetMutasi(noRekening, show, offset)
+	mutasi, err = t.repo.GetMutasi(ctx, noRekening, show, offset)
 	if err != nil {
-		err = fmt.Errorf("query data mutasi gagal")
-		t.log.WithFields(logrus.Fields{
+		err = errDomain(ErrInternal, "query data mutasi gagal")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"no_rekening": noRekening,
 			"page":        page,
 			"show":        show,
@@ -165,25 +333,56 @@ func (t *TabunganApp) GetMutasi(noRekening string, page, show int) (mutasi []mod
 	return
 }
 
-func (t *TabunganApp) TarikDana(nik, noRekening string, nominal float64) (saldoAkhir float64, err error) {
-	tx, err := t.repo.StartTransaction()
+func (t *TabunganApp) TarikDana(ctx context.Context, nik, noRekening string, nominal float64) (saldoAkhir float64, err error) {
+	ctx, span := t.mulaiSpan(ctx, "TarikDana")
+	defer akhiriSpan(ctx, span, &err)
+	perlu, err := t.PerluPersetujuan(ctx, models.RequestOperasi{
+		JenisOperasi: models.OperasiTarikDana,
+		NIK:          nik,
+		NoRekening:   noRekening,
+		Nominal:      nominal,
+	})
+	if err != nil {
+		return
+	}
+	if perlu {
+		err = errDomain(ErrPerluPersetujuan, "penarikan di atas batas memerlukan persetujuan petugas")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"no_rekening": noRekening,
+			"nominal":     nominal,
+		}).Warn(err.Error())
+		return
+	}
+	return t.tarikDana(ctx, nik, noRekening, nominal)
+}
+
+func (t *TabunganApp) tarikDana(ctx context.Context, nik, noRekening string, nominal float64) (saldoAkhir float64, err error) {
+	tx, err := t.repo.StartTransaction(ctx)
 	if err != nil {
-		err = fmt.Errorf("tarik dana nasabah error")
-		t.log.WithFields(logrus.Fields{
+		err = errDomain(ErrInternal, "tarik dana nasabah error")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"no_rekening": noRekening,
 			"nominal":     nominal,
 		}).Warn(err.Error())
 		tx.Rollback()
 		return
 	}
-	rekening, err := t.GetRekening(nik, noRekening)
+	rekening, err := t.GetRekening(ctx, nik, noRekening)
 	if err != nil {
 		tx.Rollback()
 		return
 	}
+	if err = t.cekScreeningTransaksi(ctx, nik); err != nil {
+		tx.Rollback()
+		return
+	}
+	if err = t.cekBatasKYC(ctx, nik, nominal); err != nil {
+		tx.Rollback()
+		return
+	}
 	if nominal > rekening.Saldo {
-		err = fmt.Errorf("saldo tidak mencukupi")
-		t.log.WithFields(logrus.Fields{
+		err = errDomain(ErrSaldoTidakCukup, "saldo tidak mencukupi")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"no_rekening": noRekening,
 			"saldo":       rekening.Saldo,
 			"nominal":     nominal,
@@ -192,10 +391,10 @@ func (t *TabunganApp) TarikDana(nik, noRekening string, nominal float64) (saldoA
 		return
 	}
 	saldoAkhir = rekening.Saldo - nominal
-	err = t.repo.UpdateSaldo(tx, noRekening, -nominal)
+	err = t.repo.UpdateSaldo(ctx, tx, noRekening, -nominal)
 	if err != nil {
-		err = fmt.Errorf("tarik dana rekening error")
-		t.log.WithFields(logrus.Fields{
+		err = errDomain(ErrInternal, "tarik dana rekening error")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"no_rekening": noRekening,
 			"saldo":       rekening.Saldo,
 			"nominal":     nominal,
@@ -203,35 +402,50 @@ func (t *TabunganApp) TarikDana(nik, noRekening string, nominal float64) (saldoA
 		tx.Rollback()
 		return
 	}
-	err = t.insertMutasi(tx, noRekening, "D", nominal, rekening.Saldo, saldoAkhir)
+	mutasi, err := t.insertMutasi(ctx, tx, noRekening, "D", nominal, rekening.Saldo, saldoAkhir)
+	if err == nil {
+		err = t.tulisEvent(ctx, tx, models.EventDanaDitarik, noRekening, mutasi)
+	}
 	if err != nil {
 		tx.Rollback()
+		return
 	}
 	tx.Commit()
+	hitungTransaksi(mutasi)
+	t.catatAudit(ctx, aksiTarikDana, noRekening, rekening, models.Rekening{NIK: nik, NoRekening: noRekening, Saldo: saldoAkhir})
+	t.kirimSaldo(nik, mutasi)
+	t.notifikasiTransaksi(ctx, nik, mutasi)
+	t.pantauTransaksi(ctx, nik, mutasi)
 	return
 }
 
-func (t *TabunganApp) SetorDana(nik, noRekening string, nominal float64) (saldoAkhir float64, err error) {
-	tx, err := t.repo.StartTransaction()
+func (t *TabunganApp) SetorDana(ctx context.Context, nik, noRekening string, nominal float64) (saldoAkhir float64, err error) {
+	ctx, span := t.mulaiSpan(ctx, "SetorDana")
+	defer akhiriSpan(ctx, span, &err)
+	tx, err := t.repo.StartTransaction(ctx)
 	if err != nil {
-		err = fmt.Errorf("setor dana nasabah error")
-		t.log.WithFields(logrus.Fields{
+		err = errDomain(ErrInternal, "setor dana nasabah error")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"no_rekening": noRekening,
 			"nominal":     nominal,
 		}).Warn(err.Error())
 		tx.Rollback()
 		return
 	}
-	rekening, err := t.GetRekening(nik, noRekening)
+	rekening, err := t.GetRekening(ctx, nik, noRekening)
 	if err != nil {
 		tx.Rollback()
 		return
 	}
+	if err = t.cekScreeningTransaksi(ctx, nik); err != nil {
+		tx.Rollback()
+		return
+	}
 	saldoAkhir = rekening.Saldo + nominal
-	err = t.repo.UpdateSaldo(tx, noRekening, nominal)
+	err = t.repo.UpdateSaldo(ctx, tx, noRekening, nominal)
 	if err != nil {
-		err = fmt.Errorf("tarik dana rekening error")
-		t.log.WithFields(logrus.Fields{
+		err = errDomain(ErrInternal, "tarik dana rekening error")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"no_rekening": noRekening,
 			"saldo":       rekening.Saldo,
 			"nominal":     nominal,
@@ -239,52 +453,177 @@ func (t *TabunganApp) SetorDana(nik, noRekening string, nominal float64) (saldoA
 		tx.Rollback()
 		return
 	}
-	err = t.insertMutasi(tx, noRekening, "C", nominal, rekening.Saldo, saldoAkhir)
+	mutasi, err := t.insertMutasi(ctx, tx, noRekening, "C", nominal, rekening.Saldo, saldoAkhir)
+	if err == nil {
+		err = t.tulisEvent(ctx, tx, models.EventDanaDisetor, noRekening, mutasi)
+	}
 	if err != nil {
 		tx.Rollback()
+		return
 	}
 	tx.Commit()
+	hitungTransaksi(mutasi)
+	t.catatAudit(ctx, aksiSetorDana, noRekening, rekening, models.Rekening{NIK: nik, NoRekening: noRekening, Saldo: saldoAkhir})
+	t.kirimSaldo(nik, mutasi)
+	t.notifikasiTransaksi(ctx, nik, mutasi)
+	t.pantauTransaksi(ctx, nik, mutasi)
 	return
 }
 
-func (t *TabunganApp) SavePhoto(file io.Reader, filename, nik string) (err error) {
-	id, err := t.saveFile(file, t.photoDir, filename)
+func (t *TabunganApp) SavePhoto(ctx context.Context, file io.Reader, filename, nik string) (err error) {
+	ctx, span := t.mulaiSpan(ctx, "SavePhoto")
+	defer akhiriSpan(ctx, span, &err)
+	nasabah, err := t.GetNasabah(ctx, nik)
 	if err != nil {
-		err = fmt.Errorf("failed to save photo")
-		t.log.WithField("nik", nik).Warn(err.Error())
 		return
 	}
-	err = t.repo.SaveFoto(nik, id)
+	id, hash, err := t.simpanFoto(ctx, file, filename, nik)
 	if err != nil {
-		err = fmt.Errorf("failed to update photoID in database")
-		t.log.WithFields(logrus.Fields{
+		return
+	}
+	err = t.repo.SaveFoto(ctx, nik, id)
+	if err != nil {
+		err = errDomain(ErrInternal, "failed to update photoID in database")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"nik":     nik,
 			"photoID": id,
 		}).Warn(err.Error())
+		t.hapusFoto(ctx, id)
+		return
 	}
+	t.catatAudit(ctx, aksiSimpanFoto, nik, map[string]string{"foto_id": nasabah.FotoID}, map[string]string{"foto_id": id})
+	t.periksaFotoDuplikat(ctx, nik, hash)
 	return
 }
 
-func (t *TabunganApp) SaveDoc(file io.Reader, filename, nik string) (err error) {
-	id, err := t.saveFile(file, t.docDir, filename)
+func (t *TabunganApp) SaveDoc(ctx context.Context, file io.Reader, filename, nik string) (err error) {
+	ctx, span := t.mulaiSpan(ctx, "SaveDoc")
+	defer akhiriSpan(ctx, span, &err)
+	nasabah, kyc, err := t.getNasabahKYC(ctx, nik)
 	if err != nil {
-		err = fmt.Errorf("failed to save document")
-		t.log.WithField("nik", nik).Warn(err.Error())
 		return
 	}
-	err = t.repo.SaveDokumen(nik, id)
+	id, err := t.simpanDokumen(ctx, file, filename, nik)
 	if err != nil {
-		err = fmt.Errorf("failed to update documentID in database")
-		t.log.WithFields(logrus.Fields{
+		return
+	}
+	err = t.repo.SaveDokumen(ctx, nik, id)
+	if err != nil {
+		err = errDomain(ErrInternal, "failed to update documentID in database")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"nik":        nik,
 			"documentID": id,
 		}).Warn(err.Error())
+		t.hapusFile(ctx, t.dokumen, id)
+		return
+	}
+	t.catatAudit(ctx, aksiSimpanDokumen, nik, map[string]string{"dokumen_id": nasabah.DokumenID}, map[string]string{"dokumen_id": id})
+	t.catatAudit(ctx, aksiStatusKYC, nik, kyc, models.KYC{NIK: nik, Status: models.KYCSubmitted, DokumenID: id})
+	return
+}
+
+// SaveFotoDokumen stores a photo and a document uploaded together. The
+// nasabah only points at them once both are stored, and a failure on either
+// removes whatever was already stored.
+func (t *TabunganApp) SaveFotoDokumen(ctx context.Context, foto io.Reader, namaFoto string, dokumen io.Reader, namaDokumen, nik string) (err error) {
+	ctx, span := t.mulaiSpan(ctx, "SaveFotoDokumen")
+	defer akhiriSpan(ctx, span, &err)
+	nasabah, kyc, err := t.getNasabahKYC(ctx, nik)
+	if err != nil {
+		return
+	}
+	fotoID, hash, err := t.simpanFoto(ctx, foto, namaFoto, nik)
+	if err != nil {
+		return
+	}
+	dokumenID, err := t.simpanDokumen(ctx, dokumen, namaDokumen, nik)
+	if err != nil {
+		t.hapusFoto(ctx, fotoID)
+		return
+	}
+	err = t.repo.SaveFotoDokumen(ctx, nik, fotoID, dokumenID)
+	if err != nil {
+		err = errDomain(ErrInternal, "failed to update photoID and documentID in database")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":        nik,
+			"photoID":    fotoID,
+			"documentID": dokumenID,
+		}).Warn(err.Error())
+		t.hapusFoto(ctx, fotoID)
+		t.hapusFile(ctx, t.dokumen, dokumenID)
+		return
+	}
+	t.catatAudit(ctx, aksiSimpanFoto, nik, map[string]string{"foto_id": nasabah.FotoID}, map[string]string{"foto_id": fotoID})
+	t.catatAudit(ctx, aksiSimpanDokumen, nik, map[string]string{"dokumen_id": nasabah.DokumenID}, map[string]string{"dokumen_id": dokumenID})
+	t.catatAudit(ctx, aksiStatusKYC, nik, kyc, models.KYC{NIK: nik, Status: models.KYCSubmitted, DokumenID: dokumenID})
+	t.periksaFotoDuplikat(ctx, nik, hash)
+	return
+}
+
+func (t *TabunganApp) getNasabahKYC(ctx context.Context, nik string) (nasabah models.Nasabah, kyc models.KYC, err error) {
+	nasabah, err = t.GetNasabah(ctx, nik)
+	if err != nil {
+		return
+	}
+	kyc, err = t.repo.GetKYC(ctx, nik)
+	if err != nil {
+		err = errDomain(ErrInternal, "query status kyc gagal")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
+	}
+	return
+}
+
+// simpanFoto validates and processes a photo and stores it with its
+// thumbnail, without pointing the nasabah at it yet.
+func (t *TabunganApp) simpanFoto(ctx context.Context, file io.Reader, filename, nik string) (id, hash string, err error) {
+	data, _, _, err := validasiFile(file, filename, aturanFoto)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":      nik,
+			"filename": filename,
+		}).Warn(err.Error())
+		return
+	}
+	data, thumbnail, hash, err := t.prosesFoto(ctx, data)
+	if err != nil {
+		err = errDomain(ErrInternal, "foto gagal diproses")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":      nik,
+			"filename": filename,
+		}).Warn(err.Error())
+		return
+	}
+	id, err = t.saveFile(ctx, data, t.foto, ".jpg", "image/jpeg")
+	if err != nil {
+		err = errDomain(ErrInternal, "failed to save photo")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
+		return
+	}
+	t.simpanThumbnail(ctx, id, thumbnail)
+	return
+}
+
+// simpanDokumen validates a document and stores it, without pointing the
+// nasabah at it yet.
+func (t *TabunganApp) simpanDokumen(ctx context.Context, file io.Reader, filename, nik string) (id string, err error) {
+	data, ext, contentType, err := validasiFile(file, filename, aturanDokumen)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":      nik,
+			"filename": filename,
+		}).Warn(err.Error())
+		return
+	}
+	id, err = t.saveFile(ctx, data, t.dokumen, ext, contentType)
+	if err != nil {
+		err = errDomain(ErrInternal, "failed to save document")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
 	}
 	return
 }
 
-func (t *TabunganApp) insertMutasi(tx *sqlx.Tx, noRekening, jenisMutasi string, nominal, saldoAwal, saldoAkhir float64) (err error) {
-	mutasi := models.Mutasi{
+func (t *TabunganApp) insertMutasi(ctx context.Context, tx *sqlx.Tx, noRekening, jenisMutasi string, nominal, saldoAwal, saldoAkhir float64) (mutasi models.Mutasi, err error) {
+	mutasi = models.Mutasi{
 		TransaksiID: genID(),
 		Waktu:       time.Now().String(),
 		NoRekening:  noRekening,
@@ -293,10 +632,10 @@ func (t *TabunganApp) insertMutasi(tx *sqlx.Tx, noRekening, jenisMutasi string,
 		SaldoAwal:   saldoAwal,
 		SaldoAkhir:  saldoAkhir,
 	}
-	err = t.repo.InsertMutasi(tx, mutasi)
+	err = t.repo.InsertMutasi(ctx, tx, mutasi)
 	if err != nil {
-		err = fmt.Errorf("pencatatan transaksi gagal")
-		t.log.WithFields(logrus.Fields{
+		err = errDomain(ErrInternal, "pencatatan transaksi gagal")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"no_rekening":  noRekening,
 			"jenis_mutasi": jenisMutasi,
 			"nominal":      nominal,
@@ -308,35 +647,34 @@ func (t *TabunganApp) insertMutasi(tx *sqlx.Tx, noRekening, jenisMutasi string,
 	return
 }
 
-func (t *TabunganApp) saveFile(file io.Reader, folder, filename string) (id string, err error) {
-	err = os.MkdirAll(folder, os.ModePerm)
-	if err != nil {
-		t.log.WithFields(logrus.Fields{
-			"folder": folder,
-			"error":  err.Error(),
-		}).Error("failed to create directory if not exists")
-		return
-	}
-	ext := filepath.Ext(filename)
+func (t *TabunganApp) saveFile(ctx context.Context, data []byte, store storage.Storage, ext, contentType string) (id string, err error) {
 	id = fmt.Sprintf("%s%s", genID(), ext)
-	path := fmt.Sprintf("%s/%s", folder, id)
-	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_RDONLY, os.ModePerm)
+	err = store.Put(id, data, contentType)
 	if err != nil {
-		t.log.WithFields(logrus.Fields{
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"error": err.Error(),
-			"path":  path,
-		}).Error("failed to open file")
-		return
+			"id":    id,
+		}).Error("failed to store file")
 	}
-	defer f.Close()
-	_, err = io.Copy(f, file)
+	return
+}
+
+// hapusFile removes a blob whose database update failed, so that a rejected
+// upload does not leave an orphan behind.
+func (t *TabunganApp) hapusFile(ctx context.Context, store storage.Storage, id string) {
+	err := store.Delete(id)
 	if err != nil {
-		t.log.WithFields(logrus.Fields{
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"error": err.Error(),
-			"path":  path,
-		}).Error("failed to write file")
+			"id":    id,
+		}).Error("failed to delete file")
 	}
-	return
+}
+
+// hapusFoto removes a stored photo together with its thumbnail.
+func (t *TabunganApp) hapusFoto(ctx context.Context, id string) {
+	t.hapusFile(ctx, t.foto, id)
+	t.hapusFile(ctx, t.foto, kunciThumbnail(id))
 }
 
 func genNoRekening() (noRekening string) {
@@ -349,11 +687,23 @@ func genID() string {
 	return uuid.NewString()
 }
 
-func NewTabunganApp(photoDir, docDir string, repo repository.TabunganRepoInterface, log *logrus.Logger) (app *TabunganApp) {
-	return &TabunganApp{
-		repo:     repo,
-		log:      log,
-		photoDir: photoDir,
-		docDir:   docDir,
+func waktuSekarang() string {
+	return time.Now().Format(models.LayoutWaktu)
+}
+
+func NewTabunganApp(foto, dokumen storage.Storage, repo repository.TabunganRepoInterface, log *logrus.Logger, opts ...Option) (app *TabunganApp) {
+	app = &TabunganApp{
+		repo:    repo,
+		log:     log,
+		foto:    foto,
+		dokumen: dokumen,
+		stream:  newStreamSaldo(),
+
+		antrianFoto:        make(chan struct{}, maksProsesFoto),
+		batasTarikBelumKYC: BatasTarikBelumKYCDefault,
+	}
+	for _, opt := range opts {
+		opt(app)
 	}
+	return
 }
@@ -0,0 +1,130 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"tabungan-api/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	aksiRegistrasiNasabah    = "registrasi_nasabah"
+	aksiUpdateNasabah        = "update_nasabah"
+	aksiTarikDana            = "tarik_dana"
+	aksiSetorDana            = "setor_dana"
+	aksiReversal             = "reversal"
+	aksiSimpanFoto           = "simpan_foto"
+	aksiSimpanDokumen        = "simpan_dokumen"
+	aksiAjukanOperasi        = "ajukan_operasi"
+	aksiPutuskanOperasi      = "putuskan_operasi"
+	aksiStatusKYC            = "status_kyc"
+	aksiTandaiDuplikat       = "tandai_duplikat"
+	aksiPutuskanDuplikat     = "putuskan_duplikat"
+	aksiScreening            = "screening"
+	aksiPutuskanScreening    = "putuskan_screening"
+	aksiAlertTransaksi       = "alert_transaksi"
+	aksiPutuskanAlert        = "putuskan_alert"
+	aksiUbahAturan           = "ubah_aturan_pemantauan"
+	aksiBuatLaporan          = "buat_laporan_tunai"
+	aksiStatusLaporan        = "status_laporan_tunai"
+	aksiLanggananWebhook     = "langganan_webhook"
+	aksiUlangiWebhook        = "ulangi_webhook"
+	aksiPreferensiNotifikasi = "preferensi_notifikasi"
+)
+
+type perubahan struct {
+	Sebelum interface{} `json:"sebelum"`
+	Sesudah interface{} `json:"sesudah"`
+}
+
+// Sebagai returns a view of the app that records meta as the actor of every
+// mutation it performs. The underlying repository and configuration are shared.
+func (t *TabunganApp) Sebagai(meta models.MetadataRequest) TabunganAppInterface {
+	view := *t
+	view.meta = meta
+	return &view
+}
+
+func (t *TabunganApp) GetDaftarAudit(ctx context.Context, filter models.FilterAudit) (audit []models.Audit, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetDaftarAudit")
+	defer akhiriSpan(ctx, span, &err)
+	audit, err = t.repo.GetDaftarAudit(ctx, filter)
+	if err != nil {
+		err = errDomain(ErrInternal, "query audit gagal")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"aktor":  filter.Aktor,
+			"aksi":   filter.Aksi,
+			"target": filter.Target,
+		}).Warn(err.Error())
+	}
+	return
+}
+
+// catatAudit stores only the fields that differ between sebelum and sesudah.
+// A failure to write the audit entry is logged but does not undo the action,
+// which has already been committed by the time this is called.
+func (t *TabunganApp) catatAudit(ctx context.Context, aksi, target string, sebelum, sesudah interface{}) {
+	diff, err := diffAudit(sebelum, sesudah)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"aksi":   aksi,
+			"target": target,
+			"error":  err.Error(),
+		}).Error("build audit diff error")
+	}
+	err = t.repo.InsertAudit(ctx, models.Audit{
+		AuditID:         genID(),
+		Waktu:           waktuSekarang(),
+		MetadataRequest: t.meta,
+		Aksi:            aksi,
+		Target:          target,
+		Perubahan:       diff,
+	})
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"aktor":  t.meta.Aktor,
+			"aksi":   aksi,
+			"target": target,
+		}).Error("pencatatan audit gagal")
+	}
+}
+
+func diffAudit(sebelum, sesudah interface{}) (diff string, err error) {
+	lama, err := toFieldMap(sebelum)
+	if err != nil {
+		return
+	}
+	baru, err := toFieldMap(sesudah)
+	if err != nil {
+		return
+	}
+	hasil := make(map[string]perubahan)
+	for key, value := range baru {
+		if old, ok := lama[key]; !ok || fmt.Sprint(old) != fmt.Sprint(value) {
+			hasil[key] = perubahan{Sebelum: lama[key], Sesudah: value}
+		}
+	}
+	for key, value := range lama {
+		if _, ok := baru[key]; !ok {
+			hasil[key] = perubahan{Sebelum: value}
+		}
+	}
+	b, err := json.Marshal(hasil)
+	diff = string(b)
+	return
+}
+
+func toFieldMap(v interface{}) (fields map[string]interface{}, err error) {
+	fields = make(map[string]interface{})
+	if v == nil {
+		return
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(b, &fields)
+	return
+}
@@ -0,0 +1,188 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"tabungan-api/models"
+	"tabungan-api/similarity"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ambangNama is the name similarity from which two nasabah born on the
+	// same day are flagged, ambangAlamat the address similarity from which
+	// any two are.
+	ambangNama   = 0.85
+	ambangAlamat = 0.8
+	// jarakFotoMaks is the largest photo hash distance, in bits out of 64,
+	// that still counts as the same photo.
+	jarakFotoMaks = 6
+)
+
+// periksaDuplikat compares a newly registered nasabah with everyone born on
+// the same day or living at an address that shares a blocking key with
+// theirs. Matches are only flagged for review; the registration itself
+// stands, and the applicant is not told about the flag.
+func (t *TabunganApp) periksaDuplikat(ctx context.Context, nasabah models.Nasabah) {
+	kandidat, err := t.repo.GetKandidatDuplikat(ctx, nasabah)
+	if err != nil {
+		t.log.WithContext(ctx).WithField("nik", nasabah.NIK).Warn("pemeriksaan duplikat gagal")
+		return
+	}
+	for _, lain := range kandidat {
+		if lain.TanggalLahir == nasabah.TanggalLahir {
+			if skor := similarity.Ratio(similarity.Normalize(nasabah.Nama), similarity.Normalize(lain.Nama)); skor >= ambangNama {
+				t.tandaiDuplikat(ctx, nasabah.NIK, lain.NIK, models.DuplikatNamaTanggalLahir, skor)
+			}
+		}
+		skor := kemiripanAlamat(nasabah.AlamatKTP, lain.AlamatKTP)
+		if domisili := kemiripanAlamat(nasabah.AlamatDomisili, lain.AlamatDomisili); domisili > skor {
+			skor = domisili
+		}
+		if skor >= ambangAlamat {
+			t.tandaiDuplikat(ctx, nasabah.NIK, lain.NIK, models.DuplikatAlamat, skor)
+		}
+	}
+}
+
+// periksaFotoDuplikat stores the hash of a new photo and flags every other
+// nasabah whose photo looks the same.
+func (t *TabunganApp) periksaFotoDuplikat(ctx context.Context, nik, hash string) {
+	if err := t.repo.UpdateHashFoto(ctx, nik, hash); err != nil {
+		t.log.WithContext(ctx).WithField("nik", nik).Warn("pemeriksaan duplikat foto gagal")
+		return
+	}
+	serupa, err := t.repo.GetFotoSerupa(ctx, nik, hash, jarakFotoMaks)
+	if err != nil {
+		t.log.WithContext(ctx).WithField("nik", nik).Warn("pemeriksaan duplikat foto gagal")
+		return
+	}
+	for nikLain, jarak := range serupa {
+		t.tandaiDuplikat(ctx, nik, nikLain, models.DuplikatFoto, 1-float64(jarak)/64)
+	}
+}
+
+func (t *TabunganApp) tandaiDuplikat(ctx context.Context, nik, nikLain, jenis string, skor float64) {
+	duplikat := models.Duplikat{
+		DuplikatID:  genID(),
+		NIK:         nik,
+		NIKLain:     nikLain,
+		Jenis:       jenis,
+		Skor:        skor,
+		Status:      models.DuplikatPending,
+		WaktuDibuat: waktuSekarang(),
+	}
+	inserted, err := t.repo.InsertDuplikat(ctx, duplikat)
+	if err != nil || !inserted {
+		return
+	}
+	t.log.WithContext(ctx).WithFields(logrus.Fields{
+		"nik":      nik,
+		"nik_lain": nikLain,
+		"jenis":    jenis,
+		"skor":     skor,
+	}).Warn("kemungkinan nasabah duplikat")
+	t.catatAudit(ctx, aksiTandaiDuplikat, nik, nil, duplikat)
+}
+
+func (t *TabunganApp) GetDaftarDuplikat(ctx context.Context, status, nik string) (daftar []models.Duplikat, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetDaftarDuplikat")
+	defer akhiriSpan(ctx, span, &err)
+	daftar, err = t.repo.GetDaftarDuplikat(ctx, status, nik)
+	if err != nil {
+		err = errDomain(ErrInternal, "query daftar duplikat gagal")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"status": status,
+			"nik":    nik,
+		}).Warn(err.Error())
+	}
+	return
+}
+
+func (t *TabunganApp) KonfirmasiDuplikat(ctx context.Context, duplikatID string, petugas models.Petugas, request models.RequestKeputusanDuplikat) (duplikat models.Duplikat, err error) {
+	ctx, span := t.mulaiSpan(ctx, "KonfirmasiDuplikat")
+	defer akhiriSpan(ctx, span, &err)
+	return t.putuskanDuplikat(ctx, duplikatID, petugas, models.DuplikatTerkonfirmasi, request.Catatan)
+}
+
+func (t *TabunganApp) AbaikanDuplikat(ctx context.Context, duplikatID string, petugas models.Petugas, request models.RequestKeputusanDuplikat) (duplikat models.Duplikat, err error) {
+	ctx, span := t.mulaiSpan(ctx, "AbaikanDuplikat")
+	defer akhiriSpan(ctx, span, &err)
+	if request.Catatan == "" {
+		err = errDomain(ErrValidasi, "catatan wajib diisi untuk mengabaikan duplikat")
+		t.log.WithContext(ctx).WithField("duplikat_id", duplikatID).Warn(err.Error())
+		return
+	}
+	return t.putuskanDuplikat(ctx, duplikatID, petugas, models.DuplikatBukan, request.Catatan)
+}
+
+func (t *TabunganApp) putuskanDuplikat(ctx context.Context, duplikatID string, petugas models.Petugas, status, catatan string) (duplikat models.Duplikat, err error) {
+	if petugas.Role != models.RoleSupervisor && petugas.Role != models.RoleAdmin {
+		err = errDomain(ErrTidakBerwenang, "petugas tidak berwenang memutuskan duplikat")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"duplikat_id": duplikatID,
+			"petugas":     petugas.ID,
+			"role":        petugas.Role,
+		}).Warn(err.Error())
+		return
+	}
+	duplikat, err = t.repo.GetDuplikat(ctx, duplikatID)
+	if err != nil {
+		err = errDomain(ErrInternal, "query data duplikat gagal")
+		t.log.WithContext(ctx).WithField("duplikat_id", duplikatID).Warn(err.Error())
+		return
+	}
+	sebelum := duplikat
+	duplikat.Status = status
+	duplikat.Petugas = petugas.ID
+	duplikat.Catatan = catatan
+	duplikat.WaktuDiputus = waktuSekarang()
+	updated, err := t.repo.UpdateStatusDuplikat(ctx, duplikatID, models.DuplikatPending, duplikat)
+	if err != nil {
+		err = errDomain(ErrInternal, "update status duplikat gagal")
+		t.log.WithContext(ctx).WithField("duplikat_id", duplikatID).Warn(err.Error())
+		return
+	}
+	if !updated {
+		err = errDomain(ErrKonflik, "duplikat sudah diputuskan")
+		t.log.WithContext(ctx).WithField("duplikat_id", duplikatID).Warn(err.Error())
+		return
+	}
+	t.catatAudit(ctx, aksiPutuskanDuplikat, duplikat.NIK, sebelum, duplikat)
+	return
+}
+
+// cekDuplikatTerbuka fails while nik has duplicate flags that are pending or
+// were confirmed, which must be settled before the identity is trusted.
+func (t *TabunganApp) cekDuplikatTerbuka(ctx context.Context, nik string) (err error) {
+	jumlah, err := t.repo.CountDuplikatTerbuka(ctx, nik)
+	if err != nil {
+		err = errDomain(ErrInternal, "query data duplikat gagal")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
+		return
+	}
+	if jumlah > 0 {
+		err = errDomain(ErrDiblokir, "nasabah memiliki indikasi duplikat yang belum diselesaikan")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":    nik,
+			"jumlah": jumlah,
+		}).Warn(err.Error())
+	}
+	return
+}
+
+// kemiripanAlamat compares addresses after expanding abbreviations, taking
+// the better of character similarity and word overlap so that reordered
+// address parts still match.
+func kemiripanAlamat(a, b string) float64 {
+	x, y := similarity.AddressWords(a), similarity.AddressWords(b)
+	if len(x) == 0 || len(y) == 0 {
+		return 0
+	}
+	skor := similarity.Ratio(strings.Join(x, " "), strings.Join(y, " "))
+	if jaccard := similarity.Jaccard(x, y); jaccard > skor {
+		skor = jaccard
+	}
+	return skor
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tabungan-api/metrics"
+)
+
+// Kinds of the errors the app returns. Every error built with errDomain
+// wraps one of them, so callers can tell kinds apart with errors.Is instead
+// of parsing the message.
+var (
+	ErrValidasi         = errors.New("validasi")
+	ErrTidakDitemukan   = errors.New("tidak_ditemukan")
+	ErrTidakBerwenang   = errors.New("tidak_berwenang")
+	ErrKonflik          = errors.New("konflik")
+	ErrSaldoTidakCukup  = errors.New("saldo_tidak_cukup")
+	ErrPerluPersetujuan = errors.New("perlu_persetujuan")
+	ErrBatasKYC         = errors.New("batas_kyc")
+	ErrDiblokir         = errors.New("diblokir")
+	ErrInternal         = errors.New("internal")
+)
+
+var metrikError = metrics.NewCounter("tabungan_error_domain_total",
+	"Errors returned by the app, by kind.", "jenis")
+
+type errorDomain struct {
+	jenis error
+	pesan string
+}
+
+func (e *errorDomain) Error() string {
+	return e.pesan
+}
+
+func (e *errorDomain) Unwrap() error {
+	return e.jenis
+}
+
+// errDomain formats an error of kind jenis.
+func errDomain(jenis error, format string, args ...interface{}) error {
+	return &errorDomain{jenis: jenis, pesan: fmt.Sprintf(format, args...)}
+}
+
+// JenisError is the kind err wraps, ErrInternal when it wraps none.
+func JenisError(err error) error {
+	for _, jenis := range []error{ErrValidasi, ErrTidakDitemukan, ErrTidakBerwenang, ErrKonflik,
+		ErrSaldoTidakCukup, ErrPerluPersetujuan, ErrBatasKYC, ErrDiblokir} {
+		if errors.Is(err, jenis) {
+			return jenis
+		}
+	}
+	return ErrInternal
+}
+
+type kunciHasil struct{}
+
+// hasilPanggilan is the error the last app call made with a context from
+// HitungError returned.
+type hasilPanggilan struct {
+	err error
+}
+
+// HitungError returns ctx in which app calls remember the error they
+// returned, and hitung, which counts the error of the last call by its kind.
+// The API layers wrap every request with it, so that an error is counted
+// once however many app calls it went through on its way out.
+func HitungError(ctx context.Context) (_ context.Context, hitung func()) {
+	hasil := &hasilPanggilan{}
+	return context.WithValue(ctx, kunciHasil{}, hasil), func() {
+		if hasil.err != nil {
+			metrikError.Inc(JenisError(hasil.err).Error())
+		}
+	}
+}
+
+// catatHasil remembers err as the result of the app call made with ctx.
+// Calls end from the innermost out, so the outermost call is remembered.
+func catatHasil(ctx context.Context, err error) {
+	if hasil, ok := ctx.Value(kunciHasil{}).(*hasilPanggilan); ok {
+		hasil.err = err
+	}
+}
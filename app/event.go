@@ -0,0 +1,127 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"tabungan-api/events"
+	"tabungan-api/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+const batchRelayEvent = 100
+
+// WithSinkEvent sets where RelayEvent publishes outbox events. Without sinks
+// events are only used for webhooks.
+func WithSinkEvent(sinks ...events.Sink) Option {
+	return func(t *TabunganApp) {
+		t.sinkEvent = sinks
+	}
+}
+
+// eventRekening is the payload of RekeningOpened. The outbox is not
+// encrypted, so it leaves out the NIK of the owner.
+type eventRekening struct {
+	NoRekening string  `json:"no_rekening"`
+	Saldo      float64 `json:"saldo"`
+}
+
+// tulisEvent adds an event to the outbox within tx.
+func (t *TabunganApp) tulisEvent(ctx context.Context, tx *sqlx.Tx, jenis, aggregateID string, payload interface{}) (err error) {
+	data, err := json.Marshal(payload)
+	if err == nil {
+		err = t.repo.InsertOutbox(ctx, tx, models.Outbox{
+			EventID:     genID(),
+			Jenis:       jenis,
+			AggregateID: aggregateID,
+			Payload:     string(data),
+			WaktuDibuat: waktuSekarang(),
+		})
+	}
+	if err != nil {
+		err = errDomain(ErrInternal, "pencatatan event gagal")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"jenis":        jenis,
+			"aggregate_id": aggregateID,
+		}).Warn(err.Error())
+	}
+	return
+}
+
+// RelayEvent queues webhook deliveries for pending outbox events and
+// publishes them to every sink in the order they were written. An event is
+// marked delivered only after all sinks accepted it; on the first failure the
+// relay stops so that later events do not overtake it, and the whole event is
+// retried on the next run. Sinks can therefore receive an event more than
+// once.
+func (t *TabunganApp) RelayEvent(ctx context.Context) (jumlah int, err error) {
+	ctx, span := t.mulaiSpan(ctx, "RelayEvent")
+	defer akhiriSpan(ctx, span, &err)
+	for {
+		var daftar []models.Outbox
+		daftar, err = t.repo.GetOutboxTertunda(ctx, batchRelayEvent)
+		if err != nil {
+			err = errDomain(ErrInternal, "query outbox gagal")
+			t.log.WithContext(ctx).Warn(err.Error())
+			return
+		}
+		for _, outbox := range daftar {
+			if err = t.kirimEvent(ctx, outbox); err != nil {
+				return
+			}
+			jumlah++
+		}
+		if len(daftar) < batchRelayEvent {
+			return
+		}
+	}
+}
+
+func (t *TabunganApp) kirimEvent(ctx context.Context, outbox models.Outbox) (err error) {
+	event := events.Event{
+		ID:          outbox.EventID,
+		Type:        outbox.Jenis,
+		AggregateID: outbox.AggregateID,
+		OccurredAt:  outbox.WaktuDibuat,
+		Payload:     json.RawMessage(outbox.Payload),
+	}
+	if err = t.antreWebhook(ctx, event); err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"event_id":  outbox.EventID,
+			"percobaan": outbox.Percobaan + 1,
+			"error":     err.Error(),
+		}).Error("antre webhook gagal")
+		t.repo.CatatGagalOutbox(ctx, outbox.EventID, "webhook: "+err.Error())
+		return
+	}
+	for _, sink := range t.sinkEvent {
+		if err = sink.Publish(event); err != nil {
+			t.log.WithContext(ctx).WithFields(logrus.Fields{
+				"event_id":  outbox.EventID,
+				"sink":      sink.Name(),
+				"percobaan": outbox.Percobaan + 1,
+				"error":     err.Error(),
+			}).Error("publish event gagal")
+			t.repo.CatatGagalOutbox(ctx, outbox.EventID, fmt.Sprintf("%s: %s", sink.Name(), err.Error()))
+			return
+		}
+	}
+	return t.repo.TandaiOutboxTerkirim(ctx, outbox.EventID, waktuSekarang())
+}
+
+// JalankanRelayEvent runs RelayEvent every interval until stop is closed.
+func (t *TabunganApp) JalankanRelayEvent(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.RelayEvent(context.Background())
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"tabungan-api/models"
+	"tabungan-api/storage"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrFileTidakAda is the tidak_ditemukan error for a file that was never
+// uploaded or is gone from storage.
+var ErrFileTidakAda error = &errorDomain{jenis: ErrTidakDitemukan, pesan: "file belum diunggah"}
+
+func (t *TabunganApp) GetFoto(ctx context.Context, nik string) (blob storage.Blob, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetFoto")
+	defer akhiriSpan(ctx, span, &err)
+	nasabah, err := t.GetNasabah(ctx, nik)
+	if err != nil {
+		return
+	}
+	return t.bukaFile(ctx, t.foto, nik, nasabah.FotoID)
+}
+
+func (t *TabunganApp) GetDokumen(ctx context.Context, nik string) (blob storage.Blob, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetDokumen")
+	defer akhiriSpan(ctx, span, &err)
+	nasabah, err := t.GetNasabah(ctx, nik)
+	if err != nil {
+		return
+	}
+	return t.bukaFile(ctx, t.dokumen, nik, nasabah.DokumenID)
+}
+
+func (t *TabunganApp) bukaFile(ctx context.Context, store storage.Storage, nik, id string) (blob storage.Blob, err error) {
+	if id == "" {
+		err = ErrFileTidakAda
+		return
+	}
+	blob, err = storage.Open(store, id)
+	if errors.Is(err, storage.ErrNotFound) {
+		err = ErrFileTidakAda
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":   nik,
+			"id":    id,
+			"error": err.Error(),
+		}).Warn("buka file gagal")
+		if err != ErrFileTidakAda {
+			err = errDomain(ErrInternal, "buka file gagal")
+		}
+	}
+	return
+}
+
+// GetRiwayatFile lists every photo and document the nasabah has uploaded,
+// newest first. jenis narrows the list to one kind of file.
+func (t *TabunganApp) GetRiwayatFile(ctx context.Context, nik, jenis string) (file []models.FileNasabah, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetRiwayatFile")
+	defer akhiriSpan(ctx, span, &err)
+	if jenis != "" && jenis != models.FileFoto && jenis != models.FileDokumen {
+		err = errDomain(ErrValidasi, "jenis file tidak dikenal")
+		t.log.WithContext(ctx).WithField("jenis", jenis).Warn(err.Error())
+		return
+	}
+	nasabah, err := t.GetNasabah(ctx, nik)
+	if err != nil {
+		return
+	}
+	file, err = t.repo.GetRiwayatFile(ctx, nik, jenis)
+	if err != nil {
+		err = errDomain(ErrInternal, "query riwayat file gagal")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
+		return
+	}
+	for i := range file {
+		file[i].Aktif = file[i].FileID == nasabah.FotoID || file[i].FileID == nasabah.DokumenID
+	}
+	return
+}
+
+// GetFileVersi opens any file from the nasabah's upload history, including
+// ones that have since been replaced.
+func (t *TabunganApp) GetFileVersi(ctx context.Context, nik, fileID string) (blob storage.Blob, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetFileVersi")
+	defer akhiriSpan(ctx, span, &err)
+	riwayat, err := t.GetRiwayatFile(ctx, nik, "")
+	if err != nil {
+		return
+	}
+	for _, file := range riwayat {
+		if file.FileID == fileID {
+			return t.bukaFile(ctx, t.storeFile(file.Jenis), nik, fileID)
+		}
+	}
+	err = ErrFileTidakAda
+	return
+}
+
+func (t *TabunganApp) storeFile(jenis string) storage.Storage {
+	if jenis == models.FileDokumen {
+		return t.dokumen
+	}
+	return t.foto
+}
+
+// BersihkanFileYatim deletes blobs that no nasabah has ever referenced, such
+// as leftovers from uploads that crashed between the blob write and the
+// database update. Blobs younger than umurMinimal are kept because their
+// upload may still be in flight.
+func (t *TabunganApp) BersihkanFileYatim(ctx context.Context, umurMinimal time.Duration) (jumlah int, err error) {
+	ctx, span := t.mulaiSpan(ctx, "BersihkanFileYatim")
+	defer akhiriSpan(ctx, span, &err)
+	batas := time.Now().Add(-umurMinimal)
+	for _, jenis := range []string{models.FileFoto, models.FileDokumen} {
+		store := t.storeFile(jenis)
+		var blobs []storage.Info
+		blobs, err = store.List()
+		if err != nil {
+			err = errDomain(ErrInternal, "daftar file gagal")
+			t.log.WithContext(ctx).WithField("jenis", jenis).Warn(err.Error())
+			return
+		}
+		for _, blob := range blobs {
+			if blob.ModTime.After(batas) {
+				continue
+			}
+			var terdaftar bool
+			terdaftar, err = t.repo.FileTerdaftar(ctx, jenis, fileAsal(jenis, blob.Key))
+			if err != nil {
+				err = errDomain(ErrInternal, "query riwayat file gagal")
+				t.log.WithContext(ctx).WithField("jenis", jenis).Warn(err.Error())
+				return
+			}
+			if terdaftar {
+				continue
+			}
+			err = store.Delete(blob.Key)
+			if err != nil {
+				err = errDomain(ErrInternal, "hapus file yatim gagal")
+				t.log.WithContext(ctx).WithFields(logrus.Fields{
+					"jenis": jenis,
+					"id":    blob.Key,
+				}).Warn(err.Error())
+				return
+			}
+			jumlah++
+			t.log.WithContext(ctx).WithFields(logrus.Fields{
+				"jenis": jenis,
+				"id":    blob.Key,
+			}).Info("file yatim dihapus")
+		}
+	}
+	return
+}
+
+// JalankanPembersihanFile runs BersihkanFileYatim every interval until stop
+// is closed.
+func (t *TabunganApp) JalankanPembersihanFile(interval, umurMinimal time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.BersihkanFileYatim(context.Background(), umurMinimal)
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"tabungan-api/imaging"
+	"tabungan-api/models"
+	"tabungan-api/storage"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// sisiFotoMaks is the longest side a stored photo keeps; larger uploads
+	// are scaled down.
+	sisiFotoMaks  = 2048
+	sisiThumbnail = 256
+	// prefixThumbnail namespaces thumbnails inside the photo store. A
+	// thumbnail key is the prefix followed by the ID of its photo.
+	prefixThumbnail = "thumb/"
+	// maksProsesFoto photos are decoded at once; each takes up to a few
+	// copies of aturanFoto.maxPiksel at four bytes a pixel.
+	maksProsesFoto = 2
+)
+
+// prosesFoto strips all metadata from an uploaded photo, turns it upright and
+// re-encodes it as JPEG, together with a thumbnail and a perceptual hash of it.
+// It waits for its turn among the photos being processed.
+func (t *TabunganApp) prosesFoto(ctx context.Context, data []byte) (foto, thumbnail []byte, hash string, err error) {
+	select {
+	case t.antrianFoto <- struct{}{}:
+		defer func() { <-t.antrianFoto }()
+	case <-ctx.Done():
+		err = ctx.Err()
+		return
+	}
+	img, err := imaging.Decode(data, aturanFoto.maxPiksel)
+	if err != nil {
+		return
+	}
+	img = imaging.Fit(img, sisiFotoMaks)
+	foto, err = imaging.EncodeJPEG(img)
+	if err != nil {
+		return
+	}
+	thumbnail, err = imaging.EncodeJPEG(imaging.Fit(img, sisiThumbnail))
+	hash = imaging.Hash(img)
+	return
+}
+
+func kunciThumbnail(fotoID string) string {
+	return prefixThumbnail + fotoID
+}
+
+// fileAsal maps a blob key to the file it belongs to, which for thumbnails
+// is their photo.
+func fileAsal(jenis, key string) string {
+	if jenis == models.FileFoto {
+		return strings.TrimPrefix(key, prefixThumbnail)
+	}
+	return key
+}
+
+func (t *TabunganApp) GetThumbnail(ctx context.Context, nik string) (blob storage.Blob, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetThumbnail")
+	defer akhiriSpan(ctx, span, &err)
+	nasabah, err := t.GetNasabah(ctx, nik)
+	if err != nil {
+		return
+	}
+	if nasabah.FotoID == "" {
+		err = ErrFileTidakAda
+		return
+	}
+	kunci := kunciThumbnail(nasabah.FotoID)
+	// Photos uploaded before thumbnails existed get one on first request.
+	if _, errStat := t.foto.Stat(kunci); errors.Is(errStat, storage.ErrNotFound) {
+		t.buatThumbnail(ctx, nasabah.FotoID)
+	}
+	return t.bukaFile(ctx, t.foto, nik, kunci)
+}
+
+func (t *TabunganApp) buatThumbnail(ctx context.Context, fotoID string) {
+	blob, _, err := t.foto.Get(fotoID)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"id":    fotoID,
+			"error": err.Error(),
+		}).Warn("buat thumbnail gagal")
+		return
+	}
+	data, err := io.ReadAll(blob)
+	blob.Close()
+	if err == nil {
+		_, data, _, err = t.prosesFoto(ctx, data)
+	}
+	if err == nil {
+		err = t.foto.Put(kunciThumbnail(fotoID), data, "image/jpeg")
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"id":    fotoID,
+			"error": err.Error(),
+		}).Warn("buat thumbnail gagal")
+	}
+}
+
+func (t *TabunganApp) simpanThumbnail(ctx context.Context, fotoID string, thumbnail []byte) {
+	err := t.foto.Put(kunciThumbnail(fotoID), thumbnail, "image/jpeg")
+	if err != nil {
+		err = errDomain(ErrInternal, "simpan thumbnail gagal")
+		t.log.WithContext(ctx).WithField("id", fotoID).Warn(err.Error())
+	}
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"context"
+	"tabungan-api/tracing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("tabungan-api/app")
+
+// mulaiSpan starts the span of an app operation. The aktor is left out: for
+// nasabah it is their NIK.
+func (t *TabunganApp) mulaiSpan(ctx context.Context, operasi string) (context.Context, trace.Span) {
+	ctx, span := tracer.Start(ctx, "app."+operasi)
+	if t.meta.Role != "" {
+		span.SetAttributes(attribute.String("aktor.role", t.meta.Role))
+	}
+	if t.meta.RequestID != "" {
+		span.SetAttributes(attribute.String("request_id", t.meta.RequestID))
+	}
+	return ctx, span
+}
+
+// akhiriSpan ends span with the error the operation returned, for use with
+// defer on a named err.
+func akhiriSpan(ctx context.Context, span trace.Span, err *error) {
+	catatHasil(ctx, *err)
+	if *err != nil {
+		span.SetAttributes(attribute.String("error.jenis", JenisError(*err).Error()))
+	}
+	tracing.End(span, *err)
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"context"
+	"tabungan-api/storage"
+)
+
+// kunciCekStorage is written and deleted again to check that a storage
+// accepts writes.
+const kunciCekStorage = ".cek-kesehatan"
+
+// CekKesehatan checks that the database answers and that every storage
+// accepts writes. hasil maps each of them to "ok" or to why it failed.
+func (t *TabunganApp) CekKesehatan(ctx context.Context) (hasil map[string]string, err error) {
+	ctx, span := t.mulaiSpan(ctx, "CekKesehatan")
+	defer akhiriSpan(ctx, span, &err)
+	hasil = make(map[string]string)
+	if errDB := t.repo.Ping(ctx); errDB != nil {
+		hasil["database"] = errDB.Error()
+		err = errDomain(ErrInternal, "database tidak siap")
+	} else {
+		hasil["database"] = "ok"
+	}
+	penyimpanan := map[string]storage.Storage{
+		"foto":    t.foto,
+		"dokumen": t.dokumen,
+		"laporan": t.laporan,
+	}
+	for nama, store := range penyimpanan {
+		if store == nil {
+			continue
+		}
+		errStore := store.Put(kunciCekStorage, []byte("ok"), "text/plain")
+		if errStore == nil {
+			errStore = store.Delete(kunciCekStorage)
+		}
+		if errStore != nil {
+			hasil[nama] = errStore.Error()
+			err = errDomain(ErrInternal, "storage %s tidak siap", nama)
+			continue
+		}
+		hasil[nama] = "ok"
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithField("hasil", hasil).Warn(err.Error())
+	}
+	return
+}
@@ -0,0 +1,175 @@
+package app
+
+import (
+	"context"
+	"tabungan-api/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// BatasTarikBelumKYCDefault is the largest withdrawal allowed while the
+// nasabah's KYC is not verified, unless WithBatasTarikBelumKYC says otherwise.
+const BatasTarikBelumKYCDefault = 5000000
+
+// WithBatasTarikBelumKYC sets the largest withdrawal allowed while the
+// nasabah's KYC is not verified. Zero blocks withdrawals until verification;
+// it has to be asked for explicitly.
+func WithBatasTarikBelumKYC(nominal float64) Option {
+	return func(t *TabunganApp) {
+		t.batasTarikBelumKYC = nominal
+	}
+}
+
+func (t *TabunganApp) GetKYC(ctx context.Context, nik string) (kyc models.KYC, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetKYC")
+	defer akhiriSpan(ctx, span, &err)
+	if _, err = t.GetNasabah(ctx, nik); err != nil {
+		return
+	}
+	kyc, err = t.repo.GetKYC(ctx, nik)
+	if err != nil {
+		err = errDomain(ErrInternal, "query status kyc gagal")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
+	}
+	return
+}
+
+func (t *TabunganApp) GetRiwayatKYC(ctx context.Context, nik string) (riwayat []models.KYC, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetRiwayatKYC")
+	defer akhiriSpan(ctx, span, &err)
+	if _, err = t.GetNasabah(ctx, nik); err != nil {
+		return
+	}
+	riwayat, err = t.repo.GetRiwayatKYC(ctx, nik)
+	if err != nil {
+		err = errDomain(ErrInternal, "query riwayat kyc gagal")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
+	}
+	return
+}
+
+func (t *TabunganApp) GetDaftarKYC(ctx context.Context, status string) (daftar []models.KYC, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetDaftarKYC")
+	defer akhiriSpan(ctx, span, &err)
+	switch status {
+	case models.KYCPending, models.KYCSubmitted, models.KYCVerified, models.KYCRejected:
+	default:
+		err = errDomain(ErrValidasi, "status kyc tidak dikenal")
+		t.log.WithContext(ctx).WithField("status", status).Warn(err.Error())
+		return
+	}
+	daftar, err = t.repo.GetDaftarKYC(ctx, status)
+	if err != nil {
+		err = errDomain(ErrInternal, "query daftar kyc gagal")
+		t.log.WithContext(ctx).WithField("status", status).Warn(err.Error())
+	}
+	return
+}
+
+func (t *TabunganApp) VerifikasiKYC(ctx context.Context, nik string, petugas models.Petugas, request models.RequestReviewKYC) (kyc models.KYC, err error) {
+	ctx, span := t.mulaiSpan(ctx, "VerifikasiKYC")
+	defer akhiriSpan(ctx, span, &err)
+	return t.reviewKYC(ctx, nik, petugas, models.KYCVerified, request)
+}
+
+func (t *TabunganApp) TolakKYC(ctx context.Context, nik string, petugas models.Petugas, request models.RequestReviewKYC) (kyc models.KYC, err error) {
+	ctx, span := t.mulaiSpan(ctx, "TolakKYC")
+	defer akhiriSpan(ctx, span, &err)
+	if request.Alasan == "" {
+		err = errDomain(ErrValidasi, "alasan penolakan kyc wajib diisi")
+		t.log.WithContext(ctx).WithField("petugas", petugas.ID).Warn(err.Error())
+		return
+	}
+	return t.reviewKYC(ctx, nik, petugas, models.KYCRejected, request)
+}
+
+// reviewKYC decides a submitted document. When the reviewer names the
+// document they looked at, the decision only applies if it is still the
+// document under review.
+func (t *TabunganApp) reviewKYC(ctx context.Context, nik string, petugas models.Petugas, status string, request models.RequestReviewKYC) (kyc models.KYC, err error) {
+	if petugas.Role != models.RoleSupervisor && petugas.Role != models.RoleAdmin {
+		err = errDomain(ErrTidakBerwenang, "petugas tidak berwenang mereview kyc")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"petugas": petugas.ID,
+			"role":    petugas.Role,
+		}).Warn(err.Error())
+		return
+	}
+	sebelum, err := t.GetKYC(ctx, nik)
+	if err != nil {
+		return
+	}
+	if sebelum.Status != models.KYCSubmitted {
+		err = errDomain(ErrKonflik, "kyc tidak sedang menunggu review")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":    nik,
+			"status": sebelum.Status,
+		}).Warn(err.Error())
+		return
+	}
+	if request.DokumenID != "" && request.DokumenID != sebelum.DokumenID {
+		err = errDomain(ErrKonflik, "dokumen kyc sudah diganti nasabah")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":        nik,
+			"dokumen_id": request.DokumenID,
+		}).Warn(err.Error())
+		return
+	}
+	if status == models.KYCVerified {
+		if err = t.cekDuplikatTerbuka(ctx, nik); err != nil {
+			return
+		}
+		if err = t.cekScreeningTerbuka(ctx, nik); err != nil {
+			return
+		}
+	}
+	kyc = models.KYC{
+		NIK:       nik,
+		Status:    status,
+		DokumenID: sebelum.DokumenID,
+		Alasan:    request.Alasan,
+		Petugas:   petugas.ID,
+		Waktu:     waktuSekarang(),
+	}
+	updated, err := t.repo.UpdateStatusKYC(ctx, nik, models.KYCSubmitted, kyc)
+	if err != nil {
+		err = errDomain(ErrInternal, "update status kyc gagal")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
+		return
+	}
+	if !updated {
+		err = errDomain(ErrKonflik, "kyc sudah direview atau dokumen sudah diganti")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
+		return
+	}
+	t.catatAudit(ctx, aksiStatusKYC, nik, sebelum, kyc)
+	return
+}
+
+// cekBatasKYC rejects withdrawals above the unverified limit for nasabah
+// whose KYC has not been verified yet.
+func (t *TabunganApp) cekBatasKYC(ctx context.Context, nik string, nominal float64) (err error) {
+	kyc, err := t.repo.GetKYC(ctx, nik)
+	if err != nil {
+		err = errDomain(ErrInternal, "query status kyc gagal")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
+		return
+	}
+	if kyc.Status == models.KYCVerified {
+		return
+	}
+	switch {
+	case t.batasTarikBelumKYC <= 0:
+		err = errDomain(ErrBatasKYC, "penarikan tidak diizinkan sebelum kyc terverifikasi")
+	case nominal > t.batasTarikBelumKYC:
+		err = errDomain(ErrBatasKYC, "penarikan melebihi batas nasabah yang belum terverifikasi kyc")
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":        nik,
+			"status_kyc": kyc.Status,
+			"nominal":    nominal,
+		}).Warn(err.Error())
+	}
+	return
+}
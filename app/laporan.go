@@ -0,0 +1,301 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"tabungan-api/models"
+	"tabungan-api/storage"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const layoutTanggal = "2006-01-02"
+
+// WithLaporanTunai enables cash transaction reports. Reports list every
+// nasabah whose transactions on a day add up to at least ambang, and their
+// files are kept in store.
+func WithLaporanTunai(store storage.Storage, ambang float64) Option {
+	return func(t *TabunganApp) {
+		t.laporan = store
+		t.ambangLaporanTunai = ambang
+	}
+}
+
+// BuatLaporanTunai generates the report for dari..sampai inclusive. A period
+// can be generated any number of times; each run becomes a new version built
+// from the data as it is now.
+func (t *TabunganApp) BuatLaporanTunai(ctx context.Context, petugas models.Petugas, request models.RequestLaporanTunai) (laporan models.LaporanTunai, err error) {
+	ctx, span := t.mulaiSpan(ctx, "BuatLaporanTunai")
+	defer akhiriSpan(ctx, span, &err)
+	if t.laporan == nil {
+		err = errDomain(ErrInternal, "laporan transaksi tunai tidak dikonfigurasi")
+		t.log.WithContext(ctx).Warn(err.Error())
+		return
+	}
+	if err = validasiPeriode(request.Dari, request.Sampai); err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"dari":   request.Dari,
+			"sampai": request.Sampai,
+		}).Warn(err.Error())
+		return
+	}
+	daftar, err := t.repo.GetTransaksiTunaiHarian(ctx, request.Dari, request.Sampai, t.ambangLaporanTunai)
+	if err != nil {
+		err = errDomain(ErrInternal, "query transaksi tunai gagal")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"dari":   request.Dari,
+			"sampai": request.Sampai,
+		}).Warn(err.Error())
+		return
+	}
+	versi, err := t.repo.GetVersiLaporanTunai(ctx, request.Dari, request.Sampai)
+	if err != nil {
+		err = errDomain(ErrInternal, "query versi laporan gagal")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"dari":   request.Dari,
+			"sampai": request.Sampai,
+		}).Warn(err.Error())
+		return
+	}
+	laporan = models.LaporanTunai{
+		LaporanID:     genID(),
+		PeriodeDari:   request.Dari,
+		PeriodeSampai: request.Sampai,
+		Versi:         versi + 1,
+		Ambang:        t.ambangLaporanTunai,
+		JumlahBaris:   len(daftar),
+		Status:        models.LaporanDibuat,
+		Pembuat:       petugas.ID,
+		WaktuDibuat:   waktuSekarang(),
+	}
+	laporan.WaktuDiubah = laporan.WaktuDibuat
+	for _, harian := range daftar {
+		laporan.TotalNominal += harian.Total
+	}
+	isi := tulisLaporanTunai(laporan, daftar)
+	sum := sha256.Sum256(isi)
+	laporan.Checksum = hex.EncodeToString(sum[:])
+	laporan.FileID = fmt.Sprintf("ltkt_%s_%s_v%d.txt", strings.ReplaceAll(laporan.PeriodeDari, "-", ""),
+		strings.ReplaceAll(laporan.PeriodeSampai, "-", ""), laporan.Versi)
+	err = t.laporan.Put(laporan.FileID, isi, "text/plain; charset=utf-8")
+	if err != nil {
+		err = errDomain(ErrInternal, "simpan file laporan gagal")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"laporan_id": laporan.LaporanID,
+			"file_id":    laporan.FileID,
+		}).Warn(err.Error())
+		return
+	}
+	err = t.repo.InsertLaporanTunai(ctx, laporan)
+	if err != nil {
+		err = errDomain(ErrInternal, "simpan laporan gagal")
+		t.log.WithContext(ctx).WithField("laporan_id", laporan.LaporanID).Warn(err.Error())
+		t.hapusFile(ctx, t.laporan, laporan.FileID)
+		return
+	}
+	t.log.WithContext(ctx).WithFields(logrus.Fields{
+		"laporan_id":   laporan.LaporanID,
+		"dari":         laporan.PeriodeDari,
+		"sampai":       laporan.PeriodeSampai,
+		"versi":        laporan.Versi,
+		"jumlah_baris": laporan.JumlahBaris,
+	}).Info("laporan transaksi tunai dibuat")
+	t.catatAudit(ctx, aksiBuatLaporan, laporan.LaporanID, nil, laporan)
+	return
+}
+
+func validasiPeriode(dari, sampai string) (err error) {
+	awal, errDari := time.ParseInLocation(layoutTanggal, dari, time.Local)
+	akhir, errSampai := time.ParseInLocation(layoutTanggal, sampai, time.Local)
+	if errDari != nil || errSampai != nil {
+		return errDomain(ErrValidasi, "periode laporan harus berformat YYYY-MM-DD")
+	}
+	if akhir.Before(awal) {
+		return errDomain(ErrValidasi, "akhir periode laporan sebelum awal periode")
+	}
+	if sampai >= time.Now().Format(layoutTanggal) {
+		return errDomain(ErrValidasi, "periode laporan harus sudah berakhir")
+	}
+	return
+}
+
+// tulisLaporanTunai renders a report as pipe-delimited UTF-8 text, one record
+// per line:
+//
+//	H|LTKT|laporan_id|versi|periode_dari|periode_sampai|ambang|waktu_dibuat
+//	D|tanggal|nik|nama|tanggal_lahir|alamat_ktp|no_rekening;...|jumlah_transaksi|total_setor|total_tarik|total
+//	T|jumlah_baris_D|total_nominal
+//
+// Nominal values have two decimals and no thousands separator. A period
+// without reportable transactions still has a header and trailer.
+func tulisLaporanTunai(laporan models.LaporanTunai, daftar []models.TransaksiTunaiHarian) []byte {
+	var buf bytes.Buffer
+	tulis := func(kolom ...string) {
+		for i, nilai := range kolom {
+			kolom[i] = strings.NewReplacer("|", " ", "\r", " ", "\n", " ").Replace(nilai)
+		}
+		buf.WriteString(strings.Join(kolom, "|"))
+		buf.WriteString("\n")
+	}
+	nominal := func(n float64) string {
+		return fmt.Sprintf("%.2f", n)
+	}
+	tulis("H", "LTKT", laporan.LaporanID, fmt.Sprint(laporan.Versi), laporan.PeriodeDari, laporan.PeriodeSampai,
+		nominal(laporan.Ambang), laporan.WaktuDibuat)
+	for _, harian := range daftar {
+		tulis("D", harian.Tanggal, harian.NIK, harian.Nama, harian.TanggalLahir, harian.AlamatKTP,
+			strings.Join(harian.NoRekening, ";"), fmt.Sprint(harian.JumlahTransaksi),
+			nominal(harian.TotalSetor), nominal(harian.TotalTarik), nominal(harian.Total))
+	}
+	tulis("T", fmt.Sprint(len(daftar)), nominal(laporan.TotalNominal))
+	return buf.Bytes()
+}
+
+func (t *TabunganApp) GetDaftarLaporanTunai(ctx context.Context, status, dari, sampai string) (daftar []models.LaporanTunai, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetDaftarLaporanTunai")
+	defer akhiriSpan(ctx, span, &err)
+	daftar, err = t.repo.GetDaftarLaporanTunai(ctx, status, dari, sampai)
+	if err != nil {
+		err = errDomain(ErrInternal, "query daftar laporan gagal")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"status": status,
+			"dari":   dari,
+			"sampai": sampai,
+		}).Warn(err.Error())
+	}
+	return
+}
+
+func (t *TabunganApp) GetLaporanTunai(ctx context.Context, laporanID string) (laporan models.LaporanTunai, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetLaporanTunai")
+	defer akhiriSpan(ctx, span, &err)
+	laporan, err = t.repo.GetLaporanTunai(ctx, laporanID)
+	if err != nil {
+		err = errDomain(ErrTidakDitemukan, "laporan tidak ditemukan")
+		t.log.WithContext(ctx).WithField("laporan_id", laporanID).Warn(err.Error())
+	}
+	return
+}
+
+func (t *TabunganApp) GetFileLaporanTunai(ctx context.Context, laporanID string) (blob storage.Blob, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetFileLaporanTunai")
+	defer akhiriSpan(ctx, span, &err)
+	laporan, err := t.GetLaporanTunai(ctx, laporanID)
+	if err != nil {
+		return
+	}
+	if t.laporan == nil {
+		err = errDomain(ErrInternal, "laporan transaksi tunai tidak dikonfigurasi")
+		t.log.WithContext(ctx).Warn(err.Error())
+		return
+	}
+	blob, err = storage.Open(t.laporan, laporan.FileID)
+	if errors.Is(err, storage.ErrNotFound) {
+		err = ErrFileTidakAda
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"laporan_id": laporanID,
+			"file_id":    laporan.FileID,
+			"error":      err.Error(),
+		}).Warn("buka file laporan gagal")
+		if err != ErrFileTidakAda {
+			err = errDomain(ErrInternal, "buka file gagal")
+		}
+	}
+	return
+}
+
+// KirimLaporanTunai records that the report was submitted to the regulator.
+func (t *TabunganApp) KirimLaporanTunai(ctx context.Context, laporanID string, petugas models.Petugas, request models.RequestStatusLaporan) (laporan models.LaporanTunai, err error) {
+	ctx, span := t.mulaiSpan(ctx, "KirimLaporanTunai")
+	defer akhiriSpan(ctx, span, &err)
+	return t.ubahStatusLaporan(ctx, laporanID, petugas, models.LaporanDibuat, models.LaporanDikirim, request)
+}
+
+// TerimaLaporanTunai records the regulator's acceptance. The receipt number
+// is required unless it was already given on submission.
+func (t *TabunganApp) TerimaLaporanTunai(ctx context.Context, laporanID string, petugas models.Petugas, request models.RequestStatusLaporan) (laporan models.LaporanTunai, err error) {
+	ctx, span := t.mulaiSpan(ctx, "TerimaLaporanTunai")
+	defer akhiriSpan(ctx, span, &err)
+	return t.ubahStatusLaporan(ctx, laporanID, petugas, models.LaporanDikirim, models.LaporanDiterima, request)
+}
+
+// TolakLaporanTunai records that the regulator rejected the report. The
+// period then has to be generated and submitted again.
+func (t *TabunganApp) TolakLaporanTunai(ctx context.Context, laporanID string, petugas models.Petugas, request models.RequestStatusLaporan) (laporan models.LaporanTunai, err error) {
+	ctx, span := t.mulaiSpan(ctx, "TolakLaporanTunai")
+	defer akhiriSpan(ctx, span, &err)
+	if request.Catatan == "" {
+		err = errDomain(ErrValidasi, "catatan wajib diisi untuk laporan yang ditolak")
+		t.log.WithContext(ctx).WithField("laporan_id", laporanID).Warn(err.Error())
+		return
+	}
+	return t.ubahStatusLaporan(ctx, laporanID, petugas, models.LaporanDikirim, models.LaporanDitolak, request)
+}
+
+func (t *TabunganApp) ubahStatusLaporan(ctx context.Context, laporanID string, petugas models.Petugas, statusSebelum, status string, request models.RequestStatusLaporan) (laporan models.LaporanTunai, err error) {
+	laporan, err = t.GetLaporanTunai(ctx, laporanID)
+	if err != nil {
+		return
+	}
+	if laporan.Status != statusSebelum {
+		err = errDomain(ErrKonflik, "laporan berstatus %s, tidak dapat diubah menjadi %s", laporan.Status, status)
+		t.log.WithContext(ctx).WithField("laporan_id", laporanID).Warn(err.Error())
+		return
+	}
+	sebelum := laporan
+	laporan.Status = status
+	if request.Referensi != "" {
+		laporan.Referensi = request.Referensi
+	}
+	if status == models.LaporanDiterima && laporan.Referensi == "" {
+		err = errDomain(ErrValidasi, "referensi wajib diisi untuk laporan yang diterima")
+		t.log.WithContext(ctx).WithField("laporan_id", laporanID).Warn(err.Error())
+		return
+	}
+	laporan.Catatan = request.Catatan
+	laporan.Petugas = petugas.ID
+	laporan.WaktuDiubah = waktuSekarang()
+	updated, err := t.repo.UpdateStatusLaporanTunai(ctx, laporanID, statusSebelum, laporan)
+	if err != nil {
+		err = errDomain(ErrInternal, "update status laporan gagal")
+		t.log.WithContext(ctx).WithField("laporan_id", laporanID).Warn(err.Error())
+		return
+	}
+	if !updated {
+		err = errDomain(ErrKonflik, "status laporan sudah berubah")
+		t.log.WithContext(ctx).WithField("laporan_id", laporanID).Warn(err.Error())
+		return
+	}
+	t.catatAudit(ctx, aksiStatusLaporan, laporanID, sebelum, laporan)
+	return
+}
+
+// JalankanLaporanTunai generates the report for the previous day every
+// interval until stop is closed, unless that day has been generated already.
+func (t *TabunganApp) JalankanLaporanTunai(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	petugas := models.Petugas{ID: "laporan_tunai", Role: models.RoleSistem}
+	sistem := t.Sebagai(models.MetadataRequest{Aktor: petugas.ID, Role: petugas.Role})
+	for {
+		select {
+		case <-ticker.C:
+			kemarin := time.Now().AddDate(0, 0, -1).Format(layoutTanggal)
+			versi, err := t.repo.GetVersiLaporanTunai(context.Background(), kemarin, kemarin)
+			if err != nil || versi > 0 {
+				continue
+			}
+			sistem.BuatLaporanTunai(context.Background(), petugas, models.RequestLaporanTunai{Dari: kemarin, Sampai: kemarin})
+		case <-stop:
+			return
+		}
+	}
+}
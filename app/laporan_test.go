@@ -0,0 +1,76 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"tabungan-api/models"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// cocokGolden compares got with testdata/nama, or rewrites it with -update.
+func cocokGolden(t *testing.T, nama string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", nama)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs; got:\n%s", path, got)
+	}
+}
+
+func TestTulisLaporanTunai(t *testing.T) {
+	laporan := models.LaporanTunai{LaporanID: "lap-1", Versi: 2, PeriodeDari: "2024-03-01", PeriodeSampai: "2024-03-31",
+		Ambang: 500000000, TotalNominal: 1750000000.5, WaktuDibuat: "2024-04-01 08:00:00.000000"}
+	daftar := []models.TransaksiTunaiHarian{
+		{Tanggal: "2024-03-04", NIK: "3171012345678901", Nama: "Budi Santoso", TanggalLahir: "1990-01-01",
+			AlamatKTP: "Jl. Merdeka No. 5, Bandung", NoRekening: []string{"0012345678", "0012345679"},
+			JumlahTransaksi: 3, TotalSetor: 600000000, TotalTarik: 150000000, Total: 750000000},
+		// Delimiters and line breaks in free text must not break the record.
+		{Tanggal: "2024-03-05", NIK: "3171012345678902", Nama: "Sari | Dewi", TanggalLahir: "1991-02-02",
+			AlamatKTP: "Jalan Kenanga 7\r\nSurabaya", NoRekening: []string{"0098765432"},
+			JumlahTransaksi: 1, TotalSetor: 0, TotalTarik: 1000000000.5, Total: 1000000000.5},
+	}
+	cocokGolden(t, "laporan_tunai.golden", tulisLaporanTunai(laporan, daftar))
+
+	laporan.TotalNominal = 0
+	cocokGolden(t, "laporan_tunai_kosong.golden", tulisLaporanTunai(laporan, nil))
+}
+
+func TestValidasiPeriode(t *testing.T) {
+	kemarin := time.Now().AddDate(0, 0, -1).Format(layoutTanggal)
+	hariIni := time.Now().Format(layoutTanggal)
+	cases := []struct {
+		dari, sampai string
+		valid        bool
+	}{
+		{"2024-03-01", "2024-03-31", true},
+		{kemarin, kemarin, true},
+		{"2024-03-01", hariIni, false},
+		{"2024-03-31", "2024-03-01", false},
+		{"2024-03-01", "31-03-2024", false},
+		{"2024-02-30", "2024-03-31", false},
+		{"", "2024-03-31", false},
+	}
+	for _, c := range cases {
+		err := validasiPeriode(c.dari, c.sampai)
+		if c.valid && err != nil {
+			t.Errorf("validasiPeriode(%q, %q) = %v, want valid", c.dari, c.sampai, err)
+		}
+		if !c.valid && !errors.Is(err, ErrValidasi) {
+			t.Errorf("validasiPeriode(%q, %q) = %v, want %v", c.dari, c.sampai, err, ErrValidasi)
+		}
+	}
+}
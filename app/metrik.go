@@ -0,0 +1,19 @@
+package app
+
+import (
+	"tabungan-api/metrics"
+	"tabungan-api/models"
+)
+
+var (
+	metrikTransaksi = metrics.NewCounter("tabungan_transaksi_total",
+		"Committed transactions, by jenis_mutasi.", "jenis_mutasi")
+	metrikNominal = metrics.NewCounter("tabungan_transaksi_nominal_total",
+		"Sum of the nominal of committed transactions, by jenis_mutasi.", "jenis_mutasi")
+)
+
+// hitungTransaksi counts a mutasi once its transaction is committed.
+func hitungTransaksi(mutasi models.Mutasi) {
+	metrikTransaksi.Inc(mutasi.JenisMutasi)
+	metrikNominal.Add(mutasi.Nominal, mutasi.JenisMutasi)
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"tabungan-api/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func (t *TabunganApp) GetRiwayatNasabah(ctx context.Context, nik string) (riwayat []models.VersiNasabah, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetRiwayatNasabah")
+	defer akhiriSpan(ctx, span, &err)
+	riwayat, err = t.repo.GetRiwayatNasabah(ctx, nik)
+	if err != nil {
+		err = errDomain(ErrInternal, "query riwayat nasabah gagal")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
+		return
+	}
+	if len(riwayat) == 0 {
+		err = errDomain(ErrTidakDitemukan, "nasabah tidak ditemukan")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
+	}
+	return
+}
+
+// GetNasabahPerWaktu returns the nasabah record as it was at asOf. A plain
+// date (2006-01-02) means the end of that day.
+func (t *TabunganApp) GetNasabahPerWaktu(ctx context.Context, nik, asOf string) (nasabah models.VersiNasabah, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetNasabahPerWaktu")
+	defer akhiriSpan(ctx, span, &err)
+	waktu, err := parseWaktu(asOf)
+	if err != nil {
+		err = errDomain(ErrValidasi, "format waktu as_of tidak valid")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":   nik,
+			"as_of": asOf,
+		}).Warn(err.Error())
+		return
+	}
+	nasabah, err = t.repo.GetNasabahPerWaktu(ctx, nik, waktu)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = errDomain(ErrTidakDitemukan, "nasabah belum terdaftar pada %s", asOf)
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":   nik,
+			"as_of": asOf,
+		}).Warn(err.Error())
+	} else if err != nil {
+		err = errDomain(ErrInternal, "query data nasabah gagal")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":   nik,
+			"as_of": asOf,
+		}).Warn(err.Error())
+	}
+	return
+}
+
+func parseWaktu(value string) (waktu string, err error) {
+	if parsed, errParse := time.ParseInLocation("2006-01-02", value, time.Local); errParse == nil {
+		waktu = parsed.Add(24*time.Hour - time.Microsecond).Format(models.LayoutWaktu)
+		return
+	}
+	for _, layout := range []string{models.LayoutWaktu, "2006-01-02 15:04:05", time.RFC3339Nano} {
+		var parsed time.Time
+		parsed, err = time.ParseInLocation(layout, value, time.Local)
+		if err == nil {
+			waktu = parsed.In(time.Local).Format(models.LayoutWaktu)
+			return
+		}
+	}
+	return
+}
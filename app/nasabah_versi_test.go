@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"tabungan-api/models"
+	"testing"
+	"time"
+)
+
+// TestGetNasabahPerWaktu checks that a plain date finds every change made
+// during that day, and nothing before the nasabah registered.
+func TestGetNasabahPerWaktu(t *testing.T) {
+	ctx := context.Background()
+	app, _ := appUji(t)
+	sebelum := time.Now().Format(models.LayoutWaktu)
+	err := app.UpdateNasabah(ctx, nikUji, models.RequestUpdateNasabah{Nama: "Budi Santoso", AlamatKTP: "Jl A",
+		AlamatDomisili: "Jl B"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hariIni := time.Now().Format("2006-01-02")
+	kemarin := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+
+	cases := []struct {
+		asOf string
+		// versi and domisili are what the nasabah looked like then; versi
+		// zero when they were not registered yet.
+		versi    int
+		domisili string
+	}{
+		{sebelum, 1, "Jl A"},
+		{hariIni, 2, "Jl B"},
+		{time.Now().Add(time.Hour).Format(time.RFC3339Nano), 2, "Jl B"},
+		{kemarin, 0, ""},
+		{kemarin + " 23:59:59", 0, ""},
+	}
+	for _, c := range cases {
+		nasabah, err := app.GetNasabahPerWaktu(ctx, nikUji, c.asOf)
+		if c.versi == 0 {
+			if !errors.Is(err, ErrTidakDitemukan) {
+				t.Errorf("as of %s: err = %v, want %v", c.asOf, err, ErrTidakDitemukan)
+			}
+			continue
+		}
+		if err != nil || nasabah.Versi != c.versi || nasabah.AlamatDomisili != c.domisili {
+			t.Errorf("as of %s: versi %d, alamat_domisili %q, %v; want %d, %q",
+				c.asOf, nasabah.Versi, nasabah.AlamatDomisili, err, c.versi, c.domisili)
+		}
+	}
+	if _, err := app.GetNasabahPerWaktu(ctx, nikUji, "01-03-2024"); !errors.Is(err, ErrValidasi) {
+		t.Errorf("as of an invalid date: err = %v, want %v", err, ErrValidasi)
+	}
+}
+
+func TestParseWaktu(t *testing.T) {
+	cases := []struct {
+		value, waktu string
+	}{
+		{"2024-03-01", "2024-03-01 23:59:59.999999"},
+		{"2024-02-29", "2024-02-29 23:59:59.999999"},
+		{"2024-03-01 12:00:00", "2024-03-01 12:00:00.000000"},
+		{"2024-03-01 12:00:00.123456", "2024-03-01 12:00:00.123456"},
+		{"2023-02-29", ""},
+		{"2024-03-01T12:00", ""},
+		{"", ""},
+	}
+	for _, c := range cases {
+		waktu, err := parseWaktu(c.value)
+		if (err == nil) != (c.waktu != "") || waktu != c.waktu {
+			t.Errorf("parseWaktu(%q) = %q, %v; want %q", c.value, waktu, err, c.waktu)
+		}
+	}
+}
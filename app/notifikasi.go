@@ -0,0 +1,341 @@
+package app
+
+import (
+	"context"
+	"net/mail"
+	"regexp"
+	"tabungan-api/models"
+	"tabungan-api/notification"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	batchNotifikasi = 50
+
+	notifikasiTarikDana       = "tarik_dana"
+	notifikasiSetorDana       = "setor_dana"
+	notifikasiPerubahanProfil = "perubahan_profil"
+)
+
+var polaNoHP = regexp.MustCompile(`^\+?[0-9]{8,15}$`)
+
+// dataNotifikasi is what the notification templates can refer to.
+type dataNotifikasi struct {
+	Nama       string
+	NoRekening string
+	Nominal    float64
+	Saldo      float64
+	Waktu      string
+	Kolom      []string
+}
+
+// WithNotifikasi enables customer notifications over kanal, keyed by
+// channel name. Setor and tarik of at least ambang are notified. A failed
+// send is retried after jedaAwal, doubling every attempt up to a day, and
+// given up after maksPercobaan attempts.
+func WithNotifikasi(templates *notification.Templates, kanal []notification.Channel, ambang float64, maksPercobaan int, jedaAwal time.Duration) Option {
+	return func(t *TabunganApp) {
+		t.templateNotifikasi = templates
+		t.kanalNotifikasi = make(map[string]notification.Channel)
+		for _, k := range kanal {
+			t.kanalNotifikasi[k.Name()] = k
+		}
+		t.ambangNotifikasi = ambang
+		t.maksPercobaanNotifikasi = maksPercobaan
+		t.jedaNotifikasi = jedaAwal
+	}
+}
+
+// GetPreferensiNotifikasi returns the defaults, with no channel, for a
+// nasabah who never set preferences.
+func (t *TabunganApp) GetPreferensiNotifikasi(ctx context.Context, nik string) (preferensi models.PreferensiNotifikasi, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetPreferensiNotifikasi")
+	defer akhiriSpan(ctx, span, &err)
+	if _, err = t.GetNasabah(ctx, nik); err != nil {
+		return
+	}
+	preferensi, found, err := t.repo.GetPreferensiNotifikasi(ctx, nik)
+	if err != nil {
+		err = errDomain(ErrInternal, "query preferensi notifikasi gagal")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
+		return
+	}
+	if !found {
+		preferensi = models.PreferensiNotifikasi{
+			NIK:             nik,
+			Bahasa:          notification.DefaultLanguage,
+			Kanal:           []string{},
+			Transaksi:       true,
+			PerubahanProfil: true,
+		}
+	}
+	return
+}
+
+// UbahPreferensiNotifikasi replaces the preferences of nik. When the email
+// or phone number changes, the old contact is told about it.
+func (t *TabunganApp) UbahPreferensiNotifikasi(ctx context.Context, nik string, request models.RequestPreferensiNotifikasi) (preferensi models.PreferensiNotifikasi, err error) {
+	ctx, span := t.mulaiSpan(ctx, "UbahPreferensiNotifikasi")
+	defer akhiriSpan(ctx, span, &err)
+	sebelum, err := t.GetPreferensiNotifikasi(ctx, nik)
+	if err != nil {
+		return
+	}
+	preferensi = sebelum
+	preferensi.Bahasa = request.Bahasa
+	if preferensi.Bahasa == "" {
+		preferensi.Bahasa = notification.DefaultLanguage
+	}
+	preferensi.Email = request.Email
+	preferensi.NoHP = request.NoHP
+	preferensi.Kanal = request.Kanal
+	if preferensi.Kanal == nil {
+		preferensi.Kanal = []string{}
+	}
+	if request.Transaksi != nil {
+		preferensi.Transaksi = *request.Transaksi
+	}
+	if request.PerubahanProfil != nil {
+		preferensi.PerubahanProfil = *request.PerubahanProfil
+	}
+	preferensi.WaktuDiubah = waktuSekarang()
+	if err = validasiPreferensi(preferensi); err != nil {
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
+		return
+	}
+	err = t.repo.SetPreferensiNotifikasi(ctx, preferensi)
+	if err != nil {
+		err = errDomain(ErrInternal, "simpan preferensi notifikasi gagal")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
+		return
+	}
+	t.catatAudit(ctx, aksiPreferensiNotifikasi, nik, sebelum, preferensi)
+	var kolom []string
+	if sebelum.Email != "" && sebelum.Email != preferensi.Email {
+		kolom = append(kolom, "email")
+	}
+	if sebelum.NoHP != "" && sebelum.NoHP != preferensi.NoHP {
+		kolom = append(kolom, "no_hp")
+	}
+	if len(kolom) > 0 {
+		t.notifikasiProfil(ctx, nik, sebelum, kolom)
+	}
+	return
+}
+
+func validasiPreferensi(preferensi models.PreferensiNotifikasi) (err error) {
+	if preferensi.Bahasa != notification.LanguageID && preferensi.Bahasa != notification.LanguageEN {
+		return errDomain(ErrValidasi, "bahasa harus id atau en")
+	}
+	if preferensi.Email != "" {
+		if alamat, err := mail.ParseAddress(preferensi.Email); err != nil || alamat.Address != preferensi.Email {
+			return errDomain(ErrValidasi, "email tidak valid")
+		}
+	}
+	if preferensi.NoHP != "" && !polaNoHP.MatchString(preferensi.NoHP) {
+		return errDomain(ErrValidasi, "nomor HP tidak valid")
+	}
+	for _, kanal := range preferensi.Kanal {
+		switch {
+		case kanal == notification.ChannelEmail && preferensi.Email == "":
+			return errDomain(ErrValidasi, "kanal email memerlukan email")
+		case kanal == notification.ChannelSMS && preferensi.NoHP == "":
+			return errDomain(ErrValidasi, "kanal sms memerlukan nomor HP")
+		case kanal != notification.ChannelEmail && kanal != notification.ChannelSMS:
+			return errDomain(ErrValidasi, "kanal %s tidak dikenal", kanal)
+		}
+	}
+	return
+}
+
+func (t *TabunganApp) GetDaftarNotifikasi(ctx context.Context, nik string) (daftar []models.Notifikasi, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetDaftarNotifikasi")
+	defer akhiriSpan(ctx, span, &err)
+	daftar, err = t.repo.GetDaftarNotifikasi(ctx, nik)
+	if err != nil {
+		err = errDomain(ErrInternal, "query notifikasi gagal")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
+	}
+	return
+}
+
+// notifikasiTransaksi notifies a committed setor or tarik of at least the
+// configured amount.
+func (t *TabunganApp) notifikasiTransaksi(ctx context.Context, nik string, mutasi models.Mutasi) {
+	if t.templateNotifikasi == nil || mutasi.Nominal < t.ambangNotifikasi {
+		return
+	}
+	preferensi, found, err := t.repo.GetPreferensiNotifikasi(ctx, nik)
+	if err != nil || !found || !preferensi.Transaksi {
+		return
+	}
+	nasabah, err := t.GetNasabah(ctx, nik)
+	if err != nil {
+		return
+	}
+	jenis := notifikasiSetorDana
+	if mutasi.JenisMutasi == "D" {
+		jenis = notifikasiTarikDana
+	}
+	t.antreNotifikasi(ctx, jenis, preferensi, dataNotifikasi{
+		Nama:       nasabah.Nama,
+		NoRekening: mutasi.NoRekening,
+		Nominal:    mutasi.Nominal,
+		Saldo:      mutasi.SaldoAkhir,
+		Waktu:      time.Now().Format("02-01-2006 15:04"),
+	})
+}
+
+// notifikasiProfil tells the nasabah, on the contacts in preferensi, which
+// profile fields were changed.
+func (t *TabunganApp) notifikasiProfil(ctx context.Context, nik string, preferensi models.PreferensiNotifikasi, kolom []string) {
+	if t.templateNotifikasi == nil || !preferensi.PerubahanProfil {
+		return
+	}
+	nasabah, err := t.GetNasabah(ctx, nik)
+	if err != nil {
+		return
+	}
+	t.antreNotifikasi(ctx, notifikasiPerubahanProfil, preferensi, dataNotifikasi{
+		Nama:  nasabah.Nama,
+		Kolom: kolom,
+		Waktu: time.Now().Format("02-01-2006 15:04"),
+	})
+}
+
+// antreNotifikasi renders jenis for every channel the nasabah chose and
+// queues the messages. Notifications never hold up the change that
+// triggered them; failures are only logged.
+func (t *TabunganApp) antreNotifikasi(ctx context.Context, jenis string, preferensi models.PreferensiNotifikasi, data dataNotifikasi) {
+	for _, kanal := range preferensi.Kanal {
+		if _, ok := t.kanalNotifikasi[kanal]; !ok {
+			continue
+		}
+		tujuan := preferensi.Email
+		if kanal == notification.ChannelSMS {
+			tujuan = preferensi.NoHP
+		}
+		pesan, err := t.templateNotifikasi.Render(jenis, preferensi.Bahasa, kanal, data)
+		if err != nil {
+			t.log.WithContext(ctx).WithFields(logrus.Fields{
+				"nik":   preferensi.NIK,
+				"jenis": jenis,
+				"kanal": kanal,
+				"error": err.Error(),
+			}).Error("render notifikasi gagal")
+			continue
+		}
+		t.repo.InsertNotifikasi(ctx, preferensi.NIK, models.Notifikasi{
+			NotifikasiID:    genID(),
+			Jenis:           jenis,
+			Kanal:           kanal,
+			Tujuan:          tujuan,
+			Subjek:          pesan.Subject,
+			Isi:             pesan.Body,
+			Status:          models.NotifikasiPending,
+			KirimBerikutnya: waktuSekarang(),
+			WaktuDibuat:     waktuSekarang(),
+		})
+	}
+}
+
+// KirimNotifikasi sends every queued notification that is due.
+func (t *TabunganApp) KirimNotifikasi(ctx context.Context) (jumlah int, err error) {
+	ctx, span := t.mulaiSpan(ctx, "KirimNotifikasi")
+	defer akhiriSpan(ctx, span, &err)
+	if t.templateNotifikasi == nil {
+		return
+	}
+	for {
+		var daftar []models.Notifikasi
+		daftar, err = t.repo.GetNotifikasiSiapKirim(ctx, waktuSekarang(), batchNotifikasi)
+		if err != nil {
+			err = errDomain(ErrInternal, "query notifikasi gagal")
+			t.log.WithContext(ctx).Warn(err.Error())
+			return
+		}
+		for _, notifikasi := range daftar {
+			if t.kirimNotifikasi(ctx, notifikasi) {
+				jumlah++
+			}
+		}
+		if len(daftar) < batchNotifikasi {
+			return
+		}
+	}
+}
+
+func (t *TabunganApp) kirimNotifikasi(ctx context.Context, notifikasi models.Notifikasi) (terkirim bool) {
+	notifikasi.Percobaan++
+	notifikasi.ErrorTerakhir = ""
+	var err error
+	if kanal, ok := t.kanalNotifikasi[notifikasi.Kanal]; ok {
+		err = kanal.Send(notification.Message{
+			To:      notifikasi.Tujuan,
+			Subject: notifikasi.Subjek,
+			Body:    notifikasi.Isi,
+		})
+	} else {
+		err = errDomain(ErrInternal, "kanal %s tidak dikonfigurasi", notifikasi.Kanal)
+	}
+	if err == nil {
+		terkirim = true
+		notifikasi.Status = models.NotifikasiTerkirim
+		notifikasi.WaktuTerkirim = waktuSekarang()
+	} else {
+		notifikasi.ErrorTerakhir = err.Error()
+		if notifikasi.Percobaan >= t.maksPercobaanNotifikasi {
+			notifikasi.Status = models.NotifikasiGagal
+		} else {
+			notifikasi.KirimBerikutnya = time.Now().Add(jedaUlang(t.jedaNotifikasi, notifikasi.Percobaan)).Format(models.LayoutWaktu)
+		}
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"notifikasi_id": notifikasi.NotifikasiID,
+			"kanal":         notifikasi.Kanal,
+			"percobaan":     notifikasi.Percobaan,
+			"status":        notifikasi.Status,
+			"error":         err.Error(),
+		}).Error("pengiriman notifikasi gagal")
+	}
+	t.repo.UpdateNotifikasi(ctx, notifikasi)
+	return
+}
+
+// JalankanNotifikasi runs KirimNotifikasi every interval until stop is
+// closed.
+func (t *TabunganApp) JalankanNotifikasi(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.KirimNotifikasi(context.Background())
+		case <-stop:
+			return
+		}
+	}
+}
+
+// notifikasiUpdateNasabah notifies the fields of nasabah that request
+// changes.
+func (t *TabunganApp) notifikasiUpdateNasabah(ctx context.Context, nasabah models.Nasabah, request models.RequestUpdateNasabah) {
+	var kolom []string
+	if request.Nama != nasabah.Nama {
+		kolom = append(kolom, "nama")
+	}
+	if request.AlamatKTP != nasabah.AlamatKTP {
+		kolom = append(kolom, "alamat_ktp")
+	}
+	if request.AlamatDomisili != nasabah.AlamatDomisili {
+		kolom = append(kolom, "alamat_domisili")
+	}
+	if len(kolom) == 0 {
+		return
+	}
+	preferensi, found, err := t.repo.GetPreferensiNotifikasi(ctx, nasabah.NIK)
+	if err == nil && found {
+		t.notifikasiProfil(ctx, nasabah.NIK, preferensi, kolom)
+	}
+}
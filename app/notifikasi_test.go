@@ -0,0 +1,128 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"tabungan-api/models"
+	"tabungan-api/notification"
+	"testing"
+	"time"
+)
+
+// kotakUji reads back what a file channel was given, one
+// "<tujuan>: <subject> | <body>" per message.
+func kotakUji(t *testing.T, path string) (pesan []string) {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var msg notification.Message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			t.Fatal(err)
+		}
+		pesan = append(pesan, msg.To+": "+msg.Subject+" | "+msg.Body)
+	}
+	return
+}
+
+// TestNotifikasi checks who is notified of what, in which language, for the
+// preferences a nasabah can set and amounts around the threshold.
+func TestNotifikasi(t *testing.T) {
+	const ambang = 1000000
+	ya, tidak := true, false
+	email := &models.RequestPreferensiNotifikasi{Bahasa: "id", Email: "budi@example.com", Kanal: []string{"email"}}
+	cases := []struct {
+		nama       string
+		preferensi *models.RequestPreferensiNotifikasi
+		aksi       string
+		nominal    float64
+		// email and sms start with what each channel must have been given,
+		// in order.
+		email, sms []string
+	}{
+		{"no preferences set", nil, "setor", ambang, nil, nil},
+		{"no channel chosen", &models.RequestPreferensiNotifikasi{Bahasa: "id", Email: "budi@example.com"},
+			"setor", ambang, nil, nil},
+		{"setor at the threshold", email, "setor", ambang,
+			[]string{"budi@example.com: Setoran dana ke rekening ****"}, nil},
+		{"setor just below the threshold", email, "setor", ambang - 0.01, nil, nil},
+		{"tarik at the threshold", email, "tarik", ambang,
+			[]string{"budi@example.com: Penarikan dana dari rekening ****"}, nil},
+		{"transactions opted out", &models.RequestPreferensiNotifikasi{Bahasa: "id", Email: "budi@example.com",
+			Kanal: []string{"email"}, Transaksi: &tidak}, "setor", ambang, nil, nil},
+		{"sms in english", &models.RequestPreferensiNotifikasi{Bahasa: "en", NoHP: "+6281234567890", Kanal: []string{"sms"}},
+			"tarik", 2500000.5, nil, []string{"+6281234567890:  | Withdrawal of IDR 2,500,000.50 from acct ****"}},
+		{"both channels", &models.RequestPreferensiNotifikasi{Bahasa: "id", Email: "budi@example.com", NoHP: "081234567890",
+			Kanal: []string{"email", "sms"}}, "setor", ambang,
+			[]string{"budi@example.com: Setoran dana"}, []string{"081234567890:  | Setoran Rp1.000.000 ke rek"}},
+		{"profile change with transactions opted out", &models.RequestPreferensiNotifikasi{Bahasa: "id",
+			Email: "budi@example.com", Kanal: []string{"email"}, Transaksi: &tidak, PerubahanProfil: &ya}, "update", 0,
+			[]string{"budi@example.com: Data nasabah Anda telah diubah | Yth. Budi Santoso,\n\nData berikut pada profil Anda telah diubah pada "}, nil},
+		{"profile changes opted out", &models.RequestPreferensiNotifikasi{Bahasa: "id", Email: "budi@example.com",
+			Kanal: []string{"email"}, PerubahanProfil: &tidak}, "update", 0, nil, nil},
+	}
+	for _, c := range cases {
+		dir := t.TempDir()
+		pathEmail, pathSMS := filepath.Join(dir, "email"), filepath.Join(dir, "sms")
+		kanalEmail, err := notification.NewFileChannel(notification.ChannelEmail, pathEmail)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gateway, err := notification.NewFileChannel(notification.ChannelSMS, pathSMS)
+		if err != nil {
+			t.Fatal(err)
+		}
+		templates, err := notification.LoadTemplates("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx := context.Background()
+		app, noRekening := appUji(t, WithNotifikasi(templates,
+			[]notification.Channel{kanalEmail, notification.NewSMSChannel(gateway)}, ambang, 3, time.Minute))
+		if _, err = app.SetorDana(ctx, nikUji, noRekening, 5*ambang); err != nil {
+			t.Fatal(err)
+		}
+		if c.preferensi != nil {
+			if _, err = app.UbahPreferensiNotifikasi(ctx, nikUji, *c.preferensi); err != nil {
+				t.Fatalf("%s: UbahPreferensiNotifikasi: %v", c.nama, err)
+			}
+		}
+		switch c.aksi {
+		case "setor":
+			_, err = app.SetorDana(ctx, nikUji, noRekening, c.nominal)
+		case "tarik":
+			_, err = app.TarikDana(ctx, nikUji, noRekening, c.nominal)
+		case "update":
+			err = app.UpdateNasabah(ctx, nikUji, models.RequestUpdateNasabah{Nama: "Budi Santoso", AlamatKTP: "Jl A",
+				AlamatDomisili: "Jl B"})
+		}
+		if err != nil {
+			t.Fatalf("%s: %s: %v", c.nama, c.aksi, err)
+		}
+		if _, err = app.KirimNotifikasi(ctx); err != nil {
+			t.Fatalf("%s: KirimNotifikasi: %v", c.nama, err)
+		}
+		for _, kanal := range []struct {
+			path string
+			want []string
+		}{{pathEmail, c.email}, {pathSMS, c.sms}} {
+			got := kotakUji(t, kanal.path)
+			if len(got) != len(kanal.want) {
+				t.Errorf("%s: %s got %q, want %q", c.nama, filepath.Base(kanal.path), got, kanal.want)
+				continue
+			}
+			for i := range got {
+				if !strings.HasPrefix(got[i], kanal.want[i]) {
+					t.Errorf("%s: %s got %q, want it to start with %q", c.nama, filepath.Base(kanal.path), got[i], kanal.want[i])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"tabungan-api/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PerluPersetujuan reports whether an operation has to be queued for a
+// checker instead of being executed directly.
+func (t *TabunganApp) PerluPersetujuan(ctx context.Context, request models.RequestOperasi) (perlu bool, err error) {
+	ctx, span := t.mulaiSpan(ctx, "PerluPersetujuan")
+	defer akhiriSpan(ctx, span, &err)
+	switch request.JenisOperasi {
+	case models.OperasiTarikDana:
+		perlu = t.batasTarik > 0 && request.Nominal >= t.batasTarik
+	case models.OperasiUpdateNasabah:
+		var nasabah models.Nasabah
+		nasabah, err = t.GetNasabah(ctx, request.NIK)
+		if err != nil {
+			return
+		}
+		perlu = request.Nama != nasabah.Nama || request.AlamatKTP != nasabah.AlamatKTP
+	case models.OperasiReversal:
+		perlu = true
+	default:
+		err = errDomain(ErrValidasi, "jenis operasi tidak dikenal")
+		t.log.WithContext(ctx).WithField("jenis_operasi", request.JenisOperasi).Warn(err.Error())
+	}
+	return
+}
+
+func (t *TabunganApp) AjukanOperasi(ctx context.Context, maker string, request models.RequestOperasi) (operasi models.Operasi, err error) {
+	ctx, span := t.mulaiSpan(ctx, "AjukanOperasi")
+	defer akhiriSpan(ctx, span, &err)
+	target, err := t.validasiOperasi(ctx, request)
+	if err != nil {
+		return
+	}
+	payload, err := json.Marshal(request)
+	if err != nil {
+		err = errDomain(ErrInternal, "pengajuan operasi gagal")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"jenis_operasi": request.JenisOperasi,
+			"maker":         maker,
+		}).Warn(err.Error())
+		return
+	}
+	operasi = models.Operasi{
+		OperasiID:    genID(),
+		JenisOperasi: request.JenisOperasi,
+		Target:       target,
+		Payload:      string(payload),
+		Status:       models.StatusPending,
+		Maker:        maker,
+		WaktuDibuat:  waktuSekarang(),
+	}
+	err = t.repo.InsertOperasi(ctx, operasi)
+	if err != nil {
+		err = errDomain(ErrInternal, "pengajuan operasi gagal")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"jenis_operasi": operasi.JenisOperasi,
+			"target":        operasi.Target,
+			"maker":         maker,
+		}).Warn(err.Error())
+		return
+	}
+	t.catatRiwayatOperasi(ctx, operasi.OperasiID, models.StatusPending, maker, "")
+	t.catatAudit(ctx, aksiAjukanOperasi, operasi.Target, nil, operasi)
+	return
+}
+
+func (t *TabunganApp) GetDaftarOperasi(ctx context.Context, status string) (operasi []models.Operasi, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetDaftarOperasi")
+	defer akhiriSpan(ctx, span, &err)
+	operasi, err = t.repo.GetDaftarOperasi(ctx, status)
+	if err != nil {
+		err = errDomain(ErrInternal, "query daftar operasi gagal")
+		t.log.WithContext(ctx).WithField("status", status).Warn(err.Error())
+	}
+	return
+}
+
+func (t *TabunganApp) GetOperasi(ctx context.Context, operasiID string) (operasi models.Operasi, riwayat []models.RiwayatOperasi, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetOperasi")
+	defer akhiriSpan(ctx, span, &err)
+	operasi, err = t.repo.GetOperasi(ctx, operasiID)
+	if err != nil {
+		err = errDomain(ErrInternal, "query data operasi gagal")
+		t.log.WithContext(ctx).WithField("operasi_id", operasiID).Warn(err.Error())
+		return
+	}
+	riwayat, err = t.repo.GetRiwayatOperasi(ctx, operasiID)
+	if err != nil {
+		err = errDomain(ErrInternal, "query riwayat operasi gagal")
+		t.log.WithContext(ctx).WithField("operasi_id", operasiID).Warn(err.Error())
+	}
+	return
+}
+
+func (t *TabunganApp) SetujuiOperasi(ctx context.Context, operasiID string, checker models.Petugas) (operasi models.Operasi, err error) {
+	ctx, span := t.mulaiSpan(ctx, "SetujuiOperasi")
+	defer akhiriSpan(ctx, span, &err)
+	operasi, err = t.putuskanOperasi(ctx, operasiID, checker, models.StatusApproved, "")
+	if err != nil {
+		return
+	}
+	var request models.RequestOperasi
+	err = json.Unmarshal([]byte(operasi.Payload), &request)
+	if err == nil {
+		err = t.eksekusiOperasi(ctx, request)
+	}
+	status := models.StatusExecuted
+	if err != nil {
+		status = models.StatusFailed
+		operasi.Remark = err.Error()
+		err = errDomain(JenisError(err), "eksekusi operasi gagal: %s", operasi.Remark)
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"operasi_id":    operasiID,
+			"jenis_operasi": operasi.JenisOperasi,
+			"target":        operasi.Target,
+		}).Warn(err.Error())
+	}
+	operasi.Status = status
+	_, errUpdate := t.repo.UpdateStatusOperasi(ctx, operasiID, models.StatusApproved, operasi)
+	if errUpdate != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"operasi_id": operasiID,
+			"status":     status,
+		}).Error("update status operasi gagal")
+	}
+	t.catatRiwayatOperasi(ctx, operasiID, status, checker.ID, operasi.Remark)
+	return
+}
+
+func (t *TabunganApp) TolakOperasi(ctx context.Context, operasiID string, checker models.Petugas, remark string) (operasi models.Operasi, err error) {
+	ctx, span := t.mulaiSpan(ctx, "TolakOperasi")
+	defer akhiriSpan(ctx, span, &err)
+	return t.putuskanOperasi(ctx, operasiID, checker, models.StatusRejected, remark)
+}
+
+func (t *TabunganApp) putuskanOperasi(ctx context.Context, operasiID string, checker models.Petugas, status, remark string) (operasi models.Operasi, err error) {
+	if checker.Role != models.RoleSupervisor && checker.Role != models.RoleAdmin {
+		err = errDomain(ErrTidakBerwenang, "petugas tidak berwenang memutuskan operasi")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"operasi_id": operasiID,
+			"petugas":    checker.ID,
+			"role":       checker.Role,
+		}).Warn(err.Error())
+		return
+	}
+	operasi, err = t.repo.GetOperasi(ctx, operasiID)
+	if err != nil {
+		err = errDomain(ErrInternal, "query data operasi gagal")
+		t.log.WithContext(ctx).WithField("operasi_id", operasiID).Warn(err.Error())
+		return
+	}
+	if operasi.Maker == checker.ID {
+		err = errDomain(ErrTidakBerwenang, "maker dan checker tidak boleh sama")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"operasi_id": operasiID,
+			"petugas":    checker.ID,
+		}).Warn(err.Error())
+		return
+	}
+	operasi.Status = status
+	operasi.Checker = checker.ID
+	operasi.Remark = remark
+	operasi.WaktuDiputus = waktuSekarang()
+	updated, err := t.repo.UpdateStatusOperasi(ctx, operasiID, models.StatusPending, operasi)
+	if err != nil {
+		err = errDomain(ErrInternal, "update status operasi gagal")
+		t.log.WithContext(ctx).WithField("operasi_id", operasiID).Warn(err.Error())
+		return
+	}
+	if !updated {
+		err = errDomain(ErrKonflik, "operasi sudah diputuskan")
+		t.log.WithContext(ctx).WithField("operasi_id", operasiID).Warn(err.Error())
+		return
+	}
+	t.catatRiwayatOperasi(ctx, operasiID, status, checker.ID, remark)
+	t.catatAudit(ctx, aksiPutuskanOperasi, operasi.Target, map[string]string{"operasi_id": operasiID, "status": models.StatusPending}, map[string]string{"operasi_id": operasiID, "status": status})
+	return
+}
+
+func (t *TabunganApp) validasiOperasi(ctx context.Context, request models.RequestOperasi) (target string, err error) {
+	switch request.JenisOperasi {
+	case models.OperasiTarikDana:
+		if request.Nominal <= 0 {
+			err = errDomain(ErrValidasi, "nominal penarikan tidak valid")
+			break
+		}
+		_, err = t.GetRekening(ctx, request.NIK, request.NoRekening)
+		if err == nil {
+			err = t.cekScreeningTransaksi(ctx, request.NIK)
+		}
+		if err == nil {
+			err = t.cekBatasKYC(ctx, request.NIK, request.Nominal)
+		}
+		target = request.NoRekening
+	case models.OperasiUpdateNasabah:
+		_, err = t.GetNasabah(ctx, request.NIK)
+		target = request.NIK
+	case models.OperasiReversal:
+		var jumlah int
+		_, err = t.repo.GetTransaksi(ctx, request.TransaksiID)
+		if err != nil {
+			err = errDomain(ErrTidakDitemukan, "transaksi tidak ditemukan")
+			break
+		}
+		jumlah, err = t.repo.CountOperasiAktif(ctx, models.OperasiReversal, request.TransaksiID)
+		if err == nil && jumlah > 0 {
+			err = errDomain(ErrKonflik, "transaksi sudah diajukan reversal")
+		}
+		target = request.TransaksiID
+	default:
+		err = errDomain(ErrValidasi, "jenis operasi tidak dikenal")
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"jenis_operasi": request.JenisOperasi,
+			"nik":           request.NIK,
+			"no_rekening":   request.NoRekening,
+			"transaksi_id":  request.TransaksiID,
+		}).Warn(err.Error())
+	}
+	return
+}
+
+func (t *TabunganApp) eksekusiOperasi(ctx context.Context, request models.RequestOperasi) (err error) {
+	switch request.JenisOperasi {
+	case models.OperasiTarikDana:
+		_, err = t.tarikDana(ctx, request.NIK, request.NoRekening, request.Nominal)
+	case models.OperasiUpdateNasabah:
+		err = t.updateNasabah(ctx, request.NIK, models.RequestUpdateNasabah{
+			Nama:           request.Nama,
+			AlamatKTP:      request.AlamatKTP,
+			AlamatDomisili: request.AlamatDomisili,
+		})
+	case models.OperasiReversal:
+		_, err = t.reversalTransaksi(ctx, request.TransaksiID)
+	default:
+		err = errDomain(ErrValidasi, "jenis operasi tidak dikenal")
+	}
+	return
+}
+
+func (t *TabunganApp) reversalTransaksi(ctx context.Context, transaksiID string) (saldoAkhir float64, err error) {
+	mutasi, err := t.repo.GetTransaksi(ctx, transaksiID)
+	if err != nil {
+		err = errDomain(ErrTidakDitemukan, "transaksi tidak ditemukan")
+		t.log.WithContext(ctx).WithField("transaksi_id", transaksiID).Warn(err.Error())
+		return
+	}
+	rekening, err := t.repo.GetRekeningByNomor(ctx, mutasi.NoRekening)
+	if err != nil {
+		err = errDomain(ErrInternal, "query data rekening gagal")
+		t.log.WithContext(ctx).WithField("no_rekening", mutasi.NoRekening).Warn(err.Error())
+		return
+	}
+	jenisMutasi, nominal := "C", mutasi.Nominal
+	if mutasi.JenisMutasi == "C" {
+		jenisMutasi, nominal = "D", -mutasi.Nominal
+	}
+	saldoAkhir = rekening.Saldo + nominal
+	if saldoAkhir < 0 {
+		err = errDomain(ErrSaldoTidakCukup, "saldo tidak mencukupi")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"transaksi_id": transaksiID,
+			"no_rekening":  rekening.NoRekening,
+			"saldo":        rekening.Saldo,
+			"nominal":      mutasi.Nominal,
+		}).Warn("reversal transaksi gagal")
+		return
+	}
+	tx, err := t.repo.StartTransaction(ctx)
+	if err != nil {
+		err = errDomain(ErrInternal, "reversal transaksi error")
+		t.log.WithContext(ctx).WithField("transaksi_id", transaksiID).Warn(err.Error())
+		return
+	}
+	err = t.repo.UpdateSaldo(ctx, tx, rekening.NoRekening, nominal)
+	if err != nil {
+		err = errDomain(ErrInternal, "reversal transaksi error")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"transaksi_id": transaksiID,
+			"no_rekening":  rekening.NoRekening,
+			"nominal":      mutasi.Nominal,
+		}).Warn(err.Error())
+		tx.Rollback()
+		return
+	}
+	balik, err := t.insertMutasi(ctx, tx, rekening.NoRekening, jenisMutasi, mutasi.Nominal, rekening.Saldo, saldoAkhir)
+	if err == nil {
+		// Subscribers see a reversal as the opposite movement of funds.
+		jenisEvent := models.EventDanaDitarik
+		if jenisMutasi == "C" {
+			jenisEvent = models.EventDanaDisetor
+		}
+		err = t.tulisEvent(ctx, tx, jenisEvent, rekening.NoRekening, balik)
+	}
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	tx.Commit()
+	hitungTransaksi(balik)
+	t.catatAudit(ctx, aksiReversal, rekening.NoRekening, rekening, struct {
+		models.Rekening
+		TransaksiID string `json:"transaksi_id"`
+	}{models.Rekening{NIK: rekening.NIK, NoRekening: rekening.NoRekening, Saldo: saldoAkhir}, transaksiID})
+	t.kirimSaldo(rekening.NIK, balik)
+	t.notifikasiTransaksi(ctx, rekening.NIK, balik)
+	t.pantauTransaksi(ctx, rekening.NIK, balik)
+	return
+}
+
+func (t *TabunganApp) catatRiwayatOperasi(ctx context.Context, operasiID, status, petugas, remark string) {
+	err := t.repo.InsertRiwayatOperasi(ctx, models.RiwayatOperasi{
+		OperasiID: operasiID,
+		Waktu:     waktuSekarang(),
+		Status:    status,
+		Petugas:   petugas,
+		Remark:    remark,
+	})
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"operasi_id": operasiID,
+			"status":     status,
+		}).Error("pencatatan riwayat operasi gagal")
+	}
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"tabungan-api/models"
+	"testing"
+)
+
+// TestSetujuiOperasiGagal checks that an approved operation failing on
+// execution keeps the kind of the failure.
+func TestSetujuiOperasiGagal(t *testing.T) {
+	ctx := context.Background()
+	app, noRekening := appUji(t, WithBatasPersetujuan(1000000))
+	if _, err := app.SetorDana(ctx, nikUji, noRekening, 2000000); err != nil {
+		t.Fatal(err)
+	}
+	operasi, err := app.AjukanOperasi(ctx, "petugas-1", models.RequestOperasi{JenisOperasi: models.OperasiTarikDana,
+		NIK: nikUji, NoRekening: noRekening, Nominal: 1500000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The balance drops below the pending withdrawal before it is approved.
+	if _, err = app.TarikDana(ctx, nikUji, noRekening, 900000); err != nil {
+		t.Fatal(err)
+	}
+	operasi, err = app.SetujuiOperasi(ctx, operasi.OperasiID, models.Petugas{ID: "petugas-2", Role: models.RoleSupervisor})
+	if !errors.Is(err, ErrSaldoTidakCukup) {
+		t.Errorf("SetujuiOperasi error = %v, want kind %v", err, ErrSaldoTidakCukup)
+	}
+	if operasi.Status != models.StatusFailed {
+		t.Errorf("operasi status = %q, want %q", operasi.Status, models.StatusFailed)
+	}
+}
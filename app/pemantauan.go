@@ -0,0 +1,320 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"tabungan-api/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// layoutDetikMutasi is the part of a mutasi timestamp that is shared by the
+// time.Time.String() form mutasi has always been stored in and LayoutWaktu.
+const layoutDetikMutasi = "2006-01-02 15:04:05"
+
+func waktuMutasi(waktu string) (parsed time.Time, err error) {
+	if len(waktu) < len(layoutDetikMutasi) {
+		err = errDomain(ErrValidasi, "waktu mutasi %q tidak valid", waktu)
+		return
+	}
+	return time.ParseInLocation(layoutDetikMutasi, waktu[:len(layoutDetikMutasi)], time.Local)
+}
+
+// pantauTransaksi evaluates every active monitoring rule against a committed
+// transaction. Alerts never hold up the transaction itself; they are queued
+// for a petugas to review.
+func (t *TabunganApp) pantauTransaksi(ctx context.Context, nik string, mutasi models.Mutasi) {
+	daftar, err := t.repo.GetDaftarAturan(ctx)
+	if err != nil {
+		t.log.WithContext(ctx).WithField("transaksi_id", mutasi.TransaksiID).Error("pemantauan transaksi gagal, aturan tidak dapat dibaca")
+		return
+	}
+	for _, aturan := range daftar {
+		if !aturan.Aktif {
+			continue
+		}
+		terkait, keterangan, err := t.evaluasiAturan(ctx, aturan, nik, mutasi)
+		if err != nil {
+			t.log.WithContext(ctx).WithFields(logrus.Fields{
+				"aturan_id":    aturan.AturanID,
+				"transaksi_id": mutasi.TransaksiID,
+				"error":        err.Error(),
+			}).Error("evaluasi aturan pemantauan gagal")
+			continue
+		}
+		if len(terkait) > 0 {
+			t.buatAlert(ctx, aturan, nik, mutasi, terkait, keterangan)
+		}
+	}
+}
+
+// evaluasiAturan returns the transactions that trip aturan, or none.
+func (t *TabunganApp) evaluasiAturan(ctx context.Context, aturan models.AturanPemantauan, nik string, mutasi models.Mutasi) (terkait []string, keterangan string, err error) {
+	switch aturan.Jenis {
+	case models.AturanSetoranBesar:
+		if mutasi.JenisMutasi == "C" && mutasi.Nominal >= aturan.Ambang {
+			terkait = []string{mutasi.TransaksiID}
+			keterangan = fmt.Sprintf("setoran Rp%.0f mencapai ambang Rp%.0f", mutasi.Nominal, aturan.Ambang)
+		}
+	case models.AturanDormant:
+		var jendela time.Duration
+		var waktu, waktuSebelumnya time.Time
+		var sebelumnya models.Mutasi
+		var found bool
+		if jendela, err = time.ParseDuration(aturan.Jendela); err != nil {
+			return
+		}
+		sebelumnya, found, err = t.repo.GetMutasiSebelumnya(ctx, mutasi.NoRekening, mutasi.TransaksiID)
+		if err != nil || !found {
+			return
+		}
+		if waktu, err = waktuMutasi(mutasi.Waktu); err != nil {
+			return
+		}
+		if waktuSebelumnya, err = waktuMutasi(sebelumnya.Waktu); err != nil {
+			return
+		}
+		if diam := waktu.Sub(waktuSebelumnya); diam >= jendela {
+			terkait = []string{sebelumnya.TransaksiID, mutasi.TransaksiID}
+			keterangan = fmt.Sprintf("rekening aktif kembali setelah %s tanpa transaksi", diam.Truncate(time.Second))
+		}
+	case models.AturanVelocity, models.AturanStructuring:
+		var riwayat []models.Mutasi
+		riwayat, err = t.mutasiDalamJendela(ctx, aturan, nik, mutasi)
+		if err != nil {
+			return
+		}
+		bawah := aturan.Ambang * (1 - aturan.Toleransi)
+		dalamRentang := func(nominal float64) bool {
+			return nominal >= bawah && nominal < aturan.Ambang
+		}
+		if aturan.Jenis == models.AturanStructuring && !dalamRentang(mutasi.Nominal) {
+			return
+		}
+		var cocok []string
+		for _, m := range riwayat {
+			if aturan.Jenis == models.AturanVelocity || dalamRentang(m.Nominal) {
+				cocok = append(cocok, m.TransaksiID)
+			}
+		}
+		if len(cocok) < aturan.Jumlah {
+			return
+		}
+		terkait = cocok
+		keterangan = fmt.Sprintf("%d transaksi dalam %s", len(cocok), aturan.Jendela)
+		if aturan.Jenis == models.AturanStructuring {
+			keterangan = fmt.Sprintf("%d transaksi antara Rp%.0f dan Rp%.0f dalam %s", len(cocok), bawah, aturan.Ambang, aturan.Jendela)
+		}
+	}
+	return
+}
+
+// mutasiDalamJendela returns the nasabah's transactions on all rekening over
+// the rule's window ending at mutasi.
+func (t *TabunganApp) mutasiDalamJendela(ctx context.Context, aturan models.AturanPemantauan, nik string, mutasi models.Mutasi) (riwayat []models.Mutasi, err error) {
+	jendela, err := time.ParseDuration(aturan.Jendela)
+	if err != nil {
+		return
+	}
+	waktu, err := waktuMutasi(mutasi.Waktu)
+	if err != nil {
+		return
+	}
+	return t.repo.GetMutasiNasabahSejak(ctx, nik, waktu.Add(-jendela).Format(layoutDetikMutasi))
+}
+
+// buatAlert stores an alert for the transaction. Velocity and structuring
+// patterns keep matching every further transaction while they last, so those
+// raise a new alert only once the previous one has been decided.
+func (t *TabunganApp) buatAlert(ctx context.Context, aturan models.AturanPemantauan, nik string, mutasi models.Mutasi, terkait []string, keterangan string) {
+	if aturan.Jenis == models.AturanVelocity || aturan.Jenis == models.AturanStructuring {
+		terbuka, err := t.repo.CountAlertTerbuka(ctx, nik, aturan.AturanID)
+		if err != nil || terbuka > 0 {
+			return
+		}
+	}
+	alert := models.AlertTransaksi{
+		AlertID:          genID(),
+		NIK:              nik,
+		NoRekening:       mutasi.NoRekening,
+		TransaksiID:      mutasi.TransaksiID,
+		TransaksiTerkait: terkait,
+		AturanID:         aturan.AturanID,
+		Jenis:            aturan.Jenis,
+		Severity:         aturan.Severity,
+		Keterangan:       keterangan,
+		Status:           models.AlertPending,
+		WaktuDibuat:      waktuSekarang(),
+	}
+	if err := t.repo.InsertAlert(ctx, alert); err != nil {
+		return
+	}
+	t.log.WithContext(ctx).WithFields(logrus.Fields{
+		"nik":          nik,
+		"no_rekening":  mutasi.NoRekening,
+		"transaksi_id": mutasi.TransaksiID,
+		"aturan_id":    aturan.AturanID,
+		"severity":     aturan.Severity,
+	}).Warn("transaksi memicu aturan pemantauan")
+	t.catatAudit(ctx, aksiAlertTransaksi, nik, nil, alert)
+}
+
+func (t *TabunganApp) GetDaftarAturan(ctx context.Context) (aturan []models.AturanPemantauan, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetDaftarAturan")
+	defer akhiriSpan(ctx, span, &err)
+	aturan, err = t.repo.GetDaftarAturan(ctx)
+	if err != nil {
+		err = errDomain(ErrInternal, "query aturan pemantauan gagal")
+		t.log.WithContext(ctx).Warn(err.Error())
+	}
+	return
+}
+
+// UbahAturan replaces the settings of a rule. Only admins may change rules,
+// and the rule's jenis cannot be changed.
+func (t *TabunganApp) UbahAturan(ctx context.Context, aturanID string, petugas models.Petugas, request models.RequestAturanPemantauan) (aturan models.AturanPemantauan, err error) {
+	ctx, span := t.mulaiSpan(ctx, "UbahAturan")
+	defer akhiriSpan(ctx, span, &err)
+	if petugas.Role != models.RoleAdmin {
+		err = errDomain(ErrTidakBerwenang, "aturan pemantauan hanya dapat diubah admin")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"aturan_id": aturanID,
+			"petugas":   petugas.ID,
+			"role":      petugas.Role,
+		}).Warn(err.Error())
+		return
+	}
+	aturan, err = t.repo.GetAturan(ctx, aturanID)
+	if err != nil {
+		err = errDomain(ErrTidakDitemukan, "aturan pemantauan tidak ditemukan")
+		t.log.WithContext(ctx).WithField("aturan_id", aturanID).Warn(err.Error())
+		return
+	}
+	sebelum := aturan
+	aturan.Aktif = request.Aktif
+	aturan.Severity = request.Severity
+	aturan.Ambang = request.Ambang
+	aturan.Jumlah = request.Jumlah
+	aturan.Jendela = request.Jendela
+	aturan.Toleransi = request.Toleransi
+	aturan.Petugas = petugas.ID
+	aturan.WaktuDiubah = waktuSekarang()
+	if err = validasiAturan(aturan); err != nil {
+		t.log.WithContext(ctx).WithField("aturan_id", aturanID).Warn(err.Error())
+		return
+	}
+	err = t.repo.UpdateAturan(ctx, aturan)
+	if err != nil {
+		err = errDomain(ErrInternal, "update aturan pemantauan gagal")
+		t.log.WithContext(ctx).WithField("aturan_id", aturanID).Warn(err.Error())
+		return
+	}
+	t.catatAudit(ctx, aksiUbahAturan, aturanID, sebelum, aturan)
+	return
+}
+
+func validasiAturan(aturan models.AturanPemantauan) (err error) {
+	switch aturan.Severity {
+	case models.SeverityRendah, models.SeveritySedang, models.SeverityTinggi:
+	default:
+		return errDomain(ErrValidasi, "severity harus %s, %s atau %s", models.SeverityRendah, models.SeveritySedang, models.SeverityTinggi)
+	}
+	perluAmbang := aturan.Jenis == models.AturanSetoranBesar || aturan.Jenis == models.AturanStructuring
+	perluJumlah := aturan.Jenis == models.AturanVelocity || aturan.Jenis == models.AturanStructuring
+	perluJendela := aturan.Jenis != models.AturanSetoranBesar
+	if perluAmbang && aturan.Ambang <= 0 {
+		return errDomain(ErrValidasi, "ambang harus lebih dari 0")
+	}
+	if perluJumlah && aturan.Jumlah < 1 {
+		return errDomain(ErrValidasi, "jumlah harus minimal 1")
+	}
+	if perluJendela {
+		jendela, errJendela := time.ParseDuration(aturan.Jendela)
+		if errJendela != nil || jendela <= 0 {
+			return errDomain(ErrValidasi, "jendela harus berupa durasi positif, misalnya 24h")
+		}
+	}
+	if aturan.Jenis == models.AturanStructuring && (aturan.Toleransi <= 0 || aturan.Toleransi >= 1) {
+		return errDomain(ErrValidasi, "toleransi harus di antara 0 dan 1")
+	}
+	return
+}
+
+func (t *TabunganApp) GetDaftarAlert(ctx context.Context, filter models.FilterAlert) (daftar []models.AlertTransaksi, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetDaftarAlert")
+	defer akhiriSpan(ctx, span, &err)
+	daftar, err = t.repo.GetDaftarAlert(ctx, filter)
+	if err != nil {
+		err = errDomain(ErrInternal, "query daftar alert gagal")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"status":    filter.Status,
+			"severity":  filter.Severity,
+			"aturan_id": filter.AturanID,
+			"nik":       filter.NIK,
+		}).Warn(err.Error())
+	}
+	return
+}
+
+func (t *TabunganApp) GetAlert(ctx context.Context, alertID string) (alert models.AlertTransaksi, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetAlert")
+	defer akhiriSpan(ctx, span, &err)
+	alert, err = t.repo.GetAlert(ctx, alertID)
+	if err != nil {
+		err = errDomain(ErrTidakDitemukan, "alert tidak ditemukan")
+		t.log.WithContext(ctx).WithField("alert_id", alertID).Warn(err.Error())
+	}
+	return
+}
+
+func (t *TabunganApp) KonfirmasiAlert(ctx context.Context, alertID string, petugas models.Petugas, request models.RequestKeputusanAlert) (alert models.AlertTransaksi, err error) {
+	ctx, span := t.mulaiSpan(ctx, "KonfirmasiAlert")
+	defer akhiriSpan(ctx, span, &err)
+	return t.putuskanAlert(ctx, alertID, petugas, models.AlertTerkonfirmasi, request.Catatan)
+}
+
+func (t *TabunganApp) AbaikanAlert(ctx context.Context, alertID string, petugas models.Petugas, request models.RequestKeputusanAlert) (alert models.AlertTransaksi, err error) {
+	ctx, span := t.mulaiSpan(ctx, "AbaikanAlert")
+	defer akhiriSpan(ctx, span, &err)
+	if request.Catatan == "" {
+		err = errDomain(ErrValidasi, "catatan wajib diisi untuk mengabaikan alert")
+		t.log.WithContext(ctx).WithField("alert_id", alertID).Warn(err.Error())
+		return
+	}
+	return t.putuskanAlert(ctx, alertID, petugas, models.AlertBukan, request.Catatan)
+}
+
+func (t *TabunganApp) putuskanAlert(ctx context.Context, alertID string, petugas models.Petugas, status, catatan string) (alert models.AlertTransaksi, err error) {
+	if petugas.Role != models.RoleSupervisor && petugas.Role != models.RoleAdmin {
+		err = errDomain(ErrTidakBerwenang, "petugas tidak berwenang memutuskan alert")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"alert_id": alertID,
+			"petugas":  petugas.ID,
+			"role":     petugas.Role,
+		}).Warn(err.Error())
+		return
+	}
+	alert, err = t.GetAlert(ctx, alertID)
+	if err != nil {
+		return
+	}
+	sebelum := alert
+	alert.Status = status
+	alert.Petugas = petugas.ID
+	alert.Catatan = catatan
+	alert.WaktuDiputus = waktuSekarang()
+	updated, err := t.repo.UpdateStatusAlert(ctx, alertID, models.AlertPending, alert)
+	if err != nil {
+		err = errDomain(ErrInternal, "update status alert gagal")
+		t.log.WithContext(ctx).WithField("alert_id", alertID).Warn(err.Error())
+		return
+	}
+	if !updated {
+		err = errDomain(ErrKonflik, "alert sudah diputuskan")
+		t.log.WithContext(ctx).WithField("alert_id", alertID).Warn(err.Error())
+		return
+	}
+	t.catatAudit(ctx, aksiPutuskanAlert, alert.NIK, sebelum, alert)
+	return
+}
@@ -0,0 +1,137 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"tabungan-api/models"
+	"tabungan-api/repository"
+	"testing"
+	"time"
+)
+
+// repoPemantauanUji serves the mutasi of one nasabah, in the order they were
+// committed, to the queries the monitoring rules make.
+type repoPemantauanUji struct {
+	repository.TabunganRepoInterface
+	mutasi []models.Mutasi
+}
+
+func (r *repoPemantauanUji) GetMutasiNasabahSejak(ctx context.Context, nik, sejak string) (mutasi []models.Mutasi, err error) {
+	for _, m := range r.mutasi {
+		if m.Waktu[:len(layoutDetikMutasi)] >= sejak {
+			mutasi = append(mutasi, m)
+		}
+	}
+	return
+}
+
+func (r *repoPemantauanUji) GetMutasiSebelumnya(ctx context.Context, noRekening, transaksiID string) (mutasi models.Mutasi, found bool, err error) {
+	for i, m := range r.mutasi {
+		if m.TransaksiID != transaksiID {
+			continue
+		}
+		for j := i - 1; j >= 0; j-- {
+			if r.mutasi[j].NoRekening == noRekening {
+				return r.mutasi[j], true, nil
+			}
+		}
+		return
+	}
+	return
+}
+
+// waktuUji is when the transaction under evaluation happens.
+var waktuUji = time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local)
+
+// mutasiUji is a transaction sebelum before waktuUji, stored with the
+// microseconds mutasi have.
+func mutasiUji(id, jenis string, nominal float64, sebelum time.Duration) models.Mutasi {
+	return models.Mutasi{TransaksiID: id, JenisMutasi: jenis, NoRekening: "0012345678", Nominal: nominal,
+		Waktu: waktuUji.Add(-sebelum).Format(models.LayoutWaktu)}
+}
+
+func TestEvaluasiAturan(t *testing.T) {
+	setoranBesar := models.AturanPemantauan{Jenis: models.AturanSetoranBesar, Ambang: 100000000}
+	dormant := models.AturanPemantauan{Jenis: models.AturanDormant, Jendela: "4320h"}
+	velocity := models.AturanPemantauan{Jenis: models.AturanVelocity, Jumlah: 3, Jendela: "1h"}
+	structuring := models.AturanPemantauan{Jenis: models.AturanStructuring, Ambang: 10000000, Toleransi: 0.1, Jumlah: 3, Jendela: "24h"}
+	const hari = 24 * time.Hour
+
+	cases := []struct {
+		nama       string
+		aturan     models.AturanPemantauan
+		sebelumnya []models.Mutasi
+		mutasi     models.Mutasi
+		// terkait are the IDs of the transactions that trip the rule, in
+		// order; empty when it must not trip.
+		terkait string
+	}{
+		{"setoran at the threshold", setoranBesar, nil, mutasiUji("m", "C", 100000000, 0), "m"},
+		{"setoran just below the threshold", setoranBesar, nil, mutasiUji("m", "C", 99999999.99, 0), ""},
+		{"tarik at the threshold", setoranBesar, nil, mutasiUji("m", "D", 100000000, 0), ""},
+
+		{"dormant for exactly the window", dormant, []models.Mutasi{mutasiUji("a", "C", 1000, 180*hari)},
+			mutasiUji("m", "C", 1000, 0), "a,m"},
+		{"dormant just short of the window", dormant, []models.Mutasi{mutasiUji("a", "C", 1000, 180*hari-time.Second)},
+			mutasiUji("m", "C", 1000, 0), ""},
+		{"first transaction of the rekening", dormant, nil, mutasiUji("m", "C", 1000, 0), ""},
+
+		{"velocity with the oldest at the window start", velocity,
+			[]models.Mutasi{mutasiUji("a", "D", 1000, time.Hour), mutasiUji("b", "C", 1000, 30*time.Minute)},
+			mutasiUji("m", "D", 1000, 0), "a,b,m"},
+		{"velocity with the oldest just outside the window", velocity,
+			[]models.Mutasi{mutasiUji("a", "D", 1000, time.Hour+time.Second), mutasiUji("b", "C", 1000, 30*time.Minute)},
+			mutasiUji("m", "D", 1000, 0), ""},
+
+		{"structuring at the bottom of the band", structuring,
+			[]models.Mutasi{mutasiUji("a", "C", 9000000, 24*time.Hour), mutasiUji("b", "C", 9500000, time.Hour)},
+			mutasiUji("m", "C", 9000000, 0), "a,b,m"},
+		{"structuring just below the band", structuring,
+			[]models.Mutasi{mutasiUji("a", "C", 8999999.99, time.Hour), mutasiUji("b", "C", 9500000, time.Hour)},
+			mutasiUji("m", "C", 9000000, 0), ""},
+		{"structuring at the threshold itself", structuring,
+			[]models.Mutasi{mutasiUji("a", "C", 9500000, time.Hour), mutasiUji("b", "C", 9500000, time.Hour)},
+			mutasiUji("m", "C", 10000000, 0), ""},
+		{"structuring with one just outside the window", structuring,
+			[]models.Mutasi{mutasiUji("a", "C", 9500000, 24*time.Hour+time.Second), mutasiUji("b", "C", 9500000, time.Hour)},
+			mutasiUji("m", "C", 9999999.99, 0), ""},
+	}
+	for _, c := range cases {
+		app := &TabunganApp{repo: &repoPemantauanUji{mutasi: append(c.sebelumnya, c.mutasi)}}
+		terkait, keterangan, err := app.evaluasiAturan(context.Background(), c.aturan, "3171012345678901", c.mutasi)
+		if err != nil {
+			t.Errorf("%s: %v", c.nama, err)
+			continue
+		}
+		if got := strings.Join(terkait, ","); got != c.terkait {
+			t.Errorf("%s: terkait = %q, want %q", c.nama, got, c.terkait)
+		}
+		if (keterangan != "") != (c.terkait != "") {
+			t.Errorf("%s: keterangan = %q", c.nama, keterangan)
+		}
+	}
+}
+
+func TestWaktuMutasi(t *testing.T) {
+	want := time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local)
+	for _, waktu := range []string{
+		"2024-03-01 12:00:00",
+		"2024-03-01 12:00:00.123456",
+		// The time.Time.String() form mutasi were stored in before LayoutWaktu.
+		"2024-03-01 12:00:00.123456789 +0700 WIB m=+0.000000001",
+	} {
+		got, err := waktuMutasi(waktu)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("waktuMutasi(%q) = %v, %v; want %v", waktu, got, err, want)
+		}
+	}
+	for _, waktu := range []string{"", "2024-03-01", "2024-03-01 12:00", "2024-13-01 12:00:00", "01-03-2024 12:00:00"} {
+		if _, err := waktuMutasi(waktu); err == nil {
+			t.Errorf("waktuMutasi(%q) accepted an invalid time", waktu)
+		}
+	}
+	if _, err := waktuMutasi("2024-03-01"); !errors.Is(err, ErrValidasi) {
+		t.Errorf("waktuMutasi of a date only: err = %v, want %v", err, ErrValidasi)
+	}
+}
@@ -0,0 +1,220 @@
+package app
+
+import (
+	"context"
+	"tabungan-api/models"
+	"tabungan-api/screening"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Watchlist hits scoring at least ambangScreeningReview are queued for
+	// review; from ambangScreeningBlokir the nasabah is frozen until a petugas
+	// decides.
+	ambangScreeningReview = 0.85
+	ambangScreeningBlokir = 0.95
+	batchRescreening      = 500
+)
+
+// WithDaftarPantauan enables watchlist screening against list. Without it no
+// nasabah is screened.
+func WithDaftarPantauan(list *screening.Watchlist) Option {
+	return func(t *TabunganApp) {
+		t.daftarPantauan = list
+	}
+}
+
+// screeningNasabah matches the nasabah's name against the watchlist and
+// records new hits. It returns how many were recorded.
+func (t *TabunganApp) screeningNasabah(ctx context.Context, nasabah models.Nasabah, pemicu string) (jumlah int) {
+	if t.daftarPantauan == nil {
+		return
+	}
+	for _, match := range t.daftarPantauan.Screen(nasabah.Nama, nasabah.TanggalLahir, ambangScreeningReview) {
+		tindakan := models.ScreeningReview
+		if match.Score >= ambangScreeningBlokir {
+			tindakan = models.ScreeningBlokir
+		}
+		hasil := models.HasilScreening{
+			ScreeningID: genID(),
+			NIK:         nasabah.NIK,
+			Nama:        nasabah.Nama,
+			EntriID:     match.Entry.ID,
+			NamaEntri:   match.MatchedName,
+			Sumber:      match.Entry.Source,
+			Skor:        match.Score,
+			Tindakan:    tindakan,
+			Pemicu:      pemicu,
+			VersiDaftar: t.daftarPantauan.Version(),
+			Status:      models.ScreeningPending,
+			WaktuDibuat: waktuSekarang(),
+		}
+		inserted, err := t.repo.InsertHasilScreening(ctx, hasil)
+		if err != nil || !inserted {
+			continue
+		}
+		jumlah++
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":      nasabah.NIK,
+			"entri_id": match.Entry.ID,
+			"skor":     match.Score,
+			"tindakan": tindakan,
+		}).Warn("nasabah cocok dengan daftar pantauan")
+		t.catatAudit(ctx, aksiScreening, nasabah.NIK, nil, hasil)
+	}
+	return
+}
+
+// RescreeningNasabah reloads the watchlist if its file changed and screens
+// every nasabah against it.
+func (t *TabunganApp) RescreeningNasabah(ctx context.Context) (jumlahNasabah, jumlahHit int, err error) {
+	ctx, span := t.mulaiSpan(ctx, "RescreeningNasabah")
+	defer akhiriSpan(ctx, span, &err)
+	if t.daftarPantauan == nil {
+		err = errDomain(ErrInternal, "daftar pantauan tidak dikonfigurasi")
+		t.log.WithContext(ctx).Warn(err.Error())
+		return
+	}
+	if _, errReload := t.daftarPantauan.Reload(); errReload != nil {
+		t.log.WithContext(ctx).WithField("error", errReload.Error()).Error("muat ulang daftar pantauan gagal, memakai daftar sebelumnya")
+	}
+	for offset := 0; ; offset += batchRescreening {
+		var daftar []models.Nasabah
+		daftar, err = t.repo.GetDaftarNasabah(ctx, batchRescreening, offset)
+		if err != nil {
+			err = errDomain(ErrInternal, "query daftar nasabah gagal")
+			t.log.WithContext(ctx).WithField("offset", offset).Warn(err.Error())
+			return
+		}
+		for _, nasabah := range daftar {
+			jumlahHit += t.screeningNasabah(ctx, nasabah, models.PemicuRescreening)
+		}
+		jumlahNasabah += len(daftar)
+		if len(daftar) < batchRescreening {
+			break
+		}
+	}
+	t.log.WithContext(ctx).WithFields(logrus.Fields{
+		"jumlah_nasabah": jumlahNasabah,
+		"jumlah_hit":     jumlahHit,
+		"versi_daftar":   t.daftarPantauan.Version(),
+	}).Info("rescreening nasabah selesai")
+	return
+}
+
+// JalankanRescreening runs RescreeningNasabah every interval until stop is
+// closed.
+func (t *TabunganApp) JalankanRescreening(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	sistem := t.Sebagai(models.MetadataRequest{Aktor: "rescreening", Role: models.RoleSistem})
+	for {
+		select {
+		case <-ticker.C:
+			sistem.RescreeningNasabah(context.Background())
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (t *TabunganApp) GetDaftarScreening(ctx context.Context, status, nik string) (daftar []models.HasilScreening, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetDaftarScreening")
+	defer akhiriSpan(ctx, span, &err)
+	daftar, err = t.repo.GetDaftarScreening(ctx, status, nik)
+	if err != nil {
+		err = errDomain(ErrInternal, "query daftar screening gagal")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"status": status,
+			"nik":    nik,
+		}).Warn(err.Error())
+	}
+	return
+}
+
+func (t *TabunganApp) KonfirmasiScreening(ctx context.Context, screeningID string, petugas models.Petugas, request models.RequestKeputusanScreening) (hasil models.HasilScreening, err error) {
+	ctx, span := t.mulaiSpan(ctx, "KonfirmasiScreening")
+	defer akhiriSpan(ctx, span, &err)
+	return t.putuskanScreening(ctx, screeningID, petugas, models.ScreeningTerkonfirmasi, request.Catatan)
+}
+
+func (t *TabunganApp) AbaikanScreening(ctx context.Context, screeningID string, petugas models.Petugas, request models.RequestKeputusanScreening) (hasil models.HasilScreening, err error) {
+	ctx, span := t.mulaiSpan(ctx, "AbaikanScreening")
+	defer akhiriSpan(ctx, span, &err)
+	if request.Catatan == "" {
+		err = errDomain(ErrValidasi, "catatan wajib diisi untuk mengabaikan hasil screening")
+		t.log.WithContext(ctx).WithField("screening_id", screeningID).Warn(err.Error())
+		return
+	}
+	return t.putuskanScreening(ctx, screeningID, petugas, models.ScreeningBukan, request.Catatan)
+}
+
+func (t *TabunganApp) putuskanScreening(ctx context.Context, screeningID string, petugas models.Petugas, status, catatan string) (hasil models.HasilScreening, err error) {
+	if petugas.Role != models.RoleSupervisor && petugas.Role != models.RoleAdmin {
+		err = errDomain(ErrTidakBerwenang, "petugas tidak berwenang memutuskan hasil screening")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"screening_id": screeningID,
+			"petugas":      petugas.ID,
+			"role":         petugas.Role,
+		}).Warn(err.Error())
+		return
+	}
+	hasil, err = t.repo.GetHasilScreening(ctx, screeningID)
+	if err != nil {
+		err = errDomain(ErrInternal, "query hasil screening gagal")
+		t.log.WithContext(ctx).WithField("screening_id", screeningID).Warn(err.Error())
+		return
+	}
+	sebelum := hasil
+	hasil.Status = status
+	hasil.Petugas = petugas.ID
+	hasil.Catatan = catatan
+	hasil.WaktuDiputus = waktuSekarang()
+	updated, err := t.repo.UpdateStatusScreening(ctx, screeningID, models.ScreeningPending, hasil)
+	if err != nil {
+		err = errDomain(ErrInternal, "update status screening gagal")
+		t.log.WithContext(ctx).WithField("screening_id", screeningID).Warn(err.Error())
+		return
+	}
+	if !updated {
+		err = errDomain(ErrKonflik, "hasil screening sudah diputuskan")
+		t.log.WithContext(ctx).WithField("screening_id", screeningID).Warn(err.Error())
+		return
+	}
+	t.catatAudit(ctx, aksiPutuskanScreening, hasil.NIK, sebelum, hasil)
+	return
+}
+
+// cekScreeningTransaksi refuses transactions of a frozen nasabah. The message
+// deliberately does not mention the watchlist.
+func (t *TabunganApp) cekScreeningTransaksi(ctx context.Context, nik string) (err error) {
+	blokir, _, err := t.repo.CountScreeningTerbuka(ctx, nik)
+	if err != nil {
+		err = errDomain(ErrInternal, "query hasil screening gagal")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
+		return
+	}
+	if blokir > 0 {
+		err = errDomain(ErrDiblokir, "transaksi tidak dapat diproses, silakan hubungi kantor cabang")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn("transaksi nasabah terblokir hasil screening")
+	}
+	return
+}
+
+// cekScreeningTerbuka fails while nik has watchlist hits that are pending or
+// confirmed.
+func (t *TabunganApp) cekScreeningTerbuka(ctx context.Context, nik string) (err error) {
+	blokir, pending, err := t.repo.CountScreeningTerbuka(ctx, nik)
+	if err != nil {
+		err = errDomain(ErrInternal, "query hasil screening gagal")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
+		return
+	}
+	if blokir > 0 || pending > 0 {
+		err = errDomain(ErrDiblokir, "nasabah memiliki hasil screening yang belum diselesaikan")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
+	}
+	return
+}
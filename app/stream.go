@@ -0,0 +1,135 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"tabungan-api/models"
+)
+
+// bufferStreamSaldo is how many updates a subscriber may fall behind before
+// it is dropped.
+const bufferStreamSaldo = 64
+
+// streamSaldo fans balance updates out to the open streams of each nasabah.
+type streamSaldo struct {
+	mu        sync.Mutex
+	pelanggan map[string]map[chan models.PembaruanSaldo]struct{}
+	ditutup   bool
+}
+
+func newStreamSaldo() *streamSaldo {
+	return &streamSaldo{pelanggan: make(map[string]map[chan models.PembaruanSaldo]struct{})}
+}
+
+// daftar returns nil once the hub is closed.
+func (s *streamSaldo) daftar(nik string) chan models.PembaruanSaldo {
+	ch := make(chan models.PembaruanSaldo, bufferStreamSaldo)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ditutup {
+		return nil
+	}
+	if s.pelanggan[nik] == nil {
+		s.pelanggan[nik] = make(map[chan models.PembaruanSaldo]struct{})
+	}
+	s.pelanggan[nik][ch] = struct{}{}
+	return ch
+}
+
+func (s *streamSaldo) hapus(nik string, ch chan models.PembaruanSaldo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.pelanggan[nik][ch]; !ok {
+		return
+	}
+	delete(s.pelanggan[nik], ch)
+	if len(s.pelanggan[nik]) == 0 {
+		delete(s.pelanggan, nik)
+	}
+	close(ch)
+}
+
+// kirim never blocks a transaction. A subscriber whose buffer is full is
+// closed instead; it reconnects and starts again from a fresh snapshot.
+func (s *streamSaldo) kirim(nik string, pembaruan models.PembaruanSaldo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.pelanggan[nik] {
+		select {
+		case ch <- pembaruan:
+		default:
+			delete(s.pelanggan[nik], ch)
+			close(ch)
+		}
+	}
+	if len(s.pelanggan[nik]) == 0 {
+		delete(s.pelanggan, nik)
+	}
+}
+
+// tutup closes every stream and refuses new ones.
+func (s *streamSaldo) tutup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ditutup = true
+	for nik, daftar := range s.pelanggan {
+		for ch := range daftar {
+			close(ch)
+		}
+		delete(s.pelanggan, nik)
+	}
+}
+
+// StreamSaldo subscribes to balance updates of every rekening owned by nik.
+// The current balances are returned as a snapshot taken after subscribing,
+// so no committed mutasi falls between the two; an update may repeat what
+// the snapshot already shows. The stream is closed when berhenti is called
+// or when the subscriber falls too far behind.
+func (t *TabunganApp) StreamSaldo(ctx context.Context, nik string) (snapshot []models.PembaruanSaldo, stream <-chan models.PembaruanSaldo, berhenti func(), err error) {
+	ctx, span := t.mulaiSpan(ctx, "StreamSaldo")
+	defer akhiriSpan(ctx, span, &err)
+	if _, err = t.GetNasabah(ctx, nik); err != nil {
+		return
+	}
+	ch := t.stream.daftar(nik)
+	if ch == nil {
+		err = errDomain(ErrInternal, "server sedang berhenti")
+		return
+	}
+	berhenti = func() { t.stream.hapus(nik, ch) }
+	daftarRekening, err := t.GetDaftarRekening(ctx, nik)
+	for _, noRekening := range daftarRekening {
+		if err != nil {
+			break
+		}
+		var rekening models.Rekening
+		if rekening, err = t.GetRekening(ctx, nik, noRekening); err == nil {
+			snapshot = append(snapshot, models.PembaruanSaldo{NoRekening: noRekening, Saldo: rekening.Saldo})
+		}
+	}
+	if err != nil {
+		berhenti()
+		berhenti = nil
+		snapshot = nil
+		err = errDomain(ErrInternal, "stream saldo gagal dibuka")
+		t.log.WithContext(ctx).WithField("nik", nik).Warn(err.Error())
+		return
+	}
+	stream = ch
+	return
+}
+
+// TutupStream closes every open balance stream so that shutdown does not
+// wait on them; clients reconnect to another instance.
+func (t *TabunganApp) TutupStream() {
+	t.stream.tutup()
+}
+
+// kirimSaldo pushes a committed mutasi to the nasabah's open streams.
+func (t *TabunganApp) kirimSaldo(nik string, mutasi models.Mutasi) {
+	t.stream.kirim(nik, models.PembaruanSaldo{
+		NoRekening: mutasi.NoRekening,
+		Saldo:      mutasi.SaldoAkhir,
+		Mutasi:     &mutasi,
+	})
+}
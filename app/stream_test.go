@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"tabungan-api/models"
+	"testing"
+)
+
+// terima drains what ch holds without blocking and reports whether ch was
+// closed.
+func terima(ch <-chan models.PembaruanSaldo) (pembaruan []models.PembaruanSaldo, tertutup bool) {
+	for {
+		select {
+		case p, ok := <-ch:
+			if !ok {
+				return pembaruan, true
+			}
+			pembaruan = append(pembaruan, p)
+		default:
+			return pembaruan, false
+		}
+	}
+}
+
+// TestStreamSaldoLambat checks that a subscriber that stops reading is
+// dropped once its buffer is full, without holding up the others.
+func TestStreamSaldoLambat(t *testing.T) {
+	s := newStreamSaldo()
+	lambat, cepat, lain := s.daftar(nikUji), s.daftar(nikUji), s.daftar("3171012345678902")
+	for i := 0; i <= bufferStreamSaldo; i++ {
+		s.kirim(nikUji, models.PembaruanSaldo{NoRekening: "0012345678", Saldo: float64(i)})
+		pembaruan, tertutup := terima(cepat)
+		if tertutup || len(pembaruan) != 1 {
+			t.Fatalf("update %d: the reading subscriber got %d updates, closed %v", i, len(pembaruan), tertutup)
+		}
+	}
+	pembaruan, tertutup := terima(lambat)
+	if !tertutup || len(pembaruan) != bufferStreamSaldo {
+		t.Errorf("the slow subscriber kept %d updates, closed %v; want %d then closed", len(pembaruan), tertutup, bufferStreamSaldo)
+	}
+	if pembaruan, tertutup := terima(lain); tertutup || len(pembaruan) != 0 {
+		t.Errorf("another nasabah's subscriber got %d updates, closed %v", len(pembaruan), tertutup)
+	}
+	// Unsubscribing a stream that was already dropped must not close it twice.
+	s.hapus(nikUji, lambat)
+	s.kirim(nikUji, models.PembaruanSaldo{NoRekening: "0012345678"})
+	if pembaruan, _ := terima(cepat); len(pembaruan) != 1 {
+		t.Errorf("the reading subscriber stopped receiving after the slow one was dropped")
+	}
+}
+
+// TestStreamSaldo follows a stream from its snapshot through a setor to
+// shutdown.
+func TestStreamSaldo(t *testing.T) {
+	ctx := context.Background()
+	app, noRekening := appUji(t)
+	if _, err := app.SetorDana(ctx, nikUji, noRekening, 100000); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, stream, berhenti, err := app.StreamSaldo(ctx, nikUji)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer berhenti()
+	if len(snapshot) != 1 || snapshot[0].NoRekening != noRekening || snapshot[0].Saldo != 100000 {
+		t.Errorf("snapshot = %+v, want %s at 100000", snapshot, noRekening)
+	}
+	if _, err = app.SetorDana(ctx, nikUji, noRekening, 50000); err != nil {
+		t.Fatal(err)
+	}
+	pembaruan, tertutup := terima(stream)
+	if tertutup || len(pembaruan) != 1 || pembaruan[0].Saldo != 150000 || pembaruan[0].Mutasi == nil ||
+		pembaruan[0].Mutasi.Nominal != 50000 {
+		t.Fatalf("after setor the stream got %+v, closed %v", pembaruan, tertutup)
+	}
+
+	app.TutupStream()
+	if _, tertutup = terima(stream); !tertutup {
+		t.Error("TutupStream left an open stream")
+	}
+	if _, _, _, err = app.StreamSaldo(ctx, nikUji); !errors.Is(err, ErrInternal) {
+		t.Errorf("StreamSaldo after TutupStream: err = %v, want %v", err, ErrInternal)
+	}
+	if _, err = app.SetorDana(ctx, nikUji, noRekening, 50000); err != nil {
+		t.Errorf("setor after TutupStream: %v", err)
+	}
+}
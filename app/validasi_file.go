@@ -0,0 +1,116 @@
+package app
+
+import (
+	"bytes"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+type aturanFile struct {
+	nama      string
+	maxUkuran int64
+	// tipe maps a sniffed MIME type to the file extensions accepted for it.
+	tipe      map[string][]string
+	minLebar  int
+	minTinggi int
+	maxLebar  int
+	maxTinggi int
+	// maxPiksel caps width times height of images that get decoded; zero
+	// leaves it to maxLebar and maxTinggi.
+	maxPiksel int
+}
+
+var aturanFoto = aturanFile{
+	nama:      "foto",
+	maxUkuran: 2 << 20,
+	tipe: map[string][]string{
+		"image/jpeg": {".jpg", ".jpeg"},
+		"image/png":  {".png"},
+	},
+	minLebar:  200,
+	minTinggi: 200,
+	maxLebar:  4096,
+	maxTinggi: 4096,
+	// Photos are decoded to be normalised, at four bytes a pixel per copy.
+	maxPiksel: 12000000,
+}
+
+var aturanDokumen = aturanFile{
+	nama:      "dokumen",
+	maxUkuran: 5 << 20,
+	tipe: map[string][]string{
+		"application/pdf": {".pdf"},
+		"image/jpeg":      {".jpg", ".jpeg"},
+	},
+	minLebar:  600,
+	minTinggi: 400,
+	maxLebar:  10000,
+	maxTinggi: 10000,
+}
+
+// validasiFile reads the whole upload and checks it against aturan. It returns
+// the content together with the extension and MIME type it should be stored
+// under.
+func validasiFile(file io.Reader, filename string, aturan aturanFile) (data []byte, ext, mime string, err error) {
+	data, err = io.ReadAll(io.LimitReader(file, aturan.maxUkuran+1))
+	if err != nil {
+		err = errDomain(ErrInternal, "gagal membaca %s", aturan.nama)
+		return
+	}
+	if len(data) == 0 {
+		err = errDomain(ErrValidasi, "%s kosong", aturan.nama)
+		return
+	}
+	if int64(len(data)) > aturan.maxUkuran {
+		err = errDomain(ErrValidasi, "ukuran %s melebihi batas %d MB", aturan.nama, aturan.maxUkuran>>20)
+		return
+	}
+	mime = http.DetectContentType(data)
+	if i := strings.Index(mime, ";"); i >= 0 {
+		mime = mime[:i]
+	}
+	extensions, ok := aturan.tipe[mime]
+	if !ok {
+		err = errDomain(ErrValidasi, "tipe %s %s tidak diizinkan", aturan.nama, mime)
+		return
+	}
+	ext = strings.ToLower(filepath.Ext(filename))
+	if !contains(extensions, ext) {
+		err = errDomain(ErrValidasi, "ekstensi %q tidak sesuai dengan isi %s (%s)", ext, aturan.nama, mime)
+		return
+	}
+	if !strings.HasPrefix(mime, "image/") {
+		return
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		err = errDomain(ErrValidasi, "%s bukan gambar yang valid", aturan.nama)
+		return
+	}
+	if config.Width < aturan.minLebar || config.Height < aturan.minTinggi {
+		err = errDomain(ErrValidasi, "dimensi %s minimal %dx%d piksel", aturan.nama, aturan.minLebar, aturan.minTinggi)
+		return
+	}
+	if config.Width > aturan.maxLebar || config.Height > aturan.maxTinggi {
+		err = errDomain(ErrValidasi, "dimensi %s maksimal %dx%d piksel", aturan.nama, aturan.maxLebar, aturan.maxTinggi)
+		return
+	}
+	if aturan.maxPiksel > 0 && config.Width*config.Height > aturan.maxPiksel {
+		err = errDomain(ErrValidasi, "%s maksimal %d megapiksel", aturan.nama, aturan.maxPiksel/1000000)
+	}
+	return
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+const pdfUji = "%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\ntrailer << /Root 1 0 R >>\n%%EOF\n"
+
+func pngUji(t *testing.T, lebar, tinggi int) string {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, lebar, tinggi))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func jpegUji(t *testing.T, lebar, tinggi int) string {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, lebar, tinggi)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+// sampai pads isi to exactly ukuran bytes; decoders ignore what follows the
+// end of the image.
+func sampai(isi string, ukuran int64) string {
+	return isi + strings.Repeat("\x00", int(ukuran)-len(isi))
+}
+
+func TestValidasiFile(t *testing.T) {
+	cases := []struct {
+		nama     string
+		aturan   aturanFile
+		filename string
+		isi      string
+		// ext and mime are what the file is stored under; empty when it must
+		// be rejected.
+		ext, mime string
+	}{
+		{"jpeg photo", aturanFoto, "foto.JPEG", jpegUji(t, 200, 200), ".jpeg", "image/jpeg"},
+		{"png photo", aturanFoto, "foto.png", pngUji(t, 200, 200), ".png", "image/png"},
+		{"pdf document", aturanDokumen, "ktp.pdf", pdfUji, ".pdf", "application/pdf"},
+		{"jpeg document", aturanDokumen, "ktp.jpg", jpegUji(t, 600, 400), ".jpg", "image/jpeg"},
+
+		{"png named as a jpeg", aturanFoto, "foto.jpg", pngUji(t, 200, 200), "", ""},
+		{"pdf named as a jpeg", aturanDokumen, "ktp.jpg", pdfUji, "", ""},
+		{"jpeg named as a pdf", aturanDokumen, "ktp.pdf", jpegUji(t, 600, 400), "", ""},
+		{"photo without an extension", aturanFoto, "foto", pngUji(t, 200, 200), "", ""},
+		{"pdf as a photo", aturanFoto, "foto.pdf", pdfUji, "", ""},
+		{"png as a document", aturanDokumen, "ktp.png", pngUji(t, 600, 400), "", ""},
+		{"executable named as a photo", aturanFoto, "foto.jpg", "MZ\x90\x00\x03\x00\x00\x00", "", ""},
+		{"truncated jpeg", aturanFoto, "foto.jpg", jpegUji(t, 200, 200)[:4], "", ""},
+		{"empty document", aturanDokumen, "ktp.pdf", "", "", ""},
+
+		{"photo at the size limit", aturanFoto, "foto.png", sampai(pngUji(t, 200, 200), aturanFoto.maxUkuran), ".png", "image/png"},
+		{"photo just over the size limit", aturanFoto, "foto.png", sampai(pngUji(t, 200, 200), aturanFoto.maxUkuran+1), "", ""},
+		{"document at the size limit", aturanDokumen, "ktp.pdf", sampai(pdfUji, aturanDokumen.maxUkuran), ".pdf", "application/pdf"},
+		{"document just over the size limit", aturanDokumen, "ktp.pdf", sampai(pdfUji, aturanDokumen.maxUkuran+1), "", ""},
+
+		{"photo just under the minimum width", aturanFoto, "foto.png", pngUji(t, 199, 200), "", ""},
+		{"photo just under the minimum height", aturanFoto, "foto.png", pngUji(t, 200, 199), "", ""},
+		{"photo at the maximum size", aturanFoto, "foto.png", pngUji(t, 4096, 2929), ".png", "image/png"},
+		{"photo just over the maximum width", aturanFoto, "foto.png", pngUji(t, 4097, 200), "", ""},
+		{"photo just over the maximum height", aturanFoto, "foto.png", pngUji(t, 200, 4097), "", ""},
+		{"photo just over the pixel limit", aturanFoto, "foto.png", pngUji(t, 4096, 2930), "", ""},
+		{"document just under the minimum width", aturanDokumen, "ktp.jpg", jpegUji(t, 599, 400), "", ""},
+		{"document just under the minimum height", aturanDokumen, "ktp.jpg", jpegUji(t, 600, 399), "", ""},
+	}
+	for _, c := range cases {
+		data, ext, mime, err := validasiFile(strings.NewReader(c.isi), c.filename, c.aturan)
+		if c.ext == "" {
+			if !errors.Is(err, ErrValidasi) {
+				t.Errorf("%s: err = %v, want %v", c.nama, err, ErrValidasi)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", c.nama, err)
+			continue
+		}
+		if ext != c.ext || mime != c.mime || string(data) != c.isi {
+			t.Errorf("%s: stored as %q, %q with %d bytes; want %q, %q with %d bytes",
+				c.nama, ext, mime, len(data), c.ext, c.mime, len(c.isi))
+		}
+	}
+}
@@ -0,0 +1,427 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"tabungan-api/events"
+	"tabungan-api/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const batchWebhook = 50
+
+// jedaUlangMaks caps the delay between retries of a delivery.
+const jedaUlangMaks = 24 * time.Hour
+
+// eventWebhook lists the event types partners can subscribe to.
+var eventWebhook = map[string]bool{
+	models.EventDanaDisetor: true,
+	models.EventDanaDitarik: true,
+}
+
+// WithWebhook enables delivery of webhook subscriptions. A failed delivery is
+// retried after jedaAwal, doubling every attempt up to a day, and is
+// dead-lettered after maksPercobaan attempts.
+func WithWebhook(client *http.Client, maksPercobaan int, jedaAwal time.Duration) Option {
+	return func(t *TabunganApp) {
+		t.webhook = client
+		t.maksPercobaanWebhook = maksPercobaan
+		t.jedaWebhook = jedaAwal
+	}
+}
+
+func (t *TabunganApp) BuatLanggananWebhook(ctx context.Context, petugas models.Petugas, request models.RequestLanggananWebhook) (langganan models.LanggananWebhook, err error) {
+	ctx, span := t.mulaiSpan(ctx, "BuatLanggananWebhook")
+	defer akhiriSpan(ctx, span, &err)
+	if petugas.Role != models.RoleAdmin {
+		err = errDomain(ErrTidakBerwenang, "langganan webhook hanya dapat diatur admin")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"petugas": petugas.ID,
+			"role":    petugas.Role,
+		}).Warn(err.Error())
+		return
+	}
+	langganan = models.LanggananWebhook{
+		LanggananID: genID(),
+		Aktif:       true,
+		WaktuDibuat: waktuSekarang(),
+	}
+	if request.Rahasia == "" {
+		if request.Rahasia, err = rahasiaWebhook(); err != nil {
+			err = errDomain(ErrInternal, "pembuatan rahasia webhook gagal")
+			t.log.WithContext(ctx).Warn(err.Error())
+			return
+		}
+	}
+	isiLangganan(&langganan, petugas, request)
+	if err = validasiLangganan(langganan); err != nil {
+		t.log.WithContext(ctx).WithField("partner", request.Partner).Warn(err.Error())
+		return
+	}
+	err = t.repo.InsertLanggananWebhook(ctx, langganan)
+	if err != nil {
+		err = errDomain(ErrInternal, "simpan langganan webhook gagal")
+		t.log.WithContext(ctx).WithField("partner", langganan.Partner).Warn(err.Error())
+		return
+	}
+	t.catatAudit(ctx, aksiLanggananWebhook, langganan.LanggananID, nil, tanpaRahasia(langganan))
+	return
+}
+
+// UbahLanggananWebhook replaces a subscription. The secret is kept unless a
+// new one is given, and is only returned in that case.
+func (t *TabunganApp) UbahLanggananWebhook(ctx context.Context, langgananID string, petugas models.Petugas, request models.RequestLanggananWebhook) (langganan models.LanggananWebhook, err error) {
+	ctx, span := t.mulaiSpan(ctx, "UbahLanggananWebhook")
+	defer akhiriSpan(ctx, span, &err)
+	if petugas.Role != models.RoleAdmin {
+		err = errDomain(ErrTidakBerwenang, "langganan webhook hanya dapat diatur admin")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"langganan_id": langgananID,
+			"petugas":      petugas.ID,
+			"role":         petugas.Role,
+		}).Warn(err.Error())
+		return
+	}
+	langganan, err = t.repo.GetLanggananWebhook(ctx, langgananID)
+	if err != nil {
+		err = errDomain(ErrTidakDitemukan, "langganan webhook tidak ditemukan")
+		t.log.WithContext(ctx).WithField("langganan_id", langgananID).Warn(err.Error())
+		return
+	}
+	sebelum := tanpaRahasia(langganan)
+	rahasiaBaru := request.Rahasia != ""
+	if !rahasiaBaru {
+		request.Rahasia = langganan.Rahasia
+	}
+	langganan.Aktif = true
+	isiLangganan(&langganan, petugas, request)
+	if err = validasiLangganan(langganan); err != nil {
+		t.log.WithContext(ctx).WithField("langganan_id", langgananID).Warn(err.Error())
+		return
+	}
+	err = t.repo.UpdateLanggananWebhook(ctx, langganan)
+	if err != nil {
+		err = errDomain(ErrInternal, "update langganan webhook gagal")
+		t.log.WithContext(ctx).WithField("langganan_id", langgananID).Warn(err.Error())
+		return
+	}
+	t.catatAudit(ctx, aksiLanggananWebhook, langgananID, sebelum, tanpaRahasia(langganan))
+	if !rahasiaBaru {
+		langganan = tanpaRahasia(langganan)
+	}
+	return
+}
+
+func isiLangganan(langganan *models.LanggananWebhook, petugas models.Petugas, request models.RequestLanggananWebhook) {
+	langganan.Partner = request.Partner
+	langganan.URL = request.URL
+	langganan.Rahasia = request.Rahasia
+	langganan.JenisEvent = request.JenisEvent
+	langganan.NoRekening = request.NoRekening
+	if langganan.NoRekening == nil {
+		langganan.NoRekening = []string{}
+	}
+	if request.Aktif != nil {
+		langganan.Aktif = *request.Aktif
+	}
+	langganan.Petugas = petugas.ID
+	langganan.WaktuDiubah = waktuSekarang()
+}
+
+func validasiLangganan(langganan models.LanggananWebhook) (err error) {
+	if langganan.Partner == "" {
+		return errDomain(ErrValidasi, "partner wajib diisi")
+	}
+	u, err := url.Parse(langganan.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errDomain(ErrValidasi, "url webhook harus berupa URL http atau https")
+	}
+	if len(langganan.Rahasia) < 16 {
+		return errDomain(ErrValidasi, "rahasia webhook minimal 16 karakter")
+	}
+	if len(langganan.JenisEvent) == 0 {
+		return errDomain(ErrValidasi, "jenis event wajib diisi")
+	}
+	for _, jenis := range langganan.JenisEvent {
+		if !eventWebhook[jenis] {
+			return errDomain(ErrValidasi, "jenis event %s tidak dapat dilanggan", jenis)
+		}
+	}
+	return
+}
+
+func rahasiaWebhook() (rahasia string, err error) {
+	buf := make([]byte, 32)
+	if _, err = rand.Read(buf); err != nil {
+		return
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func tanpaRahasia(langganan models.LanggananWebhook) models.LanggananWebhook {
+	langganan.Rahasia = ""
+	return langganan
+}
+
+func (t *TabunganApp) GetDaftarLanggananWebhook(ctx context.Context) (daftar []models.LanggananWebhook, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetDaftarLanggananWebhook")
+	defer akhiriSpan(ctx, span, &err)
+	daftar, err = t.repo.GetDaftarLanggananWebhook(ctx)
+	if err != nil {
+		err = errDomain(ErrInternal, "query langganan webhook gagal")
+		t.log.WithContext(ctx).Warn(err.Error())
+		return
+	}
+	for i := range daftar {
+		daftar[i] = tanpaRahasia(daftar[i])
+	}
+	return
+}
+
+func (t *TabunganApp) GetLanggananWebhook(ctx context.Context, langgananID string) (langganan models.LanggananWebhook, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetLanggananWebhook")
+	defer akhiriSpan(ctx, span, &err)
+	langganan, err = t.repo.GetLanggananWebhook(ctx, langgananID)
+	if err != nil {
+		err = errDomain(ErrTidakDitemukan, "langganan webhook tidak ditemukan")
+		t.log.WithContext(ctx).WithField("langganan_id", langgananID).Warn(err.Error())
+		return
+	}
+	langganan = tanpaRahasia(langganan)
+	return
+}
+
+// antreWebhook queues event for every active subscription that wants it.
+// Mutasi events are matched on the rekening they touch.
+func (t *TabunganApp) antreWebhook(ctx context.Context, event events.Event) (err error) {
+	if !eventWebhook[event.Type] {
+		return
+	}
+	daftar, err := t.repo.GetDaftarLanggananWebhook(ctx)
+	if err != nil {
+		return
+	}
+	var mutasi models.Mutasi
+	json.Unmarshal(event.Payload, &mutasi)
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	for _, langganan := range daftar {
+		if !langganan.Aktif || !berisi(langganan.JenisEvent, event.Type) ||
+			len(langganan.NoRekening) > 0 && !berisi(langganan.NoRekening, mutasi.NoRekening) {
+			continue
+		}
+		err = t.repo.AntreWebhook(ctx, models.PengirimanWebhook{
+			PengirimanID:    genID(),
+			LanggananID:     langganan.LanggananID,
+			EventID:         event.ID,
+			JenisEvent:      event.Type,
+			Payload:         string(payload),
+			Status:          models.WebhookPending,
+			KirimBerikutnya: waktuSekarang(),
+			WaktuDibuat:     waktuSekarang(),
+		})
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func berisi(daftar []string, nilai string) bool {
+	for _, s := range daftar {
+		if s == nilai {
+			return true
+		}
+	}
+	return false
+}
+
+// KirimWebhook delivers every delivery that is due. Each request is a POST of
+// the event as JSON, signed with the subscription's secret:
+//
+//	X-Webhook-Signature: sha256=hex(HMAC-SHA256(rahasia, X-Webhook-Timestamp + "." + body))
+//
+// Any 2xx response counts as delivered. Partners should deduplicate on
+// X-Webhook-ID, the event ID, since a delivery can arrive more than once.
+func (t *TabunganApp) KirimWebhook(ctx context.Context) (jumlah int, err error) {
+	ctx, span := t.mulaiSpan(ctx, "KirimWebhook")
+	defer akhiriSpan(ctx, span, &err)
+	if t.webhook == nil {
+		return
+	}
+	for {
+		var daftar []models.PengirimanWebhook
+		daftar, err = t.repo.GetWebhookSiapKirim(ctx, waktuSekarang(), batchWebhook)
+		if err != nil {
+			err = errDomain(ErrInternal, "query pengiriman webhook gagal")
+			t.log.WithContext(ctx).Warn(err.Error())
+			return
+		}
+		langganan := make(map[string]models.LanggananWebhook)
+		for _, pengiriman := range daftar {
+			l, ok := langganan[pengiriman.LanggananID]
+			if !ok {
+				if l, err = t.repo.GetLanggananWebhook(ctx, pengiriman.LanggananID); err != nil {
+					err = errDomain(ErrTidakDitemukan, "langganan webhook tidak ditemukan")
+					t.log.WithContext(ctx).WithField("pengiriman_id", pengiriman.PengirimanID).Warn(err.Error())
+					return
+				}
+				langganan[l.LanggananID] = l
+			}
+			if t.kirimWebhook(ctx, l, pengiriman) {
+				jumlah++
+			}
+		}
+		if len(daftar) < batchWebhook {
+			return
+		}
+	}
+}
+
+func (t *TabunganApp) kirimWebhook(ctx context.Context, langganan models.LanggananWebhook, pengiriman models.PengirimanWebhook) (terkirim bool) {
+	pengiriman.Percobaan++
+	pengiriman.StatusHTTP = 0
+	pengiriman.ErrorTerakhir = ""
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(langganan.Rahasia))
+	mac.Write([]byte(timestamp + "." + pengiriman.Payload))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, langganan.URL, bytes.NewBufferString(pengiriman.Payload))
+	if err == nil {
+		req.Header.Set("Content-Type", "application/json")
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+		req.Header.Set("X-Webhook-ID", pengiriman.EventID)
+		req.Header.Set("X-Webhook-Event", pengiriman.JenisEvent)
+		req.Header.Set("X-Webhook-Timestamp", timestamp)
+		req.Header.Set("X-Webhook-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		var resp *http.Response
+		resp, err = t.webhook.Do(req)
+		if err == nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+			pengiriman.StatusHTTP = resp.StatusCode
+			if resp.StatusCode < 200 || resp.StatusCode > 299 {
+				err = errDomain(ErrInternal, "HTTP %d", resp.StatusCode)
+			}
+		}
+	}
+	if err == nil {
+		terkirim = true
+		pengiriman.Status = models.WebhookTerkirim
+		pengiriman.WaktuTerkirim = waktuSekarang()
+	} else {
+		pengiriman.ErrorTerakhir = err.Error()
+		if pengiriman.Percobaan >= t.maksPercobaanWebhook {
+			pengiriman.Status = models.WebhookGagal
+		} else {
+			pengiriman.KirimBerikutnya = time.Now().Add(jedaUlang(t.jedaWebhook, pengiriman.Percobaan)).Format(models.LayoutWaktu)
+		}
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"pengiriman_id": pengiriman.PengirimanID,
+			"langganan_id":  langganan.LanggananID,
+			"partner":       langganan.Partner,
+			"percobaan":     pengiriman.Percobaan,
+			"status":        pengiriman.Status,
+			"error":         err.Error(),
+		}).Error("pengiriman webhook gagal")
+	}
+	t.repo.UpdatePengirimanWebhook(ctx, pengiriman)
+	return
+}
+
+// jedaUlang is how long to wait after failed attempt percobaan: awal after
+// the first, doubling every attempt but never more than jedaUlangMaks, so
+// that a high attempt count cannot overflow into a negative delay.
+func jedaUlang(awal time.Duration, percobaan int) (jeda time.Duration) {
+	jeda = awal
+	for i := 1; i < percobaan && jeda < jedaUlangMaks; i++ {
+		jeda *= 2
+	}
+	if jeda > jedaUlangMaks {
+		jeda = jedaUlangMaks
+	}
+	return
+}
+
+func (t *TabunganApp) GetDaftarPengirimanWebhook(ctx context.Context, langgananID, status string) (daftar []models.PengirimanWebhook, err error) {
+	ctx, span := t.mulaiSpan(ctx, "GetDaftarPengirimanWebhook")
+	defer akhiriSpan(ctx, span, &err)
+	daftar, err = t.repo.GetDaftarPengirimanWebhook(ctx, langgananID, status)
+	if err != nil {
+		err = errDomain(ErrInternal, "query pengiriman webhook gagal")
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"langganan_id": langgananID,
+			"status":       status,
+		}).Warn(err.Error())
+	}
+	return
+}
+
+// UlangiPengirimanWebhook sends one delivery again with a fresh retry
+// budget, whatever its status.
+func (t *TabunganApp) UlangiPengirimanWebhook(ctx context.Context, pengirimanID string, petugas models.Petugas) (pengiriman models.PengirimanWebhook, err error) {
+	ctx, span := t.mulaiSpan(ctx, "UlangiPengirimanWebhook")
+	defer akhiriSpan(ctx, span, &err)
+	sebelum, err := t.repo.GetPengirimanWebhook(ctx, pengirimanID)
+	if err != nil {
+		err = errDomain(ErrTidakDitemukan, "pengiriman webhook tidak ditemukan")
+		t.log.WithContext(ctx).WithField("pengiriman_id", pengirimanID).Warn(err.Error())
+		return
+	}
+	_, err = t.repo.UlangiPengirimanWebhook(ctx, pengirimanID, "", "", waktuSekarang())
+	if err == nil {
+		pengiriman, err = t.repo.GetPengirimanWebhook(ctx, pengirimanID)
+	}
+	if err != nil {
+		err = errDomain(ErrInternal, "ulangi pengiriman webhook gagal")
+		t.log.WithContext(ctx).WithField("pengiriman_id", pengirimanID).Warn(err.Error())
+		return
+	}
+	t.catatAudit(ctx, aksiUlangiWebhook, pengirimanID, sebelum, pengiriman)
+	return
+}
+
+// UlangiWebhookGagal requeues every dead-lettered delivery of a subscription.
+func (t *TabunganApp) UlangiWebhookGagal(ctx context.Context, langgananID string, petugas models.Petugas) (jumlah int, err error) {
+	ctx, span := t.mulaiSpan(ctx, "UlangiWebhookGagal")
+	defer akhiriSpan(ctx, span, &err)
+	if _, err = t.GetLanggananWebhook(ctx, langgananID); err != nil {
+		return
+	}
+	jumlah, err = t.repo.UlangiPengirimanWebhook(ctx, "", langgananID, models.WebhookGagal, waktuSekarang())
+	if err != nil {
+		err = errDomain(ErrInternal, "ulangi pengiriman webhook gagal")
+		t.log.WithContext(ctx).WithField("langganan_id", langgananID).Warn(err.Error())
+		return
+	}
+	t.catatAudit(ctx, aksiUlangiWebhook, langgananID, nil, map[string]int{"jumlah": jumlah})
+	return
+}
+
+// JalankanWebhook runs KirimWebhook every interval until stop is closed.
+func (t *TabunganApp) JalankanWebhook(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.KirimWebhook(context.Background())
+		case <-stop:
+			return
+		}
+	}
+}
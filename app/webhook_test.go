@@ -0,0 +1,179 @@
+package app
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"tabungan-api/encryption"
+	"tabungan-api/events"
+	"tabungan-api/models"
+	"tabungan-api/repository"
+	"tabungan-api/storage"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const nikUji = "3171012345678901"
+
+// appUji is an app on a fresh database with one registered nasabah, whose
+// rekening is returned.
+func appUji(t *testing.T, opts ...Option) (app *TabunganApp, noRekening string) {
+	dir := t.TempDir()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	keys, err := encryption.LoadKeyFile(filepath.Join(dir, "keys.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := repository.InitDatabase(filepath.Join(dir, "tabungan.db"), keys, logger)
+	t.Cleanup(func() { repo.Close() })
+	app = NewTabunganApp(storage.NewLocalStorage(filepath.Join(dir, "photo")),
+		storage.NewLocalStorage(filepath.Join(dir, "document")), repo, logger, opts...)
+	rekening, err := app.RegistrasiNasabah(context.Background(), models.RequestRegistrasiNasabah{NIK: nikUji,
+		Nama: "Budi Santoso", AlamatKTP: "Jl A", AlamatDomisili: "Jl A", JenisKelamin: "L", TanggalLahir: "1990-01-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app, rekening.NoRekening
+}
+
+// penerimaUji is a partner endpoint that answers with status and records
+// what it receives.
+type penerimaUji struct {
+	mu      sync.Mutex
+	status  int
+	request []*http.Request
+	body    []string
+}
+
+func (p *penerimaUji) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.request = append(p.request, r)
+	p.body = append(p.body, string(body))
+	w.WriteHeader(p.status)
+}
+
+func (p *penerimaUji) setStatus(status int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status = status
+}
+
+// TestKirimWebhook follows one delivery through signing, retries, the dead
+// letter after the last attempt and a replay.
+func TestKirimWebhook(t *testing.T) {
+	const rahasia = "rahasia-partner-uji"
+	const maksPercobaan = 3
+	penerima := &penerimaUji{status: http.StatusInternalServerError}
+	server := httptest.NewServer(penerima)
+	defer server.Close()
+	ctx := context.Background()
+	app, noRekening := appUji(t, WithWebhook(server.Client(), maksPercobaan, time.Millisecond))
+
+	admin := models.Petugas{ID: "admin-1", Role: models.RoleAdmin}
+	langganan, err := app.BuatLanggananWebhook(ctx, admin, models.RequestLanggananWebhook{Partner: "partner",
+		URL: server.URL, Rahasia: rahasia, JenisEvent: []string{models.EventDanaDisetor}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = app.SetorDana(ctx, nikUji, noRekening, 250000); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = app.RelayEvent(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	pengiriman := func() models.PengirimanWebhook {
+		daftar, err := app.GetDaftarPengirimanWebhook(ctx, langganan.LanggananID, "")
+		if err != nil || len(daftar) != 1 {
+			t.Fatalf("GetDaftarPengirimanWebhook = %+v, %v", daftar, err)
+		}
+		return daftar[0]
+	}
+	for percobaan := 1; percobaan <= maksPercobaan; percobaan++ {
+		time.Sleep(time.Duration(percobaan) * 5 * time.Millisecond)
+		if jumlah, err := app.KirimWebhook(ctx); err != nil || jumlah != 0 {
+			t.Fatalf("attempt %d: KirimWebhook = %d, %v", percobaan, jumlah, err)
+		}
+		p := pengiriman()
+		want := models.WebhookPending
+		if percobaan == maksPercobaan {
+			want = models.WebhookGagal
+		}
+		if p.Percobaan != percobaan || p.Status != want || p.StatusHTTP != http.StatusInternalServerError {
+			t.Errorf("after attempt %d: percobaan %d, status %q, HTTP %d; want %d, %q, 500",
+				percobaan, p.Percobaan, p.Status, p.StatusHTTP, percobaan, want)
+		}
+	}
+	if len(penerima.request) != maksPercobaan {
+		t.Fatalf("partner received %d requests, want %d", len(penerima.request), maksPercobaan)
+	}
+	if jumlah, _ := app.KirimWebhook(ctx); jumlah != 0 || len(penerima.request) != maksPercobaan {
+		t.Error("a dead-lettered delivery was sent again")
+	}
+
+	req, body := penerima.request[0], penerima.body[0]
+	mac := hmac.New(sha256.New, []byte(rahasia))
+	mac.Write([]byte(req.Header.Get("X-Webhook-Timestamp") + "." + body))
+	if got, want := req.Header.Get("X-Webhook-Signature"), "sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Errorf("X-Webhook-Signature = %q, want %q", got, want)
+	}
+	var event events.Event
+	if err = json.Unmarshal([]byte(body), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Type != models.EventDanaDisetor || event.AggregateID != noRekening ||
+		req.Header.Get("X-Webhook-ID") != event.ID || req.Header.Get("X-Webhook-Event") != event.Type {
+		t.Errorf("delivered %+v with headers %v", event, req.Header)
+	}
+
+	penerima.setStatus(http.StatusNoContent)
+	if jumlah, err := app.UlangiWebhookGagal(ctx, langganan.LanggananID, admin); err != nil || jumlah != 1 {
+		t.Fatalf("UlangiWebhookGagal = %d, %v", jumlah, err)
+	}
+	if p := pengiriman(); p.Status != models.WebhookPending || p.Percobaan != 0 {
+		t.Errorf("replayed delivery: status %q, percobaan %d; want pending with a fresh budget", p.Status, p.Percobaan)
+	}
+	if jumlah, err := app.KirimWebhook(ctx); err != nil || jumlah != 1 {
+		t.Fatalf("KirimWebhook after replay = %d, %v", jumlah, err)
+	}
+	if p := pengiriman(); p.Status != models.WebhookTerkirim || p.WaktuTerkirim == "" {
+		t.Errorf("replayed delivery: status %q, waktu_terkirim %q; want delivered", p.Status, p.WaktuTerkirim)
+	}
+	if len(penerima.body) != maksPercobaan+1 || penerima.body[maksPercobaan] != body {
+		t.Error("the replay did not send the same event again")
+	}
+}
+
+func TestJedaUlang(t *testing.T) {
+	cases := []struct {
+		awal      time.Duration
+		percobaan int
+		jeda      time.Duration
+	}{
+		{time.Minute, 1, time.Minute},
+		{time.Minute, 2, 2 * time.Minute},
+		{time.Minute, 5, 16 * time.Minute},
+		{time.Minute, 11, 1024 * time.Minute},
+		{time.Minute, 12, jedaUlangMaks},
+		{time.Minute, 64, jedaUlangMaks},
+		{time.Minute, 1000, jedaUlangMaks},
+		{48 * time.Hour, 1, jedaUlangMaks},
+	}
+	for _, c := range cases {
+		if got := jedaUlang(c.awal, c.percobaan); got != c.jeda {
+			t.Errorf("jedaUlang(%v, %d) = %v, want %v", c.awal, c.percobaan, got, c.jeda)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package authtoken
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	secret := []byte("rahasia")
+	now := time.Unix(1700000000, 0)
+	token, err := Sign(secret, Claims{Subject: "petugas-1", Role: "supervisor", Expires: now.Add(time.Hour).Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := Verify(secret, token, now)
+	if err != nil || claims.Subject != "petugas-1" || claims.Role != "supervisor" {
+		t.Fatalf("Verify = %+v, %v", claims, err)
+	}
+
+	if _, err := Verify(secret, token, now.Add(time.Hour)); !errors.Is(err, ErrExpired) {
+		t.Errorf("expired token: err = %v, want ErrExpired", err)
+	}
+	if _, err := Verify([]byte("lain"), token, now); !errors.Is(err, ErrInvalid) {
+		t.Errorf("token signed with another secret: err = %v, want ErrInvalid", err)
+	}
+
+	// Changing the role in the payload must invalidate the signature.
+	body, sig, _ := strings.Cut(token, ".")
+	payload, _ := base64.RawURLEncoding.DecodeString(body)
+	forged := strings.Replace(string(payload), "supervisor", "admin", 1)
+	forgedToken := base64.RawURLEncoding.EncodeToString([]byte(forged)) + "." + sig
+	if _, err := Verify(secret, forgedToken, now); !errors.Is(err, ErrInvalid) {
+		t.Errorf("forged role: err = %v, want ErrInvalid", err)
+	}
+
+	for _, token := range []string{"", "tanpa-titik", ".", token + "x"} {
+		if _, err := Verify(secret, token, now); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Verify(%q): err = %v, want ErrInvalid", token, err)
+		}
+	}
+	if _, err := Sign(nil, Claims{Subject: "petugas-1"}); err == nil {
+		t.Error("Sign accepted an empty secret")
+	}
+}
@@ -0,0 +1,143 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// KeyProvider supplies the key-encryption keys (KEK) used to wrap per-row data
+// keys, and the key used to compute blind indexes. Old KEKs must stay
+// available until every row wrapped with them has been rotated.
+type KeyProvider interface {
+	ActiveKeyID() string
+	Key(id string) (key []byte, err error)
+	IndexKey() []byte
+}
+
+type Cipher struct {
+	keys KeyProvider
+}
+
+// DataKey is a per-row data encryption key. Wrapped is the key sealed with the
+// KEK identified by KeyID and is what gets stored next to the row.
+type DataKey struct {
+	KeyID   string
+	Wrapped string
+	plain   []byte
+}
+
+func (c *Cipher) NewDataKey() (dek DataKey, err error) {
+	dek.KeyID = c.keys.ActiveKeyID()
+	kek, err := c.keys.Key(dek.KeyID)
+	if err != nil {
+		return
+	}
+	dek.plain = make([]byte, 32)
+	if _, err = io.ReadFull(rand.Reader, dek.plain); err != nil {
+		return
+	}
+	dek.Wrapped, err = seal(kek, dek.plain, []byte(dek.KeyID))
+	return
+}
+
+func (c *Cipher) OpenDataKey(keyID, wrapped string) (dek DataKey, err error) {
+	kek, err := c.keys.Key(keyID)
+	if err != nil {
+		return
+	}
+	dek.KeyID = keyID
+	dek.Wrapped = wrapped
+	dek.plain, err = open(kek, wrapped, []byte(keyID))
+	return
+}
+
+// RewrapDataKey seals the data key wrapped under keyID again with the active
+// KEK. Values encrypted with the data key stay valid, so rows that must not be
+// rewritten can still be moved off a retired KEK.
+func (c *Cipher) RewrapDataKey(keyID, wrapped string) (dek DataKey, err error) {
+	dek, err = c.OpenDataKey(keyID, wrapped)
+	if err != nil {
+		return
+	}
+	dek.KeyID = c.keys.ActiveKeyID()
+	kek, err := c.keys.Key(dek.KeyID)
+	if err != nil {
+		return
+	}
+	dek.Wrapped, err = seal(kek, dek.plain, []byte(dek.KeyID))
+	return
+}
+
+// BlindIndex returns a deterministic keyed hash of value so that encrypted
+// columns can still be looked up by equality.
+func (c *Cipher) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, c.keys.IndexKey())
+	mac.Write([]byte(strings.TrimSpace(value)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (c *Cipher) ActiveKeyID() string {
+	return c.keys.ActiveKeyID()
+}
+
+// Encrypt seals value for the given column. The column name is bound as
+// associated data so ciphertexts cannot be swapped between columns.
+func (d DataKey) Encrypt(column, value string) (ciphertext string, err error) {
+	return seal(d.plain, []byte(value), []byte(column))
+}
+
+func (d DataKey) Decrypt(column, ciphertext string) (value string, err error) {
+	plain, err := open(d.plain, ciphertext, []byte(column))
+	value = string(plain)
+	return
+}
+
+func seal(key, plaintext, additional []byte) (sealed string, err error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return
+	}
+	sealed = base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plaintext, additional))
+	return
+}
+
+func open(key []byte, sealed string, additional []byte) (plaintext []byte, err error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return
+	}
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return
+	}
+	if len(data) < gcm.NonceSize() {
+		err = fmt.Errorf("ciphertext too short")
+		return
+	}
+	plaintext, err = gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], additional)
+	return
+}
+
+func newGCM(key []byte) (gcm cipher.AEAD, err error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return
+	}
+	return cipher.NewGCM(block)
+}
+
+func NewCipher(keys KeyProvider) *Cipher {
+	return &Cipher{keys: keys}
+}
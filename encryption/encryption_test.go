@@ -0,0 +1,138 @@
+package encryption
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func bukaKeyFile(t *testing.T) (keys *LocalKeyFile, path string) {
+	path = filepath.Join(t.TempDir(), "keys.json")
+	keys, err := LoadKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestDataKeyRoundTrip(t *testing.T) {
+	keys, _ := bukaKeyFile(t)
+	c := NewCipher(keys)
+	dek, err := c.NewDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, err := dek.Encrypt("nama", "Budi Santoso")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct == "Budi Santoso" {
+		t.Fatal("ciphertext equals plaintext")
+	}
+	again, _ := dek.Encrypt("nama", "Budi Santoso")
+	if again == ct {
+		t.Fatal("two encryptions of the same value share a nonce")
+	}
+
+	dibuka, err := c.OpenDataKey(dek.KeyID, dek.Wrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := dibuka.Decrypt("nama", ct)
+	if err != nil || value != "Budi Santoso" {
+		t.Fatalf("decrypt = %q, %v", value, err)
+	}
+	if _, err := dibuka.Decrypt("alamat_ktp", ct); err == nil {
+		t.Fatal("ciphertext decrypted under another column")
+	}
+	lain, _ := c.NewDataKey()
+	if _, err := lain.Decrypt("nama", ct); err == nil {
+		t.Fatal("ciphertext decrypted with another data key")
+	}
+	if _, err := c.OpenDataKey(dek.KeyID, dek.Wrapped[:len(dek.Wrapped)-4]+"AAAA"); err == nil {
+		t.Fatal("tampered wrapped key opened")
+	}
+}
+
+func TestBlindIndexStable(t *testing.T) {
+	keys, path := bukaKeyFile(t)
+	c := NewCipher(keys)
+	index := c.BlindIndex("3171012345678901")
+	if index != c.BlindIndex(" 3171012345678901 ") {
+		t.Fatal("blind index depends on surrounding whitespace")
+	}
+	if index == c.BlindIndex("3171012345678902") {
+		t.Fatal("different values share a blind index")
+	}
+
+	if _, err := keys.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if c.BlindIndex("3171012345678901") != index {
+		t.Fatal("blind index changed after key rotation")
+	}
+	reloaded, err := LoadKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if NewCipher(reloaded).BlindIndex("3171012345678901") != index {
+		t.Fatal("blind index changed after reloading the key file")
+	}
+
+	other, _ := bukaKeyFile(t)
+	if NewCipher(other).BlindIndex("3171012345678901") == index {
+		t.Fatal("blind index does not depend on the index key")
+	}
+}
+
+func TestRotate(t *testing.T) {
+	keys, path := bukaKeyFile(t)
+	c := NewCipher(keys)
+	lama, err := c.NewDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, _ := lama.Encrypt("nik", "3171012345678901")
+
+	id, err := keys.Rotate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id == lama.KeyID || keys.ActiveKeyID() != id {
+		t.Fatalf("active key = %q, want new key %q", keys.ActiveKeyID(), id)
+	}
+	baru, _ := c.NewDataKey()
+	if baru.KeyID != id {
+		t.Fatalf("new data key wrapped under %q, want %q", baru.KeyID, id)
+	}
+
+	reloaded, err := LoadKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = NewCipher(reloaded)
+	if reloaded.ActiveKeyID() != id {
+		t.Fatal("rotation was not saved")
+	}
+	dek, err := c.OpenDataKey(lama.KeyID, lama.Wrapped)
+	if err != nil {
+		t.Fatalf("data key under the retired KEK no longer opens: %v", err)
+	}
+	if value, _ := dek.Decrypt("nik", ct); value != "3171012345678901" {
+		t.Fatalf("decrypt after rotation = %q", value)
+	}
+
+	dek, err = c.RewrapDataKey(lama.KeyID, lama.Wrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dek.KeyID != id || dek.Wrapped == lama.Wrapped {
+		t.Fatal("data key was not rewrapped under the active KEK")
+	}
+	dek, err = c.OpenDataKey(dek.KeyID, dek.Wrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := dek.Decrypt("nik", ct); value != "3171012345678901" {
+		t.Fatalf("decrypt after rewrap = %q", value)
+	}
+}
@@ -0,0 +1,117 @@
+package encryption
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// LocalKeyFile is a KeyProvider backed by a JSON file on local disk. It is
+// meant for single-node deployments; the file must be kept out of backups of
+// the database it protects.
+type LocalKeyFile struct {
+	mu   sync.RWMutex
+	path string
+	data keyFileData
+}
+
+type keyFileData struct {
+	Active   string            `json:"active"`
+	IndexKey string            `json:"index_key"`
+	Keys     map[string]string `json:"keys"`
+}
+
+func (l *LocalKeyFile) ActiveKeyID() string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.data.Active
+}
+
+func (l *LocalKeyFile) Key(id string) (key []byte, err error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	encoded, ok := l.data.Keys[id]
+	if !ok {
+		err = fmt.Errorf("key %q not found in %s", id, l.path)
+		return
+	}
+	return base64.StdEncoding.DecodeString(encoded)
+}
+
+func (l *LocalKeyFile) IndexKey() []byte {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	key, _ := base64.StdEncoding.DecodeString(l.data.IndexKey)
+	return key
+}
+
+// Rotate adds a freshly generated key, makes it the active one and saves the
+// file. Rows wrapped with older keys keep working until they are re-encrypted.
+func (l *LocalKeyFile) Rotate() (id string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	key, err := randomKey()
+	if err != nil {
+		return
+	}
+	id = uuid.NewString()
+	l.data.Keys[id] = key
+	l.data.Active = id
+	err = l.save()
+	return
+}
+
+func (l *LocalKeyFile) save() (err error) {
+	b, err := json.MarshalIndent(l.data, "", "  ")
+	if err != nil {
+		return
+	}
+	tmp := l.path + ".tmp"
+	err = os.WriteFile(tmp, b, 0600)
+	if err != nil {
+		return
+	}
+	return os.Rename(tmp, l.path)
+}
+
+func randomKey() (encoded string, err error) {
+	key := make([]byte, 32)
+	if _, err = io.ReadFull(rand.Reader, key); err != nil {
+		return
+	}
+	encoded = base64.StdEncoding.EncodeToString(key)
+	return
+}
+
+// LoadKeyFile reads the key file at path, creating it with a new active key and
+// index key when it does not exist yet.
+func LoadKeyFile(path string) (keys *LocalKeyFile, err error) {
+	keys = &LocalKeyFile{path: path}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		keys.data.Keys = make(map[string]string)
+		if keys.data.IndexKey, err = randomKey(); err != nil {
+			return
+		}
+		_, err = keys.Rotate()
+		return
+	}
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(b, &keys.data)
+	if err != nil {
+		return
+	}
+	if _, ok := keys.data.Keys[keys.data.Active]; !ok || keys.data.IndexKey == "" {
+		err = fmt.Errorf("key file %s has no active key or index key", path)
+	}
+	return
+}
@@ -0,0 +1,70 @@
+// Package events delivers domain events to other systems. Events are read
+// from the outbox by a relay and handed to every configured Sink; a sink may
+// see the same event more than once and consumers should deduplicate on ID.
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+type Event struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// AggregateID is the number of the rekening the event is about; a new
+	// nasabah is identified by the rekening opened with them. Events of one
+	// aggregate are published in the order they occurred.
+	AggregateID string          `json:"aggregate_id"`
+	OccurredAt  string          `json:"occurred_at"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+type Sink interface {
+	Name() string
+	// Publish returns only once the event is durably handed over.
+	Publish(event Event) error
+}
+
+// WriterSink writes each event as one line of JSON.
+type WriterSink struct {
+	name string
+	mu   sync.Mutex
+	w    io.Writer
+}
+
+func (s *WriterSink) Name() string {
+	return s.name
+}
+
+func (s *WriterSink) Publish(event Event) (err error) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	if err != nil {
+		return
+	}
+	if f, ok := s.w.(*os.File); ok && f != os.Stdout {
+		err = f.Sync()
+	}
+	return
+}
+
+func NewStdoutSink() *WriterSink {
+	return &WriterSink{name: "stdout", w: os.Stdout}
+}
+
+// NewFileSink appends events to path, syncing the file after every event.
+func NewFileSink(path string) (sink *WriterSink, err error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0640)
+	if err != nil {
+		return
+	}
+	sink = &WriterSink{name: "file", w: f}
+	return
+}
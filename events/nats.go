@@ -0,0 +1,231 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const natsTimeout = 5 * time.Second
+
+// NATSSink publishes each event to the subject prefix + "." + event type
+// over the NATS client protocol. Every publish is followed by a PING and
+// counts as delivered once the server's PONG arrives, so the server has
+// accepted the message; whether it is persisted depends on the server.
+type NATSSink struct {
+	address string
+	prefix  string
+	mu      sync.Mutex
+	conn    net.Conn
+	reader  *bufio.Reader
+}
+
+func (s *NATSSink) Name() string {
+	return "nats"
+}
+
+func (s *NATSSink) Publish(event Event) (err error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		if err = s.connect(); err != nil {
+			return
+		}
+	}
+	subject := s.prefix + "." + event.Type
+	err = s.send(fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", subject, len(data), data))
+	if err != nil {
+		s.close()
+	}
+	return
+}
+
+func (s *NATSSink) connect() (err error) {
+	s.conn, err = net.DialTimeout("tcp", s.address, natsTimeout)
+	if err != nil {
+		s.conn = nil
+		return
+	}
+	s.reader = bufio.NewReader(s.conn)
+	s.conn.SetReadDeadline(time.Now().Add(natsTimeout))
+	line, err := s.reader.ReadString('\n')
+	if err == nil && !strings.HasPrefix(line, "INFO ") {
+		err = fmt.Errorf("nats: unexpected greeting %q", strings.TrimSpace(line))
+	}
+	if err == nil {
+		err = s.send("CONNECT {\"verbose\":false,\"pedantic\":false,\"name\":\"tabungan-api\"}\r\nPING\r\n")
+	}
+	if err != nil {
+		s.close()
+	}
+	return
+}
+
+// send writes commands that end with a PING and waits for the PONG.
+func (s *NATSSink) send(commands string) (err error) {
+	s.conn.SetDeadline(time.Now().Add(natsTimeout))
+	if _, err = s.conn.Write([]byte(commands)); err != nil {
+		return
+	}
+	for {
+		var line string
+		line, err = s.reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return
+		case line == "PING":
+			if _, err = s.conn.Write([]byte("PONG\r\n")); err != nil {
+				return
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats: %s", line)
+		}
+	}
+}
+
+func (s *NATSSink) close() {
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.conn = nil
+	s.reader = nil
+}
+
+func NewNATSSink(address, prefix string) *NATSSink {
+	return &NATSSink{address: address, prefix: prefix}
+}
+
+// NATSStandIn is a minimal in-memory NATS-compatible server for local
+// development and tests. It understands CONNECT, PUB, SUB, UNSUB, PING and
+// PONG with the usual * and > wildcards, keeps nothing, and is not meant to
+// be exposed to a network.
+type NATSStandIn struct {
+	mu   sync.Mutex
+	subs map[*standInConn]map[string]string
+}
+
+type standInConn struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (c *standInConn) write(data string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn.Write([]byte(data))
+}
+
+// Serve accepts connections on listener until it is closed.
+func (s *NATSStandIn) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go s.handle(&standInConn{conn: conn})
+	}
+}
+
+func (s *NATSStandIn) handle(c *standInConn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.subs, c)
+		s.mu.Unlock()
+		c.conn.Close()
+	}()
+	c.write("INFO {\"server_id\":\"standin\",\"version\":\"2.0.0\",\"max_payload\":1048576}\r\n")
+	reader := bufio.NewReader(c.conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "CONNECT", "PONG":
+		case "PING":
+			c.write("PONG\r\n")
+		case "SUB":
+			// SUB subject [queue] sid
+			if len(fields) < 3 {
+				c.write("-ERR 'Invalid Subscription'\r\n")
+				continue
+			}
+			s.mu.Lock()
+			if s.subs == nil {
+				s.subs = make(map[*standInConn]map[string]string)
+			}
+			if s.subs[c] == nil {
+				s.subs[c] = make(map[string]string)
+			}
+			s.subs[c][fields[len(fields)-1]] = fields[1]
+			s.mu.Unlock()
+		case "UNSUB":
+			if len(fields) >= 2 {
+				s.mu.Lock()
+				delete(s.subs[c], fields[1])
+				s.mu.Unlock()
+			}
+		case "PUB":
+			// PUB subject [reply-to] size
+			var size int
+			if len(fields) < 3 {
+				c.write("-ERR 'Unknown Protocol Operation'\r\n")
+				return
+			}
+			if _, err = fmt.Sscan(fields[len(fields)-1], &size); err != nil || size < 0 {
+				c.write("-ERR 'Unknown Protocol Operation'\r\n")
+				return
+			}
+			payload := make([]byte, size+2)
+			if _, err = io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			s.deliver(fields[1], payload[:size])
+		default:
+			c.write("-ERR 'Unknown Protocol Operation'\r\n")
+		}
+	}
+}
+
+func (s *NATSStandIn) deliver(subject string, payload []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c, subs := range s.subs {
+		for sid, pattern := range subs {
+			if subjectMatches(pattern, subject) {
+				c.write(fmt.Sprintf("MSG %s %s %d\r\n%s\r\n", subject, sid, len(payload), payload))
+			}
+		}
+	}
+}
+
+func subjectMatches(pattern, subject string) bool {
+	want := strings.Split(pattern, ".")
+	got := strings.Split(subject, ".")
+	for i, token := range want {
+		if token == ">" {
+			return len(got) > i
+		}
+		if i >= len(got) || (token != "*" && token != got[i]) {
+			return false
+		}
+	}
+	return len(want) == len(got)
+}
@@ -0,0 +1,379 @@
+// Package grpcapi serves the nasabah operations of the app over gRPC for
+// internal services. The service is defined in tabungan.proto; the code in pb
+// is generated from it.
+package grpcapi
+
+//go:generate protoc --go_out=.. --go_opt=module=tabungan-api --go-grpc_out=.. --go-grpc_opt=module=tabungan-api tabungan.proto
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"tabungan-api/app"
+	"tabungan-api/grpcapi/pb"
+	"tabungan-api/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type TabunganGRPCAPI struct {
+	pb.UnimplementedTabunganServer
+	server *grpc.Server
+	host   string
+	port   int
+	app    app.TabunganAppInterface
+	log    *logrus.Logger
+}
+
+func NewGRPCAPI(host string, port int, app app.TabunganAppInterface, logger *logrus.Logger) *TabunganGRPCAPI {
+	api := &TabunganGRPCAPI{
+		server: grpc.NewServer(grpc.ChainUnaryInterceptor(lacakUnary, hitungUnary), grpc.ChainStreamInterceptor(lacakStream, hitungStream)),
+		host:   host,
+		port:   port,
+		app:    app,
+		log:    logger,
+	}
+	pb.RegisterTabunganServer(api.server, api)
+	return api
+}
+
+// Start serves until Shutdown is called, which makes it return nil.
+func (t *TabunganGRPCAPI) Start() (err error) {
+	addr := fmt.Sprintf("%s:%d", t.host, t.port)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return
+	}
+	return t.server.Serve(listener)
+}
+
+// Shutdown stops accepting calls and waits up to timeout for the calls in
+// flight to finish before cutting them off.
+func (t *TabunganGRPCAPI) Shutdown(timeout time.Duration) (err error) {
+	selesai := make(chan struct{})
+	go func() {
+		t.server.GracefulStop()
+		close(selesai)
+	}()
+	select {
+	case <-selesai:
+	case <-time.After(timeout):
+		t.server.Stop()
+		err = fmt.Errorf("call belum selesai setelah %s", timeout)
+	}
+	return
+}
+
+// getNIK reads the caller's NIK from the "authorization" metadata.
+func (t *TabunganGRPCAPI) getNIK(ctx context.Context) (nik string, err error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if nilai := md.Get("authorization"); len(nilai) > 0 {
+			nik = nilai[0]
+		}
+	}
+	if nik == "" {
+		err = fmt.Errorf("missing NIK in authorization metadata")
+		t.log.WithContext(ctx).Warn(err.Error())
+		return "", status.Error(codes.Unauthenticated, err.Error())
+	}
+	return
+}
+
+// appSebagai returns the app bound to the caller of this call so that
+// mutations are attributed to them in the audit log.
+func (t *TabunganGRPCAPI) appSebagai(ctx context.Context, aktor, role string) app.TabunganAppInterface {
+	meta := models.MetadataRequest{Aktor: aktor, Role: role}
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			meta.IP = host
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if nilai := md.Get("user-agent"); len(nilai) > 0 {
+			meta.UserAgent = nilai[0]
+		}
+		if nilai := md.Get("x-request-id"); len(nilai) > 0 {
+			meta.RequestID = nilai[0]
+		}
+	}
+	if meta.RequestID == "" {
+		meta.RequestID = uuid.NewString()
+	}
+	return t.app.Sebagai(meta)
+}
+
+// gagal maps an app error to the status returned to the client. App errors
+// describe invalid requests, as the 400 responses of the REST API do.
+func gagal(err error) error {
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// gagalPersetujuan is the status of a call whose PerluPersetujuan check
+// failed, chosen as the REST API does.
+func gagalPersetujuan(err error) error {
+	switch app.JenisError(err) {
+	case app.ErrTidakDitemukan:
+		return status.Error(codes.NotFound, err.Error())
+	case app.ErrInternal:
+		return status.Error(codes.Internal, err.Error())
+	default:
+		return gagal(err)
+	}
+}
+
+func (t *TabunganGRPCAPI) RegistrasiNasabah(ctx context.Context, request *pb.RequestRegistrasiNasabah) (*pb.Rekening, error) {
+	rekening, err := t.appSebagai(ctx, request.Nik, models.RoleNasabah).RegistrasiNasabah(ctx, models.RequestRegistrasiNasabah{
+		NIK:            request.Nik,
+		Nama:           request.Nama,
+		AlamatKTP:      request.AlamatKtp,
+		AlamatDomisili: request.AlamatDomisili,
+		JenisKelamin:   request.JenisKelamin,
+		TanggalLahir:   request.TanggalLahir,
+	})
+	if err != nil {
+		return nil, gagal(err)
+	}
+	return pbRekening(rekening), nil
+}
+
+func (t *TabunganGRPCAPI) GetNasabah(ctx context.Context, request *pb.RequestGetNasabah) (*pb.Nasabah, error) {
+	nik, err := t.getNIK(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if request.AsOf != "" {
+		nasabah, err := t.app.GetNasabahPerWaktu(ctx, nik, request.AsOf)
+		if err != nil {
+			return nil, gagal(err)
+		}
+		hasil := pbNasabah(nasabah.Nasabah)
+		hasil.Versi = int64(nasabah.Versi)
+		hasil.BerlakuSejak = nasabah.BerlakuSejak
+		return hasil, nil
+	}
+	nasabah, err := t.app.GetNasabah(ctx, nik)
+	if err != nil {
+		return nil, gagal(err)
+	}
+	return pbNasabah(nasabah), nil
+}
+
+func (t *TabunganGRPCAPI) UpdateNasabah(ctx context.Context, request *pb.RequestUpdateNasabah) (*pb.ResponseUpdateNasabah, error) {
+	nik, err := t.getNIK(ctx)
+	if err != nil {
+		return nil, err
+	}
+	operasi := models.RequestOperasi{
+		JenisOperasi:   models.OperasiUpdateNasabah,
+		NIK:            nik,
+		Nama:           request.Nama,
+		AlamatKTP:      request.AlamatKtp,
+		AlamatDomisili: request.AlamatDomisili,
+	}
+	perlu, err := t.app.PerluPersetujuan(ctx, operasi)
+	if err != nil {
+		return nil, gagalPersetujuan(err)
+	}
+	if perlu {
+		diajukan, err := t.appSebagai(ctx, nik, models.RoleNasabah).AjukanOperasi(ctx, nik, operasi)
+		if err != nil {
+			return nil, gagal(err)
+		}
+		return &pb.ResponseUpdateNasabah{Operasi: pbOperasi(diajukan)}, nil
+	}
+	err = t.appSebagai(ctx, nik, models.RoleNasabah).UpdateNasabah(ctx, nik, models.RequestUpdateNasabah{
+		Nama:           request.Nama,
+		AlamatKTP:      request.AlamatKtp,
+		AlamatDomisili: request.AlamatDomisili,
+	})
+	if err != nil {
+		return nil, gagal(err)
+	}
+	return &pb.ResponseUpdateNasabah{}, nil
+}
+
+func (t *TabunganGRPCAPI) GetDaftarRekening(ctx context.Context, request *pb.RequestGetDaftarRekening) (*pb.DaftarRekening, error) {
+	nik, err := t.getNIK(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rekening, err := t.app.GetDaftarRekening(ctx, nik)
+	if err != nil {
+		return nil, gagal(err)
+	}
+	return &pb.DaftarRekening{NoRekening: rekening}, nil
+}
+
+func (t *TabunganGRPCAPI) GetRekening(ctx context.Context, request *pb.RequestGetRekening) (*pb.Rekening, error) {
+	nik, err := t.getNIK(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rekening, err := t.app.GetRekening(ctx, nik, request.NoRekening)
+	if err != nil {
+		return nil, gagal(err)
+	}
+	return pbRekening(rekening), nil
+}
+
+func (t *TabunganGRPCAPI) TarikDana(ctx context.Context, request *pb.RequestTarikSetorDana) (*pb.ResponseTarikSetorDana, error) {
+	nik, err := t.getNIK(ctx)
+	if err != nil {
+		return nil, err
+	}
+	operasi := models.RequestOperasi{
+		JenisOperasi: models.OperasiTarikDana,
+		NIK:          nik,
+		NoRekening:   request.NoRekening,
+		Nominal:      request.Nominal,
+	}
+	perlu, err := t.app.PerluPersetujuan(ctx, operasi)
+	if err != nil {
+		return nil, gagalPersetujuan(err)
+	}
+	if perlu {
+		diajukan, err := t.appSebagai(ctx, nik, models.RoleNasabah).AjukanOperasi(ctx, nik, operasi)
+		if err != nil {
+			return nil, gagal(err)
+		}
+		return &pb.ResponseTarikSetorDana{Operasi: pbOperasi(diajukan)}, nil
+	}
+	saldoAkhir, err := t.appSebagai(ctx, nik, models.RoleNasabah).TarikDana(ctx, nik, request.NoRekening, request.Nominal)
+	if err != nil {
+		return nil, gagal(err)
+	}
+	return &pb.ResponseTarikSetorDana{SaldoAkhir: saldoAkhir}, nil
+}
+
+func (t *TabunganGRPCAPI) SetorDana(ctx context.Context, request *pb.RequestTarikSetorDana) (*pb.ResponseTarikSetorDana, error) {
+	nik, err := t.getNIK(ctx)
+	if err != nil {
+		return nil, err
+	}
+	saldoAkhir, err := t.appSebagai(ctx, nik, models.RoleNasabah).SetorDana(ctx, nik, request.NoRekening, request.Nominal)
+	if err != nil {
+		return nil, gagal(err)
+	}
+	return &pb.ResponseTarikSetorDana{SaldoAkhir: saldoAkhir}, nil
+}
+
+// GetMutasi only lists rekening of the caller, unlike the REST route.
+func (t *TabunganGRPCAPI) GetMutasi(request *pb.RequestGetMutasi, stream pb.Tabungan_GetMutasiServer) error {
+	ctx := stream.Context()
+	nik, err := t.getNIK(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err = t.app.GetRekening(ctx, nik, request.NoRekening); err != nil {
+		return gagal(err)
+	}
+	page, show := int(request.Page), int(request.Show)
+	if page == 0 {
+		page = 1
+	}
+	if show == 0 {
+		show = 1
+	}
+	mutasi, err := t.app.GetMutasi(ctx, request.NoRekening, page, show)
+	if err != nil {
+		return gagal(err)
+	}
+	for _, m := range mutasi {
+		if err = stream.Send(pbMutasi(m)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StreamSaldo sends the same updates as the server-sent events of the REST
+// API until the client cancels, falls too far behind or the server shuts
+// down; in the latter two cases the client should reconnect.
+func (t *TabunganGRPCAPI) StreamSaldo(request *pb.RequestStreamSaldo, stream pb.Tabungan_StreamSaldoServer) error {
+	ctx := stream.Context()
+	nik, err := t.getNIK(ctx)
+	if err != nil {
+		return err
+	}
+	snapshot, pembaruan, berhenti, err := t.app.StreamSaldo(ctx, nik)
+	if err != nil {
+		return gagal(err)
+	}
+	defer berhenti()
+	for _, saldo := range snapshot {
+		if err = stream.Send(pbPembaruanSaldo(saldo)); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case saldo, ok := <-pembaruan:
+			if !ok {
+				return status.Error(codes.Unavailable, "stream ditutup, silakan sambung ulang")
+			}
+			if err = stream.Send(pbPembaruanSaldo(saldo)); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func pbRekening(rekening models.Rekening) *pb.Rekening {
+	return &pb.Rekening{
+		Nik:        rekening.NIK,
+		NoRekening: rekening.NoRekening,
+		Saldo:      rekening.Saldo,
+	}
+}
+
+func pbNasabah(nasabah models.Nasabah) *pb.Nasabah {
+	return &pb.Nasabah{
+		Nik:            nasabah.NIK,
+		Nama:           nasabah.Nama,
+		AlamatKtp:      nasabah.AlamatKTP,
+		AlamatDomisili: nasabah.AlamatDomisili,
+		JenisKelamin:   nasabah.JenisKelamin,
+		TanggalLahir:   nasabah.TanggalLahir,
+	}
+}
+
+func pbOperasi(operasi models.Operasi) *pb.Operasi {
+	return &pb.Operasi{
+		OperasiId:    operasi.OperasiID,
+		JenisOperasi: operasi.JenisOperasi,
+		Status:       operasi.Status,
+		WaktuDibuat:  operasi.WaktuDibuat,
+	}
+}
+
+func pbMutasi(mutasi models.Mutasi) *pb.Mutasi {
+	return &pb.Mutasi{
+		TransaksiId: mutasi.TransaksiID,
+		Waktu:       mutasi.Waktu,
+		JenisMutasi: mutasi.JenisMutasi,
+		NoRekening:  mutasi.NoRekening,
+		Nominal:     mutasi.Nominal,
+		SaldoAwal:   mutasi.SaldoAwal,
+		SaldoAkhir:  mutasi.SaldoAkhir,
+	}
+}
+
+func pbPembaruanSaldo(pembaruan models.PembaruanSaldo) *pb.PembaruanSaldo {
+	hasil := &pb.PembaruanSaldo{
+		NoRekening: pembaruan.NoRekening,
+		Saldo:      pembaruan.Saldo,
+	}
+	if pembaruan.Mutasi != nil {
+		hasil.Mutasi = pbMutasi(*pembaruan.Mutasi)
+	}
+	return hasil
+}
@@ -0,0 +1,167 @@
+package grpcapi
+
+import (
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"tabungan-api/app"
+	"tabungan-api/encryption"
+	"tabungan-api/grpcapi/pb"
+	"tabungan-api/repository"
+	"tabungan-api/storage"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const nikUji = "3171012345678901"
+
+// grpcUji serves a fresh app over an in-memory connection and returns a
+// client of it.
+func grpcUji(t *testing.T) (pb.TabunganClient, *app.TabunganApp) {
+	dir := t.TempDir()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	keys, err := encryption.LoadKeyFile(filepath.Join(dir, "keys.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := repository.InitDatabase(filepath.Join(dir, "tabungan.db"), keys, logger)
+	t.Cleanup(func() { repo.Close() })
+	tabungan := app.NewTabunganApp(storage.NewLocalStorage(filepath.Join(dir, "photo")),
+		storage.NewLocalStorage(filepath.Join(dir, "document")), repo, logger)
+
+	api := NewGRPCAPI("", 0, tabungan, logger)
+	listener := bufconn.Listen(1 << 20)
+	go api.server.Serve(listener)
+	t.Cleanup(api.server.Stop)
+	conn, err := grpc.Dial("bufconn", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewTabunganClient(conn), tabungan
+}
+
+func sebagai(nik string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", nik)
+}
+
+func periksaKode(t *testing.T, panggilan string, err error, kode codes.Code) {
+	t.Helper()
+	if status.Code(err) != kode {
+		t.Errorf("%s: err = %v, want code %s", panggilan, err, kode)
+	}
+}
+
+func TestGRPC(t *testing.T) {
+	client, _ := grpcUji(t)
+	rekening, err := client.RegistrasiNasabah(context.Background(), &pb.RequestRegistrasiNasabah{Nik: nikUji,
+		Nama: "Budi Santoso", AlamatKtp: "Jl A", AlamatDomisili: "Jl A", JenisKelamin: "L", TanggalLahir: "1990-01-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := sebagai(nikUji)
+
+	_, err = client.GetNasabah(context.Background(), &pb.RequestGetNasabah{})
+	periksaKode(t, "GetNasabah without authorization", err, codes.Unauthenticated)
+	nasabah, err := client.GetNasabah(ctx, &pb.RequestGetNasabah{})
+	if err != nil || nasabah.Nama != "Budi Santoso" || nasabah.Nik != nikUji {
+		t.Errorf("GetNasabah = %v, %v", nasabah, err)
+	}
+	if nasabah, err = client.GetNasabah(ctx, &pb.RequestGetNasabah{AsOf: "2999-01-01"}); err != nil || nasabah.Versi != 1 {
+		t.Errorf("GetNasabah as of a later date = %v, %v; want version 1", nasabah, err)
+	}
+
+	daftar, err := client.GetDaftarRekening(ctx, &pb.RequestGetDaftarRekening{})
+	if err != nil || len(daftar.NoRekening) != 1 || daftar.NoRekening[0] != rekening.NoRekening {
+		t.Errorf("GetDaftarRekening = %v, %v; want [%s]", daftar, err, rekening.NoRekening)
+	}
+	setor, err := client.SetorDana(ctx, &pb.RequestTarikSetorDana{NoRekening: rekening.NoRekening, Nominal: 100000})
+	if err != nil || setor.SaldoAkhir != 100000 {
+		t.Errorf("SetorDana = %v, %v", setor, err)
+	}
+	tarik, err := client.TarikDana(ctx, &pb.RequestTarikSetorDana{NoRekening: rekening.NoRekening, Nominal: 40000})
+	if err != nil || tarik.SaldoAkhir != 60000 || tarik.Operasi != nil {
+		t.Errorf("TarikDana = %v, %v", tarik, err)
+	}
+	_, err = client.TarikDana(ctx, &pb.RequestTarikSetorDana{NoRekening: rekening.NoRekening, Nominal: 100000})
+	periksaKode(t, "TarikDana over the balance", err, codes.InvalidArgument)
+	if saldo, err := client.GetRekening(ctx, &pb.RequestGetRekening{NoRekening: rekening.NoRekening}); err != nil || saldo.Saldo != 60000 {
+		t.Errorf("GetRekening = %v, %v; want saldo 60000", saldo, err)
+	}
+
+	stream, err := client.GetMutasi(ctx, &pb.RequestGetMutasi{NoRekening: rekening.NoRekening, Page: 1, Show: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jenis string
+	for {
+		mutasi, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("GetMutasi: %v", err)
+		}
+		jenis += mutasi.JenisMutasi
+	}
+	if len(jenis) != 2 {
+		t.Errorf("GetMutasi sent mutasi %q, want the setor and the tarik", jenis)
+	}
+	stream, err = client.GetMutasi(sebagai("3171012345678902"), &pb.RequestGetMutasi{NoRekening: rekening.NoRekening})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	periksaKode(t, "GetMutasi of another nasabah's rekening", err, codes.InvalidArgument)
+
+	_, err = client.UpdateNasabah(sebagai("3171012345678902"), &pb.RequestUpdateNasabah{Nama: "Sari", AlamatKtp: "Jl B",
+		AlamatDomisili: "Jl B"})
+	periksaKode(t, "UpdateNasabah of an unknown nasabah", err, codes.NotFound)
+	diajukan, err := client.UpdateNasabah(ctx, &pb.RequestUpdateNasabah{Nama: "Budi Santosa", AlamatKtp: "Jl A",
+		AlamatDomisili: "Jl A"})
+	if err != nil || diajukan.Operasi == nil || diajukan.Operasi.OperasiId == "" {
+		t.Errorf("UpdateNasabah of the nama = %v, %v; want it submitted for approval", diajukan, err)
+	}
+}
+
+// TestGRPCStreamSaldo follows a balance stream from its snapshot through a
+// setor to the server shutting streams down.
+func TestGRPCStreamSaldo(t *testing.T) {
+	client, tabungan := grpcUji(t)
+	rekening, err := client.RegistrasiNasabah(context.Background(), &pb.RequestRegistrasiNasabah{Nik: nikUji,
+		Nama: "Budi Santoso", AlamatKtp: "Jl A", AlamatDomisili: "Jl A", JenisKelamin: "L", TanggalLahir: "1990-01-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(sebagai(nikUji))
+	defer cancel()
+	stream, err := client.StreamSaldo(ctx, &pb.RequestStreamSaldo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := stream.Recv()
+	if err != nil || snapshot.NoRekening != rekening.NoRekening || snapshot.Saldo != 0 || snapshot.Mutasi != nil {
+		t.Fatalf("snapshot = %v, %v", snapshot, err)
+	}
+	if _, err = client.SetorDana(ctx, &pb.RequestTarikSetorDana{NoRekening: rekening.NoRekening, Nominal: 250000}); err != nil {
+		t.Fatal(err)
+	}
+	pembaruan, err := stream.Recv()
+	if err != nil || pembaruan.Saldo != 250000 || pembaruan.Mutasi == nil || pembaruan.Mutasi.Nominal != 250000 {
+		t.Fatalf("update after setor = %v, %v", pembaruan, err)
+	}
+	tabungan.TutupStream()
+	_, err = stream.Recv()
+	periksaKode(t, "StreamSaldo after shutdown", err, codes.Unavailable)
+}
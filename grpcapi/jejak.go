@@ -0,0 +1,76 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+	"tabungan-api/tracing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var tracer = otel.Tracer("tabungan-api/grpcapi")
+
+// mulaiSpan starts the span of a call, continuing the caller's trace when
+// it sent traceparent metadata.
+func mulaiSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	return tracer.Start(ctx, strings.TrimPrefix(fullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("rpc.system", "grpc"), attribute.String("rpc.method", fullMethod)))
+}
+
+func akhiriSpan(span trace.Span, err error) {
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(status.Code(err))))
+	tracing.End(span, err)
+}
+
+func lacakUnary(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (response interface{}, err error) {
+	ctx, span := mulaiSpan(ctx, info.FullMethod)
+	response, err = handler(ctx, request)
+	akhiriSpan(span, err)
+	return
+}
+
+func lacakStream(server interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	ctx, span := mulaiSpan(stream.Context(), info.FullMethod)
+	err = handler(server, streamTerlacak{ServerStream: stream, ctx: ctx})
+	akhiriSpan(span, err)
+	return
+}
+
+// streamTerlacak is stream with the span of the call in its context.
+type streamTerlacak struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s streamTerlacak) Context() context.Context {
+	return s.ctx
+}
+
+// metadataCarrier reads and writes gRPC metadata for propagators.
+type metadataCarrier metadata.MD
+
+func (m metadataCarrier) Get(key string) string {
+	if nilai := metadata.MD(m).Get(key); len(nilai) > 0 {
+		return nilai[0]
+	}
+	return ""
+}
+
+func (m metadataCarrier) Set(key, value string) {
+	metadata.MD(m).Set(key, value)
+}
+
+func (m metadataCarrier) Keys() (keys []string) {
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return
+}
@@ -0,0 +1,23 @@
+package grpcapi
+
+import (
+	"context"
+	"tabungan-api/app"
+
+	"google.golang.org/grpc"
+)
+
+// hitungUnary counts the error the app answered a call with.
+func hitungUnary(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (response interface{}, err error) {
+	ctx, hitungError := app.HitungError(ctx)
+	response, err = handler(ctx, request)
+	hitungError()
+	return
+}
+
+func hitungStream(server interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	ctx, hitungError := app.HitungError(stream.Context())
+	err = handler(server, streamTerlacak{ServerStream: stream, ctx: ctx})
+	hitungError()
+	return
+}
@@ -0,0 +1,1446 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        v3.20.3
+// source: tabungan.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RequestRegistrasiNasabah struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Nik            string `protobuf:"bytes,1,opt,name=nik,proto3" json:"nik,omitempty"`
+	Nama           string `protobuf:"bytes,2,opt,name=nama,proto3" json:"nama,omitempty"`
+	AlamatKtp      string `protobuf:"bytes,3,opt,name=alamat_ktp,json=alamatKtp,proto3" json:"alamat_ktp,omitempty"`
+	AlamatDomisili string `protobuf:"bytes,4,opt,name=alamat_domisili,json=alamatDomisili,proto3" json:"alamat_domisili,omitempty"`
+	JenisKelamin   string `protobuf:"bytes,5,opt,name=jenis_kelamin,json=jenisKelamin,proto3" json:"jenis_kelamin,omitempty"`
+	TanggalLahir   string `protobuf:"bytes,6,opt,name=tanggal_lahir,json=tanggalLahir,proto3" json:"tanggal_lahir,omitempty"`
+}
+
+func (x *RequestRegistrasiNasabah) Reset() {
+	*x = RequestRegistrasiNasabah{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tabungan_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestRegistrasiNasabah) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestRegistrasiNasabah) ProtoMessage() {}
+
+func (x *RequestRegistrasiNasabah) ProtoReflect() protoreflect.Message {
+	mi := &file_tabungan_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestRegistrasiNasabah.ProtoReflect.Descriptor instead.
+func (*RequestRegistrasiNasabah) Descriptor() ([]byte, []int) {
+	return file_tabungan_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RequestRegistrasiNasabah) GetNik() string {
+	if x != nil {
+		return x.Nik
+	}
+	return ""
+}
+
+func (x *RequestRegistrasiNasabah) GetNama() string {
+	if x != nil {
+		return x.Nama
+	}
+	return ""
+}
+
+func (x *RequestRegistrasiNasabah) GetAlamatKtp() string {
+	if x != nil {
+		return x.AlamatKtp
+	}
+	return ""
+}
+
+func (x *RequestRegistrasiNasabah) GetAlamatDomisili() string {
+	if x != nil {
+		return x.AlamatDomisili
+	}
+	return ""
+}
+
+func (x *RequestRegistrasiNasabah) GetJenisKelamin() string {
+	if x != nil {
+		return x.JenisKelamin
+	}
+	return ""
+}
+
+func (x *RequestRegistrasiNasabah) GetTanggalLahir() string {
+	if x != nil {
+		return x.TanggalLahir
+	}
+	return ""
+}
+
+type RequestGetNasabah struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// as_of returns the nasabah as they were at that time when set.
+	AsOf string `protobuf:"bytes,1,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"`
+}
+
+func (x *RequestGetNasabah) Reset() {
+	*x = RequestGetNasabah{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tabungan_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestGetNasabah) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestGetNasabah) ProtoMessage() {}
+
+func (x *RequestGetNasabah) ProtoReflect() protoreflect.Message {
+	mi := &file_tabungan_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestGetNasabah.ProtoReflect.Descriptor instead.
+func (*RequestGetNasabah) Descriptor() ([]byte, []int) {
+	return file_tabungan_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RequestGetNasabah) GetAsOf() string {
+	if x != nil {
+		return x.AsOf
+	}
+	return ""
+}
+
+type Nasabah struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Nik            string `protobuf:"bytes,1,opt,name=nik,proto3" json:"nik,omitempty"`
+	Nama           string `protobuf:"bytes,2,opt,name=nama,proto3" json:"nama,omitempty"`
+	AlamatKtp      string `protobuf:"bytes,3,opt,name=alamat_ktp,json=alamatKtp,proto3" json:"alamat_ktp,omitempty"`
+	AlamatDomisili string `protobuf:"bytes,4,opt,name=alamat_domisili,json=alamatDomisili,proto3" json:"alamat_domisili,omitempty"`
+	JenisKelamin   string `protobuf:"bytes,5,opt,name=jenis_kelamin,json=jenisKelamin,proto3" json:"jenis_kelamin,omitempty"`
+	TanggalLahir   string `protobuf:"bytes,6,opt,name=tanggal_lahir,json=tanggalLahir,proto3" json:"tanggal_lahir,omitempty"`
+	Versi          int64  `protobuf:"varint,7,opt,name=versi,proto3" json:"versi,omitempty"`
+	BerlakuSejak   string `protobuf:"bytes,8,opt,name=berlaku_sejak,json=berlakuSejak,proto3" json:"berlaku_sejak,omitempty"`
+}
+
+func (x *Nasabah) Reset() {
+	*x = Nasabah{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tabungan_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Nasabah) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Nasabah) ProtoMessage() {}
+
+func (x *Nasabah) ProtoReflect() protoreflect.Message {
+	mi := &file_tabungan_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Nasabah.ProtoReflect.Descriptor instead.
+func (*Nasabah) Descriptor() ([]byte, []int) {
+	return file_tabungan_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Nasabah) GetNik() string {
+	if x != nil {
+		return x.Nik
+	}
+	return ""
+}
+
+func (x *Nasabah) GetNama() string {
+	if x != nil {
+		return x.Nama
+	}
+	return ""
+}
+
+func (x *Nasabah) GetAlamatKtp() string {
+	if x != nil {
+		return x.AlamatKtp
+	}
+	return ""
+}
+
+func (x *Nasabah) GetAlamatDomisili() string {
+	if x != nil {
+		return x.AlamatDomisili
+	}
+	return ""
+}
+
+func (x *Nasabah) GetJenisKelamin() string {
+	if x != nil {
+		return x.JenisKelamin
+	}
+	return ""
+}
+
+func (x *Nasabah) GetTanggalLahir() string {
+	if x != nil {
+		return x.TanggalLahir
+	}
+	return ""
+}
+
+func (x *Nasabah) GetVersi() int64 {
+	if x != nil {
+		return x.Versi
+	}
+	return 0
+}
+
+func (x *Nasabah) GetBerlakuSejak() string {
+	if x != nil {
+		return x.BerlakuSejak
+	}
+	return ""
+}
+
+type RequestUpdateNasabah struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Nama           string `protobuf:"bytes,1,opt,name=nama,proto3" json:"nama,omitempty"`
+	AlamatKtp      string `protobuf:"bytes,2,opt,name=alamat_ktp,json=alamatKtp,proto3" json:"alamat_ktp,omitempty"`
+	AlamatDomisili string `protobuf:"bytes,3,opt,name=alamat_domisili,json=alamatDomisili,proto3" json:"alamat_domisili,omitempty"`
+}
+
+func (x *RequestUpdateNasabah) Reset() {
+	*x = RequestUpdateNasabah{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tabungan_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestUpdateNasabah) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestUpdateNasabah) ProtoMessage() {}
+
+func (x *RequestUpdateNasabah) ProtoReflect() protoreflect.Message {
+	mi := &file_tabungan_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestUpdateNasabah.ProtoReflect.Descriptor instead.
+func (*RequestUpdateNasabah) Descriptor() ([]byte, []int) {
+	return file_tabungan_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RequestUpdateNasabah) GetNama() string {
+	if x != nil {
+		return x.Nama
+	}
+	return ""
+}
+
+func (x *RequestUpdateNasabah) GetAlamatKtp() string {
+	if x != nil {
+		return x.AlamatKtp
+	}
+	return ""
+}
+
+func (x *RequestUpdateNasabah) GetAlamatDomisili() string {
+	if x != nil {
+		return x.AlamatDomisili
+	}
+	return ""
+}
+
+// ResponseUpdateNasabah carries the queued operasi when the change needs
+// petugas approval.
+type ResponseUpdateNasabah struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Operasi *Operasi `protobuf:"bytes,1,opt,name=operasi,proto3" json:"operasi,omitempty"`
+}
+
+func (x *ResponseUpdateNasabah) Reset() {
+	*x = ResponseUpdateNasabah{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tabungan_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResponseUpdateNasabah) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResponseUpdateNasabah) ProtoMessage() {}
+
+func (x *ResponseUpdateNasabah) ProtoReflect() protoreflect.Message {
+	mi := &file_tabungan_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResponseUpdateNasabah.ProtoReflect.Descriptor instead.
+func (*ResponseUpdateNasabah) Descriptor() ([]byte, []int) {
+	return file_tabungan_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ResponseUpdateNasabah) GetOperasi() *Operasi {
+	if x != nil {
+		return x.Operasi
+	}
+	return nil
+}
+
+type RequestGetDaftarRekening struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *RequestGetDaftarRekening) Reset() {
+	*x = RequestGetDaftarRekening{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tabungan_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestGetDaftarRekening) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestGetDaftarRekening) ProtoMessage() {}
+
+func (x *RequestGetDaftarRekening) ProtoReflect() protoreflect.Message {
+	mi := &file_tabungan_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestGetDaftarRekening.ProtoReflect.Descriptor instead.
+func (*RequestGetDaftarRekening) Descriptor() ([]byte, []int) {
+	return file_tabungan_proto_rawDescGZIP(), []int{5}
+}
+
+type DaftarRekening struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NoRekening []string `protobuf:"bytes,1,rep,name=no_rekening,json=noRekening,proto3" json:"no_rekening,omitempty"`
+}
+
+func (x *DaftarRekening) Reset() {
+	*x = DaftarRekening{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tabungan_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DaftarRekening) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DaftarRekening) ProtoMessage() {}
+
+func (x *DaftarRekening) ProtoReflect() protoreflect.Message {
+	mi := &file_tabungan_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DaftarRekening.ProtoReflect.Descriptor instead.
+func (*DaftarRekening) Descriptor() ([]byte, []int) {
+	return file_tabungan_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DaftarRekening) GetNoRekening() []string {
+	if x != nil {
+		return x.NoRekening
+	}
+	return nil
+}
+
+type RequestGetRekening struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NoRekening string `protobuf:"bytes,1,opt,name=no_rekening,json=noRekening,proto3" json:"no_rekening,omitempty"`
+}
+
+func (x *RequestGetRekening) Reset() {
+	*x = RequestGetRekening{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tabungan_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestGetRekening) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestGetRekening) ProtoMessage() {}
+
+func (x *RequestGetRekening) ProtoReflect() protoreflect.Message {
+	mi := &file_tabungan_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestGetRekening.ProtoReflect.Descriptor instead.
+func (*RequestGetRekening) Descriptor() ([]byte, []int) {
+	return file_tabungan_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RequestGetRekening) GetNoRekening() string {
+	if x != nil {
+		return x.NoRekening
+	}
+	return ""
+}
+
+type Rekening struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Nik        string  `protobuf:"bytes,1,opt,name=nik,proto3" json:"nik,omitempty"`
+	NoRekening string  `protobuf:"bytes,2,opt,name=no_rekening,json=noRekening,proto3" json:"no_rekening,omitempty"`
+	Saldo      float64 `protobuf:"fixed64,3,opt,name=saldo,proto3" json:"saldo,omitempty"`
+}
+
+func (x *Rekening) Reset() {
+	*x = Rekening{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tabungan_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Rekening) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rekening) ProtoMessage() {}
+
+func (x *Rekening) ProtoReflect() protoreflect.Message {
+	mi := &file_tabungan_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rekening.ProtoReflect.Descriptor instead.
+func (*Rekening) Descriptor() ([]byte, []int) {
+	return file_tabungan_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Rekening) GetNik() string {
+	if x != nil {
+		return x.Nik
+	}
+	return ""
+}
+
+func (x *Rekening) GetNoRekening() string {
+	if x != nil {
+		return x.NoRekening
+	}
+	return ""
+}
+
+func (x *Rekening) GetSaldo() float64 {
+	if x != nil {
+		return x.Saldo
+	}
+	return 0
+}
+
+type RequestTarikSetorDana struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NoRekening string  `protobuf:"bytes,1,opt,name=no_rekening,json=noRekening,proto3" json:"no_rekening,omitempty"`
+	Nominal    float64 `protobuf:"fixed64,2,opt,name=nominal,proto3" json:"nominal,omitempty"`
+}
+
+func (x *RequestTarikSetorDana) Reset() {
+	*x = RequestTarikSetorDana{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tabungan_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestTarikSetorDana) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestTarikSetorDana) ProtoMessage() {}
+
+func (x *RequestTarikSetorDana) ProtoReflect() protoreflect.Message {
+	mi := &file_tabungan_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestTarikSetorDana.ProtoReflect.Descriptor instead.
+func (*RequestTarikSetorDana) Descriptor() ([]byte, []int) {
+	return file_tabungan_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RequestTarikSetorDana) GetNoRekening() string {
+	if x != nil {
+		return x.NoRekening
+	}
+	return ""
+}
+
+func (x *RequestTarikSetorDana) GetNominal() float64 {
+	if x != nil {
+		return x.Nominal
+	}
+	return 0
+}
+
+// ResponseTarikSetorDana carries either the balance after the transaction
+// or, when it needs petugas approval, the queued operasi.
+type ResponseTarikSetorDana struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SaldoAkhir float64  `protobuf:"fixed64,1,opt,name=saldo_akhir,json=saldoAkhir,proto3" json:"saldo_akhir,omitempty"`
+	Operasi    *Operasi `protobuf:"bytes,2,opt,name=operasi,proto3" json:"operasi,omitempty"`
+}
+
+func (x *ResponseTarikSetorDana) Reset() {
+	*x = ResponseTarikSetorDana{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tabungan_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResponseTarikSetorDana) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResponseTarikSetorDana) ProtoMessage() {}
+
+func (x *ResponseTarikSetorDana) ProtoReflect() protoreflect.Message {
+	mi := &file_tabungan_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResponseTarikSetorDana.ProtoReflect.Descriptor instead.
+func (*ResponseTarikSetorDana) Descriptor() ([]byte, []int) {
+	return file_tabungan_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ResponseTarikSetorDana) GetSaldoAkhir() float64 {
+	if x != nil {
+		return x.SaldoAkhir
+	}
+	return 0
+}
+
+func (x *ResponseTarikSetorDana) GetOperasi() *Operasi {
+	if x != nil {
+		return x.Operasi
+	}
+	return nil
+}
+
+type Operasi struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	OperasiId    string `protobuf:"bytes,1,opt,name=operasi_id,json=operasiId,proto3" json:"operasi_id,omitempty"`
+	JenisOperasi string `protobuf:"bytes,2,opt,name=jenis_operasi,json=jenisOperasi,proto3" json:"jenis_operasi,omitempty"`
+	Status       string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	WaktuDibuat  string `protobuf:"bytes,4,opt,name=waktu_dibuat,json=waktuDibuat,proto3" json:"waktu_dibuat,omitempty"`
+}
+
+func (x *Operasi) Reset() {
+	*x = Operasi{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tabungan_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Operasi) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Operasi) ProtoMessage() {}
+
+func (x *Operasi) ProtoReflect() protoreflect.Message {
+	mi := &file_tabungan_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Operasi.ProtoReflect.Descriptor instead.
+func (*Operasi) Descriptor() ([]byte, []int) {
+	return file_tabungan_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Operasi) GetOperasiId() string {
+	if x != nil {
+		return x.OperasiId
+	}
+	return ""
+}
+
+func (x *Operasi) GetJenisOperasi() string {
+	if x != nil {
+		return x.JenisOperasi
+	}
+	return ""
+}
+
+func (x *Operasi) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Operasi) GetWaktuDibuat() string {
+	if x != nil {
+		return x.WaktuDibuat
+	}
+	return ""
+}
+
+type RequestGetMutasi struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NoRekening string `protobuf:"bytes,1,opt,name=no_rekening,json=noRekening,proto3" json:"no_rekening,omitempty"`
+	Page       int32  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	Show       int32  `protobuf:"varint,3,opt,name=show,proto3" json:"show,omitempty"`
+}
+
+func (x *RequestGetMutasi) Reset() {
+	*x = RequestGetMutasi{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tabungan_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestGetMutasi) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestGetMutasi) ProtoMessage() {}
+
+func (x *RequestGetMutasi) ProtoReflect() protoreflect.Message {
+	mi := &file_tabungan_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestGetMutasi.ProtoReflect.Descriptor instead.
+func (*RequestGetMutasi) Descriptor() ([]byte, []int) {
+	return file_tabungan_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RequestGetMutasi) GetNoRekening() string {
+	if x != nil {
+		return x.NoRekening
+	}
+	return ""
+}
+
+func (x *RequestGetMutasi) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *RequestGetMutasi) GetShow() int32 {
+	if x != nil {
+		return x.Show
+	}
+	return 0
+}
+
+type Mutasi struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TransaksiId string  `protobuf:"bytes,1,opt,name=transaksi_id,json=transaksiId,proto3" json:"transaksi_id,omitempty"`
+	Waktu       string  `protobuf:"bytes,2,opt,name=waktu,proto3" json:"waktu,omitempty"`
+	JenisMutasi string  `protobuf:"bytes,3,opt,name=jenis_mutasi,json=jenisMutasi,proto3" json:"jenis_mutasi,omitempty"`
+	NoRekening  string  `protobuf:"bytes,4,opt,name=no_rekening,json=noRekening,proto3" json:"no_rekening,omitempty"`
+	Nominal     float64 `protobuf:"fixed64,5,opt,name=nominal,proto3" json:"nominal,omitempty"`
+	SaldoAwal   float64 `protobuf:"fixed64,6,opt,name=saldo_awal,json=saldoAwal,proto3" json:"saldo_awal,omitempty"`
+	SaldoAkhir  float64 `protobuf:"fixed64,7,opt,name=saldo_akhir,json=saldoAkhir,proto3" json:"saldo_akhir,omitempty"`
+}
+
+func (x *Mutasi) Reset() {
+	*x = Mutasi{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tabungan_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Mutasi) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Mutasi) ProtoMessage() {}
+
+func (x *Mutasi) ProtoReflect() protoreflect.Message {
+	mi := &file_tabungan_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Mutasi.ProtoReflect.Descriptor instead.
+func (*Mutasi) Descriptor() ([]byte, []int) {
+	return file_tabungan_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Mutasi) GetTransaksiId() string {
+	if x != nil {
+		return x.TransaksiId
+	}
+	return ""
+}
+
+func (x *Mutasi) GetWaktu() string {
+	if x != nil {
+		return x.Waktu
+	}
+	return ""
+}
+
+func (x *Mutasi) GetJenisMutasi() string {
+	if x != nil {
+		return x.JenisMutasi
+	}
+	return ""
+}
+
+func (x *Mutasi) GetNoRekening() string {
+	if x != nil {
+		return x.NoRekening
+	}
+	return ""
+}
+
+func (x *Mutasi) GetNominal() float64 {
+	if x != nil {
+		return x.Nominal
+	}
+	return 0
+}
+
+func (x *Mutasi) GetSaldoAwal() float64 {
+	if x != nil {
+		return x.SaldoAwal
+	}
+	return 0
+}
+
+func (x *Mutasi) GetSaldoAkhir() float64 {
+	if x != nil {
+		return x.SaldoAkhir
+	}
+	return 0
+}
+
+type RequestStreamSaldo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *RequestStreamSaldo) Reset() {
+	*x = RequestStreamSaldo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tabungan_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestStreamSaldo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestStreamSaldo) ProtoMessage() {}
+
+func (x *RequestStreamSaldo) ProtoReflect() protoreflect.Message {
+	mi := &file_tabungan_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestStreamSaldo.ProtoReflect.Descriptor instead.
+func (*RequestStreamSaldo) Descriptor() ([]byte, []int) {
+	return file_tabungan_proto_rawDescGZIP(), []int{14}
+}
+
+// PembaruanSaldo has no mutasi in the snapshot sent when the stream opens.
+type PembaruanSaldo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NoRekening string  `protobuf:"bytes,1,opt,name=no_rekening,json=noRekening,proto3" json:"no_rekening,omitempty"`
+	Saldo      float64 `protobuf:"fixed64,2,opt,name=saldo,proto3" json:"saldo,omitempty"`
+	Mutasi     *Mutasi `protobuf:"bytes,3,opt,name=mutasi,proto3" json:"mutasi,omitempty"`
+}
+
+func (x *PembaruanSaldo) Reset() {
+	*x = PembaruanSaldo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tabungan_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PembaruanSaldo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PembaruanSaldo) ProtoMessage() {}
+
+func (x *PembaruanSaldo) ProtoReflect() protoreflect.Message {
+	mi := &file_tabungan_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PembaruanSaldo.ProtoReflect.Descriptor instead.
+func (*PembaruanSaldo) Descriptor() ([]byte, []int) {
+	return file_tabungan_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *PembaruanSaldo) GetNoRekening() string {
+	if x != nil {
+		return x.NoRekening
+	}
+	return ""
+}
+
+func (x *PembaruanSaldo) GetSaldo() float64 {
+	if x != nil {
+		return x.Saldo
+	}
+	return 0
+}
+
+func (x *PembaruanSaldo) GetMutasi() *Mutasi {
+	if x != nil {
+		return x.Mutasi
+	}
+	return nil
+}
+
+var File_tabungan_proto protoreflect.FileDescriptor
+
+var file_tabungan_proto_rawDesc = []byte{
+	0x0a, 0x0e, 0x74, 0x61, 0x62, 0x75, 0x6e, 0x67, 0x61, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x08, 0x74, 0x61, 0x62, 0x75, 0x6e, 0x67, 0x61, 0x6e, 0x22, 0xd2, 0x01, 0x0a, 0x18, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x73, 0x69,
+	0x4e, 0x61, 0x73, 0x61, 0x62, 0x61, 0x68, 0x12, 0x10, 0x0a, 0x03, 0x6e, 0x69, 0x6b, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6e, 0x69, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x61, 0x12, 0x1d, 0x0a,
+	0x0a, 0x61, 0x6c, 0x61, 0x6d, 0x61, 0x74, 0x5f, 0x6b, 0x74, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x61, 0x6c, 0x61, 0x6d, 0x61, 0x74, 0x4b, 0x74, 0x70, 0x12, 0x27, 0x0a, 0x0f,
+	0x61, 0x6c, 0x61, 0x6d, 0x61, 0x74, 0x5f, 0x64, 0x6f, 0x6d, 0x69, 0x73, 0x69, 0x6c, 0x69, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x61, 0x6c, 0x61, 0x6d, 0x61, 0x74, 0x44, 0x6f, 0x6d,
+	0x69, 0x73, 0x69, 0x6c, 0x69, 0x12, 0x23, 0x0a, 0x0d, 0x6a, 0x65, 0x6e, 0x69, 0x73, 0x5f, 0x6b,
+	0x65, 0x6c, 0x61, 0x6d, 0x69, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6a, 0x65,
+	0x6e, 0x69, 0x73, 0x4b, 0x65, 0x6c, 0x61, 0x6d, 0x69, 0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x61,
+	0x6e, 0x67, 0x67, 0x61, 0x6c, 0x5f, 0x6c, 0x61, 0x68, 0x69, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0c, 0x74, 0x61, 0x6e, 0x67, 0x67, 0x61, 0x6c, 0x4c, 0x61, 0x68, 0x69, 0x72, 0x22,
+	0x28, 0x0a, 0x11, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x47, 0x65, 0x74, 0x4e, 0x61, 0x73,
+	0x61, 0x62, 0x61, 0x68, 0x12, 0x13, 0x0a, 0x05, 0x61, 0x73, 0x5f, 0x6f, 0x66, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x61, 0x73, 0x4f, 0x66, 0x22, 0xfc, 0x01, 0x0a, 0x07, 0x4e, 0x61,
+	0x73, 0x61, 0x62, 0x61, 0x68, 0x12, 0x10, 0x0a, 0x03, 0x6e, 0x69, 0x6b, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6e, 0x69, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x61, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x61, 0x12, 0x1d, 0x0a, 0x0a, 0x61,
+	0x6c, 0x61, 0x6d, 0x61, 0x74, 0x5f, 0x6b, 0x74, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x61, 0x6c, 0x61, 0x6d, 0x61, 0x74, 0x4b, 0x74, 0x70, 0x12, 0x27, 0x0a, 0x0f, 0x61, 0x6c,
+	0x61, 0x6d, 0x61, 0x74, 0x5f, 0x64, 0x6f, 0x6d, 0x69, 0x73, 0x69, 0x6c, 0x69, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0e, 0x61, 0x6c, 0x61, 0x6d, 0x61, 0x74, 0x44, 0x6f, 0x6d, 0x69, 0x73,
+	0x69, 0x6c, 0x69, 0x12, 0x23, 0x0a, 0x0d, 0x6a, 0x65, 0x6e, 0x69, 0x73, 0x5f, 0x6b, 0x65, 0x6c,
+	0x61, 0x6d, 0x69, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6a, 0x65, 0x6e, 0x69,
+	0x73, 0x4b, 0x65, 0x6c, 0x61, 0x6d, 0x69, 0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x61, 0x6e, 0x67,
+	0x67, 0x61, 0x6c, 0x5f, 0x6c, 0x61, 0x68, 0x69, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0c, 0x74, 0x61, 0x6e, 0x67, 0x67, 0x61, 0x6c, 0x4c, 0x61, 0x68, 0x69, 0x72, 0x12, 0x14, 0x0a,
+	0x05, 0x76, 0x65, 0x72, 0x73, 0x69, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x12, 0x23, 0x0a, 0x0d, 0x62, 0x65, 0x72, 0x6c, 0x61, 0x6b, 0x75, 0x5f, 0x73,
+	0x65, 0x6a, 0x61, 0x6b, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x62, 0x65, 0x72, 0x6c,
+	0x61, 0x6b, 0x75, 0x53, 0x65, 0x6a, 0x61, 0x6b, 0x22, 0x72, 0x0a, 0x14, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4e, 0x61, 0x73, 0x61, 0x62, 0x61, 0x68,
+	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x6e, 0x61, 0x6d, 0x61, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x6c, 0x61, 0x6d, 0x61, 0x74, 0x5f, 0x6b,
+	0x74, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x6c, 0x61, 0x6d, 0x61, 0x74,
+	0x4b, 0x74, 0x70, 0x12, 0x27, 0x0a, 0x0f, 0x61, 0x6c, 0x61, 0x6d, 0x61, 0x74, 0x5f, 0x64, 0x6f,
+	0x6d, 0x69, 0x73, 0x69, 0x6c, 0x69, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x61, 0x6c,
+	0x61, 0x6d, 0x61, 0x74, 0x44, 0x6f, 0x6d, 0x69, 0x73, 0x69, 0x6c, 0x69, 0x22, 0x44, 0x0a, 0x15,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4e, 0x61,
+	0x73, 0x61, 0x62, 0x61, 0x68, 0x12, 0x2b, 0x0a, 0x07, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x73, 0x69,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x74, 0x61, 0x62, 0x75, 0x6e, 0x67, 0x61,
+	0x6e, 0x2e, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x73, 0x69, 0x52, 0x07, 0x6f, 0x70, 0x65, 0x72, 0x61,
+	0x73, 0x69, 0x22, 0x1a, 0x0a, 0x18, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x47, 0x65, 0x74,
+	0x44, 0x61, 0x66, 0x74, 0x61, 0x72, 0x52, 0x65, 0x6b, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x22, 0x31,
+	0x0a, 0x0e, 0x44, 0x61, 0x66, 0x74, 0x61, 0x72, 0x52, 0x65, 0x6b, 0x65, 0x6e, 0x69, 0x6e, 0x67,
+	0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x6f, 0x5f, 0x72, 0x65, 0x6b, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x6e, 0x6f, 0x52, 0x65, 0x6b, 0x65, 0x6e, 0x69, 0x6e,
+	0x67, 0x22, 0x35, 0x0a, 0x12, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x47, 0x65, 0x74, 0x52,
+	0x65, 0x6b, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x6f, 0x5f, 0x72, 0x65,
+	0x6b, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6e, 0x6f,
+	0x52, 0x65, 0x6b, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x22, 0x53, 0x0a, 0x08, 0x52, 0x65, 0x6b, 0x65,
+	0x6e, 0x69, 0x6e, 0x67, 0x12, 0x10, 0x0a, 0x03, 0x6e, 0x69, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6e, 0x69, 0x6b, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x6f, 0x5f, 0x72, 0x65, 0x6b,
+	0x65, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6e, 0x6f, 0x52,
+	0x65, 0x6b, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x61, 0x6c, 0x64, 0x6f,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x73, 0x61, 0x6c, 0x64, 0x6f, 0x22, 0x52, 0x0a,
+	0x15, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x54, 0x61, 0x72, 0x69, 0x6b, 0x53, 0x65, 0x74,
+	0x6f, 0x72, 0x44, 0x61, 0x6e, 0x61, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x6f, 0x5f, 0x72, 0x65, 0x6b,
+	0x65, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6e, 0x6f, 0x52,
+	0x65, 0x6b, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x6f, 0x6d, 0x69, 0x6e,
+	0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x6e, 0x6f, 0x6d, 0x69, 0x6e, 0x61,
+	0x6c, 0x22, 0x66, 0x0a, 0x16, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x54, 0x61, 0x72,
+	0x69, 0x6b, 0x53, 0x65, 0x74, 0x6f, 0x72, 0x44, 0x61, 0x6e, 0x61, 0x12, 0x1f, 0x0a, 0x0b, 0x73,
+	0x61, 0x6c, 0x64, 0x6f, 0x5f, 0x61, 0x6b, 0x68, 0x69, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x0a, 0x73, 0x61, 0x6c, 0x64, 0x6f, 0x41, 0x6b, 0x68, 0x69, 0x72, 0x12, 0x2b, 0x0a, 0x07,
+	0x6f, 0x70, 0x65, 0x72, 0x61, 0x73, 0x69, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e,
+	0x74, 0x61, 0x62, 0x75, 0x6e, 0x67, 0x61, 0x6e, 0x2e, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x73, 0x69,
+	0x52, 0x07, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x73, 0x69, 0x22, 0x88, 0x01, 0x0a, 0x07, 0x4f, 0x70,
+	0x65, 0x72, 0x61, 0x73, 0x69, 0x12, 0x1d, 0x0a, 0x0a, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x73, 0x69,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61,
+	0x73, 0x69, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x6a, 0x65, 0x6e, 0x69, 0x73, 0x5f, 0x6f, 0x70,
+	0x65, 0x72, 0x61, 0x73, 0x69, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6a, 0x65, 0x6e,
+	0x69, 0x73, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x73, 0x69, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x21, 0x0a, 0x0c, 0x77, 0x61, 0x6b, 0x74, 0x75, 0x5f, 0x64, 0x69, 0x62, 0x75, 0x61,
+	0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x77, 0x61, 0x6b, 0x74, 0x75, 0x44, 0x69,
+	0x62, 0x75, 0x61, 0x74, 0x22, 0x5b, 0x0a, 0x10, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x47,
+	0x65, 0x74, 0x4d, 0x75, 0x74, 0x61, 0x73, 0x69, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x6f, 0x5f, 0x72,
+	0x65, 0x6b, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6e,
+	0x6f, 0x52, 0x65, 0x6b, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x67,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x73, 0x68, 0x6f, 0x77, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x73, 0x68, 0x6f,
+	0x77, 0x22, 0xdf, 0x01, 0x0a, 0x06, 0x4d, 0x75, 0x74, 0x61, 0x73, 0x69, 0x12, 0x21, 0x0a, 0x0c,
+	0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x6b, 0x73, 0x69, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x6b, 0x73, 0x69, 0x49, 0x64, 0x12,
+	0x14, 0x0a, 0x05, 0x77, 0x61, 0x6b, 0x74, 0x75, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x77, 0x61, 0x6b, 0x74, 0x75, 0x12, 0x21, 0x0a, 0x0c, 0x6a, 0x65, 0x6e, 0x69, 0x73, 0x5f, 0x6d,
+	0x75, 0x74, 0x61, 0x73, 0x69, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6a, 0x65, 0x6e,
+	0x69, 0x73, 0x4d, 0x75, 0x74, 0x61, 0x73, 0x69, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x6f, 0x5f, 0x72,
+	0x65, 0x6b, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6e,
+	0x6f, 0x52, 0x65, 0x6b, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x6f, 0x6d,
+	0x69, 0x6e, 0x61, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x6e, 0x6f, 0x6d, 0x69,
+	0x6e, 0x61, 0x6c, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x61, 0x6c, 0x64, 0x6f, 0x5f, 0x61, 0x77, 0x61,
+	0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x73, 0x61, 0x6c, 0x64, 0x6f, 0x41, 0x77,
+	0x61, 0x6c, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x61, 0x6c, 0x64, 0x6f, 0x5f, 0x61, 0x6b, 0x68, 0x69,
+	0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x73, 0x61, 0x6c, 0x64, 0x6f, 0x41, 0x6b,
+	0x68, 0x69, 0x72, 0x22, 0x14, 0x0a, 0x12, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x53, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x53, 0x61, 0x6c, 0x64, 0x6f, 0x22, 0x71, 0x0a, 0x0e, 0x50, 0x65, 0x6d,
+	0x62, 0x61, 0x72, 0x75, 0x61, 0x6e, 0x53, 0x61, 0x6c, 0x64, 0x6f, 0x12, 0x1f, 0x0a, 0x0b, 0x6e,
+	0x6f, 0x5f, 0x72, 0x65, 0x6b, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x6e, 0x6f, 0x52, 0x65, 0x6b, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x14, 0x0a, 0x05,
+	0x73, 0x61, 0x6c, 0x64, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x73, 0x61, 0x6c,
+	0x64, 0x6f, 0x12, 0x28, 0x0a, 0x06, 0x6d, 0x75, 0x74, 0x61, 0x73, 0x69, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x10, 0x2e, 0x74, 0x61, 0x62, 0x75, 0x6e, 0x67, 0x61, 0x6e, 0x2e, 0x4d, 0x75,
+	0x74, 0x61, 0x73, 0x69, 0x52, 0x06, 0x6d, 0x75, 0x74, 0x61, 0x73, 0x69, 0x32, 0xa1, 0x05, 0x0a,
+	0x08, 0x54, 0x61, 0x62, 0x75, 0x6e, 0x67, 0x61, 0x6e, 0x12, 0x4b, 0x0a, 0x11, 0x52, 0x65, 0x67,
+	0x69, 0x73, 0x74, 0x72, 0x61, 0x73, 0x69, 0x4e, 0x61, 0x73, 0x61, 0x62, 0x61, 0x68, 0x12, 0x22,
+	0x2e, 0x74, 0x61, 0x62, 0x75, 0x6e, 0x67, 0x61, 0x6e, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x73, 0x69, 0x4e, 0x61, 0x73, 0x61, 0x62,
+	0x61, 0x68, 0x1a, 0x12, 0x2e, 0x74, 0x61, 0x62, 0x75, 0x6e, 0x67, 0x61, 0x6e, 0x2e, 0x52, 0x65,
+	0x6b, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x3c, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x4e, 0x61, 0x73,
+	0x61, 0x62, 0x61, 0x68, 0x12, 0x1b, 0x2e, 0x74, 0x61, 0x62, 0x75, 0x6e, 0x67, 0x61, 0x6e, 0x2e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x47, 0x65, 0x74, 0x4e, 0x61, 0x73, 0x61, 0x62, 0x61,
+	0x68, 0x1a, 0x11, 0x2e, 0x74, 0x61, 0x62, 0x75, 0x6e, 0x67, 0x61, 0x6e, 0x2e, 0x4e, 0x61, 0x73,
+	0x61, 0x62, 0x61, 0x68, 0x12, 0x50, 0x0a, 0x0d, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4e, 0x61,
+	0x73, 0x61, 0x62, 0x61, 0x68, 0x12, 0x1e, 0x2e, 0x74, 0x61, 0x62, 0x75, 0x6e, 0x67, 0x61, 0x6e,
+	0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4e, 0x61,
+	0x73, 0x61, 0x62, 0x61, 0x68, 0x1a, 0x1f, 0x2e, 0x74, 0x61, 0x62, 0x75, 0x6e, 0x67, 0x61, 0x6e,
+	0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4e,
+	0x61, 0x73, 0x61, 0x62, 0x61, 0x68, 0x12, 0x51, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x44, 0x61, 0x66,
+	0x74, 0x61, 0x72, 0x52, 0x65, 0x6b, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x22, 0x2e, 0x74, 0x61,
+	0x62, 0x75, 0x6e, 0x67, 0x61, 0x6e, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x47, 0x65,
+	0x74, 0x44, 0x61, 0x66, 0x74, 0x61, 0x72, 0x52, 0x65, 0x6b, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x1a,
+	0x18, 0x2e, 0x74, 0x61, 0x62, 0x75, 0x6e, 0x67, 0x61, 0x6e, 0x2e, 0x44, 0x61, 0x66, 0x74, 0x61,
+	0x72, 0x52, 0x65, 0x6b, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x3f, 0x0a, 0x0b, 0x47, 0x65, 0x74,
+	0x52, 0x65, 0x6b, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x1c, 0x2e, 0x74, 0x61, 0x62, 0x75, 0x6e,
+	0x67, 0x61, 0x6e, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x47, 0x65, 0x74, 0x52, 0x65,
+	0x6b, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x1a, 0x12, 0x2e, 0x74, 0x61, 0x62, 0x75, 0x6e, 0x67, 0x61,
+	0x6e, 0x2e, 0x52, 0x65, 0x6b, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x4e, 0x0a, 0x09, 0x54, 0x61,
+	0x72, 0x69, 0x6b, 0x44, 0x61, 0x6e, 0x61, 0x12, 0x1f, 0x2e, 0x74, 0x61, 0x62, 0x75, 0x6e, 0x67,
+	0x61, 0x6e, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x54, 0x61, 0x72, 0x69, 0x6b, 0x53,
+	0x65, 0x74, 0x6f, 0x72, 0x44, 0x61, 0x6e, 0x61, 0x1a, 0x20, 0x2e, 0x74, 0x61, 0x62, 0x75, 0x6e,
+	0x67, 0x61, 0x6e, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x54, 0x61, 0x72, 0x69,
+	0x6b, 0x53, 0x65, 0x74, 0x6f, 0x72, 0x44, 0x61, 0x6e, 0x61, 0x12, 0x4e, 0x0a, 0x09, 0x53, 0x65,
+	0x74, 0x6f, 0x72, 0x44, 0x61, 0x6e, 0x61, 0x12, 0x1f, 0x2e, 0x74, 0x61, 0x62, 0x75, 0x6e, 0x67,
+	0x61, 0x6e, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x54, 0x61, 0x72, 0x69, 0x6b, 0x53,
+	0x65, 0x74, 0x6f, 0x72, 0x44, 0x61, 0x6e, 0x61, 0x1a, 0x20, 0x2e, 0x74, 0x61, 0x62, 0x75, 0x6e,
+	0x67, 0x61, 0x6e, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x54, 0x61, 0x72, 0x69,
+	0x6b, 0x53, 0x65, 0x74, 0x6f, 0x72, 0x44, 0x61, 0x6e, 0x61, 0x12, 0x3b, 0x0a, 0x09, 0x47, 0x65,
+	0x74, 0x4d, 0x75, 0x74, 0x61, 0x73, 0x69, 0x12, 0x1a, 0x2e, 0x74, 0x61, 0x62, 0x75, 0x6e, 0x67,
+	0x61, 0x6e, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x47, 0x65, 0x74, 0x4d, 0x75, 0x74,
+	0x61, 0x73, 0x69, 0x1a, 0x10, 0x2e, 0x74, 0x61, 0x62, 0x75, 0x6e, 0x67, 0x61, 0x6e, 0x2e, 0x4d,
+	0x75, 0x74, 0x61, 0x73, 0x69, 0x30, 0x01, 0x12, 0x47, 0x0a, 0x0b, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x53, 0x61, 0x6c, 0x64, 0x6f, 0x12, 0x1c, 0x2e, 0x74, 0x61, 0x62, 0x75, 0x6e, 0x67, 0x61,
+	0x6e, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x53,
+	0x61, 0x6c, 0x64, 0x6f, 0x1a, 0x18, 0x2e, 0x74, 0x61, 0x62, 0x75, 0x6e, 0x67, 0x61, 0x6e, 0x2e,
+	0x50, 0x65, 0x6d, 0x62, 0x61, 0x72, 0x75, 0x61, 0x6e, 0x53, 0x61, 0x6c, 0x64, 0x6f, 0x30, 0x01,
+	0x42, 0x19, 0x5a, 0x17, 0x74, 0x61, 0x62, 0x75, 0x6e, 0x67, 0x61, 0x6e, 0x2d, 0x61, 0x70, 0x69,
+	0x2f, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
+}
+
+var (
+	file_tabungan_proto_rawDescOnce sync.Once
+	file_tabungan_proto_rawDescData = file_tabungan_proto_rawDesc
+)
+
+func file_tabungan_proto_rawDescGZIP() []byte {
+	file_tabungan_proto_rawDescOnce.Do(func() {
+		file_tabungan_proto_rawDescData = protoimpl.X.CompressGZIP(file_tabungan_proto_rawDescData)
+	})
+	return file_tabungan_proto_rawDescData
+}
+
+var file_tabungan_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_tabungan_proto_goTypes = []interface{}{
+	(*RequestRegistrasiNasabah)(nil), // 0: tabungan.RequestRegistrasiNasabah
+	(*RequestGetNasabah)(nil),        // 1: tabungan.RequestGetNasabah
+	(*Nasabah)(nil),                  // 2: tabungan.Nasabah
+	(*RequestUpdateNasabah)(nil),     // 3: tabungan.RequestUpdateNasabah
+	(*ResponseUpdateNasabah)(nil),    // 4: tabungan.ResponseUpdateNasabah
+	(*RequestGetDaftarRekening)(nil), // 5: tabungan.RequestGetDaftarRekening
+	(*DaftarRekening)(nil),           // 6: tabungan.DaftarRekening
+	(*RequestGetRekening)(nil),       // 7: tabungan.RequestGetRekening
+	(*Rekening)(nil),                 // 8: tabungan.Rekening
+	(*RequestTarikSetorDana)(nil),    // 9: tabungan.RequestTarikSetorDana
+	(*ResponseTarikSetorDana)(nil),   // 10: tabungan.ResponseTarikSetorDana
+	(*Operasi)(nil),                  // 11: tabungan.Operasi
+	(*RequestGetMutasi)(nil),         // 12: tabungan.RequestGetMutasi
+	(*Mutasi)(nil),                   // 13: tabungan.Mutasi
+	(*RequestStreamSaldo)(nil),       // 14: tabungan.RequestStreamSaldo
+	(*PembaruanSaldo)(nil),           // 15: tabungan.PembaruanSaldo
+}
+var file_tabungan_proto_depIdxs = []int32{
+	11, // 0: tabungan.ResponseUpdateNasabah.operasi:type_name -> tabungan.Operasi
+	11, // 1: tabungan.ResponseTarikSetorDana.operasi:type_name -> tabungan.Operasi
+	13, // 2: tabungan.PembaruanSaldo.mutasi:type_name -> tabungan.Mutasi
+	0,  // 3: tabungan.Tabungan.RegistrasiNasabah:input_type -> tabungan.RequestRegistrasiNasabah
+	1,  // 4: tabungan.Tabungan.GetNasabah:input_type -> tabungan.RequestGetNasabah
+	3,  // 5: tabungan.Tabungan.UpdateNasabah:input_type -> tabungan.RequestUpdateNasabah
+	5,  // 6: tabungan.Tabungan.GetDaftarRekening:input_type -> tabungan.RequestGetDaftarRekening
+	7,  // 7: tabungan.Tabungan.GetRekening:input_type -> tabungan.RequestGetRekening
+	9,  // 8: tabungan.Tabungan.TarikDana:input_type -> tabungan.RequestTarikSetorDana
+	9,  // 9: tabungan.Tabungan.SetorDana:input_type -> tabungan.RequestTarikSetorDana
+	12, // 10: tabungan.Tabungan.GetMutasi:input_type -> tabungan.RequestGetMutasi
+	14, // 11: tabungan.Tabungan.StreamSaldo:input_type -> tabungan.RequestStreamSaldo
+	8,  // 12: tabungan.Tabungan.RegistrasiNasabah:output_type -> tabungan.Rekening
+	2,  // 13: tabungan.Tabungan.GetNasabah:output_type -> tabungan.Nasabah
+	4,  // 14: tabungan.Tabungan.UpdateNasabah:output_type -> tabungan.ResponseUpdateNasabah
+	6,  // 15: tabungan.Tabungan.GetDaftarRekening:output_type -> tabungan.DaftarRekening
+	8,  // 16: tabungan.Tabungan.GetRekening:output_type -> tabungan.Rekening
+	10, // 17: tabungan.Tabungan.TarikDana:output_type -> tabungan.ResponseTarikSetorDana
+	10, // 18: tabungan.Tabungan.SetorDana:output_type -> tabungan.ResponseTarikSetorDana
+	13, // 19: tabungan.Tabungan.GetMutasi:output_type -> tabungan.Mutasi
+	15, // 20: tabungan.Tabungan.StreamSaldo:output_type -> tabungan.PembaruanSaldo
+	12, // [12:21] is the sub-list for method output_type
+	3,  // [3:12] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_tabungan_proto_init() }
+func file_tabungan_proto_init() {
+	if File_tabungan_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_tabungan_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestRegistrasiNasabah); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tabungan_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestGetNasabah); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tabungan_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Nasabah); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tabungan_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestUpdateNasabah); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tabungan_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResponseUpdateNasabah); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tabungan_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestGetDaftarRekening); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tabungan_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DaftarRekening); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tabungan_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestGetRekening); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tabungan_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Rekening); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tabungan_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestTarikSetorDana); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tabungan_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResponseTarikSetorDana); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tabungan_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Operasi); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tabungan_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestGetMutasi); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tabungan_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Mutasi); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tabungan_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestStreamSaldo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tabungan_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PembaruanSaldo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_tabungan_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_tabungan_proto_goTypes,
+		DependencyIndexes: file_tabungan_proto_depIdxs,
+		MessageInfos:      file_tabungan_proto_msgTypes,
+	}.Build()
+	File_tabungan_proto = out.File
+	file_tabungan_proto_rawDesc = nil
+	file_tabungan_proto_goTypes = nil
+	file_tabungan_proto_depIdxs = nil
+}
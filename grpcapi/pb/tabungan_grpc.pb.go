@@ -0,0 +1,466 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v3.20.3
+// source: tabungan.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Tabungan_RegistrasiNasabah_FullMethodName = "/tabungan.Tabungan/RegistrasiNasabah"
+	Tabungan_GetNasabah_FullMethodName        = "/tabungan.Tabungan/GetNasabah"
+	Tabungan_UpdateNasabah_FullMethodName     = "/tabungan.Tabungan/UpdateNasabah"
+	Tabungan_GetDaftarRekening_FullMethodName = "/tabungan.Tabungan/GetDaftarRekening"
+	Tabungan_GetRekening_FullMethodName       = "/tabungan.Tabungan/GetRekening"
+	Tabungan_TarikDana_FullMethodName         = "/tabungan.Tabungan/TarikDana"
+	Tabungan_SetorDana_FullMethodName         = "/tabungan.Tabungan/SetorDana"
+	Tabungan_GetMutasi_FullMethodName         = "/tabungan.Tabungan/GetMutasi"
+	Tabungan_StreamSaldo_FullMethodName       = "/tabungan.Tabungan/StreamSaldo"
+)
+
+// TabunganClient is the client API for Tabungan service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TabunganClient interface {
+	RegistrasiNasabah(ctx context.Context, in *RequestRegistrasiNasabah, opts ...grpc.CallOption) (*Rekening, error)
+	GetNasabah(ctx context.Context, in *RequestGetNasabah, opts ...grpc.CallOption) (*Nasabah, error)
+	UpdateNasabah(ctx context.Context, in *RequestUpdateNasabah, opts ...grpc.CallOption) (*ResponseUpdateNasabah, error)
+	GetDaftarRekening(ctx context.Context, in *RequestGetDaftarRekening, opts ...grpc.CallOption) (*DaftarRekening, error)
+	GetRekening(ctx context.Context, in *RequestGetRekening, opts ...grpc.CallOption) (*Rekening, error)
+	TarikDana(ctx context.Context, in *RequestTarikSetorDana, opts ...grpc.CallOption) (*ResponseTarikSetorDana, error)
+	SetorDana(ctx context.Context, in *RequestTarikSetorDana, opts ...grpc.CallOption) (*ResponseTarikSetorDana, error)
+	// GetMutasi streams one page of transactions, newest first.
+	GetMutasi(ctx context.Context, in *RequestGetMutasi, opts ...grpc.CallOption) (Tabungan_GetMutasiClient, error)
+	// StreamSaldo sends the balance of every rekening, then an update for
+	// every setor or tarik until the client cancels.
+	StreamSaldo(ctx context.Context, in *RequestStreamSaldo, opts ...grpc.CallOption) (Tabungan_StreamSaldoClient, error)
+}
+
+type tabunganClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTabunganClient(cc grpc.ClientConnInterface) TabunganClient {
+	return &tabunganClient{cc}
+}
+
+func (c *tabunganClient) RegistrasiNasabah(ctx context.Context, in *RequestRegistrasiNasabah, opts ...grpc.CallOption) (*Rekening, error) {
+	out := new(Rekening)
+	err := c.cc.Invoke(ctx, Tabungan_RegistrasiNasabah_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tabunganClient) GetNasabah(ctx context.Context, in *RequestGetNasabah, opts ...grpc.CallOption) (*Nasabah, error) {
+	out := new(Nasabah)
+	err := c.cc.Invoke(ctx, Tabungan_GetNasabah_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tabunganClient) UpdateNasabah(ctx context.Context, in *RequestUpdateNasabah, opts ...grpc.CallOption) (*ResponseUpdateNasabah, error) {
+	out := new(ResponseUpdateNasabah)
+	err := c.cc.Invoke(ctx, Tabungan_UpdateNasabah_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tabunganClient) GetDaftarRekening(ctx context.Context, in *RequestGetDaftarRekening, opts ...grpc.CallOption) (*DaftarRekening, error) {
+	out := new(DaftarRekening)
+	err := c.cc.Invoke(ctx, Tabungan_GetDaftarRekening_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tabunganClient) GetRekening(ctx context.Context, in *RequestGetRekening, opts ...grpc.CallOption) (*Rekening, error) {
+	out := new(Rekening)
+	err := c.cc.Invoke(ctx, Tabungan_GetRekening_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tabunganClient) TarikDana(ctx context.Context, in *RequestTarikSetorDana, opts ...grpc.CallOption) (*ResponseTarikSetorDana, error) {
+	out := new(ResponseTarikSetorDana)
+	err := c.cc.Invoke(ctx, Tabungan_TarikDana_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tabunganClient) SetorDana(ctx context.Context, in *RequestTarikSetorDana, opts ...grpc.CallOption) (*ResponseTarikSetorDana, error) {
+	out := new(ResponseTarikSetorDana)
+	err := c.cc.Invoke(ctx, Tabungan_SetorDana_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tabunganClient) GetMutasi(ctx context.Context, in *RequestGetMutasi, opts ...grpc.CallOption) (Tabungan_GetMutasiClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Tabungan_ServiceDesc.Streams[0], Tabungan_GetMutasi_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tabunganGetMutasiClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Tabungan_GetMutasiClient interface {
+	Recv() (*Mutasi, error)
+	grpc.ClientStream
+}
+
+type tabunganGetMutasiClient struct {
+	grpc.ClientStream
+}
+
+func (x *tabunganGetMutasiClient) Recv() (*Mutasi, error) {
+	m := new(Mutasi)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *tabunganClient) StreamSaldo(ctx context.Context, in *RequestStreamSaldo, opts ...grpc.CallOption) (Tabungan_StreamSaldoClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Tabungan_ServiceDesc.Streams[1], Tabungan_StreamSaldo_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tabunganStreamSaldoClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Tabungan_StreamSaldoClient interface {
+	Recv() (*PembaruanSaldo, error)
+	grpc.ClientStream
+}
+
+type tabunganStreamSaldoClient struct {
+	grpc.ClientStream
+}
+
+func (x *tabunganStreamSaldoClient) Recv() (*PembaruanSaldo, error) {
+	m := new(PembaruanSaldo)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TabunganServer is the server API for Tabungan service.
+// All implementations must embed UnimplementedTabunganServer
+// for forward compatibility
+type TabunganServer interface {
+	RegistrasiNasabah(context.Context, *RequestRegistrasiNasabah) (*Rekening, error)
+	GetNasabah(context.Context, *RequestGetNasabah) (*Nasabah, error)
+	UpdateNasabah(context.Context, *RequestUpdateNasabah) (*ResponseUpdateNasabah, error)
+	GetDaftarRekening(context.Context, *RequestGetDaftarRekening) (*DaftarRekening, error)
+	GetRekening(context.Context, *RequestGetRekening) (*Rekening, error)
+	TarikDana(context.Context, *RequestTarikSetorDana) (*ResponseTarikSetorDana, error)
+	SetorDana(context.Context, *RequestTarikSetorDana) (*ResponseTarikSetorDana, error)
+	// GetMutasi streams one page of transactions, newest first.
+	GetMutasi(*RequestGetMutasi, Tabungan_GetMutasiServer) error
+	// StreamSaldo sends the balance of every rekening, then an update for
+	// every setor or tarik until the client cancels.
+	StreamSaldo(*RequestStreamSaldo, Tabungan_StreamSaldoServer) error
+	mustEmbedUnimplementedTabunganServer()
+}
+
+// UnimplementedTabunganServer must be embedded to have forward compatible implementations.
+type UnimplementedTabunganServer struct {
+}
+
+func (UnimplementedTabunganServer) RegistrasiNasabah(context.Context, *RequestRegistrasiNasabah) (*Rekening, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegistrasiNasabah not implemented")
+}
+func (UnimplementedTabunganServer) GetNasabah(context.Context, *RequestGetNasabah) (*Nasabah, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNasabah not implemented")
+}
+func (UnimplementedTabunganServer) UpdateNasabah(context.Context, *RequestUpdateNasabah) (*ResponseUpdateNasabah, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateNasabah not implemented")
+}
+func (UnimplementedTabunganServer) GetDaftarRekening(context.Context, *RequestGetDaftarRekening) (*DaftarRekening, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDaftarRekening not implemented")
+}
+func (UnimplementedTabunganServer) GetRekening(context.Context, *RequestGetRekening) (*Rekening, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRekening not implemented")
+}
+func (UnimplementedTabunganServer) TarikDana(context.Context, *RequestTarikSetorDana) (*ResponseTarikSetorDana, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TarikDana not implemented")
+}
+func (UnimplementedTabunganServer) SetorDana(context.Context, *RequestTarikSetorDana) (*ResponseTarikSetorDana, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetorDana not implemented")
+}
+func (UnimplementedTabunganServer) GetMutasi(*RequestGetMutasi, Tabungan_GetMutasiServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetMutasi not implemented")
+}
+func (UnimplementedTabunganServer) StreamSaldo(*RequestStreamSaldo, Tabungan_StreamSaldoServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamSaldo not implemented")
+}
+func (UnimplementedTabunganServer) mustEmbedUnimplementedTabunganServer() {}
+
+// UnsafeTabunganServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TabunganServer will
+// result in compilation errors.
+type UnsafeTabunganServer interface {
+	mustEmbedUnimplementedTabunganServer()
+}
+
+func RegisterTabunganServer(s grpc.ServiceRegistrar, srv TabunganServer) {
+	s.RegisterService(&Tabungan_ServiceDesc, srv)
+}
+
+func _Tabungan_RegistrasiNasabah_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestRegistrasiNasabah)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TabunganServer).RegistrasiNasabah(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Tabungan_RegistrasiNasabah_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TabunganServer).RegistrasiNasabah(ctx, req.(*RequestRegistrasiNasabah))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Tabungan_GetNasabah_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestGetNasabah)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TabunganServer).GetNasabah(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Tabungan_GetNasabah_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TabunganServer).GetNasabah(ctx, req.(*RequestGetNasabah))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Tabungan_UpdateNasabah_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestUpdateNasabah)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TabunganServer).UpdateNasabah(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Tabungan_UpdateNasabah_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TabunganServer).UpdateNasabah(ctx, req.(*RequestUpdateNasabah))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Tabungan_GetDaftarRekening_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestGetDaftarRekening)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TabunganServer).GetDaftarRekening(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Tabungan_GetDaftarRekening_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TabunganServer).GetDaftarRekening(ctx, req.(*RequestGetDaftarRekening))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Tabungan_GetRekening_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestGetRekening)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TabunganServer).GetRekening(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Tabungan_GetRekening_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TabunganServer).GetRekening(ctx, req.(*RequestGetRekening))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Tabungan_TarikDana_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestTarikSetorDana)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TabunganServer).TarikDana(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Tabungan_TarikDana_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TabunganServer).TarikDana(ctx, req.(*RequestTarikSetorDana))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Tabungan_SetorDana_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestTarikSetorDana)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TabunganServer).SetorDana(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Tabungan_SetorDana_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TabunganServer).SetorDana(ctx, req.(*RequestTarikSetorDana))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Tabungan_GetMutasi_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RequestGetMutasi)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TabunganServer).GetMutasi(m, &tabunganGetMutasiServer{stream})
+}
+
+type Tabungan_GetMutasiServer interface {
+	Send(*Mutasi) error
+	grpc.ServerStream
+}
+
+type tabunganGetMutasiServer struct {
+	grpc.ServerStream
+}
+
+func (x *tabunganGetMutasiServer) Send(m *Mutasi) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Tabungan_StreamSaldo_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RequestStreamSaldo)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TabunganServer).StreamSaldo(m, &tabunganStreamSaldoServer{stream})
+}
+
+type Tabungan_StreamSaldoServer interface {
+	Send(*PembaruanSaldo) error
+	grpc.ServerStream
+}
+
+type tabunganStreamSaldoServer struct {
+	grpc.ServerStream
+}
+
+func (x *tabunganStreamSaldoServer) Send(m *PembaruanSaldo) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Tabungan_ServiceDesc is the grpc.ServiceDesc for Tabungan service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Tabungan_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tabungan.Tabungan",
+	HandlerType: (*TabunganServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RegistrasiNasabah",
+			Handler:    _Tabungan_RegistrasiNasabah_Handler,
+		},
+		{
+			MethodName: "GetNasabah",
+			Handler:    _Tabungan_GetNasabah_Handler,
+		},
+		{
+			MethodName: "UpdateNasabah",
+			Handler:    _Tabungan_UpdateNasabah_Handler,
+		},
+		{
+			MethodName: "GetDaftarRekening",
+			Handler:    _Tabungan_GetDaftarRekening_Handler,
+		},
+		{
+			MethodName: "GetRekening",
+			Handler:    _Tabungan_GetRekening_Handler,
+		},
+		{
+			MethodName: "TarikDana",
+			Handler:    _Tabungan_TarikDana_Handler,
+		},
+		{
+			MethodName: "SetorDana",
+			Handler:    _Tabungan_SetorDana_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetMutasi",
+			Handler:       _Tabungan_GetMutasi_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamSaldo",
+			Handler:       _Tabungan_StreamSaldo_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "tabungan.proto",
+}
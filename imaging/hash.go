@@ -0,0 +1,41 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math/bits"
+	"strconv"
+)
+
+// Hash returns a 64-bit difference hash of img as 16 hex digits. Copies of the
+// same picture hash a few bits apart even after scaling or re-encoding, so
+// hashes are compared with Distance rather than for equality.
+func Hash(img *image.RGBA) string {
+	small := resize(img, 9, 8)
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if luma(small, x, y) < luma(small, x+1, y) {
+				hash |= 1
+			}
+		}
+	}
+	return fmt.Sprintf("%016x", hash)
+}
+
+// Distance counts the bits that differ between two hashes from Hash. Hashes
+// that cannot be parsed are as far apart as possible.
+func Distance(a, b string) int {
+	x, errA := strconv.ParseUint(a, 16, 64)
+	y, errB := strconv.ParseUint(b, 16, 64)
+	if errA != nil || errB != nil {
+		return 64
+	}
+	return bits.OnesCount64(x ^ y)
+}
+
+func luma(img *image.RGBA, x, y int) int {
+	i := img.PixOffset(x, y)
+	return 299*int(img.Pix[i]) + 587*int(img.Pix[i+1]) + 114*int(img.Pix[i+2])
+}
@@ -0,0 +1,23 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func TestDecodeMaxPixels(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 100, 50))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Decode(buf.Bytes(), 4999); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Decode over the budget: err = %v, want ErrTooLarge", err)
+	}
+	img, err := Decode(buf.Bytes(), 5000)
+	if err != nil || img.Bounds().Dx() != 100 || img.Bounds().Dy() != 50 {
+		t.Errorf("Decode within the budget = %v, %v", img.Bounds(), err)
+	}
+}
@@ -1,22 +1,72 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 	"tabungan-api/api"
 	"tabungan-api/app"
+	"tabungan-api/authtoken"
+	"tabungan-api/encryption"
+	"tabungan-api/events"
+	"tabungan-api/grpcapi"
+	"tabungan-api/masking"
+	"tabungan-api/models"
+	"tabungan-api/notification"
 	"tabungan-api/repository"
+	"tabungan-api/screening"
+	"tabungan-api/storage"
+	"tabungan-api/tracing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
 func main() {
+	rotateKeys := flag.Bool("rotate-keys", false, "generate a new encryption key, move all encrypted data onto it and exit")
+	batchSize := flag.Int("batch-size", 500, "rows rotated per transaction by -rotate-keys")
+	issueToken := flag.String("issue-token", "", "print a petugas token for ID:ROLE, signed with PETUGAS_TOKEN_SECRET, and exit")
+	tokenTTL := flag.Duration("token-ttl", 12*time.Hour, "validity of the token printed by -issue-token")
+	flag.Parse()
+
 	logger := logrus.New()
+	logger.AddHook(masking.NewHook(masking.DefaultLogPolicy))
+	logger.AddHook(tracing.LogHook{})
 	var database string
 	var host string
 	var port int
+	var grpcPort int
 	var photoDir string
 	var docDir string
+	var reportDir string
+	var storageBackend string
+	var keyFile string
+	var rahasiaPetugas string
+	var sunset time.Time
+	var batasPersetujuan float64
+	var batasTarikBelumKYC float64
+	var intervalPembersihan time.Duration
+	var umurFileYatim time.Duration
+	var intervalRescreening time.Duration
+	var ambangLaporanTunai float64
+	var intervalLaporanTunai time.Duration
+	var intervalRelayEvent time.Duration
+	var intervalWebhook time.Duration
+	var maksPercobaanWebhook int
+	var jedaWebhook time.Duration
+	var ambangNotifikasi float64
+	var intervalNotifikasi time.Duration
+	var maksPercobaanNotifikasi int
+	var jedaNotifikasi time.Duration
+	var batasShutdown time.Duration
+	var konfigurasiTrace tracing.Config
 	viper.SetConfigFile("./.env")
 	viper.AutomaticEnv()
 	err := viper.ReadInConfig()
@@ -32,15 +82,338 @@ func main() {
 	if port = viper.GetInt("API_PORT"); port == 0 {
 		port = 8888
 	}
+	if grpcPort = viper.GetInt("GRPC_PORT"); grpcPort == 0 {
+		grpcPort = 9888
+	}
 	if photoDir = viper.GetString("PHOTO_DIR"); photoDir == "" {
 		photoDir = "./photo"
 	}
 	if docDir = viper.GetString("DOC_DIR"); docDir == "" {
 		docDir = "./document"
 	}
+	if reportDir = viper.GetString("REPORT_DIR"); reportDir == "" {
+		reportDir = "./report"
+	}
+	if storageBackend = viper.GetString("STORAGE_BACKEND"); storageBackend == "" {
+		storageBackend = "local"
+	}
+	if keyFile = viper.GetString("KEY_FILE"); keyFile == "" {
+		keyFile = "./keys.json"
+	}
+	// Petugas tokens are signed with this secret; without it every back-office
+	// request is rejected.
+	rahasiaPetugas = viper.GetString("PETUGAS_TOKEN_SECRET")
+	if *issueToken != "" {
+		id, role, _ := strings.Cut(*issueToken, ":")
+		if id == "" || (role != models.RoleTeller && role != models.RoleSupervisor && role != models.RoleAdmin) {
+			panic(fmt.Errorf("invalid -issue-token %q, want ID:ROLE with role teller, supervisor or admin", *issueToken))
+		}
+		token, err := authtoken.Sign([]byte(rahasiaPetugas), authtoken.Claims{
+			Subject: id,
+			Role:    role,
+			Expires: time.Now().Add(*tokenTTL).Unix(),
+		})
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(token)
+		return
+	}
+	// Unversioned paths are deprecated aliases of /v1, announced to be
+	// removed at API_UNVERSIONED_SUNSET.
+	if sunsetTanpaVersi := viper.GetString("API_UNVERSIONED_SUNSET"); sunsetTanpaVersi != "" {
+		if sunset, err = time.Parse("2006-01-02", sunsetTanpaVersi); err != nil {
+			panic(fmt.Errorf("invalid API_UNVERSIONED_SUNSET: %w", err))
+		}
+	}
+	// /docs serves Swagger UI from DOCS_ASSETS_DIR, which holds swagger-ui.css
+	// and swagger-ui-bundle.js of the swagger-ui-dist release named in
+	// api/openapi.go. Without it only openapi.json is served.
+	dirDocs := viper.GetString("DOCS_ASSETS_DIR")
+	if batasPersetujuan = viper.GetFloat64("APPROVAL_LIMIT"); batasPersetujuan == 0 {
+		batasPersetujuan = 10000000
+	}
+	// Nasabah whose KYC is not verified yet may withdraw up to this amount per
+	// transaction. Only an explicit KYC_UNVERIFIED_LIMIT=0 blocks them entirely.
+	batasTarikBelumKYC = app.BatasTarikBelumKYCDefault
+	if viper.IsSet("KYC_UNVERIFIED_LIMIT") {
+		batasTarikBelumKYC = viper.GetFloat64("KYC_UNVERIFIED_LIMIT")
+	}
+	if intervalPembersihan = viper.GetDuration("ORPHAN_CLEANUP_INTERVAL"); intervalPembersihan == 0 {
+		intervalPembersihan = time.Hour
+	}
+	if umurFileYatim = viper.GetDuration("ORPHAN_MIN_AGE"); umurFileYatim == 0 {
+		umurFileYatim = 24 * time.Hour
+	}
+	if intervalRescreening = viper.GetDuration("WATCHLIST_RESCREEN_INTERVAL"); intervalRescreening == 0 {
+		intervalRescreening = 24 * time.Hour
+	}
+	if ambangLaporanTunai = viper.GetFloat64("CASH_REPORT_THRESHOLD"); ambangLaporanTunai == 0 {
+		ambangLaporanTunai = 500000000
+	}
+	if intervalLaporanTunai = viper.GetDuration("CASH_REPORT_INTERVAL"); intervalLaporanTunai == 0 {
+		intervalLaporanTunai = time.Hour
+	}
+	if intervalRelayEvent = viper.GetDuration("EVENT_RELAY_INTERVAL"); intervalRelayEvent == 0 {
+		intervalRelayEvent = time.Second
+	}
+	if intervalWebhook = viper.GetDuration("WEBHOOK_INTERVAL"); intervalWebhook == 0 {
+		intervalWebhook = 5 * time.Second
+	}
+	if maksPercobaanWebhook = viper.GetInt("WEBHOOK_MAX_ATTEMPTS"); maksPercobaanWebhook == 0 {
+		maksPercobaanWebhook = 8
+	}
+	if jedaWebhook = viper.GetDuration("WEBHOOK_RETRY_BASE"); jedaWebhook == 0 {
+		jedaWebhook = 30 * time.Second
+	}
+	if ambangNotifikasi = viper.GetFloat64("NOTIFICATION_THRESHOLD"); ambangNotifikasi == 0 {
+		ambangNotifikasi = 1000000
+	}
+	if intervalNotifikasi = viper.GetDuration("NOTIFICATION_INTERVAL"); intervalNotifikasi == 0 {
+		intervalNotifikasi = 5 * time.Second
+	}
+	if maksPercobaanNotifikasi = viper.GetInt("NOTIFICATION_MAX_ATTEMPTS"); maksPercobaanNotifikasi == 0 {
+		maksPercobaanNotifikasi = 5
+	}
+	if jedaNotifikasi = viper.GetDuration("NOTIFICATION_RETRY_BASE"); jedaNotifikasi == 0 {
+		jedaNotifikasi = time.Minute
+	}
+	// Requests, calls and jobs in flight get SHUTDOWN_TIMEOUT to finish after
+	// SIGTERM.
+	if batasShutdown = viper.GetDuration("SHUTDOWN_TIMEOUT"); batasShutdown == 0 {
+		batasShutdown = 30 * time.Second
+	}
+	// Before that, /readyz fails for SHUTDOWN_READY_DELAY while the servers
+	// keep serving, so load balancers can take the instance out first.
+	jedaSiap := 5 * time.Second
+	if viper.IsSet("SHUTDOWN_READY_DELAY") {
+		jedaSiap = viper.GetDuration("SHUTDOWN_READY_DELAY")
+	}
+	// Spans go to an OTLP/HTTP collector at OTLP_ENDPOINT (host:port) and/or
+	// to TRACE_FILE as JSON lines. Without either, logs still carry trace IDs.
+	if konfigurasiTrace.ServiceName = viper.GetString("TRACE_SERVICE_NAME"); konfigurasiTrace.ServiceName == "" {
+		konfigurasiTrace.ServiceName = "tabungan-api"
+	}
+	konfigurasiTrace.OTLPEndpoint = viper.GetString("OTLP_ENDPOINT")
+	konfigurasiTrace.OTLPInsecure = viper.GetBool("OTLP_INSECURE")
+	konfigurasiTrace.File = viper.GetString("TRACE_FILE")
 	fmt.Print(host, port)
-	repo := repository.InitDatabase(database, logger)
-	app := app.NewTabunganApp(photoDir, docDir, repo, logger)
-	api := api.NewRESTAPI(host, port, app, logger)
-	api.Start()
+	tutupTrace, err := tracing.Setup(konfigurasiTrace)
+	if err != nil {
+		panic(err)
+	}
+	keys, err := encryption.LoadKeyFile(keyFile)
+	if err != nil {
+		panic(err)
+	}
+	repo := repository.InitDatabase(database, keys, logger)
+	if *rotateKeys {
+		keyID, err := keys.Rotate()
+		if err != nil {
+			panic(err)
+		}
+		jumlah, err := repo.RotasiKunci(*batchSize)
+		if err != nil {
+			panic(err)
+		}
+		logger.WithFields(logrus.Fields{
+			"key_id": keyID,
+			"jumlah": jumlah,
+		}).Info("rotasi kunci selesai")
+		return
+	}
+	var fotoStorage, dokumenStorage, laporanStorage storage.Storage
+	switch storageBackend {
+	case "local":
+		fotoStorage = storage.NewLocalStorage(photoDir)
+		dokumenStorage = storage.NewLocalStorage(docDir)
+		laporanStorage = storage.NewLocalStorage(reportDir)
+	case "s3":
+		s3Config := storage.S3Config{
+			Endpoint:  viper.GetString("S3_ENDPOINT"),
+			Region:    viper.GetString("S3_REGION"),
+			Bucket:    viper.GetString("S3_BUCKET"),
+			AccessKey: viper.GetString("S3_ACCESS_KEY"),
+			SecretKey: viper.GetString("S3_SECRET_KEY"),
+			PathStyle: !viper.IsSet("S3_PATH_STYLE") || viper.GetBool("S3_PATH_STYLE"),
+		}
+		s3Config.Prefix = "photo/"
+		fotoStorage = storage.NewS3Storage(s3Config)
+		s3Config.Prefix = "document/"
+		dokumenStorage = storage.NewS3Storage(s3Config)
+		s3Config.Prefix = "report/"
+		laporanStorage = storage.NewS3Storage(s3Config)
+	default:
+		panic(fmt.Errorf("unknown STORAGE_BACKEND %q", storageBackend))
+	}
+	opts := []app.Option{
+		app.WithBatasPersetujuan(batasPersetujuan),
+		app.WithBatasTarikBelumKYC(batasTarikBelumKYC),
+		app.WithLaporanTunai(laporanStorage, ambangLaporanTunai),
+		app.WithWebhook(&http.Client{Timeout: 10 * time.Second}, maksPercobaanWebhook, jedaWebhook),
+	}
+	// Events are always written to the outbox; EVENT_SINKS lists where the
+	// relay publishes them, e.g. "stdout,file,nats".
+	var sinks []events.Sink
+	for _, name := range strings.Split(viper.GetString("EVENT_SINKS"), ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "stdout":
+			sinks = append(sinks, events.NewStdoutSink())
+		case "file":
+			eventFile := viper.GetString("EVENT_FILE")
+			if eventFile == "" {
+				eventFile = "./events.jsonl"
+			}
+			sink, err := events.NewFileSink(eventFile)
+			if err != nil {
+				panic(err)
+			}
+			sinks = append(sinks, sink)
+		case "nats":
+			natsAddress := viper.GetString("NATS_ADDRESS")
+			if natsAddress == "" {
+				natsAddress = "127.0.0.1:4222"
+			}
+			natsPrefix := viper.GetString("NATS_SUBJECT_PREFIX")
+			if natsPrefix == "" {
+				natsPrefix = "tabungan"
+			}
+			sinks = append(sinks, events.NewNATSSink(natsAddress, natsPrefix))
+		default:
+			panic(fmt.Errorf("unknown event sink %q", name))
+		}
+	}
+	opts = append(opts, app.WithSinkEvent(sinks...))
+	// Email goes out over SMTP when SMTP_HOST is set. Without it, and for SMS
+	// until a gateway is integrated, messages are written to local files.
+	templates, err := notification.LoadTemplates(viper.GetString("NOTIFICATION_TEMPLATE_DIR"))
+	if err != nil {
+		panic(err)
+	}
+	var kanal []notification.Channel
+	if smtpHost := viper.GetString("SMTP_HOST"); smtpHost != "" {
+		kanal = append(kanal, notification.NewSMTPChannel(notification.SMTPConfig{
+			Host:     smtpHost,
+			Port:     viper.GetInt("SMTP_PORT"),
+			Username: viper.GetString("SMTP_USERNAME"),
+			Password: viper.GetString("SMTP_PASSWORD"),
+			From:     viper.GetString("SMTP_FROM"),
+		}))
+	} else {
+		emailFile := viper.GetString("EMAIL_FILE")
+		if emailFile == "" {
+			emailFile = "./email.jsonl"
+		}
+		email, err := notification.NewFileChannel(notification.ChannelEmail, emailFile)
+		if err != nil {
+			panic(err)
+		}
+		kanal = append(kanal, email)
+	}
+	smsFile := viper.GetString("SMS_FILE")
+	if smsFile == "" {
+		smsFile = "./sms.jsonl"
+	}
+	smsGateway, err := notification.NewFileChannel(notification.ChannelSMS, smsFile)
+	if err != nil {
+		panic(err)
+	}
+	kanal = append(kanal, notification.NewSMSChannel(smsGateway))
+	opts = append(opts, app.WithNotifikasi(templates, kanal, ambangNotifikasi, maksPercobaanNotifikasi, jedaNotifikasi))
+	// Screening is off unless a watchlist file is configured.
+	var daftarPantauan *screening.Watchlist
+	if watchlistFile := viper.GetString("WATCHLIST_FILE"); watchlistFile != "" {
+		daftarPantauan, err = screening.Load(watchlistFile)
+		if err != nil {
+			panic(err)
+		}
+		logger.WithFields(logrus.Fields{
+			"file":   watchlistFile,
+			"jumlah": daftarPantauan.Len(),
+			"versi":  daftarPantauan.Version(),
+		}).Info("daftar pantauan dimuat")
+		opts = append(opts, app.WithDaftarPantauan(daftarPantauan))
+	}
+	app := app.NewTabunganApp(fotoStorage, dokumenStorage, repo, logger, opts...)
+	// Background jobs are counted so that shutdown waits for the run in
+	// progress before closing the database.
+	stop := make(chan struct{})
+	var jobs sync.WaitGroup
+	jalankan := func(job func()) {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			job()
+		}()
+	}
+	jalankan(func() { app.JalankanPembersihanFile(intervalPembersihan, umurFileYatim, stop) })
+	jalankan(func() { app.JalankanLaporanTunai(intervalLaporanTunai, stop) })
+	jalankan(func() { app.JalankanRelayEvent(intervalRelayEvent, stop) })
+	jalankan(func() { app.JalankanWebhook(intervalWebhook, stop) })
+	jalankan(func() { app.JalankanNotifikasi(intervalNotifikasi, stop) })
+	if daftarPantauan != nil {
+		jalankan(func() { app.JalankanRescreening(intervalRescreening, stop) })
+	}
+	grpcAPI := grpcapi.NewGRPCAPI(host, grpcPort, app, logger)
+	api := api.NewRESTAPI(host, port, rahasiaPetugas, sunset, dirDocs, app, logger)
+	berhenti := make(chan error, 2)
+	go func() { berhenti <- grpcAPI.Start() }()
+	go func() { berhenti <- api.Start() }()
+	sinyal := make(chan os.Signal, 1)
+	signal.Notify(sinyal, syscall.SIGTERM, syscall.SIGINT)
+	select {
+	case s := <-sinyal:
+		logger.WithField("signal", s.String()).Info("server berhenti")
+	case err = <-berhenti:
+		if err != nil {
+			logger.WithField("error", err.Error()).Error("server gagal berjalan")
+		}
+	}
+
+	// The REST server fails readiness at once and everything keeps serving
+	// for jedaSiap. Streams never finish on their own, so they are closed
+	// next; then the servers drain, the jobs finish their current run and the
+	// database is closed last. Everything shares one deadline, which starts
+	// counting SHUTDOWN_TIMEOUT after the delay.
+	batas := time.Now().Add(jedaSiap + batasShutdown)
+	var servers sync.WaitGroup
+	matikan := func(nama string, shutdown func() error) {
+		servers.Add(1)
+		go func() {
+			defer servers.Done()
+			if errShutdown := shutdown(); errShutdown != nil {
+				logger.WithFields(logrus.Fields{
+					"server": nama,
+					"error":  errShutdown.Error(),
+				}).Error("shutdown server gagal")
+			}
+		}()
+	}
+	matikan("rest", func() error { return api.Shutdown(jedaSiap, time.Until(batas)) })
+	time.Sleep(jedaSiap)
+	app.TutupStream()
+	matikan("grpc", func() error { return grpcAPI.Shutdown(time.Until(batas)) })
+	servers.Wait()
+	close(stop)
+	jobsSelesai := make(chan struct{})
+	go func() {
+		jobs.Wait()
+		close(jobsSelesai)
+	}()
+	select {
+	case <-jobsSelesai:
+		repo.Close()
+		logger.Info("server berhenti dengan bersih")
+	case <-time.After(time.Until(batas)):
+		logger.Error("job latar belakang belum selesai, database tidak ditutup")
+	}
+	ctxTrace, batal := context.WithDeadline(context.Background(), batas)
+	if errTrace := tutupTrace(ctxTrace); errTrace != nil {
+		logger.WithField("error", errTrace.Error()).Error("pengiriman span terakhir gagal")
+	}
+	batal()
+	if err != nil {
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,76 @@
+package masking
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultLogPolicy keeps enough of a NIK to correlate log lines while hiding
+// the rest of the customer's identity.
+var DefaultLogPolicy = Policy{
+	"nik":             ShowLast(4),
+	"nik_lain":        ShowLast(4),
+	"aktor":           ShowLast(4),
+	"target":          ShowLast(4),
+	"maker":           ShowLast(4),
+	"nama":            Redact,
+	"alamat_ktp":      Redact,
+	"alamat_domisili": Redact,
+	"tanggal_lahir":   Redact,
+	"email":           Redact,
+	"no_hp":           ShowLast(4),
+}
+
+// Hook is a logrus hook that masks PII fields before an entry is written.
+type Hook struct {
+	policy Policy
+}
+
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire masks covered fields whole, and fields holding structured data, such
+// as maps or JSON payloads, wherever the policy covers a key inside them.
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	for key, value := range entry.Data {
+		if value == nil {
+			continue
+		}
+		if rule, ok := h.policy[key]; ok {
+			entry.Data[key] = rule(fmt.Sprint(value))
+			continue
+		}
+		if terstruktur(value) {
+			entry.Data[key] = h.policy.Apply(value)
+		}
+	}
+	return nil
+}
+
+// terstruktur tells whether value may hold covered fields inside it. Errors
+// are left alone, as their JSON shape drops the message.
+func terstruktur(value interface{}) bool {
+	if _, ok := value.(error); ok {
+		return false
+	}
+	if s, ok := value.(string); ok {
+		return strings.HasPrefix(s, "{")
+	}
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		return true
+	}
+	return false
+}
+
+func NewHook(policy Policy) *Hook {
+	return &Hook{policy: policy}
+}
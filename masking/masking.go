@@ -0,0 +1,117 @@
+package masking
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"tabungan-api/models"
+	"unicode/utf8"
+)
+
+// Rule turns a sensitive value into what may be shown.
+type Rule func(value string) string
+
+// Policy maps field names, as they appear in logrus fields and JSON keys, to
+// the rule applied to them. Fields not in the policy are left untouched.
+type Policy map[string]Rule
+
+func Redact(value string) string {
+	if value == "" {
+		return value
+	}
+	return "[REDACTED]"
+}
+
+// ShowLast masks every character except the last n.
+func ShowLast(n int) Rule {
+	return func(value string) string {
+		length := utf8.RuneCountInString(value)
+		if length <= n {
+			return strings.Repeat("*", length)
+		}
+		runes := []rune(value)
+		return strings.Repeat("*", length-n) + string(runes[length-n:])
+	}
+}
+
+// Apply returns a copy of v, converted to its JSON shape, with every field
+// covered by the policy masked. A covered field holding an object or a list
+// is masked all the way down. Strings holding a JSON object, such as operasi
+// payloads and audit diffs, are masked recursively as well.
+func (p Policy) Apply(v interface{}) interface{} {
+	if len(p) == 0 {
+		return v
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var generic interface{}
+	if err = json.Unmarshal(b, &generic); err != nil {
+		return v
+	}
+	return p.apply(nil, generic)
+}
+
+// apply masks v with rule, the rule of the closest enclosing field the
+// policy covers, or nil outside of covered fields.
+func (p Policy) apply(rule Rule, v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, field := range value {
+			value[k] = p.apply(p.rule(k, rule), field)
+		}
+		return value
+	case []interface{}:
+		for i, item := range value {
+			value[i] = p.apply(rule, item)
+		}
+		return value
+	case string:
+		if strings.HasPrefix(value, "{") {
+			var nested map[string]interface{}
+			if json.Unmarshal([]byte(value), &nested) == nil {
+				b, _ := json.Marshal(p.apply(rule, nested))
+				return string(b)
+			}
+		}
+		if rule != nil {
+			return rule(value)
+		}
+		return value
+	case nil:
+		return value
+	default:
+		if rule != nil {
+			return rule(fmt.Sprint(value))
+		}
+		return value
+	}
+}
+
+// rule is the rule for field key, falling back to the rule of the field it
+// is nested in.
+func (p Policy) rule(key string, parent Rule) Rule {
+	if rule, ok := p[key]; ok {
+		return rule
+	}
+	return parent
+}
+
+// DefaultResponsePolicies holds the response masking applied per petugas role.
+// Roles without an entry see data unmasked.
+var DefaultResponsePolicies = map[string]Policy{
+	models.RoleTeller: {
+		"nik":           ShowLast(4),
+		"nik_lain":      ShowLast(4),
+		"target":        ShowLast(4),
+		"aktor":         ShowLast(4),
+		"maker":         ShowLast(4),
+		"alamat_ktp":    Redact,
+		"tanggal_lahir": Redact,
+		"email":         Redact,
+		"no_hp":         ShowLast(4),
+		"tujuan":        Redact,
+		"isi":           Redact,
+	},
+}
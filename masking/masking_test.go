@@ -0,0 +1,60 @@
+package masking
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestApplySubtree(t *testing.T) {
+	policy := Policy{"nik": ShowLast(4), "nasabah": Redact}
+	got := policy.Apply(map[string]interface{}{
+		"nasabah": map[string]interface{}{"nama": "Budi", "alamat": []string{"Jl A", "Jl B"}, "nik": "3171012345678901"},
+		"payload": `{"nik":"3171012345678901","nominal":5000}`,
+		"daftar":  []map[string]string{{"nik": "3171012345678902"}},
+		"status":  "aktif",
+	}).(map[string]interface{})
+
+	nasabah := got["nasabah"].(map[string]interface{})
+	if nasabah["nama"] != "[REDACTED]" || nasabah["alamat"].([]interface{})[1] != "[REDACTED]" {
+		t.Errorf("fields under a covered key = %v, want them redacted", nasabah)
+	}
+	if nasabah["nik"] != "************8901" {
+		t.Errorf("a covered key under another keeps its own rule: nik = %v", nasabah["nik"])
+	}
+	if got["payload"] != `{"nik":"************8901","nominal":5000}` {
+		t.Errorf("payload = %v", got["payload"])
+	}
+	if nik := got["daftar"].([]interface{})[0].(map[string]interface{})["nik"]; nik != "************8902" {
+		t.Errorf("nik in a list = %v", nik)
+	}
+	if got["status"] != "aktif" {
+		t.Errorf("status = %v, want it untouched", got["status"])
+	}
+}
+
+func TestHook(t *testing.T) {
+	var out strings.Builder
+	logger := logrus.New()
+	logger.SetOutput(&out)
+	logger.SetFormatter(&logrus.JSONFormatter{DisableTimestamp: true})
+	logger.AddHook(NewHook(DefaultLogPolicy))
+	logger.WithFields(logrus.Fields{
+		"target":  "3171012345678901",
+		"maker":   "petugas-0001",
+		"payload": map[string]string{"nama": "Budi Santoso", "nik": "3171012345678902"},
+		"error":   errors.New("nik 3171012345678903 tidak ditemukan"),
+	}).Warn("operasi gagal")
+
+	log := out.String()
+	for _, bocor := range []string{"3171012345678901", "petugas-", "Budi Santoso", "3171012345678902"} {
+		if strings.Contains(log, bocor) {
+			t.Errorf("log line %s leaks %q", log, bocor)
+		}
+	}
+	if !strings.Contains(log, "tidak ditemukan") {
+		t.Errorf("log line %s lost the error message", log)
+	}
+}
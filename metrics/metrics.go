@@ -0,0 +1,289 @@
+// Package metrics keeps counters and histograms in memory and writes them in
+// the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are upper bounds in seconds suited to request and query
+// latencies.
+var DefaultBuckets = []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Default is the registry that NewCounter and NewHistogram add to.
+var Default = &Registry{}
+
+type metric interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// Registry is a set of metrics exposed together.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.metrics {
+		if existing.name() == m.name() {
+			panic(fmt.Sprintf("metric %s registered twice", m.name()))
+		}
+	}
+	r.metrics = append(r.metrics, m)
+}
+
+// WriteTo writes every metric of the registry, sorted by name.
+func (r *Registry) WriteTo(w io.Writer) (n int64, err error) {
+	r.mu.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mu.Unlock()
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].name() < metrics[j].name() })
+	counter := &countingWriter{w: w}
+	buf := bufio.NewWriter(counter)
+	for _, m := range metrics {
+		m.write(buf)
+	}
+	err = buf.Flush()
+	return counter.n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (n int, err error) {
+	n, err = c.w.Write(p)
+	c.n += int64(n)
+	return
+}
+
+// series holds the label values of one series, joined so they can key a
+// map.
+type series struct {
+	labels []string
+	mu     sync.Mutex
+	key    string
+}
+
+func seriesKey(labels []string) string {
+	return strings.Join(labels, "\xff")
+}
+
+type family struct {
+	metricName string
+	help       string
+	labelNames []string
+	mu         sync.Mutex
+}
+
+func (f *family) name() string {
+	return f.metricName
+}
+
+func (f *family) checkLabels(values []string) {
+	if len(values) != len(f.labelNames) {
+		panic(fmt.Sprintf("metric %s takes %d label values, got %d", f.metricName, len(f.labelNames), len(values)))
+	}
+}
+
+func (f *family) writeHeader(w *bufio.Writer, kind string) {
+	help := strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(f.help)
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.metricName, help, f.metricName, kind)
+}
+
+// writeSample writes one line. A non-empty extraName adds a label after the
+// series labels, as le does for histogram buckets.
+func (f *family) writeSample(w *bufio.Writer, suffix string, labels []string, extraName, extraValue string, value float64) {
+	w.WriteString(f.metricName + suffix)
+	names, values := f.labelNames, labels
+	if extraName != "" {
+		names = append(names[:len(names):len(names)], extraName)
+		values = append(values[:len(values):len(values)], extraValue)
+	}
+	if len(names) > 0 {
+		w.WriteByte('{')
+		for i, name := range names {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(name + `="` + escapeLabel(values[i]) + `"`)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteString(" " + formatFloat(value) + "\n")
+}
+
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// Counter is a monotonically increasing value per combination of labels.
+type Counter struct {
+	family
+	values map[string]*counterSeries
+}
+
+type counterSeries struct {
+	series
+	value float64
+}
+
+// NewCounter registers a counter in Default.
+func NewCounter(name, help string, labelNames ...string) *Counter {
+	c := &Counter{
+		family: family{metricName: name, help: help, labelNames: labelNames},
+		values: make(map[string]*counterSeries),
+	}
+	if len(labelNames) == 0 {
+		c.get(nil)
+	}
+	Default.register(c)
+	return c
+}
+
+func (c *Counter) get(labels []string) *counterSeries {
+	c.checkLabels(labels)
+	key := seriesKey(labels)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.values[key]
+	if !ok {
+		s = &counterSeries{series: series{labels: append([]string(nil), labels...), key: key}}
+		c.values[key] = s
+	}
+	return s
+}
+
+// Add adds value, which must not be negative, to the series of labels.
+func (c *Counter) Add(value float64, labels ...string) {
+	if value < 0 {
+		panic(fmt.Sprintf("counter %s cannot decrease", c.metricName))
+	}
+	s := c.get(labels)
+	s.mu.Lock()
+	s.value += value
+	s.mu.Unlock()
+}
+
+func (c *Counter) Inc(labels ...string) {
+	c.Add(1, labels...)
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.writeHeader(w, "counter")
+	for _, s := range c.sorted() {
+		s.mu.Lock()
+		value := s.value
+		s.mu.Unlock()
+		c.writeSample(w, "", s.labels, "", "", value)
+	}
+}
+
+func (c *Counter) sorted() (list []*counterSeries) {
+	c.mu.Lock()
+	for _, s := range c.values {
+		list = append(list, s)
+	}
+	c.mu.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].key < list[j].key })
+	return
+}
+
+// Histogram counts observations into buckets per combination of labels.
+type Histogram struct {
+	family
+	buckets []float64
+	values  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	series
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram registers a histogram in Default. buckets are the upper
+// bounds in increasing order; +Inf is implied.
+func NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	h := &Histogram{
+		family:  family{metricName: name, help: help, labelNames: labelNames},
+		buckets: buckets,
+		values:  make(map[string]*histogramSeries),
+	}
+	Default.register(h)
+	return h
+}
+
+func (h *Histogram) get(labels []string) *histogramSeries {
+	h.checkLabels(labels)
+	key := seriesKey(labels)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.values[key]
+	if !ok {
+		s = &histogramSeries{
+			series: series{labels: append([]string(nil), labels...), key: key},
+			counts: make([]uint64, len(h.buckets)),
+		}
+		h.values[key] = s
+	}
+	return s
+}
+
+func (h *Histogram) Observe(value float64, labels ...string) {
+	s := h.get(labels)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += value
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.writeHeader(w, "histogram")
+	h.mu.Lock()
+	var list []*histogramSeries
+	for _, s := range h.values {
+		list = append(list, s)
+	}
+	h.mu.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].key < list[j].key })
+	for _, s := range list {
+		s.mu.Lock()
+		counts := append([]uint64(nil), s.counts...)
+		count, sum := s.count, s.sum
+		s.mu.Unlock()
+		for i, bound := range h.buckets {
+			h.writeSample(w, "_bucket", s.labels, "le", formatFloat(bound), float64(counts[i]))
+		}
+		h.writeSample(w, "_bucket", s.labels, "le", "+Inf", float64(count))
+		h.writeSample(w, "_sum", s.labels, "", "", sum)
+		h.writeSample(w, "_count", s.labels, "", "", float64(count))
+	}
+}
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func tulis(t *testing.T, metrics ...metric) string {
+	r := &Registry{}
+	for _, m := range metrics {
+		r.register(m)
+	}
+	var out strings.Builder
+	n, err := r.WriteTo(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(out.Len()) {
+		t.Errorf("WriteTo = %d bytes, wrote %d", n, out.Len())
+	}
+	return out.String()
+}
+
+func TestCounterText(t *testing.T) {
+	c := NewCounter("uji_counter_total", "Help with a \\ backslash\nand a newline.", "path", "status")
+	c.Inc(`C:\tmp`, "200")
+	c.Add(2.5, `say "hi"`+"\nbye", "500")
+	c.Inc(`C:\tmp`, "200")
+	tanpaLabel := NewCounter("uji_tanpa_label_total", "No labels.")
+
+	want := `# HELP uji_counter_total Help with a \\ backslash\nand a newline.
+# TYPE uji_counter_total counter
+uji_counter_total{path="C:\\tmp",status="200"} 2
+uji_counter_total{path="say \"hi\"\nbye",status="500"} 2.5
+# HELP uji_tanpa_label_total No labels.
+# TYPE uji_tanpa_label_total counter
+uji_tanpa_label_total 0
+`
+	if got := tulis(t, tanpaLabel, c); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHistogramText(t *testing.T) {
+	h := NewHistogram("uji_durasi_seconds", "Durations.", []float64{0.1, 1, 5}, "route")
+	for _, v := range []float64{0.05, 0.1, 0.5, 5, 7} {
+		h.Observe(v, "/a")
+	}
+	h.Observe(2, "/b")
+
+	want := `# HELP uji_durasi_seconds Durations.
+# TYPE uji_durasi_seconds histogram
+uji_durasi_seconds_bucket{route="/a",le="0.1"} 2
+uji_durasi_seconds_bucket{route="/a",le="1"} 3
+uji_durasi_seconds_bucket{route="/a",le="5"} 4
+uji_durasi_seconds_bucket{route="/a",le="+Inf"} 5
+uji_durasi_seconds_sum{route="/a"} 12.65
+uji_durasi_seconds_count{route="/a"} 5
+uji_durasi_seconds_bucket{route="/b",le="0.1"} 0
+uji_durasi_seconds_bucket{route="/b",le="1"} 0
+uji_durasi_seconds_bucket{route="/b",le="5"} 1
+uji_durasi_seconds_bucket{route="/b",le="+Inf"} 1
+uji_durasi_seconds_sum{route="/b"} 2
+uji_durasi_seconds_count{route="/b"} 1
+`
+	if got := tulis(t, h); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMisuse(t *testing.T) {
+	panics := func(nama string, f func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s did not panic", nama)
+			}
+		}()
+		f()
+	}
+	c := NewCounter("uji_salah_total", "Misused.", "jenis")
+	panics("wrong number of labels", func() { c.Inc() })
+	panics("negative Add", func() { c.Add(-1, "a") })
+	panics("registering a name twice", func() { NewCounter("uji_salah_total", "Again.") })
+}
@@ -1,5 +1,87 @@
 package models
 
+// LayoutWaktu is the timestamp layout stored in every table written after
+// mutasi. It sorts lexically, so range filters can compare plain strings.
+const LayoutWaktu = "2006-01-02 15:04:05.000000"
+
+const (
+	OperasiTarikDana     = "tarik_dana"
+	OperasiUpdateNasabah = "update_nasabah"
+	OperasiReversal      = "reversal"
+
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+	StatusExecuted = "executed"
+	StatusFailed   = "failed"
+
+	KYCPending   = "pending"
+	KYCSubmitted = "submitted"
+	KYCVerified  = "verified"
+	KYCRejected  = "rejected"
+
+	DuplikatNamaTanggalLahir = "nama_tanggal_lahir"
+	DuplikatAlamat           = "alamat"
+	DuplikatFoto             = "foto"
+
+	DuplikatPending       = "pending"
+	DuplikatTerkonfirmasi = "confirmed"
+	DuplikatBukan         = "dismissed"
+
+	ScreeningBlokir = "blokir"
+	ScreeningReview = "review"
+
+	ScreeningPending       = "pending"
+	ScreeningTerkonfirmasi = "confirmed"
+	ScreeningBukan         = "dismissed"
+
+	AturanVelocity     = "velocity"
+	AturanStructuring  = "structuring"
+	AturanSetoranBesar = "setoran_tunai_besar"
+	AturanDormant      = "reaktivasi_dormant"
+
+	SeverityRendah = "low"
+	SeveritySedang = "medium"
+	SeverityTinggi = "high"
+
+	AlertPending       = "pending"
+	AlertTerkonfirmasi = "confirmed"
+	AlertBukan         = "dismissed"
+
+	LaporanDibuat     = "generated"
+	LaporanDigantikan = "superseded"
+	LaporanDikirim    = "submitted"
+	LaporanDiterima   = "accepted"
+	LaporanDitolak    = "rejected"
+
+	EventNasabahRegistered = "NasabahRegistered"
+	EventRekeningOpened    = "RekeningOpened"
+	EventDanaDisetor       = "DanaDisetor"
+	EventDanaDitarik       = "DanaDitarik"
+
+	WebhookPending  = "pending"
+	WebhookTerkirim = "delivered"
+	WebhookGagal    = "dead"
+
+	NotifikasiPending  = "pending"
+	NotifikasiTerkirim = "sent"
+	NotifikasiGagal    = "failed"
+
+	PemicuRegistrasi  = "registrasi"
+	PemicuUpdate      = "update_nasabah"
+	PemicuRescreening = "rescreening"
+
+	FileFoto    = "foto"
+	FileDokumen = "dokumen"
+
+	RoleNasabah    = "nasabah"
+	RoleTeller     = "teller"
+	RoleSupervisor = "supervisor"
+	RoleAdmin      = "admin"
+	// RoleSistem attributes actions of scheduled jobs.
+	RoleSistem = "sistem"
+)
+
 type RequestRegistrasiNasabah struct {
 	NIK            string `json:"nik" db:"nik"`
 	Nama           string `json:"nama" db:"nama"`
@@ -27,6 +109,282 @@ type Nasabah struct {
 	DokumenID string `json:"dokumen_id" db:"dokumen_id"`
 }
 
+type VersiNasabah struct {
+	Versi        int    `json:"versi" db:"versi"`
+	BerlakuSejak string `json:"berlaku_sejak" db:"berlaku_sejak"`
+	Nasabah
+}
+
+type FileNasabah struct {
+	FileID      string `json:"file_id" db:"file_id"`
+	Jenis       string `json:"jenis" db:"jenis"`
+	WaktuUnggah string `json:"waktu_unggah" db:"waktu_unggah"`
+	Aktif       bool   `json:"aktif" db:"-"`
+}
+
+type KYC struct {
+	NIK       string `json:"nik" db:"-"`
+	Status    string `json:"status" db:"status"`
+	DokumenID string `json:"dokumen_id" db:"dokumen_id"`
+	Alasan    string `json:"alasan" db:"alasan"`
+	Petugas   string `json:"petugas" db:"petugas"`
+	Waktu     string `json:"waktu" db:"waktu"`
+}
+
+type RequestReviewKYC struct {
+	DokumenID string `json:"dokumen_id"`
+	Alasan    string `json:"alasan"`
+}
+
+// Duplikat flags two nasabah that may be the same person. Jenis names the
+// signal that matched and Skor how strongly, from 0 to 1.
+type Duplikat struct {
+	DuplikatID   string  `json:"duplikat_id" db:"duplikat_id"`
+	NIK          string  `json:"nik" db:"-"`
+	NIKLain      string  `json:"nik_lain" db:"-"`
+	Jenis        string  `json:"jenis" db:"jenis"`
+	Skor         float64 `json:"skor" db:"skor"`
+	Status       string  `json:"status" db:"status"`
+	Petugas      string  `json:"petugas" db:"petugas"`
+	Catatan      string  `json:"catatan" db:"catatan"`
+	WaktuDibuat  string  `json:"waktu_dibuat" db:"waktu_dibuat"`
+	WaktuDiputus string  `json:"waktu_diputus" db:"waktu_diputus"`
+}
+
+type RequestKeputusanDuplikat struct {
+	Catatan string `json:"catatan"`
+}
+
+// HasilScreening is a watchlist hit on a nasabah. Tindakan records whether the
+// hit froze the nasabah (blokir) or only queued it for review.
+type HasilScreening struct {
+	ScreeningID  string  `json:"screening_id" db:"screening_id"`
+	NIK          string  `json:"nik" db:"-"`
+	Nama         string  `json:"nama" db:"-"`
+	EntriID      string  `json:"entri_id" db:"entri_id"`
+	NamaEntri    string  `json:"nama_entri" db:"nama_entri"`
+	Sumber       string  `json:"sumber" db:"sumber"`
+	Skor         float64 `json:"skor" db:"skor"`
+	Tindakan     string  `json:"tindakan" db:"tindakan"`
+	Pemicu       string  `json:"pemicu" db:"pemicu"`
+	VersiDaftar  string  `json:"versi_daftar" db:"versi_daftar"`
+	Status       string  `json:"status" db:"status"`
+	Petugas      string  `json:"petugas" db:"petugas"`
+	Catatan      string  `json:"catatan" db:"catatan"`
+	WaktuDibuat  string  `json:"waktu_dibuat" db:"waktu_dibuat"`
+	WaktuDiputus string  `json:"waktu_diputus" db:"waktu_diputus"`
+}
+
+type RequestKeputusanScreening struct {
+	Catatan string `json:"catatan"`
+}
+
+// AturanPemantauan configures one transaction monitoring rule. Which of
+// Ambang, Jumlah, Jendela and Toleransi are used depends on Jenis.
+type AturanPemantauan struct {
+	AturanID string `json:"aturan_id" db:"aturan_id"`
+	Jenis    string `json:"jenis" db:"jenis"`
+	Aktif    bool   `json:"aktif" db:"aktif"`
+	Severity string `json:"severity" db:"severity"`
+	// Ambang is a nominal threshold in rupiah.
+	Ambang float64 `json:"ambang" db:"ambang"`
+	// Jumlah is how many transactions make up the pattern.
+	Jumlah int `json:"jumlah" db:"jumlah"`
+	// Jendela is the period looked back over, as a Go duration such as "24h".
+	Jendela string `json:"jendela" db:"jendela"`
+	// Toleransi is how far below Ambang, as a fraction of it, a transaction
+	// still counts as structuring.
+	Toleransi   float64 `json:"toleransi" db:"toleransi"`
+	Petugas     string  `json:"petugas" db:"petugas"`
+	WaktuDiubah string  `json:"waktu_diubah" db:"waktu_diubah"`
+}
+
+type RequestAturanPemantauan struct {
+	Aktif     bool    `json:"aktif"`
+	Severity  string  `json:"severity"`
+	Ambang    float64 `json:"ambang"`
+	Jumlah    int     `json:"jumlah"`
+	Jendela   string  `json:"jendela"`
+	Toleransi float64 `json:"toleransi"`
+}
+
+// AlertTransaksi is raised when a transaction trips a monitoring rule.
+// TransaksiTerkait lists every transaction that forms the pattern, including
+// TransaksiID itself.
+type AlertTransaksi struct {
+	AlertID          string   `json:"alert_id" db:"alert_id"`
+	NIK              string   `json:"nik" db:"-"`
+	NoRekening       string   `json:"no_rekening" db:"no_rekening"`
+	TransaksiID      string   `json:"transaksi_id" db:"transaksi_id"`
+	TransaksiTerkait []string `json:"transaksi_terkait" db:"-"`
+	AturanID         string   `json:"aturan_id" db:"aturan_id"`
+	Jenis            string   `json:"jenis" db:"jenis"`
+	Severity         string   `json:"severity" db:"severity"`
+	Keterangan       string   `json:"keterangan" db:"keterangan"`
+	Status           string   `json:"status" db:"status"`
+	Petugas          string   `json:"petugas" db:"petugas"`
+	Catatan          string   `json:"catatan" db:"catatan"`
+	WaktuDibuat      string   `json:"waktu_dibuat" db:"waktu_dibuat"`
+	WaktuDiputus     string   `json:"waktu_diputus" db:"waktu_diputus"`
+}
+
+type FilterAlert struct {
+	Status   string
+	Severity string
+	AturanID string
+	NIK      string
+}
+
+type RequestKeputusanAlert struct {
+	Catatan string `json:"catatan"`
+}
+
+// LaporanTunai is one generated cash transaction report covering the days
+// from PeriodeDari to PeriodeSampai inclusive. Generating a period again
+// produces a new Versi and supersedes earlier versions not yet submitted.
+type LaporanTunai struct {
+	LaporanID     string  `json:"laporan_id" db:"laporan_id"`
+	PeriodeDari   string  `json:"periode_dari" db:"periode_dari"`
+	PeriodeSampai string  `json:"periode_sampai" db:"periode_sampai"`
+	Versi         int     `json:"versi" db:"versi"`
+	Ambang        float64 `json:"ambang" db:"ambang"`
+	JumlahBaris   int     `json:"jumlah_baris" db:"jumlah_baris"`
+	TotalNominal  float64 `json:"total_nominal" db:"total_nominal"`
+	FileID        string  `json:"file_id" db:"file_id"`
+	Checksum      string  `json:"checksum" db:"checksum"`
+	Status        string  `json:"status" db:"status"`
+	// Referensi is the receipt number given by the regulator on submission.
+	Referensi   string `json:"referensi" db:"referensi"`
+	Catatan     string `json:"catatan" db:"catatan"`
+	Pembuat     string `json:"pembuat" db:"pembuat"`
+	Petugas     string `json:"petugas" db:"petugas"`
+	WaktuDibuat string `json:"waktu_dibuat" db:"waktu_dibuat"`
+	WaktuDiubah string `json:"waktu_diubah" db:"waktu_diubah"`
+}
+
+// TransaksiTunaiHarian is one nasabah's cash transactions on one day.
+type TransaksiTunaiHarian struct {
+	Tanggal         string   `json:"tanggal" db:"tanggal"`
+	NIK             string   `json:"nik" db:"nik"`
+	Nama            string   `json:"nama" db:"-"`
+	TanggalLahir    string   `json:"tanggal_lahir" db:"-"`
+	AlamatKTP       string   `json:"alamat_ktp" db:"-"`
+	NoRekening      []string `json:"no_rekening" db:"-"`
+	JumlahTransaksi int      `json:"jumlah_transaksi" db:"jumlah_transaksi"`
+	TotalSetor      float64  `json:"total_setor" db:"total_setor"`
+	TotalTarik      float64  `json:"total_tarik" db:"total_tarik"`
+	Total           float64  `json:"total" db:"total"`
+}
+
+type RequestLaporanTunai struct {
+	Dari   string `json:"dari"`
+	Sampai string `json:"sampai"`
+}
+
+type RequestStatusLaporan struct {
+	Referensi string `json:"referensi"`
+	Catatan   string `json:"catatan"`
+}
+
+// Outbox is a domain event written in the same transaction as the change it
+// describes. WaktuTerkirim stays empty until every sink has accepted it.
+type Outbox struct {
+	EventID       string `json:"event_id" db:"event_id"`
+	Jenis         string `json:"jenis" db:"jenis"`
+	AggregateID   string `json:"aggregate_id" db:"aggregate_id"`
+	Payload       string `json:"payload" db:"payload"`
+	WaktuDibuat   string `json:"waktu_dibuat" db:"waktu_dibuat"`
+	WaktuTerkirim string `json:"waktu_terkirim" db:"waktu_terkirim"`
+	Percobaan     int    `json:"percobaan" db:"percobaan"`
+	ErrorTerakhir string `json:"error_terakhir" db:"error_terakhir"`
+}
+
+// LanggananWebhook is a partner's subscription to account events. An empty
+// NoRekening subscribes to every rekening. Rahasia signs deliveries and is
+// only returned when it is set.
+type LanggananWebhook struct {
+	LanggananID string   `json:"langganan_id" db:"langganan_id"`
+	Partner     string   `json:"partner" db:"partner"`
+	URL         string   `json:"url" db:"url"`
+	Rahasia     string   `json:"rahasia,omitempty" db:"-"`
+	JenisEvent  []string `json:"jenis_event" db:"-"`
+	NoRekening  []string `json:"no_rekening" db:"-"`
+	Aktif       bool     `json:"aktif" db:"aktif"`
+	Petugas     string   `json:"petugas" db:"petugas"`
+	WaktuDibuat string   `json:"waktu_dibuat" db:"waktu_dibuat"`
+	WaktuDiubah string   `json:"waktu_diubah" db:"waktu_diubah"`
+}
+
+// RequestLanggananWebhook creates or replaces a subscription. An empty
+// Rahasia generates a new secret on creation and keeps the current one on
+// update; Aktif defaults to true.
+type RequestLanggananWebhook struct {
+	Partner    string   `json:"partner"`
+	URL        string   `json:"url"`
+	Rahasia    string   `json:"rahasia"`
+	JenisEvent []string `json:"jenis_event"`
+	NoRekening []string `json:"no_rekening"`
+	Aktif      *bool    `json:"aktif"`
+}
+
+// PengirimanWebhook is one event to be delivered to one subscription.
+// Payload is the request body, fixed when the delivery is queued so that
+// retries and replays send the same bytes.
+type PengirimanWebhook struct {
+	PengirimanID    string `json:"pengiriman_id" db:"pengiriman_id"`
+	LanggananID     string `json:"langganan_id" db:"langganan_id"`
+	EventID         string `json:"event_id" db:"event_id"`
+	JenisEvent      string `json:"jenis_event" db:"jenis_event"`
+	Payload         string `json:"payload" db:"payload"`
+	Status          string `json:"status" db:"status"`
+	Percobaan       int    `json:"percobaan" db:"percobaan"`
+	KirimBerikutnya string `json:"kirim_berikutnya" db:"kirim_berikutnya"`
+	StatusHTTP      int    `json:"status_http" db:"status_http"`
+	ErrorTerakhir   string `json:"error_terakhir" db:"error_terakhir"`
+	WaktuDibuat     string `json:"waktu_dibuat" db:"waktu_dibuat"`
+	WaktuTerkirim   string `json:"waktu_terkirim" db:"waktu_terkirim"`
+}
+
+// PreferensiNotifikasi is how a nasabah wants to be notified. Kanal lists
+// the channels used, "email" and "sms", each needing its contact to be set.
+type PreferensiNotifikasi struct {
+	NIK             string   `json:"nik" db:"-"`
+	Bahasa          string   `json:"bahasa" db:"bahasa"`
+	Email           string   `json:"email" db:"-"`
+	NoHP            string   `json:"no_hp" db:"-"`
+	Kanal           []string `json:"kanal" db:"-"`
+	Transaksi       bool     `json:"transaksi" db:"transaksi"`
+	PerubahanProfil bool     `json:"perubahan_profil" db:"perubahan_profil"`
+	WaktuDiubah     string   `json:"waktu_diubah" db:"waktu_diubah"`
+}
+
+// RequestPreferensiNotifikasi replaces the preferences; unset flags keep
+// their current value.
+type RequestPreferensiNotifikasi struct {
+	Bahasa          string   `json:"bahasa"`
+	Email           string   `json:"email"`
+	NoHP            string   `json:"no_hp"`
+	Kanal           []string `json:"kanal"`
+	Transaksi       *bool    `json:"transaksi"`
+	PerubahanProfil *bool    `json:"perubahan_profil"`
+}
+
+// Notifikasi is one rendered message to a nasabah on one channel.
+type Notifikasi struct {
+	NotifikasiID    string `json:"notifikasi_id" db:"notifikasi_id"`
+	Jenis           string `json:"jenis" db:"jenis"`
+	Kanal           string `json:"kanal" db:"kanal"`
+	Tujuan          string `json:"tujuan" db:"-"`
+	Subjek          string `json:"subjek" db:"subjek"`
+	Isi             string `json:"isi" db:"-"`
+	Status          string `json:"status" db:"status"`
+	Percobaan       int    `json:"percobaan" db:"percobaan"`
+	KirimBerikutnya string `json:"kirim_berikutnya" db:"kirim_berikutnya"`
+	ErrorTerakhir   string `json:"error_terakhir" db:"error_terakhir"`
+	WaktuDibuat     string `json:"waktu_dibuat" db:"waktu_dibuat"`
+	WaktuTerkirim   string `json:"waktu_terkirim" db:"waktu_terkirim"`
+}
+
 type Rekening struct {
 	NIK        string  `json:"nik" db:"nik"`
 	NoRekening string  `json:"no_rekening" db:"no_rekening"`
@@ -42,3 +400,79 @@ type Mutasi struct {
 	SaldoAwal   float64 `json:"saldo_awal" db:"saldo_awal"`
 	SaldoAkhir  float64 `json:"saldo_akhir" db:"saldo_akhir"`
 }
+
+// PembaruanSaldo is pushed on a nasabah's balance stream. Mutasi is empty in
+// the snapshot sent when the stream opens.
+type PembaruanSaldo struct {
+	NoRekening string  `json:"no_rekening"`
+	Saldo      float64 `json:"saldo"`
+	Mutasi     *Mutasi `json:"mutasi,omitempty"`
+}
+
+type Petugas struct {
+	ID   string `json:"id"`
+	Role string `json:"role"`
+}
+
+type RequestOperasi struct {
+	JenisOperasi   string  `json:"jenis_operasi"`
+	NIK            string  `json:"nik,omitempty"`
+	NoRekening     string  `json:"no_rekening,omitempty"`
+	Nominal        float64 `json:"nominal,omitempty"`
+	TransaksiID    string  `json:"transaksi_id,omitempty"`
+	Nama           string  `json:"nama,omitempty"`
+	AlamatKTP      string  `json:"alamat_ktp,omitempty"`
+	AlamatDomisili string  `json:"alamat_domisili,omitempty"`
+}
+
+type RequestKeputusanOperasi struct {
+	Remark string `json:"remark"`
+}
+
+type Operasi struct {
+	OperasiID    string `json:"operasi_id" db:"operasi_id"`
+	JenisOperasi string `json:"jenis_operasi" db:"jenis_operasi"`
+	Target       string `json:"target" db:"target"`
+	Payload      string `json:"payload" db:"payload"`
+	Status       string `json:"status" db:"status"`
+	Maker        string `json:"maker" db:"maker"`
+	Checker      string `json:"checker" db:"checker"`
+	Remark       string `json:"remark" db:"remark"`
+	WaktuDibuat  string `json:"waktu_dibuat" db:"waktu_dibuat"`
+	WaktuDiputus string `json:"waktu_diputus" db:"waktu_diputus"`
+}
+
+type RiwayatOperasi struct {
+	OperasiID string `json:"operasi_id" db:"operasi_id"`
+	Waktu     string `json:"waktu" db:"waktu"`
+	Status    string `json:"status" db:"status"`
+	Petugas   string `json:"petugas" db:"petugas"`
+	Remark    string `json:"remark" db:"remark"`
+}
+
+type MetadataRequest struct {
+	Aktor     string `json:"aktor" db:"aktor"`
+	Role      string `json:"role" db:"role"`
+	IP        string `json:"ip" db:"ip"`
+	UserAgent string `json:"user_agent" db:"user_agent"`
+	RequestID string `json:"request_id" db:"request_id"`
+}
+
+type Audit struct {
+	AuditID string `json:"audit_id" db:"audit_id"`
+	Waktu   string `json:"waktu" db:"waktu"`
+	MetadataRequest
+	Aksi      string `json:"aksi" db:"aksi"`
+	Target    string `json:"target" db:"target"`
+	Perubahan string `json:"perubahan" db:"perubahan"`
+}
+
+type FilterAudit struct {
+	Aktor  string
+	Aksi   string
+	Target string
+	Dari   string
+	Sampai string
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,89 @@
+// Package notification sends rendered messages to customers over email and
+// SMS. Channels only deliver; choosing the recipient, the language and when
+// to retry is left to the caller.
+package notification
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+)
+
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject,omitempty"`
+	Body    string `json:"body"`
+}
+
+type Channel interface {
+	Name() string
+	// Send returns once the message is accepted for delivery.
+	Send(msg Message) error
+}
+
+// SMSGateway is implemented by the SMS provider clients. Subjects are not
+// part of an SMS and are dropped by SMSChannel.
+type SMSGateway interface {
+	SendSMS(to, body string) error
+}
+
+// SMSChannel adapts an SMSGateway to a Channel.
+type SMSChannel struct {
+	gateway SMSGateway
+}
+
+func (c *SMSChannel) Name() string {
+	return ChannelSMS
+}
+
+func (c *SMSChannel) Send(msg Message) error {
+	return c.gateway.SendSMS(msg.To, msg.Body)
+}
+
+func NewSMSChannel(gateway SMSGateway) *SMSChannel {
+	return &SMSChannel{gateway: gateway}
+}
+
+// WriterChannel writes each message as one line of JSON instead of sending
+// it. It stands in for a real channel in development and tests.
+type WriterChannel struct {
+	name string
+	mu   sync.Mutex
+	w    io.Writer
+}
+
+func (c *WriterChannel) Name() string {
+	return c.name
+}
+
+func (c *WriterChannel) Send(msg Message) (err error) {
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err = c.w.Write(append(line, '\n'))
+	return
+}
+
+// SendSMS lets a WriterChannel act as a fake SMS gateway.
+func (c *WriterChannel) SendSMS(to, body string) error {
+	return c.Send(Message{To: to, Body: body})
+}
+
+// NewFileChannel appends the messages of channel name to path.
+func NewFileChannel(name, path string) (channel *WriterChannel, err error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0640)
+	if err != nil {
+		return
+	}
+	channel = &WriterChannel{name: name, w: f}
+	return
+}
@@ -0,0 +1,171 @@
+package notification
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const (
+	LanguageID = "id"
+	LanguageEN = "en"
+	// DefaultLanguage is used when a template has no translation in the
+	// customer's language.
+	DefaultLanguage = LanguageID
+)
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// labels names the nasabah fields a profile change notification can list.
+var labels = map[string]map[string]string{
+	LanguageID: {
+		"nama":            "nama",
+		"alamat_ktp":      "alamat KTP",
+		"alamat_domisili": "alamat domisili",
+		"email":           "email",
+		"no_hp":           "nomor HP",
+	},
+	LanguageEN: {
+		"nama":            "name",
+		"alamat_ktp":      "ID card address",
+		"alamat_domisili": "residential address",
+		"email":           "email",
+		"no_hp":           "phone number",
+	},
+}
+
+// Templates holds one text template per name, language and channel, read
+// from files named <name>.<language>.<channel>.tmpl. An email template
+// starts with a "Subject: " line followed by a blank line; everything after
+// is the body.
+type Templates struct {
+	templates map[string]*template.Template
+}
+
+// LoadTemplates reads the built-in templates, then the ones in dir, which
+// replace built-ins of the same file name. An empty dir loads the built-ins
+// only.
+func LoadTemplates(dir string) (t *Templates, err error) {
+	t = &Templates{templates: make(map[string]*template.Template)}
+	if err = t.load(defaultTemplates, "templates"); err != nil {
+		return
+	}
+	if dir != "" {
+		err = t.load(os.DirFS(dir), ".")
+	}
+	return
+}
+
+func (t *Templates) load(fsys fs.FS, dir string) (err error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.tmpl"))
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		name := path.Base(file)
+		parts := strings.Split(strings.TrimSuffix(name, ".tmpl"), ".")
+		if len(parts) != 3 {
+			return fmt.Errorf("template %s: name must be <name>.<language>.<channel>.tmpl", name)
+		}
+		var text []byte
+		if text, err = fs.ReadFile(fsys, file); err != nil {
+			return
+		}
+		var tmpl *template.Template
+		tmpl, err = template.New(name).Funcs(funcs(parts[1])).Parse(string(text))
+		if err != nil {
+			return fmt.Errorf("template %s: %w", name, err)
+		}
+		t.templates[name] = tmpl
+	}
+	return
+}
+
+// Render fills the template for name in language and channel with data,
+// falling back to DefaultLanguage.
+func (t *Templates) Render(name, language, channel string, data interface{}) (msg Message, err error) {
+	tmpl, ok := t.templates[fmt.Sprintf("%s.%s.%s.tmpl", name, language, channel)]
+	if !ok {
+		tmpl, ok = t.templates[fmt.Sprintf("%s.%s.%s.tmpl", name, DefaultLanguage, channel)]
+	}
+	if !ok {
+		return msg, fmt.Errorf("no %s template %s", channel, name)
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, data); err != nil {
+		return
+	}
+	text := buf.String()
+	if strings.HasPrefix(text, "Subject: ") {
+		subject, body, _ := strings.Cut(text, "\n")
+		msg.Subject = strings.TrimSpace(strings.TrimPrefix(subject, "Subject: "))
+		text = strings.TrimLeft(body, "\n")
+	}
+	msg.Body = strings.TrimSpace(text)
+	return
+}
+
+func funcs(language string) template.FuncMap {
+	label := func(field string) string {
+		if l, ok := labels[language][field]; ok {
+			return l
+		}
+		if l, ok := labels[DefaultLanguage][field]; ok {
+			return l
+		}
+		return field
+	}
+	return template.FuncMap{
+		"label": label,
+		"labels": func(fields []string) string {
+			names := make([]string, len(fields))
+			for i, field := range fields {
+				names[i] = label(field)
+			}
+			return strings.Join(names, ", ")
+		},
+		"mask": func(noRekening string) string {
+			if len(noRekening) <= 4 {
+				return noRekening
+			}
+			return strings.Repeat("*", len(noRekening)-4) + noRekening[len(noRekening)-4:]
+		},
+		"rupiah": func(nominal float64) string {
+			if language == LanguageEN {
+				return "IDR " + groupDigits(nominal, ",", ".")
+			}
+			return "Rp" + groupDigits(nominal, ".", ",")
+		},
+	}
+}
+
+// groupDigits formats nominal with thousands separators and, only when it
+// has cents, two decimals.
+func groupDigits(nominal float64, thousands, decimal string) string {
+	sign := ""
+	if nominal < 0 {
+		sign = "-"
+		nominal = -nominal
+	}
+	cents := int64(math.Round(nominal * 100))
+	digits := strconv.FormatInt(cents/100, 10)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(thousands)
+		}
+		b.WriteRune(d)
+	}
+	if cents%100 != 0 {
+		fmt.Fprintf(&b, "%s%02d", decimal, cents%100)
+	}
+	return sign + b.String()
+}
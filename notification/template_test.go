@@ -0,0 +1,125 @@
+package notification
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// dataUji has the fields the app passes to the templates.
+type dataUji struct {
+	Nama       string
+	NoRekening string
+	Nominal    float64
+	Saldo      float64
+	Waktu      string
+	Kolom      []string
+}
+
+// TestTemplateBawaan renders every built-in template and compares it with
+// testdata/<name>.<language>.<channel>.golden, or rewrites it with -update.
+func TestTemplateBawaan(t *testing.T) {
+	templates, err := LoadTemplates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := dataUji{Nama: "Budi Santoso", NoRekening: "0012345678", Nominal: 1250000.5, Saldo: 10000000,
+		Waktu: "01-03-2024 12:00", Kolom: []string{"alamat_ktp", "no_hp"}}
+	for _, name := range []string{"setor_dana", "tarik_dana", "perubahan_profil"} {
+		for _, language := range []string{LanguageID, LanguageEN} {
+			for _, channel := range []string{ChannelEmail, ChannelSMS} {
+				msg, err := templates.Render(name, language, channel, data)
+				if err != nil {
+					t.Errorf("%s %s %s: %v", name, language, channel, err)
+					continue
+				}
+				if (msg.Subject != "") != (channel == ChannelEmail) {
+					t.Errorf("%s %s %s: subject %q", name, language, channel, msg.Subject)
+				}
+				got := []byte(msg.Body + "\n")
+				if msg.Subject != "" {
+					got = []byte(fmt.Sprintf("Subject: %s\n\n%s", msg.Subject, got))
+				}
+				path := filepath.Join("testdata", fmt.Sprintf("%s.%s.%s.golden", name, language, channel))
+				if *update {
+					if err := os.WriteFile(path, got, 0o644); err != nil {
+						t.Fatal(err)
+					}
+				}
+				want, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("%s differs; got:\n%s", path, got)
+				}
+			}
+		}
+	}
+}
+
+// TestTemplateFallback checks that a language without a translation falls
+// back to DefaultLanguage and that templates in dir replace the built-ins.
+func TestTemplateFallback(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "setor_dana.en.sms.tmpl"), []byte("Deposit {{rupiah .Nominal}}\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := dataUji{NoRekening: "0012345678", Nominal: 1500}
+	cases := []struct {
+		name, language, channel, body string
+	}{
+		{"setor_dana", LanguageEN, ChannelSMS, "Deposit IDR 1,500"},
+		{"setor_dana", "fr", ChannelSMS, "Setoran Rp1.500 ke rek ******5678 pada . Saldo Rp0."},
+	}
+	for _, c := range cases {
+		msg, err := templates.Render(c.name, c.language, c.channel, data)
+		if err != nil || msg.Body != c.body {
+			t.Errorf("Render(%s, %s, %s) = %q, %v; want %q", c.name, c.language, c.channel, msg.Body, err, c.body)
+		}
+	}
+	if _, err = templates.Render("tidak_ada", LanguageID, ChannelSMS, data); err == nil {
+		t.Error("rendering an unknown template succeeded")
+	}
+
+	if err = os.WriteFile(filepath.Join(dir, "salah.tmpl"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadTemplates(dir); err == nil {
+		t.Error("a template named without language and channel was loaded")
+	}
+}
+
+func TestGroupDigits(t *testing.T) {
+	cases := []struct {
+		nominal float64
+		id, en  string
+	}{
+		{0, "0", "0"},
+		{999, "999", "999"},
+		{1000, "1.000", "1,000"},
+		{1250000.5, "1.250.000,50", "1,250,000.50"},
+		{100000000, "100.000.000", "100,000,000"},
+		{0.005, "0,01", "0.01"},
+		{999.999, "1.000", "1,000"},
+		{-2500.25, "-2.500,25", "-2,500.25"},
+	}
+	for _, c := range cases {
+		if got := groupDigits(c.nominal, ".", ","); got != c.id {
+			t.Errorf("groupDigits(%v) in id = %q, want %q", c.nominal, got, c.id)
+		}
+		if got := groupDigits(c.nominal, ",", "."); got != c.en {
+			t.Errorf("groupDigits(%v) in en = %q, want %q", c.nominal, got, c.en)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"tabungan-api/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+// kolomAudit lists the audit columns that may carry PII. aktor and target are
+// often NIKs and perubahan holds the changed profile fields, so all three are
+// stored as ciphertext and filtered through their blind indexes.
+var kolomAudit = []string{"aktor", "target", "perubahan"}
+
+type auditRow struct {
+	Aktor       string `db:"aktor"`
+	Target      string `db:"target"`
+	Perubahan   string `db:"perubahan"`
+	AktorIndex  string `db:"aktor_index"`
+	TargetIndex string `db:"target_index"`
+	KunciID     string `db:"kunci_id"`
+	KunciData   string `db:"kunci_data"`
+	models.Audit
+}
+
+func (r *auditRow) kolom() map[string]*string {
+	return map[string]*string{
+		"aktor":     &r.Aktor,
+		"target":    &r.Target,
+		"perubahan": &r.Perubahan,
+	}
+}
+
+func (t *TabunganRepo) enkripsiAudit(audit models.Audit) (row auditRow, err error) {
+	row = auditRow{
+		Aktor:       audit.Aktor,
+		Target:      audit.Target,
+		Perubahan:   audit.Perubahan,
+		AktorIndex:  t.cipher.BlindIndex(audit.Aktor),
+		TargetIndex: t.cipher.BlindIndex(audit.Target),
+		Audit:       audit,
+	}
+	dek, err := t.cipher.NewDataKey()
+	if err != nil {
+		return
+	}
+	for _, nama := range kolomAudit {
+		kolom := row.kolom()[nama]
+		if *kolom, err = dek.Encrypt(nama, *kolom); err != nil {
+			return
+		}
+	}
+	row.KunciID = dek.KeyID
+	row.KunciData = dek.Wrapped
+	return
+}
+
+func (t *TabunganRepo) dekripsiAudit(row auditRow) (audit models.Audit, err error) {
+	dek, err := t.cipher.OpenDataKey(row.KunciID, row.KunciData)
+	if err != nil {
+		return
+	}
+	for _, nama := range kolomAudit {
+		kolom := row.kolom()[nama]
+		if *kolom, err = dek.Decrypt(nama, *kolom); err != nil {
+			return
+		}
+	}
+	audit = row.Audit
+	audit.Aktor = row.Aktor
+	audit.Target = row.Target
+	audit.Perubahan = row.Perubahan
+	return
+}
+
+func (t *TabunganRepo) InsertAudit(ctx context.Context, audit models.Audit) (err error) {
+	row, err := t.enkripsiAudit(audit)
+	if err == nil {
+		SQL := `INSERT INTO audit (audit_id, waktu, aktor, role, ip, user_agent, request_id, aksi, target, perubahan,
+			aktor_index, target_index, kunci_id, kunci_data)
+			VALUES (:audit_id, :waktu, :aktor, :role, :ip, :user_agent, :request_id, :aksi, :target, :perubahan,
+			:aktor_index, :target_index, :kunci_id, :kunci_data)`
+		_, err = t.db.NamedExecContext(ctx, SQL, row)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"audit_id": audit.AuditID,
+			"aktor":    audit.Aktor,
+			"aksi":     audit.Aksi,
+			"target":   audit.Target,
+			"error":    err.Error(),
+		}).Error("insert audit error")
+	}
+	return
+}
+
+// GetDaftarAudit treats empty filter fields as "any". Dari and Sampai are
+// compared as strings against waktu, so both must use the same layout.
+func (t *TabunganRepo) GetDaftarAudit(ctx context.Context, filter models.FilterAudit) (audit []models.Audit, err error) {
+	var aktorIndex, targetIndex string
+	if filter.Aktor != "" {
+		aktorIndex = t.cipher.BlindIndex(filter.Aktor)
+	}
+	if filter.Target != "" {
+		targetIndex = t.cipher.BlindIndex(filter.Target)
+	}
+	var rows []auditRow
+	SQL := `SELECT * FROM audit
+		WHERE ($1 = '' OR aktor_index = $1)
+		AND ($2 = '' OR aksi = $2)
+		AND ($3 = '' OR target_index = $3)
+		AND ($4 = '' OR waktu >= $4)
+		AND ($5 = '' OR waktu < $5)
+		ORDER BY waktu DESC, rowid DESC LIMIT $6 OFFSET $7`
+	err = t.db.SelectContext(ctx, &rows, SQL, aktorIndex, filter.Aksi, targetIndex, filter.Dari, filter.Sampai, filter.Limit, filter.Offset)
+	for _, row := range rows {
+		var a models.Audit
+		if a, err = t.dekripsiAudit(row); err != nil {
+			break
+		}
+		audit = append(audit, a)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"aktor":  filter.Aktor,
+			"aksi":   filter.Aksi,
+			"target": filter.Target,
+			"dari":   filter.Dari,
+			"sampai": filter.Sampai,
+			"error":  err.Error(),
+		}).Error("query audit error")
+	}
+	return
+}
+
+// migrateAudit encrypts audit rows written before the PII columns were
+// encrypted. The append-only triggers are dropped and recreated in the same
+// transaction; the new update trigger leaves kunci_id and kunci_data writable
+// so RotasiKunci can rewrap the data key of a row without touching its content.
+func (t *TabunganRepo) migrateAudit() {
+	t.tambahKolom("audit", "aktor_index", "target_index", "kunci_id", "kunci_data")
+	t.db.MustExec("CREATE INDEX IF NOT EXISTS audit_aktor_index ON audit (aktor_index)")
+	t.db.MustExec("CREATE INDEX IF NOT EXISTS audit_target_index ON audit (target_index)")
+
+	var rows []auditRow
+	SQL := `SELECT audit_id, waktu, COALESCE(aktor, '') AS aktor, role, ip, user_agent, request_id, aksi,
+		COALESCE(target, '') AS target, COALESCE(perubahan, '') AS perubahan
+		FROM audit WHERE kunci_id IS NULL`
+	if err := t.db.Select(&rows, SQL); err != nil {
+		panic(err)
+	}
+	tx := t.db.MustBegin()
+	if err := t.enkripsiAuditLama(tx, rows); err != nil {
+		tx.Rollback()
+		panic(err)
+	}
+	if err := tx.Commit(); err != nil {
+		panic(err)
+	}
+}
+
+func (t *TabunganRepo) enkripsiAuditLama(tx *sqlx.Tx, rows []auditRow) (err error) {
+	for _, SQL := range []string{"DROP TRIGGER IF EXISTS audit_no_update", "DROP TRIGGER IF EXISTS audit_no_delete"} {
+		if _, err = tx.Exec(SQL); err != nil {
+			return
+		}
+	}
+	UPDATE := `UPDATE audit SET aktor = :aktor, target = :target, perubahan = :perubahan, aktor_index = :aktor_index,
+		target_index = :target_index, kunci_id = :kunci_id, kunci_data = :kunci_data WHERE audit_id = :audit_id`
+	for _, row := range rows {
+		row.Audit.Aktor = row.Aktor
+		row.Audit.Target = row.Target
+		row.Audit.Perubahan = row.Perubahan
+		if row, err = t.enkripsiAudit(row.Audit); err != nil {
+			return
+		}
+		if _, err = tx.NamedExec(UPDATE, row); err != nil {
+			return
+		}
+	}
+	SQL := `CREATE TRIGGER audit_no_update BEFORE UPDATE OF audit_id, waktu, aktor, role, ip, user_agent,
+		request_id, aksi, target, perubahan, aktor_index, target_index ON audit
+		BEGIN SELECT RAISE(ABORT, 'audit is append-only'); END;`
+	if _, err = tx.Exec(SQL); err != nil {
+		return
+	}
+	SQL = `CREATE TRIGGER audit_no_delete BEFORE DELETE ON audit
+		BEGIN SELECT RAISE(ABORT, 'audit is append-only'); END;`
+	_, err = tx.Exec(SQL)
+	return
+}
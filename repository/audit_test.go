@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"tabungan-api/models"
+	"testing"
+)
+
+// TestAuditAppendOnly checks that recorded audit entries can neither be
+// changed nor removed, while their data key can still be rewrapped.
+func TestAuditAppendOnly(t *testing.T) {
+	repo, _ := repoUji(t, t.TempDir())
+	ctx := context.Background()
+	audit := models.Audit{AuditID: "audit-1", Waktu: "2024-03-01 12:00:00.000000",
+		MetadataRequest: models.MetadataRequest{Aktor: nikUji, Role: models.RoleNasabah, IP: "10.0.0.1",
+			UserAgent: "uji", RequestID: "req-1"},
+		Aksi: "update_nasabah", Target: nikUji, Perubahan: `{"nama":"Budi Santoso"}`}
+	if err := repo.InsertAudit(ctx, audit); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, SQL := range []string{
+		"UPDATE audit SET audit_id = 'audit-2'",
+		"UPDATE audit SET waktu = '2024-01-01 00:00:00.000000'",
+		"UPDATE audit SET aktor = ''",
+		"UPDATE audit SET role = 'admin'",
+		"UPDATE audit SET ip = ''",
+		"UPDATE audit SET user_agent = ''",
+		"UPDATE audit SET request_id = ''",
+		"UPDATE audit SET aksi = 'setor_dana'",
+		"UPDATE audit SET target = ''",
+		"UPDATE audit SET perubahan = ''",
+		"UPDATE audit SET aktor_index = ''",
+		"UPDATE audit SET target_index = ''",
+		"DELETE FROM audit WHERE audit_id = 'audit-1'",
+		"DELETE FROM audit",
+	} {
+		if _, err := repo.db.Exec(SQL); err == nil {
+			t.Errorf("%s was allowed on the audit trail", SQL)
+		}
+	}
+	// RotasiKunci rewraps the data key of audit rows in place.
+	if _, err := repo.db.Exec("UPDATE audit SET kunci_data = kunci_data"); err != nil {
+		t.Errorf("rewrapping the data key of an audit row: %v", err)
+	}
+
+	daftar, err := repo.GetDaftarAudit(ctx, models.FilterAudit{Target: nikUji, Limit: 10})
+	if err != nil || len(daftar) != 1 || !reflect.DeepEqual(daftar[0], audit) {
+		t.Errorf("GetDaftarAudit = %+v, %v; want %+v unchanged", daftar, err, audit)
+	}
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"runtime"
+	"strings"
+	"tabungan-api/metrics"
+	"tabungan-api/tracing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var metrikDurasiQuery = metrics.NewHistogram("tabungan_db_query_duration_seconds",
+	"Time to run SQL statements, by the repository method that ran them.", metrics.DefaultBuckets, "metode")
+
+var tracer = otel.Tracer("tabungan-api/repository")
+
+// prefixMetode is how runtime names the methods of TabunganRepo.
+var prefixMetode = reflect.TypeOf(TabunganRepo{}).PkgPath() + ".(*TabunganRepo)."
+
+// metodeRepo names the TabunganRepo method on the call stack, or "lainnya"
+// for statements run from elsewhere.
+func metodeRepo() string {
+	pc := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pc[:runtime.Callers(3, pc)])
+	for {
+		frame, lagi := frames.Next()
+		if nama := strings.TrimPrefix(frame.Function, prefixMetode); nama != frame.Function {
+			// Closures are named Method.func1.
+			if i := strings.Index(nama, "."); i >= 0 {
+				nama = nama[:i]
+			}
+			return nama
+		}
+		if !lagi {
+			return "lainnya"
+		}
+	}
+}
+
+// mulaiQuery starts timing a statement and, when ctx is traced, the span of
+// the statement. selesai records both once the statement returned err.
+func mulaiQuery(ctx context.Context, query string) (ctxQuery context.Context, selesai func(err error)) {
+	metode := metodeRepo()
+	mulai := time.Now()
+	ctxQuery = ctx
+	var span trace.Span
+	// Statements outside a traced operation, such as migrations, would each
+	// start a trace of their own.
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		ctxQuery, span = tracer.Start(ctx, "sql."+metode,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.String("db.system", "sqlite"), attribute.String("db.statement", query)))
+	}
+	selesai = func(err error) {
+		metrikDurasiQuery.Observe(time.Since(mulai).Seconds(), metode)
+		if span != nil {
+			tracing.End(span, err)
+		}
+	}
+	return
+}
+
+// connectorUkur opens connections of the wrapped driver that time and trace
+// every statement. A query is timed until its first rows are ready, not until
+// they are all read.
+type connectorUkur struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c connectorUkur) Connect(context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &connUkur{conn}, nil
+}
+
+func (c connectorUkur) Driver() driver.Driver {
+	return c.driver
+}
+
+type connUkur struct {
+	driver.Conn
+}
+
+func (c *connUkur) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, selesai := mulaiQuery(ctx, query)
+	result, err := execer.ExecContext(ctx, query, args)
+	selesai(err)
+	return result, err
+}
+
+func (c *connUkur) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, selesai := mulaiQuery(ctx, query)
+	rows, err := queryer.QueryContext(ctx, query, args)
+	selesai(err)
+	return rows, err
+}
+
+func (c *connUkur) PrepareContext(ctx context.Context, query string) (stmt driver.Stmt, err error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stmtUkur{Stmt: stmt, query: query}, nil
+}
+
+func (c *connUkur) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *connUkur) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *connUkur) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *connUkur) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *connUkur) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *connUkur) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+type stmtUkur struct {
+	driver.Stmt
+	query string
+}
+
+func (s *stmtUkur) ExecContext(ctx context.Context, args []driver.NamedValue) (result driver.Result, err error) {
+	ctx, selesai := mulaiQuery(ctx, s.query)
+	defer func() { selesai(err) }()
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return execer.ExecContext(ctx, args)
+	}
+	values, err := nilaiArgumen(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Exec(values)
+}
+
+func (s *stmtUkur) QueryContext(ctx context.Context, args []driver.NamedValue) (rows driver.Rows, err error) {
+	ctx, selesai := mulaiQuery(ctx, s.query)
+	defer func() { selesai(err) }()
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return queryer.QueryContext(ctx, args)
+	}
+	values, err := nilaiArgumen(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Query(values)
+}
+
+func nilaiArgumen(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, driver.ErrSkip
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
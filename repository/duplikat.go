@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"context"
+	"tabungan-api/imaging"
+	"tabungan-api/models"
+	"tabungan-api/similarity"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+type duplikatRow struct {
+	NIKIndex     string `db:"nik_index"`
+	NIKIndexLain string `db:"nik_index_lain"`
+	models.Duplikat
+}
+
+// indeksTanggalLahir is the blind index duplicate detection blocks on. It is
+// domain-separated from nik_index so the two cannot be correlated.
+func (t *TabunganRepo) indeksTanggalLahir(tanggalLahir string) string {
+	return t.cipher.BlindIndex("tanggal_lahir:" + tanggalLahir)
+}
+
+// indeksAlamat is the blind index of an address blocking key, as given by
+// similarity.AddressKeys.
+func (t *TabunganRepo) indeksAlamat(kunci string) string {
+	return t.cipher.BlindIndex("alamat:" + kunci)
+}
+
+// versiSidik changes whenever setSidik starts recording something new, so
+// that migrateSidik fingerprints existing nasabah again.
+const versiSidik = "2"
+
+func (t *TabunganRepo) setSidik(ctx context.Context, tx *sqlx.Tx, nasabah models.Nasabah) (err error) {
+	SQL := `INSERT INTO sidik_nasabah (nik_index, tanggal_lahir_index, foto_hash, versi) VALUES ($1, $2, '', $3)
+		ON CONFLICT (nik_index) DO UPDATE SET tanggal_lahir_index = excluded.tanggal_lahir_index, versi = excluded.versi`
+	_, err = tx.ExecContext(ctx, SQL, t.cipher.BlindIndex(nasabah.NIK), t.indeksTanggalLahir(nasabah.TanggalLahir), versiSidik)
+	if err == nil {
+		err = t.setBlokAlamat(ctx, tx, nasabah.NIK, nasabah.AlamatKTP, nasabah.AlamatDomisili)
+	}
+	return
+}
+
+// setBlokAlamat replaces the address blocking keys of nik with those of
+// alamat.
+func (t *TabunganRepo) setBlokAlamat(ctx context.Context, tx *sqlx.Tx, nik string, alamat ...string) (err error) {
+	nikIndex := t.cipher.BlindIndex(nik)
+	if _, err = tx.ExecContext(ctx, "DELETE FROM blok_alamat WHERE nik_index = $1", nikIndex); err != nil {
+		return
+	}
+	SQL := "INSERT OR IGNORE INTO blok_alamat (kunci_index, nik_index) VALUES ($1, $2)"
+	for _, a := range alamat {
+		for _, kunci := range similarity.AddressKeys(a) {
+			if _, err = tx.ExecContext(ctx, SQL, t.indeksAlamat(kunci), nikIndex); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// migrateSidik fingerprints nasabah registered before duplicate detection,
+// or before the current versiSidik.
+func (t *TabunganRepo) migrateSidik() {
+	var rows []nasabahRow
+	SQL := `SELECT * FROM nasabah WHERE nik_index NOT IN (SELECT nik_index FROM sidik_nasabah WHERE versi = $1)`
+	err := t.db.Select(&rows, SQL, versiSidik)
+	if err != nil {
+		panic(err)
+	}
+	if len(rows) == 0 {
+		return
+	}
+	tx := t.db.MustBegin()
+	for _, row := range rows {
+		var nasabah models.Nasabah
+		if nasabah, err = t.dekripsiNasabah(row); err != nil {
+			break
+		}
+		if err = t.setSidik(context.Background(), tx, nasabah); err != nil {
+			break
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		panic(err)
+	}
+	if err = tx.Commit(); err != nil {
+		panic(err)
+	}
+}
+
+func (t *TabunganRepo) UpdateHashFoto(ctx context.Context, nik, hash string) (err error) {
+	SQL := "UPDATE sidik_nasabah SET foto_hash = $1 WHERE nik_index = $2"
+	_, err = t.db.ExecContext(ctx, SQL, hash, t.cipher.BlindIndex(nik))
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":   nik,
+			"error": err.Error(),
+		}).Error("update hash foto error")
+	}
+	return
+}
+
+// GetKandidatDuplikat returns the other nasabah born on the same day as
+// nasabah or sharing an address blocking key with them. Only these are
+// decrypted and compared, instead of every nasabah.
+func (t *TabunganRepo) GetKandidatDuplikat(ctx context.Context, nasabah models.Nasabah) (kandidat []models.Nasabah, err error) {
+	var daftarIndex []string
+	SQL := "SELECT nik_index FROM sidik_nasabah WHERE tanggal_lahir_index = $1 AND nik_index != $2"
+	nikIndex := t.cipher.BlindIndex(nasabah.NIK)
+	err = t.db.SelectContext(ctx, &daftarIndex, SQL, t.indeksTanggalLahir(nasabah.TanggalLahir), nikIndex)
+	SQL = "SELECT nik_index FROM blok_alamat WHERE kunci_index = $1 AND nik_index != $2"
+	for _, alamat := range []string{nasabah.AlamatKTP, nasabah.AlamatDomisili} {
+		for _, kunci := range similarity.AddressKeys(alamat) {
+			if err != nil {
+				break
+			}
+			var blok []string
+			err = t.db.SelectContext(ctx, &blok, SQL, t.indeksAlamat(kunci), nikIndex)
+			daftarIndex = append(daftarIndex, blok...)
+		}
+	}
+	seen := make(map[string]bool)
+	for _, index := range daftarIndex {
+		if err != nil {
+			break
+		}
+		if seen[index] {
+			continue
+		}
+		seen[index] = true
+		var lain models.Nasabah
+		if lain, err = t.getNasabahByIndex(ctx, index); err == nil {
+			kandidat = append(kandidat, lain)
+		}
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":   nasabah.NIK,
+			"error": err.Error(),
+		}).Error("query kandidat duplikat error")
+	}
+	return
+}
+
+// GetFotoSerupa returns the NIK of every other nasabah whose photo hash is at
+// most jarakMaks bits away from hash, with the distance found.
+func (t *TabunganRepo) GetFotoSerupa(ctx context.Context, nik, hash string, jarakMaks int) (serupa map[string]int, err error) {
+	var rows []struct {
+		NIKIndex string `db:"nik_index"`
+		FotoHash string `db:"foto_hash"`
+	}
+	SQL := "SELECT nik_index, foto_hash FROM sidik_nasabah WHERE foto_hash != '' AND nik_index != $1"
+	err = t.db.SelectContext(ctx, &rows, SQL, t.cipher.BlindIndex(nik))
+	serupa = make(map[string]int)
+	for _, row := range rows {
+		jarak := imaging.Distance(hash, row.FotoHash)
+		if jarak > jarakMaks {
+			continue
+		}
+		var nikLain string
+		if nikLain, err = t.nikDariIndex(ctx, row.NIKIndex); err != nil {
+			break
+		}
+		serupa[nikLain] = jarak
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":   nik,
+			"error": err.Error(),
+		}).Error("query foto serupa error")
+	}
+	return
+}
+
+func (t *TabunganRepo) nikDariIndex(ctx context.Context, nikIndex string) (nik string, err error) {
+	var row nasabahRow
+	err = t.db.GetContext(ctx, &row, "SELECT * FROM nasabah WHERE nik_index = $1", nikIndex)
+	if err != nil {
+		return
+	}
+	err = t.dekripsiRow(&row)
+	nik = row.NIK
+	return
+}
+
+// InsertDuplikat records a flag unless the same pair was already flagged for
+// the same reason, in either direction.
+func (t *TabunganRepo) InsertDuplikat(ctx context.Context, duplikat models.Duplikat) (inserted bool, err error) {
+	SQL := `INSERT INTO duplikat (duplikat_id, nik_index, nik_index_lain, jenis, skor, status, petugas, catatan, waktu_dibuat, waktu_diputus)
+		SELECT $1, $2, $3, $4, $5, $6, '', '', $7, ''
+		WHERE NOT EXISTS (SELECT 1 FROM duplikat WHERE jenis = $4 AND
+			((nik_index = $2 AND nik_index_lain = $3) OR (nik_index = $3 AND nik_index_lain = $2)))`
+	result, err := t.db.ExecContext(ctx, SQL, duplikat.DuplikatID, t.cipher.BlindIndex(duplikat.NIK), t.cipher.BlindIndex(duplikat.NIKLain),
+		duplikat.Jenis, duplikat.Skor, duplikat.Status, duplikat.WaktuDibuat)
+	if err == nil {
+		var affected int64
+		affected, err = result.RowsAffected()
+		inserted = affected > 0
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":      duplikat.NIK,
+			"nik_lain": duplikat.NIKLain,
+			"jenis":    duplikat.Jenis,
+			"error":    err.Error(),
+		}).Error("insert duplikat error")
+	}
+	return
+}
+
+// GetDaftarDuplikat lists flags by status, by nasabah on either side, or
+// both. Empty filters match everything.
+func (t *TabunganRepo) GetDaftarDuplikat(ctx context.Context, status, nik string) (daftar []models.Duplikat, err error) {
+	var rows []duplikatRow
+	nikIndex := ""
+	if nik != "" {
+		nikIndex = t.cipher.BlindIndex(nik)
+	}
+	SQL := `SELECT * FROM duplikat WHERE ($1 = '' OR status = $1)
+		AND ($2 = '' OR nik_index = $2 OR nik_index_lain = $2) ORDER BY waktu_dibuat, rowid`
+	err = t.db.SelectContext(ctx, &rows, SQL, status, nikIndex)
+	for _, row := range rows {
+		var duplikat models.Duplikat
+		if duplikat, err = t.bukaDuplikat(ctx, row); err != nil {
+			break
+		}
+		daftar = append(daftar, duplikat)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"status": status,
+			"nik":    nik,
+			"error":  err.Error(),
+		}).Error("query daftar duplikat error")
+	}
+	return
+}
+
+func (t *TabunganRepo) GetDuplikat(ctx context.Context, duplikatID string) (duplikat models.Duplikat, err error) {
+	var row duplikatRow
+	err = t.db.GetContext(ctx, &row, "SELECT * FROM duplikat WHERE duplikat_id = $1", duplikatID)
+	if err == nil {
+		duplikat, err = t.bukaDuplikat(ctx, row)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"duplikat_id": duplikatID,
+			"error":       err.Error(),
+		}).Error("get duplikat error")
+	}
+	return
+}
+
+func (t *TabunganRepo) bukaDuplikat(ctx context.Context, row duplikatRow) (duplikat models.Duplikat, err error) {
+	duplikat = row.Duplikat
+	if duplikat.NIK, err = t.nikDariIndex(ctx, row.NIKIndex); err != nil {
+		return
+	}
+	duplikat.NIKLain, err = t.nikDariIndex(ctx, row.NIKIndexLain)
+	return
+}
+
+func (t *TabunganRepo) UpdateStatusDuplikat(ctx context.Context, duplikatID, statusSebelum string, duplikat models.Duplikat) (updated bool, err error) {
+	SQL := `UPDATE duplikat SET status = $1, petugas = $2, catatan = $3, waktu_diputus = $4
+		WHERE duplikat_id = $5 AND status = $6`
+	result, err := t.db.ExecContext(ctx, SQL, duplikat.Status, duplikat.Petugas, duplikat.Catatan, duplikat.WaktuDiputus, duplikatID, statusSebelum)
+	if err == nil {
+		var affected int64
+		affected, err = result.RowsAffected()
+		updated = affected > 0
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"duplikat_id": duplikatID,
+			"status":      duplikat.Status,
+			"error":       err.Error(),
+		}).Error("update status duplikat error")
+	}
+	return
+}
+
+// CountDuplikatTerbuka counts the flags on nik that are still pending or were
+// confirmed.
+func (t *TabunganRepo) CountDuplikatTerbuka(ctx context.Context, nik string) (jumlah int, err error) {
+	SQL := `SELECT COUNT(*) FROM duplikat WHERE status IN ($1, $2) AND (nik_index = $3 OR nik_index_lain = $3)`
+	err = t.db.GetContext(ctx, &jumlah, SQL, models.DuplikatPending, models.DuplikatTerkonfirmasi, t.cipher.BlindIndex(nik))
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":   nik,
+			"error": err.Error(),
+		}).Error("count duplikat error")
+	}
+	return
+}
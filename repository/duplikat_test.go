@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"tabungan-api/models"
+	"testing"
+)
+
+func nasabahUji(nik, nama, alamat, tanggalLahir string) models.Nasabah {
+	return models.Nasabah{RequestRegistrasiNasabah: models.RequestRegistrasiNasabah{
+		NIK: nik, Nama: nama, AlamatKTP: alamat, AlamatDomisili: alamat, JenisKelamin: "L", TanggalLahir: tanggalLahir}}
+}
+
+func nikKandidat(t *testing.T, repo *TabunganRepo, nasabah models.Nasabah) string {
+	kandidat, err := repo.GetKandidatDuplikat(context.Background(), nasabah)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nik []string
+	for _, lain := range kandidat {
+		nik = append(nik, lain.NIK)
+	}
+	sort.Strings(nik)
+	return strings.Join(nik, ",")
+}
+
+// TestKandidatDuplikat checks that candidates come from the birth date and
+// from addresses sharing a blocking key, and that the keys follow updates
+// and are built for nasabah fingerprinted before they existed.
+func TestKandidatDuplikat(t *testing.T) {
+	dir := t.TempDir()
+	repo, _ := repoUji(t, dir)
+	ctx := context.Background()
+	tx, err := repo.StartTransaction(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, nasabah := range []models.Nasabah{
+		nasabahUji("3171010000000001", "Budi Santoso", "Jl. Merdeka No. 5, Bandung", "1990-01-01"),
+		nasabahUji("3171010000000002", "Sari Dewi", "Jalan Kenanga 7 Surabaya", "1990-01-01"),
+		nasabahUji("3171010000000003", "Andi Wijaya", "Jalan Mawar 9 Medan", "1985-05-05"),
+	} {
+		if err = repo.InsertNasabah(ctx, tx, nasabah); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	baru := nasabahUji("3171010000000009", "Budi S", "Jalan Merdeka Nomor 5 Bandung", "1970-12-31")
+	if got := nikKandidat(t, repo, baru); got != "3171010000000001" {
+		t.Errorf("candidates by address = %q, want the nasabah at the same address", got)
+	}
+	baru.TanggalLahir = "1985-05-05"
+	if got := nikKandidat(t, repo, baru); got != "3171010000000001,3171010000000003" {
+		t.Errorf("candidates by address and birth date = %q", got)
+	}
+
+	err = repo.UpdateNasabah(ctx, models.RequestUpdateNasabah{NIK: "3171010000000001", Nama: "Budi Santoso",
+		AlamatKTP: "Jalan Anggrek 3 Bogor", AlamatDomisili: "Jalan Anggrek 3 Bogor"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := nikKandidat(t, repo, baru); got != "3171010000000003" {
+		t.Errorf("candidates after the address moved = %q, want only the birth date match", got)
+	}
+
+	// Nasabah fingerprinted before address keys existed get them on start.
+	repo.db.MustExec("DELETE FROM blok_alamat")
+	repo.db.MustExec("UPDATE sidik_nasabah SET versi = NULL")
+	repo.Close()
+	repo, _ = repoUji(t, dir)
+	baru.AlamatKTP, baru.AlamatDomisili = "Jl Kenanga 7, Surabaya", ""
+	if got := nikKandidat(t, repo, baru); got != "3171010000000002,3171010000000003" {
+		t.Errorf("candidates after migration = %q", got)
+	}
+}
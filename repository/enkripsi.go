@@ -0,0 +1,322 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"tabungan-api/encryption"
+	"tabungan-api/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+// kolomTerenkripsi lists the nasabah columns stored as ciphertext. The NIK is
+// looked up through nik_index, a blind index of the plaintext NIK.
+var kolomTerenkripsi = []string{"nik", "nama", "alamat_ktp", "alamat_domisili", "tanggal_lahir"}
+
+type nasabahRow struct {
+	NIK            string `db:"nik"`
+	Nama           string `db:"nama"`
+	AlamatKTP      string `db:"alamat_ktp"`
+	AlamatDomisili string `db:"alamat_domisili"`
+	JenisKelamin   string `db:"jenis_kelamin"`
+	TanggalLahir   string `db:"tanggal_lahir"`
+	FotoID         string `db:"foto_id"`
+	DokumenID      string `db:"dokumen_id"`
+	NIKIndex       string `db:"nik_index"`
+	KunciID        string `db:"kunci_id"`
+	KunciData      string `db:"kunci_data"`
+}
+
+type versiNasabahRow struct {
+	Versi        int    `db:"versi"`
+	BerlakuSejak string `db:"berlaku_sejak"`
+	nasabahRow
+}
+
+type rowTerenkripsi struct {
+	RowID int64 `db:"rowid"`
+	nasabahRow
+}
+
+func (r *nasabahRow) kolom() map[string]*string {
+	return map[string]*string{
+		"nik":             &r.NIK,
+		"nama":            &r.Nama,
+		"alamat_ktp":      &r.AlamatKTP,
+		"alamat_domisili": &r.AlamatDomisili,
+		"tanggal_lahir":   &r.TanggalLahir,
+	}
+}
+
+func (t *TabunganRepo) enkripsiNasabah(nasabah models.Nasabah) (row nasabahRow, err error) {
+	row = nasabahRow{
+		NIK:            nasabah.NIK,
+		Nama:           nasabah.Nama,
+		AlamatKTP:      nasabah.AlamatKTP,
+		AlamatDomisili: nasabah.AlamatDomisili,
+		JenisKelamin:   nasabah.JenisKelamin,
+		TanggalLahir:   nasabah.TanggalLahir,
+		FotoID:         nasabah.FotoID,
+		DokumenID:      nasabah.DokumenID,
+		NIKIndex:       t.cipher.BlindIndex(nasabah.NIK),
+	}
+	err = t.enkripsiRow(&row)
+	return
+}
+
+// enkripsiRow seals the plaintext columns of row under a fresh data key.
+func (t *TabunganRepo) enkripsiRow(row *nasabahRow) (err error) {
+	dek, err := t.cipher.NewDataKey()
+	if err != nil {
+		return
+	}
+	for _, nama := range kolomTerenkripsi {
+		kolom := row.kolom()[nama]
+		if *kolom, err = dek.Encrypt(nama, *kolom); err != nil {
+			return
+		}
+	}
+	row.KunciID = dek.KeyID
+	row.KunciData = dek.Wrapped
+	return
+}
+
+func (t *TabunganRepo) dekripsiNasabah(row nasabahRow) (nasabah models.Nasabah, err error) {
+	err = t.dekripsiRow(&row)
+	if err != nil {
+		return
+	}
+	nasabah.NIK = row.NIK
+	nasabah.Nama = row.Nama
+	nasabah.AlamatKTP = row.AlamatKTP
+	nasabah.AlamatDomisili = row.AlamatDomisili
+	nasabah.JenisKelamin = row.JenisKelamin
+	nasabah.TanggalLahir = row.TanggalLahir
+	nasabah.FotoID = row.FotoID
+	nasabah.DokumenID = row.DokumenID
+	return
+}
+
+// dekripsiRow opens the encrypted columns of row in place. Rows without a data
+// key are still plaintext from before encryption was enabled.
+func (t *TabunganRepo) dekripsiRow(row *nasabahRow) (err error) {
+	if row.KunciID == "" {
+		return
+	}
+	dek, err := t.cipher.OpenDataKey(row.KunciID, row.KunciData)
+	if err != nil {
+		return
+	}
+	for _, nama := range kolomTerenkripsi {
+		kolom := row.kolom()[nama]
+		if *kolom, err = dek.Decrypt(nama, *kolom); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (t *TabunganRepo) dekripsiVersi(row versiNasabahRow) (nasabah models.VersiNasabah, err error) {
+	nasabah.Versi = row.Versi
+	nasabah.BerlakuSejak = row.BerlakuSejak
+	nasabah.Nasabah, err = t.dekripsiNasabah(row.nasabahRow)
+	return
+}
+
+// migrateEnkripsi adds the encryption columns to databases created before
+// field-level encryption and encrypts whatever rows are still plaintext.
+func (t *TabunganRepo) migrateEnkripsi() {
+	for _, table := range []string{"nasabah", "nasabah_versi"} {
+		t.tambahKolom(table, "nik_index", "kunci_id", "kunci_data")
+		// nik itself now holds ciphertext, so uniqueness of nasabah is enforced
+		// on the blind index instead of the original primary key.
+		unique := ""
+		if table == "nasabah" {
+			unique = "UNIQUE"
+		}
+		t.db.MustExec(fmt.Sprintf("CREATE %s INDEX IF NOT EXISTS %s_nik_index ON %s (nik_index)", unique, table, table))
+		if _, err := t.enkripsiUlang(table, true, 500); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// kolomAda returns the columns table currently has.
+func (t *TabunganRepo) kolomAda(table string) (ada map[string]bool) {
+	var columns []string
+	t.db.Select(&columns, "SELECT name FROM pragma_table_info($1)", table)
+	ada = make(map[string]bool)
+	for _, column := range columns {
+		ada[column] = true
+	}
+	return
+}
+
+// tambahKolom adds the text columns table is missing.
+func (t *TabunganRepo) tambahKolom(table string, columns ...string) {
+	ada := t.kolomAda(table)
+	for _, column := range columns {
+		if !ada[column] {
+			t.db.MustExec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s text", table, column))
+		}
+	}
+}
+
+// migrateRekening replaces the plaintext nik of rekening with its blind index.
+func (t *TabunganRepo) migrateRekening() {
+	if t.kolomAda("rekening")["nik"] {
+		t.tambahKolom("rekening", "nik_index")
+		var rows []struct {
+			RowID int64  `db:"rowid"`
+			NIK   string `db:"nik"`
+		}
+		if err := t.db.Select(&rows, "SELECT rowid, COALESCE(nik, '') AS nik FROM rekening"); err != nil {
+			panic(err)
+		}
+		tx := t.db.MustBegin()
+		for _, row := range rows {
+			tx.MustExec("UPDATE rekening SET nik_index = $1 WHERE rowid = $2", t.cipher.BlindIndex(row.NIK), row.RowID)
+		}
+		tx.MustExec("ALTER TABLE rekening DROP COLUMN nik")
+		if err := tx.Commit(); err != nil {
+			panic(err)
+		}
+	}
+	t.db.MustExec("CREATE INDEX IF NOT EXISTS rekening_nik_index ON rekening (nik_index)")
+}
+
+// RotasiKunci moves every row that is not yet under the active key onto it,
+// batchSize rows per transaction. Nasabah rows are re-encrypted under a fresh
+// data key; every other encrypted table keeps its data keys and only gets them
+// rewrapped, which is all the append-only audit table allows. It is safe to
+// interrupt and rerun.
+func (t *TabunganRepo) RotasiKunci(batchSize int) (jumlah int, err error) {
+	for _, table := range []string{"nasabah", "nasabah_versi"} {
+		var n int
+		n, err = t.enkripsiUlang(table, false, batchSize)
+		jumlah += n
+		if err != nil {
+			return
+		}
+	}
+	for _, table := range []string{"operasi", "audit", "preferensi_notifikasi", "notifikasi", "langganan_webhook"} {
+		var n int
+		n, err = t.bungkusUlang(table, batchSize)
+		jumlah += n
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// bungkusUlang rewraps the data key of every row of table that is not under
+// the active key.
+func (t *TabunganRepo) bungkusUlang(table string, batchSize int) (jumlah int, err error) {
+	SQL := fmt.Sprintf(`SELECT rowid, kunci_id, kunci_data FROM %s
+		WHERE kunci_id IS NOT NULL AND kunci_id != $1 LIMIT $2`, table)
+	UPDATE := fmt.Sprintf("UPDATE %s SET kunci_id = :kunci_id, kunci_data = :kunci_data WHERE rowid = :rowid", table)
+	for {
+		var rows []struct {
+			RowID     int64  `db:"rowid"`
+			KunciID   string `db:"kunci_id"`
+			KunciData string `db:"kunci_data"`
+		}
+		err = t.db.Select(&rows, SQL, t.cipher.ActiveKeyID(), batchSize)
+		if err != nil || len(rows) == 0 {
+			break
+		}
+		var tx *sqlx.Tx
+		tx, err = t.StartTransaction(context.Background())
+		if err != nil {
+			break
+		}
+		for i := range rows {
+			var dek encryption.DataKey
+			if dek, err = t.cipher.RewrapDataKey(rows[i].KunciID, rows[i].KunciData); err != nil {
+				break
+			}
+			rows[i].KunciID = dek.KeyID
+			rows[i].KunciData = dek.Wrapped
+			if _, err = tx.NamedExec(UPDATE, rows[i]); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			tx.Rollback()
+			break
+		}
+		if err = tx.Commit(); err != nil {
+			break
+		}
+		jumlah += len(rows)
+		t.log.WithFields(logrus.Fields{
+			"table":  table,
+			"jumlah": jumlah,
+		}).Info("bungkus ulang kunci data")
+	}
+	if err != nil {
+		t.log.WithFields(logrus.Fields{
+			"table": table,
+			"error": err.Error(),
+		}).Error("bungkus ulang kunci data error")
+	}
+	return
+}
+
+func (t *TabunganRepo) enkripsiUlang(table string, plaintextOnly bool, batchSize int) (jumlah int, err error) {
+	SQL := fmt.Sprintf(`SELECT rowid, nik, nama, alamat_ktp, alamat_domisili,
+		COALESCE(jenis_kelamin, '') AS jenis_kelamin, tanggal_lahir,
+		COALESCE(foto_id, '') AS foto_id, COALESCE(dokumen_id, '') AS dokumen_id,
+		COALESCE(nik_index, '') AS nik_index, COALESCE(kunci_id, '') AS kunci_id,
+		COALESCE(kunci_data, '') AS kunci_data
+		FROM %s WHERE kunci_id IS NULL OR ($1 = 0 AND kunci_id != $2) LIMIT $3`, table)
+	UPDATE := fmt.Sprintf(`UPDATE %s SET nik = :nik, nama = :nama, alamat_ktp = :alamat_ktp,
+		alamat_domisili = :alamat_domisili, tanggal_lahir = :tanggal_lahir, nik_index = :nik_index,
+		kunci_id = :kunci_id, kunci_data = :kunci_data WHERE rowid = :rowid`, table)
+	for {
+		var rows []rowTerenkripsi
+		err = t.db.Select(&rows, SQL, plaintextOnly, t.cipher.ActiveKeyID(), batchSize)
+		if err != nil || len(rows) == 0 {
+			break
+		}
+		var tx *sqlx.Tx
+		tx, err = t.StartTransaction(context.Background())
+		if err != nil {
+			break
+		}
+		for i := range rows {
+			row := &rows[i].nasabahRow
+			if err = t.dekripsiRow(row); err != nil {
+				break
+			}
+			row.NIKIndex = t.cipher.BlindIndex(row.NIK)
+			if err = t.enkripsiRow(row); err != nil {
+				break
+			}
+			if _, err = tx.NamedExec(UPDATE, rows[i]); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			tx.Rollback()
+			break
+		}
+		if err = tx.Commit(); err != nil {
+			break
+		}
+		jumlah += len(rows)
+		t.log.WithFields(logrus.Fields{
+			"table":  table,
+			"jumlah": jumlah,
+		}).Info("enkripsi ulang nasabah")
+	}
+	if err != nil {
+		t.log.WithFields(logrus.Fields{
+			"table": table,
+			"error": err.Error(),
+		}).Error("enkripsi ulang nasabah error")
+	}
+	return
+}
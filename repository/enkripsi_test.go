@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"path/filepath"
+	"strings"
+	"tabungan-api/encryption"
+	"tabungan-api/models"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+const nikUji = "3171012345678901"
+
+func repoUji(t *testing.T, dir string) (repo *TabunganRepo, keys *encryption.LocalKeyFile) {
+	keys, err := encryption.LoadKeyFile(filepath.Join(dir, "keys.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	repo = InitDatabase(filepath.Join(dir, "tabungan.db"), keys, logger)
+	t.Cleanup(func() { repo.Close() })
+	return
+}
+
+// pastikanTanpaPlaintext fails if any column of table still contains one of
+// the given plaintext values.
+func pastikanTanpaPlaintext(t *testing.T, db *sqlx.DB, table string, plaintext ...string) {
+	rows, err := db.Queryx("SELECT * FROM " + table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		row := make(map[string]interface{})
+		if err := rows.MapScan(row); err != nil {
+			t.Fatal(err)
+		}
+		for column, value := range row {
+			var s string
+			switch v := value.(type) {
+			case string:
+				s = v
+			case []byte:
+				s = string(v)
+			}
+			for _, p := range plaintext {
+				if strings.Contains(s, p) {
+					t.Errorf("%s.%s stores %q in plaintext", table, column, p)
+				}
+			}
+		}
+	}
+}
+
+func isiDataUji(t *testing.T, repo *TabunganRepo) {
+	ctx := context.Background()
+	tx, err := repo.StartTransaction(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repo.InsertNasabah(ctx, tx, models.Nasabah{RequestRegistrasiNasabah: models.RequestRegistrasiNasabah{
+		NIK: nikUji, Nama: "Budi Santoso", AlamatKTP: "Jl Melati 1", AlamatDomisili: "Jl Melati 1",
+		JenisKelamin: "L", TanggalLahir: "1990-01-01"}})
+	if err == nil {
+		err = repo.InsertRekening(ctx, tx, models.Rekening{NIK: nikUji, NoRekening: "0012345678", Saldo: 0})
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err == nil {
+		err = repo.InsertOperasi(ctx, models.Operasi{OperasiID: "op-1", JenisOperasi: "update_nasabah", Target: nikUji,
+			Payload: `{"nama":"Budi Santoso","alamat_ktp":"Jl Melati 1"}`, Status: models.StatusPending, Maker: "petugas-1"})
+	}
+	if err == nil {
+		err = repo.InsertAudit(ctx, models.Audit{AuditID: "audit-1", Waktu: "2024-01-01 10:00:00",
+			MetadataRequest: models.MetadataRequest{Aktor: nikUji, Role: "nasabah"}, Aksi: "update_nasabah",
+			Target: nikUji, Perubahan: `{"nama":{"sebelum":"Budi","sesudah":"Budi Santoso"}}`})
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func periksaDataUji(t *testing.T, repo *TabunganRepo) {
+	ctx := context.Background()
+	nasabah, err := repo.GetNasabah(ctx, nikUji)
+	if err != nil || nasabah.Nama != "Budi Santoso" {
+		t.Fatalf("GetNasabah = %+v, %v", nasabah, err)
+	}
+	rekening, err := repo.GetRekeningByNomor(ctx, "0012345678")
+	if err != nil || rekening.NIK != nikUji {
+		t.Fatalf("GetRekeningByNomor = %+v, %v", rekening, err)
+	}
+	if daftar, err := repo.GetDaftarRekening(ctx, nikUji); err != nil || len(daftar) != 1 {
+		t.Fatalf("GetDaftarRekening = %v, %v", daftar, err)
+	}
+	operasi, err := repo.GetOperasi(ctx, "op-1")
+	if err != nil || operasi.Target != nikUji || !strings.Contains(operasi.Payload, "Jl Melati 1") {
+		t.Fatalf("GetOperasi = %+v, %v", operasi, err)
+	}
+	if jumlah, err := repo.CountOperasiAktif(ctx, "update_nasabah", nikUji); err != nil || jumlah != 1 {
+		t.Fatalf("CountOperasiAktif = %d, %v", jumlah, err)
+	}
+	audit, err := repo.GetDaftarAudit(ctx, models.FilterAudit{Aktor: nikUji, Target: nikUji, Limit: 10})
+	if err != nil || len(audit) != 1 || audit[0].Aktor != nikUji || !strings.Contains(audit[0].Perubahan, "Budi Santoso") {
+		t.Fatalf("GetDaftarAudit = %+v, %v", audit, err)
+	}
+}
+
+func pastikanAuditAppendOnly(t *testing.T, repo *TabunganRepo) {
+	if _, err := repo.db.Exec("UPDATE audit SET perubahan = '' WHERE audit_id = 'audit-1'"); err == nil {
+		t.Error("audit content can be updated")
+	}
+	if _, err := repo.db.Exec("DELETE FROM audit"); err == nil {
+		t.Error("audit rows can be deleted")
+	}
+}
+
+func TestEnkripsiPII(t *testing.T) {
+	repo, _ := repoUji(t, t.TempDir())
+	isiDataUji(t, repo)
+	for _, table := range []string{"nasabah", "rekening", "operasi", "audit"} {
+		pastikanTanpaPlaintext(t, repo.db, table, nikUji, "Budi Santoso", "Jl Melati 1")
+	}
+	periksaDataUji(t, repo)
+	pastikanAuditAppendOnly(t, repo)
+}
+
+func TestRotasiKunci(t *testing.T) {
+	repo, keys := repoUji(t, t.TempDir())
+	isiDataUji(t, repo)
+	var perubahan string
+	repo.db.Get(&perubahan, "SELECT perubahan FROM audit")
+
+	lama := keys.ActiveKeyID()
+	if _, err := keys.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	jumlah, err := repo.RotasiKunci(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jumlah != 4 {
+		t.Errorf("RotasiKunci moved %d rows, want 4 (nasabah, nasabah_versi, operasi, audit)", jumlah)
+	}
+	for _, table := range []string{"nasabah", "nasabah_versi", "operasi", "audit"} {
+		var n int
+		repo.db.Get(&n, "SELECT COUNT(*) FROM "+table+" WHERE kunci_id = $1", lama)
+		if n != 0 {
+			t.Errorf("%s still has %d rows under the retired key", table, n)
+		}
+	}
+	var sesudah string
+	repo.db.Get(&sesudah, "SELECT perubahan FROM audit")
+	if sesudah != perubahan {
+		t.Error("rotation rewrote audit content instead of rewrapping its data key")
+	}
+	periksaDataUji(t, repo)
+	pastikanAuditAppendOnly(t, repo)
+
+	if jumlah, err = repo.RotasiKunci(1); err != nil || jumlah != 0 {
+		t.Errorf("second RotasiKunci = %d, %v; want nothing left to rotate", jumlah, err)
+	}
+}
+
+// TestMigrasiPlaintext opens a database written before PII was encrypted and
+// checks that every row is encrypted in place on startup.
+func TestMigrasiPlaintext(t *testing.T) {
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "tabungan.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, SQL := range []string{
+		`CREATE TABLE nasabah (nik text PRIMARY KEY, nama text, alamat_ktp text, alamat_domisili text,
+			jenis_kelamin text, tanggal_lahir text, foto_id text, dokumen_id text)`,
+		`INSERT INTO nasabah VALUES ('3171012345678901', 'Budi Santoso', 'Jl Melati 1', 'Jl Melati 1', 'L', '1990-01-01', '', '')`,
+		`CREATE TABLE rekening (nik text, no_rekening text PRIMARY KEY, saldo real)`,
+		`INSERT INTO rekening VALUES ('3171012345678901', '0012345678', 0)`,
+		`CREATE TABLE operasi (operasi_id text PRIMARY KEY, jenis_operasi text, target text, payload text, status text,
+			maker text, checker text, remark text, waktu_dibuat text, waktu_diputus text)`,
+		`INSERT INTO operasi VALUES ('op-1', 'update_nasabah', '3171012345678901',
+			'{"nama":"Budi Santoso","alamat_ktp":"Jl Melati 1"}', 'pending', 'petugas-1', '', '', '', '')`,
+		`CREATE TABLE audit (audit_id text PRIMARY KEY, waktu text, aktor text, role text, ip text, user_agent text,
+			request_id text, aksi text, target text, perubahan text)`,
+		`INSERT INTO audit VALUES ('audit-1', '2024-01-01 10:00:00', '3171012345678901', 'nasabah', '', '', '',
+			'update_nasabah', '3171012345678901', '{"nama":{"sebelum":"Budi","sesudah":"Budi Santoso"}}')`,
+		`CREATE TABLE outbox (event_id text PRIMARY KEY, jenis text, aggregate_id text, payload text, waktu_dibuat text,
+			waktu_terkirim text, percobaan integer, error_terakhir text)`,
+		`INSERT INTO outbox VALUES ('event-1', 'NasabahRegistered', '3171012345678901', '{"nik":"3171012345678901"}', '', '', 0, ''),
+			('event-2', 'RekeningOpened', '0012345678', '{"nik":"3171012345678901","no_rekening":"0012345678","saldo":0}', '', '', 0, '')`,
+		`CREATE TRIGGER audit_no_update BEFORE UPDATE ON audit BEGIN SELECT RAISE(ABORT, 'audit is append-only'); END`,
+		`CREATE TRIGGER audit_no_delete BEFORE DELETE ON audit BEGIN SELECT RAISE(ABORT, 'audit is append-only'); END`,
+	} {
+		if _, err := db.Exec(SQL); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	repo, _ := repoUji(t, dir)
+	if repo.kolomAda("rekening")["nik"] {
+		t.Error("rekening still has its plaintext nik column")
+	}
+	for _, table := range []string{"nasabah", "rekening", "operasi", "audit", "outbox"} {
+		pastikanTanpaPlaintext(t, repo.db, table, nikUji, "Budi Santoso", "Jl Melati 1")
+	}
+	periksaDataUji(t, repo)
+	pastikanAuditAppendOnly(t, repo)
+
+	outbox, err := repo.GetOutboxTertunda(context.Background(), 10)
+	if err != nil || len(outbox) != 2 {
+		t.Fatalf("GetOutboxTertunda = %+v, %v", outbox, err)
+	}
+	for _, event := range outbox {
+		if event.AggregateID != "0012345678" || !strings.Contains(event.Payload, `"no_rekening":"0012345678"`) {
+			t.Errorf("%s after migration = %+v, want it keyed by and naming the rekening", event.Jenis, event)
+		}
+	}
+}
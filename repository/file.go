@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"tabungan-api/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+// gantiFile points the nasabah at fileID and records it in the file history.
+// Earlier files stay in the history so they can still be retrieved.
+func (t *TabunganRepo) gantiFile(ctx context.Context, tx *sqlx.Tx, SQL, nik, jenis, fileID string) (err error) {
+	nikIndex := t.cipher.BlindIndex(nik)
+	result, err := tx.ExecContext(ctx, SQL, fileID, nikIndex)
+	if err != nil {
+		return
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if affected == 0 {
+		err = sql.ErrNoRows
+		return
+	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO riwayat_file VALUES ($1, $2, $3, $4)", fileID, jenis, nikIndex, time.Now().Format(models.LayoutWaktu))
+	return
+}
+
+func (t *TabunganRepo) GetRiwayatFile(ctx context.Context, nik, jenis string) (file []models.FileNasabah, err error) {
+	SQL := `SELECT file_id, jenis, waktu_unggah FROM riwayat_file
+		WHERE nik_index = $1 AND ($2 = '' OR jenis = $2) ORDER BY waktu_unggah DESC, rowid DESC`
+	err = t.db.SelectContext(ctx, &file, SQL, t.cipher.BlindIndex(nik), jenis)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":   nik,
+			"jenis": jenis,
+			"error": err.Error(),
+		}).Error("query riwayat file error")
+	}
+	return
+}
+
+func (t *TabunganRepo) FileTerdaftar(ctx context.Context, jenis, fileID string) (terdaftar bool, err error) {
+	var jumlah int
+	SQL := "SELECT COUNT(*) FROM riwayat_file WHERE jenis = $1 AND file_id = $2"
+	err = t.db.GetContext(ctx, &jumlah, SQL, jenis, fileID)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"jenis":   jenis,
+			"file_id": fileID,
+			"error":   err.Error(),
+		}).Error("query riwayat file error")
+	}
+	terdaftar = jumlah > 0
+	return
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"tabungan-api/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+// setKYC records kyc as the current KYC state of the nasabah and appends it
+// to the KYC history.
+func (t *TabunganRepo) setKYC(ctx context.Context, tx *sqlx.Tx, nik string, kyc models.KYC) (err error) {
+	nikIndex := t.cipher.BlindIndex(nik)
+	SQL := `INSERT INTO kyc (nik_index, status, dokumen_id, alasan, petugas, waktu) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (nik_index) DO UPDATE SET status = excluded.status, dokumen_id = excluded.dokumen_id,
+		alasan = excluded.alasan, petugas = excluded.petugas, waktu = excluded.waktu`
+	_, err = tx.ExecContext(ctx, SQL, nikIndex, kyc.Status, kyc.DokumenID, kyc.Alasan, kyc.Petugas, kyc.Waktu)
+	if err != nil {
+		return
+	}
+	err = t.insertRiwayatKYC(ctx, tx, nikIndex, kyc)
+	return
+}
+
+func (t *TabunganRepo) insertRiwayatKYC(ctx context.Context, tx *sqlx.Tx, nikIndex string, kyc models.KYC) (err error) {
+	SQL := "INSERT INTO riwayat_kyc (nik_index, status, dokumen_id, alasan, petugas, waktu) VALUES ($1, $2, $3, $4, $5, $6)"
+	_, err = tx.ExecContext(ctx, SQL, nikIndex, kyc.Status, kyc.DokumenID, kyc.Alasan, kyc.Petugas, kyc.Waktu)
+	return
+}
+
+// UpdateStatusKYC moves the nasabah to kyc only if the KYC is still in
+// statusSebelum for the same document, so a review never applies to a
+// document that was replaced after the reviewer opened it.
+func (t *TabunganRepo) UpdateStatusKYC(ctx context.Context, nik, statusSebelum string, kyc models.KYC) (updated bool, err error) {
+	nikIndex := t.cipher.BlindIndex(nik)
+	SQL := `UPDATE kyc SET status = $1, alasan = $2, petugas = $3, waktu = $4
+		WHERE nik_index = $5 AND status = $6 AND dokumen_id = $7`
+	tx, err := t.StartTransaction(ctx)
+	if err != nil {
+		return
+	}
+	result, err := tx.ExecContext(ctx, SQL, kyc.Status, kyc.Alasan, kyc.Petugas, kyc.Waktu, nikIndex, statusSebelum, kyc.DokumenID)
+	if err == nil {
+		var affected int64
+		affected, err = result.RowsAffected()
+		updated = affected > 0
+	}
+	if err == nil && updated {
+		err = t.insertRiwayatKYC(ctx, tx, nikIndex, kyc)
+	}
+	if err == nil {
+		err = tx.Commit()
+	} else {
+		tx.Rollback()
+	}
+	if err != nil {
+		updated = false
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":    nik,
+			"status": kyc.Status,
+			"error":  err.Error(),
+		}).Error("update status kyc error")
+	}
+	return
+}
+
+func (t *TabunganRepo) GetKYC(ctx context.Context, nik string) (kyc models.KYC, err error) {
+	SQL := "SELECT status, dokumen_id, alasan, petugas, waktu FROM kyc WHERE nik_index = $1"
+	err = t.db.GetContext(ctx, &kyc, SQL, t.cipher.BlindIndex(nik))
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":   nik,
+			"error": err.Error(),
+		}).Error("get kyc error")
+		return
+	}
+	kyc.NIK = nik
+	return
+}
+
+func (t *TabunganRepo) GetRiwayatKYC(ctx context.Context, nik string) (riwayat []models.KYC, err error) {
+	SQL := `SELECT status, dokumen_id, alasan, petugas, waktu FROM riwayat_kyc
+		WHERE nik_index = $1 ORDER BY waktu, rowid`
+	err = t.db.SelectContext(ctx, &riwayat, SQL, t.cipher.BlindIndex(nik))
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":   nik,
+			"error": err.Error(),
+		}).Error("query riwayat kyc error")
+	}
+	for i := range riwayat {
+		riwayat[i].NIK = nik
+	}
+	return
+}
+
+// GetDaftarKYC lists the nasabah whose KYC is in status, oldest first, which
+// is the order a review queue is worked through.
+func (t *TabunganRepo) GetDaftarKYC(ctx context.Context, status string) (daftar []models.KYC, err error) {
+	var rows []struct {
+		NIKIndex string `db:"nik_index"`
+		models.KYC
+	}
+	SQL := `SELECT nik_index, status, dokumen_id, alasan, petugas, waktu FROM kyc
+		WHERE status = $1 ORDER BY waktu, rowid`
+	err = t.db.SelectContext(ctx, &rows, SQL, status)
+	for _, row := range rows {
+		var nasabah nasabahRow
+		err = t.db.GetContext(ctx, &nasabah, "SELECT * FROM nasabah WHERE nik_index = $1", row.NIKIndex)
+		if err != nil {
+			break
+		}
+		if err = t.dekripsiRow(&nasabah); err != nil {
+			break
+		}
+		row.KYC.NIK = nasabah.NIK
+		daftar = append(daftar, row.KYC)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"status": status,
+			"error":  err.Error(),
+		}).Error("query daftar kyc error")
+	}
+	return
+}
+
+// alasanKYCLama marks nasabah who were verified by migrateKYC rather than by a
+// petugas.
+const alasanKYCLama = "terdaftar sebelum kyc diberlakukan"
+
+// migrateKYC grandfathers nasabah who registered before KYC existed as
+// verified, so they keep being able to withdraw after the upgrade. They are
+// recognised by having no KYC history: every registration, upload and review
+// since then appends to it. An earlier version of this migration left them
+// pending or submitted without history, and those rows are repaired too.
+func (t *TabunganRepo) migrateKYC() {
+	tx := t.db.MustBegin()
+	SQL := `INSERT INTO kyc (nik_index, status, dokumen_id, alasan, petugas, waktu)
+		SELECT nik_index, $1, COALESCE(dokumen_id, ''), $2, '', $3 FROM nasabah
+		WHERE nik_index NOT IN (SELECT nik_index FROM riwayat_kyc)
+		ON CONFLICT (nik_index) DO UPDATE SET status = excluded.status, dokumen_id = excluded.dokumen_id,
+		alasan = excluded.alasan, petugas = excluded.petugas, waktu = excluded.waktu`
+	tx.MustExec(SQL, models.KYCVerified, alasanKYCLama, time.Now().Format(models.LayoutWaktu))
+	SQL = `INSERT INTO riwayat_kyc (nik_index, status, dokumen_id, alasan, petugas, waktu)
+		SELECT nik_index, status, dokumen_id, alasan, petugas, waktu FROM kyc
+		WHERE nik_index NOT IN (SELECT nik_index FROM riwayat_kyc)`
+	tx.MustExec(SQL)
+	if err := tx.Commit(); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"tabungan-api/models"
+	"testing"
+)
+
+// TestMigrasiKYC checks that nasabah from before KYC are grandfathered as
+// verified, including those the earlier migration left pending, while
+// nasabah registered afterwards keep going through review.
+func TestMigrasiKYC(t *testing.T) {
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "tabungan.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, SQL := range []string{
+		`CREATE TABLE nasabah (nik text PRIMARY KEY, nama text, alamat_ktp text, alamat_domisili text,
+			jenis_kelamin text, tanggal_lahir text, foto_id text, dokumen_id text)`,
+		`INSERT INTO nasabah VALUES ('3171012345678903', 'Budi Santoso', 'Jl A', 'Jl A', 'L', '1990-01-01', '', '')`,
+		`INSERT INTO nasabah VALUES ('3171012345678902', 'Sari Dewi', 'Jl B', 'Jl B', 'P', '1991-02-02', '', 'dok-1')`,
+	} {
+		if _, err := db.Exec(SQL); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	repo, _ := repoUji(t, dir)
+	// Simulate the earlier migration, which left one of them pending.
+	repo.db.MustExec("UPDATE kyc SET status = 'pending', alasan = '' WHERE dokumen_id = ''")
+	repo.db.MustExec("DELETE FROM riwayat_kyc WHERE dokumen_id = ''")
+	repo.Close()
+	repo, _ = repoUji(t, dir)
+
+	ctx := context.Background()
+	for _, nik := range []string{"3171012345678903", "3171012345678902"} {
+		kyc, err := repo.GetKYC(ctx, nik)
+		if err != nil || kyc.Status != models.KYCVerified {
+			t.Errorf("GetKYC(%s) = %+v, %v; want grandfathered as verified", nik, kyc, err)
+		}
+		if riwayat, err := repo.GetRiwayatKYC(ctx, nik); err != nil || len(riwayat) != 1 {
+			t.Errorf("GetRiwayatKYC(%s) = %+v, %v; want the grandfathering recorded once", nik, riwayat, err)
+		}
+	}
+
+	isiDataUji(t, repo)
+	repo.Close()
+	repo, _ = repoUji(t, dir)
+	if kyc, err := repo.GetKYC(ctx, nikUji); err != nil || kyc.Status != models.KYCPending {
+		t.Errorf("GetKYC of a new nasabah = %+v, %v; want pending", kyc, err)
+	}
+}
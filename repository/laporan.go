@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"tabungan-api/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+type transaksiTunaiRow struct {
+	NIKIndex   string `db:"nik_index"`
+	NoRekening string `db:"no_rekening"`
+	models.TransaksiTunaiHarian
+}
+
+// GetTransaksiTunaiHarian sums every nasabah's transactions per day over
+// dari..sampai inclusive, both as YYYY-MM-DD, and returns the days on which
+// deposits and withdrawals together reach ambang.
+func (t *TabunganRepo) GetTransaksiTunaiHarian(ctx context.Context, dari, sampai string, ambang float64) (daftar []models.TransaksiTunaiHarian, err error) {
+	var rows []transaksiTunaiRow
+	SQL := `SELECT substr(mutasi.waktu, 1, 10) AS tanggal, rekening.nik_index AS nik_index,
+		GROUP_CONCAT(DISTINCT mutasi.no_rekening) AS no_rekening,
+		COUNT(*) AS jumlah_transaksi,
+		SUM(CASE WHEN mutasi.jenis_mutasi = 'C' THEN mutasi.nominal ELSE 0 END) AS total_setor,
+		SUM(CASE WHEN mutasi.jenis_mutasi = 'D' THEN mutasi.nominal ELSE 0 END) AS total_tarik,
+		SUM(mutasi.nominal) AS total
+		FROM mutasi JOIN rekening ON rekening.no_rekening = mutasi.no_rekening
+		WHERE substr(mutasi.waktu, 1, 10) BETWEEN $1 AND $2
+		GROUP BY tanggal, rekening.nik_index HAVING SUM(mutasi.nominal) >= $3
+		ORDER BY tanggal`
+	err = t.db.SelectContext(ctx, &rows, SQL, dari, sampai, ambang)
+	for _, row := range rows {
+		var nasabah models.Nasabah
+		if nasabah, err = t.getNasabahByIndex(ctx, row.NIKIndex); err != nil {
+			break
+		}
+		harian := row.TransaksiTunaiHarian
+		harian.NIK = nasabah.NIK
+		harian.Nama = nasabah.Nama
+		harian.TanggalLahir = nasabah.TanggalLahir
+		harian.AlamatKTP = nasabah.AlamatKTP
+		harian.NoRekening = strings.Split(row.NoRekening, ",")
+		daftar = append(daftar, harian)
+	}
+	// The blind index says nothing about the NIK's order, so the rows of a day
+	// are sorted only once the NIKs are decrypted.
+	sort.SliceStable(daftar, func(i, j int) bool {
+		if daftar[i].Tanggal != daftar[j].Tanggal {
+			return daftar[i].Tanggal < daftar[j].Tanggal
+		}
+		return daftar[i].NIK < daftar[j].NIK
+	})
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"dari":   dari,
+			"sampai": sampai,
+			"error":  err.Error(),
+		}).Error("query transaksi tunai harian error")
+	}
+	return
+}
+
+// GetVersiLaporanTunai returns the latest version generated for the period,
+// or 0 if it has never been generated.
+func (t *TabunganRepo) GetVersiLaporanTunai(ctx context.Context, dari, sampai string) (versi int, err error) {
+	SQL := "SELECT COALESCE(MAX(versi), 0) FROM laporan_tunai WHERE periode_dari = $1 AND periode_sampai = $2"
+	err = t.db.GetContext(ctx, &versi, SQL, dari, sampai)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"dari":   dari,
+			"sampai": sampai,
+			"error":  err.Error(),
+		}).Error("query versi laporan tunai error")
+	}
+	return
+}
+
+// InsertLaporanTunai stores a new version of a period's report and marks the
+// earlier versions that were never submitted as superseded.
+func (t *TabunganRepo) InsertLaporanTunai(ctx context.Context, laporan models.LaporanTunai) (err error) {
+	tx, err := t.db.BeginTxx(ctx, nil)
+	if err == nil {
+		SQL := `UPDATE laporan_tunai SET status = $1, waktu_diubah = $2
+			WHERE periode_dari = $3 AND periode_sampai = $4 AND status = $5`
+		_, err = tx.ExecContext(ctx, SQL, models.LaporanDigantikan, laporan.WaktuDibuat, laporan.PeriodeDari, laporan.PeriodeSampai, models.LaporanDibuat)
+		if err == nil {
+			SQL = `INSERT INTO laporan_tunai VALUES (:laporan_id, :periode_dari, :periode_sampai, :versi, :ambang,
+				:jumlah_baris, :total_nominal, :file_id, :checksum, :status, :referensi, :catatan, :pembuat, :petugas,
+				:waktu_dibuat, :waktu_diubah)`
+			_, err = tx.NamedExecContext(ctx, SQL, laporan)
+		}
+		if err == nil {
+			err = tx.Commit()
+		} else {
+			tx.Rollback()
+		}
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"laporan_id": laporan.LaporanID,
+			"dari":       laporan.PeriodeDari,
+			"sampai":     laporan.PeriodeSampai,
+			"versi":      laporan.Versi,
+			"error":      err.Error(),
+		}).Error("insert laporan tunai error")
+	}
+	return
+}
+
+// GetDaftarLaporanTunai lists reports whose period overlaps dari..sampai.
+// Empty filters match everything.
+func (t *TabunganRepo) GetDaftarLaporanTunai(ctx context.Context, status, dari, sampai string) (daftar []models.LaporanTunai, err error) {
+	SQL := `SELECT * FROM laporan_tunai
+		WHERE ($1 = '' OR status = $1)
+		AND ($2 = '' OR periode_sampai >= $2)
+		AND ($3 = '' OR periode_dari <= $3)
+		ORDER BY periode_dari DESC, periode_sampai DESC, versi DESC`
+	err = t.db.SelectContext(ctx, &daftar, SQL, status, dari, sampai)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"status": status,
+			"dari":   dari,
+			"sampai": sampai,
+			"error":  err.Error(),
+		}).Error("query daftar laporan tunai error")
+	}
+	return
+}
+
+func (t *TabunganRepo) GetLaporanTunai(ctx context.Context, laporanID string) (laporan models.LaporanTunai, err error) {
+	err = t.db.GetContext(ctx, &laporan, "SELECT * FROM laporan_tunai WHERE laporan_id = $1", laporanID)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"laporan_id": laporanID,
+			"error":      err.Error(),
+		}).Error("get laporan tunai error")
+	}
+	return
+}
+
+func (t *TabunganRepo) UpdateStatusLaporanTunai(ctx context.Context, laporanID, statusSebelum string, laporan models.LaporanTunai) (updated bool, err error) {
+	SQL := `UPDATE laporan_tunai SET status = $1, referensi = $2, catatan = $3, petugas = $4, waktu_diubah = $5
+		WHERE laporan_id = $6 AND status = $7`
+	result, err := t.db.ExecContext(ctx, SQL, laporan.Status, laporan.Referensi, laporan.Catatan, laporan.Petugas, laporan.WaktuDiubah,
+		laporanID, statusSebelum)
+	if err == nil {
+		var affected int64
+		affected, err = result.RowsAffected()
+		updated = affected > 0
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"laporan_id": laporanID,
+			"status":     laporan.Status,
+			"error":      err.Error(),
+		}).Error("update status laporan tunai error")
+	}
+	return
+}
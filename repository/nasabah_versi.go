@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"tabungan-api/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+// updateNasabahVersi runs update and records the resulting nasabah row as a
+// new version in the same transaction.
+func (t *TabunganRepo) updateNasabahVersi(ctx context.Context, nik string, update func(tx *sqlx.Tx) error) (err error) {
+	tx, err := t.StartTransaction(ctx)
+	if err != nil {
+		return
+	}
+	err = update(tx)
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	err = t.snapshotNasabah(ctx, tx, nik)
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+func (t *TabunganRepo) snapshotNasabah(ctx context.Context, tx *sqlx.Tx, nik string) (err error) {
+	SQL := `INSERT INTO nasabah_versi (nik, versi, berlaku_sejak, nama, alamat_ktp, alamat_domisili,
+		jenis_kelamin, tanggal_lahir, foto_id, dokumen_id, nik_index, kunci_id, kunci_data)
+		SELECT nik, (SELECT COALESCE(MAX(versi), 0) + 1 FROM nasabah_versi WHERE nik_index = $1), $2,
+		nama, alamat_ktp, alamat_domisili, jenis_kelamin, tanggal_lahir, foto_id, dokumen_id,
+		nik_index, kunci_id, kunci_data
+		FROM nasabah WHERE nik_index = $1`
+	_, err = tx.ExecContext(ctx, SQL, t.cipher.BlindIndex(nik), time.Now().Format(models.LayoutWaktu))
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":   nik,
+			"error": err.Error(),
+		}).Error("insert nasabah versi error")
+	}
+	return
+}
+
+func (t *TabunganRepo) GetRiwayatNasabah(ctx context.Context, nik string) (riwayat []models.VersiNasabah, err error) {
+	var rows []versiNasabahRow
+	SQL := "SELECT * FROM nasabah_versi WHERE nik_index = $1 ORDER BY versi"
+	err = t.db.SelectContext(ctx, &rows, SQL, t.cipher.BlindIndex(nik))
+	for _, row := range rows {
+		var versi models.VersiNasabah
+		if versi, err = t.dekripsiVersi(row); err != nil {
+			break
+		}
+		riwayat = append(riwayat, versi)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":   nik,
+			"error": err.Error(),
+		}).Error("query riwayat nasabah error")
+	}
+	return
+}
+
+func (t *TabunganRepo) GetNasabahPerWaktu(ctx context.Context, nik, waktu string) (nasabah models.VersiNasabah, err error) {
+	var row versiNasabahRow
+	SQL := "SELECT * FROM nasabah_versi WHERE nik_index = $1 AND berlaku_sejak <= $2 ORDER BY versi DESC LIMIT 1"
+	err = t.db.GetContext(ctx, &row, SQL, t.cipher.BlindIndex(nik), waktu)
+	if err == nil {
+		nasabah, err = t.dekripsiVersi(row)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":   nik,
+			"waktu": waktu,
+			"error": err.Error(),
+		}).Error("get nasabah versi error")
+	}
+	return
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"tabungan-api/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+type preferensiRow struct {
+	NIKIndex  string `db:"nik_index"`
+	Email     string `db:"email"`
+	NoHP      string `db:"no_hp"`
+	KunciID   string `db:"kunci_id"`
+	KunciData string `db:"kunci_data"`
+	Kanal     string `db:"kanal"`
+	models.PreferensiNotifikasi
+}
+
+type notifikasiRow struct {
+	NIKIndex  string `db:"nik_index"`
+	Tujuan    string `db:"tujuan"`
+	Isi       string `db:"isi"`
+	KunciID   string `db:"kunci_id"`
+	KunciData string `db:"kunci_data"`
+	models.Notifikasi
+}
+
+func (t *TabunganRepo) SetPreferensiNotifikasi(ctx context.Context, preferensi models.PreferensiNotifikasi) (err error) {
+	row := preferensiRow{NIKIndex: t.cipher.BlindIndex(preferensi.NIK), PreferensiNotifikasi: preferensi}
+	kanal, err := json.Marshal(preferensi.Kanal)
+	if err != nil {
+		return
+	}
+	row.Kanal = string(kanal)
+	dek, err := t.cipher.NewDataKey()
+	if err == nil {
+		row.KunciID = dek.KeyID
+		row.KunciData = dek.Wrapped
+		row.Email, err = dek.Encrypt("email", preferensi.Email)
+	}
+	if err == nil {
+		row.NoHP, err = dek.Encrypt("no_hp", preferensi.NoHP)
+	}
+	if err == nil {
+		SQL := `INSERT INTO preferensi_notifikasi VALUES (:nik_index, :bahasa, :email, :no_hp, :kunci_id, :kunci_data,
+			:kanal, :transaksi, :perubahan_profil, :waktu_diubah)
+			ON CONFLICT (nik_index) DO UPDATE SET bahasa = excluded.bahasa, email = excluded.email,
+			no_hp = excluded.no_hp, kunci_id = excluded.kunci_id, kunci_data = excluded.kunci_data,
+			kanal = excluded.kanal, transaksi = excluded.transaksi, perubahan_profil = excluded.perubahan_profil,
+			waktu_diubah = excluded.waktu_diubah`
+		_, err = t.db.NamedExecContext(ctx, SQL, row)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":   preferensi.NIK,
+			"error": err.Error(),
+		}).Error("set preferensi notifikasi error")
+	}
+	return
+}
+
+// GetPreferensiNotifikasi returns found false if the nasabah never set any
+// preferences.
+func (t *TabunganRepo) GetPreferensiNotifikasi(ctx context.Context, nik string) (preferensi models.PreferensiNotifikasi, found bool, err error) {
+	var row preferensiRow
+	err = t.db.GetContext(ctx, &row, "SELECT * FROM preferensi_notifikasi WHERE nik_index = $1", t.cipher.BlindIndex(nik))
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+		return
+	}
+	if err == nil {
+		preferensi = row.PreferensiNotifikasi
+		preferensi.NIK = nik
+		err = json.Unmarshal([]byte(row.Kanal), &preferensi.Kanal)
+	}
+	if err == nil {
+		preferensi.Email, preferensi.NoHP, err = t.bukaKontak(row)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":   nik,
+			"error": err.Error(),
+		}).Error("get preferensi notifikasi error")
+		return
+	}
+	found = true
+	return
+}
+
+func (t *TabunganRepo) bukaKontak(row preferensiRow) (email, noHP string, err error) {
+	dek, err := t.cipher.OpenDataKey(row.KunciID, row.KunciData)
+	if err != nil {
+		return
+	}
+	if email, err = dek.Decrypt("email", row.Email); err != nil {
+		return
+	}
+	noHP, err = dek.Decrypt("no_hp", row.NoHP)
+	return
+}
+
+// InsertNotifikasi queues notifikasi for nik. The recipient and the body are
+// sealed under a fresh data key; the subject carries no PII.
+func (t *TabunganRepo) InsertNotifikasi(ctx context.Context, nik string, notifikasi models.Notifikasi) (err error) {
+	row := notifikasiRow{NIKIndex: t.cipher.BlindIndex(nik), Notifikasi: notifikasi}
+	dek, err := t.cipher.NewDataKey()
+	if err == nil {
+		row.KunciID = dek.KeyID
+		row.KunciData = dek.Wrapped
+		row.Tujuan, err = dek.Encrypt("tujuan", notifikasi.Tujuan)
+	}
+	if err == nil {
+		row.Isi, err = dek.Encrypt("isi", notifikasi.Isi)
+	}
+	if err == nil {
+		SQL := `INSERT INTO notifikasi VALUES (:notifikasi_id, :nik_index, :jenis, :kanal, :tujuan, :subjek, :isi,
+			:kunci_id, :kunci_data, :status, 0, :kirim_berikutnya, '', :waktu_dibuat, '')`
+		_, err = t.db.NamedExecContext(ctx, SQL, row)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":   nik,
+			"jenis": notifikasi.Jenis,
+			"kanal": notifikasi.Kanal,
+			"error": err.Error(),
+		}).Error("insert notifikasi error")
+	}
+	return
+}
+
+func (t *TabunganRepo) bukaNotifikasi(rows []notifikasiRow) (daftar []models.Notifikasi, err error) {
+	for _, row := range rows {
+		notifikasi := row.Notifikasi
+		dek, err := t.cipher.OpenDataKey(row.KunciID, row.KunciData)
+		if err != nil {
+			return nil, err
+		}
+		if notifikasi.Tujuan, err = dek.Decrypt("tujuan", row.Tujuan); err != nil {
+			return nil, err
+		}
+		if notifikasi.Isi, err = dek.Decrypt("isi", row.Isi); err != nil {
+			return nil, err
+		}
+		daftar = append(daftar, notifikasi)
+	}
+	return
+}
+
+// GetNotifikasiSiapKirim returns pending notifications that are due at
+// sekarang.
+func (t *TabunganRepo) GetNotifikasiSiapKirim(ctx context.Context, sekarang string, limit int) (daftar []models.Notifikasi, err error) {
+	var rows []notifikasiRow
+	SQL := `SELECT * FROM notifikasi WHERE status = $1 AND kirim_berikutnya <= $2
+		ORDER BY kirim_berikutnya, rowid LIMIT $3`
+	err = t.db.SelectContext(ctx, &rows, SQL, models.NotifikasiPending, sekarang, limit)
+	if err == nil {
+		daftar, err = t.bukaNotifikasi(rows)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"sekarang": sekarang,
+			"error":    err.Error(),
+		}).Error("query notifikasi error")
+	}
+	return
+}
+
+func (t *TabunganRepo) UpdateNotifikasi(ctx context.Context, notifikasi models.Notifikasi) (err error) {
+	SQL := `UPDATE notifikasi SET status = :status, percobaan = :percobaan, kirim_berikutnya = :kirim_berikutnya,
+		error_terakhir = :error_terakhir, waktu_terkirim = :waktu_terkirim WHERE notifikasi_id = :notifikasi_id`
+	_, err = t.db.NamedExecContext(ctx, SQL, notifikasi)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"notifikasi_id": notifikasi.NotifikasiID,
+			"error":         err.Error(),
+		}).Error("update notifikasi error")
+	}
+	return
+}
+
+// GetDaftarNotifikasi returns the notifications of nik, newest first.
+func (t *TabunganRepo) GetDaftarNotifikasi(ctx context.Context, nik string) (daftar []models.Notifikasi, err error) {
+	var rows []notifikasiRow
+	SQL := "SELECT * FROM notifikasi WHERE nik_index = $1 ORDER BY waktu_dibuat DESC, rowid DESC"
+	err = t.db.SelectContext(ctx, &rows, SQL, t.cipher.BlindIndex(nik))
+	if err == nil {
+		daftar, err = t.bukaNotifikasi(rows)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":   nik,
+			"error": err.Error(),
+		}).Error("query daftar notifikasi error")
+	}
+	return
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"tabungan-api/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+func (t *TabunganRepo) GetTransaksi(ctx context.Context, transaksiID string) (mutasi models.Mutasi, err error) {
+	SQL := "SELECT * FROM mutasi WHERE transaksi_id = $1"
+	err = t.db.GetContext(ctx, &mutasi, SQL, transaksiID)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"transaksi_id": transaksiID,
+			"error":        err.Error(),
+		}).Error("get transaksi error")
+	}
+	return
+}
+
+// operasiRow stores target and payload as ciphertext: the target is usually a
+// NIK and the payload carries the requested profile or transaction. Lookups by
+// target go through target_index.
+type operasiRow struct {
+	Target      string `db:"target"`
+	Payload     string `db:"payload"`
+	TargetIndex string `db:"target_index"`
+	KunciID     string `db:"kunci_id"`
+	KunciData   string `db:"kunci_data"`
+	models.Operasi
+}
+
+func (t *TabunganRepo) enkripsiOperasi(operasi models.Operasi) (row operasiRow, err error) {
+	row = operasiRow{TargetIndex: t.cipher.BlindIndex(operasi.Target), Operasi: operasi}
+	dek, err := t.cipher.NewDataKey()
+	if err == nil {
+		row.KunciID = dek.KeyID
+		row.KunciData = dek.Wrapped
+		row.Target, err = dek.Encrypt("target", operasi.Target)
+	}
+	if err == nil {
+		row.Payload, err = dek.Encrypt("payload", operasi.Payload)
+	}
+	return
+}
+
+func (t *TabunganRepo) dekripsiOperasi(row operasiRow) (operasi models.Operasi, err error) {
+	operasi = row.Operasi
+	dek, err := t.cipher.OpenDataKey(row.KunciID, row.KunciData)
+	if err != nil {
+		return
+	}
+	if operasi.Target, err = dek.Decrypt("target", row.Target); err != nil {
+		return
+	}
+	operasi.Payload, err = dek.Decrypt("payload", row.Payload)
+	return
+}
+
+func (t *TabunganRepo) InsertOperasi(ctx context.Context, operasi models.Operasi) (err error) {
+	row, err := t.enkripsiOperasi(operasi)
+	if err == nil {
+		SQL := `INSERT INTO operasi (operasi_id, jenis_operasi, target, payload, status, maker, checker, remark,
+			waktu_dibuat, waktu_diputus, target_index, kunci_id, kunci_data)
+			VALUES (:operasi_id, :jenis_operasi, :target, :payload, :status, :maker, :checker, :remark,
+			:waktu_dibuat, :waktu_diputus, :target_index, :kunci_id, :kunci_data)`
+		_, err = t.db.NamedExecContext(ctx, SQL, row)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"operasi_id":    operasi.OperasiID,
+			"jenis_operasi": operasi.JenisOperasi,
+			"target":        operasi.Target,
+			"maker":         operasi.Maker,
+			"error":         err.Error(),
+		}).Error("insert operasi error")
+	}
+	return
+}
+
+func (t *TabunganRepo) GetOperasi(ctx context.Context, operasiID string) (operasi models.Operasi, err error) {
+	var row operasiRow
+	SQL := "SELECT * FROM operasi WHERE operasi_id = $1"
+	err = t.db.GetContext(ctx, &row, SQL, operasiID)
+	if err == nil {
+		operasi, err = t.dekripsiOperasi(row)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"operasi_id": operasiID,
+			"error":      err.Error(),
+		}).Error("get operasi error")
+	}
+	return
+}
+
+func (t *TabunganRepo) GetDaftarOperasi(ctx context.Context, status string) (operasi []models.Operasi, err error) {
+	var rows []operasiRow
+	SQL := "SELECT * FROM operasi WHERE $1 = '' OR status = $1 ORDER BY waktu_dibuat, rowid"
+	err = t.db.SelectContext(ctx, &rows, SQL, status)
+	for _, row := range rows {
+		var o models.Operasi
+		if o, err = t.dekripsiOperasi(row); err != nil {
+			break
+		}
+		operasi = append(operasi, o)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"status": status,
+			"error":  err.Error(),
+		}).Error("query operasi error")
+	}
+	return
+}
+
+func (t *TabunganRepo) CountOperasiAktif(ctx context.Context, jenisOperasi, target string) (jumlah int, err error) {
+	SQL := "SELECT COUNT(*) FROM operasi WHERE jenis_operasi = $1 AND target_index = $2 AND status IN ($3, $4, $5)"
+	err = t.db.GetContext(ctx, &jumlah, SQL, jenisOperasi, t.cipher.BlindIndex(target), models.StatusPending, models.StatusApproved, models.StatusExecuted)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"jenis_operasi": jenisOperasi,
+			"target":        target,
+			"error":         err.Error(),
+		}).Error("count operasi error")
+	}
+	return
+}
+
+// UpdateStatusOperasi only moves the operasi forward when it is still in
+// statusAwal, so two checkers deciding at the same time cannot both win.
+func (t *TabunganRepo) UpdateStatusOperasi(ctx context.Context, operasiID, statusAwal string, operasi models.Operasi) (updated bool, err error) {
+	SQL := "UPDATE operasi SET status = $1, checker = $2, remark = $3, waktu_diputus = $4 WHERE operasi_id = $5 AND status = $6"
+	result, err := t.db.ExecContext(ctx, SQL, operasi.Status, operasi.Checker, operasi.Remark, operasi.WaktuDiputus, operasiID, statusAwal)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"operasi_id": operasiID,
+			"status":     operasi.Status,
+			"error":      err.Error(),
+		}).Error("update status operasi error")
+		return
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"operasi_id": operasiID,
+			"error":      err.Error(),
+		}).Error("update status operasi error")
+		return
+	}
+	updated = affected > 0
+	return
+}
+
+func (t *TabunganRepo) InsertRiwayatOperasi(ctx context.Context, riwayat models.RiwayatOperasi) (err error) {
+	SQL := "INSERT INTO riwayat_operasi VALUES (:operasi_id, :waktu, :status, :petugas, :remark)"
+	_, err = t.db.NamedExecContext(ctx, SQL, riwayat)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"operasi_id": riwayat.OperasiID,
+			"status":     riwayat.Status,
+			"petugas":    riwayat.Petugas,
+			"error":      err.Error(),
+		}).Error("insert riwayat operasi error")
+	}
+	return
+}
+
+func (t *TabunganRepo) GetRiwayatOperasi(ctx context.Context, operasiID string) (riwayat []models.RiwayatOperasi, err error) {
+	SQL := "SELECT * FROM riwayat_operasi WHERE operasi_id = $1 ORDER BY waktu, rowid"
+	err = t.db.SelectContext(ctx, &riwayat, SQL, operasiID)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"operasi_id": operasiID,
+			"error":      err.Error(),
+		}).Error("query riwayat operasi error")
+	}
+	return
+}
+
+// migrateOperasi encrypts operasi rows written before target and payload were
+// encrypted.
+func (t *TabunganRepo) migrateOperasi() {
+	t.tambahKolom("operasi", "target_index", "kunci_id", "kunci_data")
+	t.db.MustExec("CREATE INDEX IF NOT EXISTS operasi_target_index ON operasi (jenis_operasi, target_index)")
+
+	var rows []operasiRow
+	SQL := `SELECT operasi_id, jenis_operasi, COALESCE(target, '') AS target, COALESCE(payload, '') AS payload,
+		status, maker, checker, remark, waktu_dibuat, waktu_diputus FROM operasi WHERE kunci_id IS NULL`
+	if err := t.db.Select(&rows, SQL); err != nil {
+		panic(err)
+	}
+	UPDATE := `UPDATE operasi SET target = :target, payload = :payload, target_index = :target_index,
+		kunci_id = :kunci_id, kunci_data = :kunci_data WHERE operasi_id = :operasi_id`
+	tx := t.db.MustBegin()
+	for _, row := range rows {
+		row.Operasi.Target = row.Target
+		row.Operasi.Payload = row.Payload
+		row, err := t.enkripsiOperasi(row.Operasi)
+		if err == nil {
+			_, err = tx.NamedExec(UPDATE, row)
+		}
+		if err != nil {
+			tx.Rollback()
+			panic(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"tabungan-api/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+// InsertOutbox must run in the transaction of the change the event
+// describes, so that the event exists if and only if the change does.
+func (t *TabunganRepo) InsertOutbox(ctx context.Context, tx *sqlx.Tx, event models.Outbox) (err error) {
+	SQL := `INSERT INTO outbox VALUES (:event_id, :jenis, :aggregate_id, :payload, :waktu_dibuat, '', 0, '')`
+	_, err = tx.NamedExecContext(ctx, SQL, event)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"event_id":     event.EventID,
+			"jenis":        event.Jenis,
+			"aggregate_id": event.AggregateID,
+			"error":        err.Error(),
+		}).Error("insert outbox error")
+	}
+	return
+}
+
+// GetOutboxTertunda returns the oldest events not yet delivered, in the order
+// they were written.
+func (t *TabunganRepo) GetOutboxTertunda(ctx context.Context, limit int) (daftar []models.Outbox, err error) {
+	err = t.db.SelectContext(ctx, &daftar, "SELECT * FROM outbox WHERE waktu_terkirim = '' ORDER BY rowid LIMIT $1", limit)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"limit": limit,
+			"error": err.Error(),
+		}).Error("query outbox error")
+	}
+	return
+}
+
+func (t *TabunganRepo) TandaiOutboxTerkirim(ctx context.Context, eventID, waktu string) (err error) {
+	_, err = t.db.ExecContext(ctx, "UPDATE outbox SET waktu_terkirim = $1, percobaan = percobaan + 1 WHERE event_id = $2", waktu, eventID)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"event_id": eventID,
+			"error":    err.Error(),
+		}).Error("update outbox error")
+	}
+	return
+}
+
+func (t *TabunganRepo) CatatGagalOutbox(ctx context.Context, eventID, pesan string) (err error) {
+	_, err = t.db.ExecContext(ctx, "UPDATE outbox SET percobaan = percobaan + 1, error_terakhir = $1 WHERE event_id = $2", pesan, eventID)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"event_id": eventID,
+			"error":    err.Error(),
+		}).Error("update outbox error")
+	}
+	return
+}
+
+// migrateOutbox removes the NIK from events written before the outbox left it
+// out: NasabahRegistered was keyed by NIK and RekeningOpened carried it in its
+// payload. NasabahRegistered is rekeyed to the first rekening of the nasabah,
+// which was opened in the same transaction.
+func (t *TabunganRepo) migrateOutbox() {
+	var daftar []models.Outbox
+	err := t.db.Select(&daftar, `SELECT * FROM outbox WHERE jenis IN ($1, $2) AND payload LIKE '%"nik"%'`,
+		models.EventNasabahRegistered, models.EventRekeningOpened)
+	if err != nil {
+		panic(err)
+	}
+	tx := t.db.MustBegin()
+	for _, event := range daftar {
+		var payload map[string]interface{}
+		if err = json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+			panic(err)
+		}
+		nik, _ := payload["nik"].(string)
+		delete(payload, "nik")
+		if event.Jenis == models.EventNasabahRegistered {
+			var noRekening string
+			tx.Get(&noRekening, "SELECT no_rekening FROM rekening WHERE nik_index = $1 ORDER BY rowid LIMIT 1", t.cipher.BlindIndex(nik))
+			event.AggregateID = noRekening
+			payload["no_rekening"] = noRekening
+		}
+		data, _ := json.Marshal(payload)
+		tx.MustExec("UPDATE outbox SET aggregate_id = $1, payload = $2 WHERE event_id = $3", event.AggregateID, string(data), event.EventID)
+	}
+	if err = tx.Commit(); err != nil {
+		panic(err)
+	}
+}
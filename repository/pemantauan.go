@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"tabungan-api/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+type alertRow struct {
+	NIKIndex         string `db:"nik_index"`
+	TransaksiTerkait string `db:"transaksi_terkait"`
+	models.AlertTransaksi
+}
+
+func (t *TabunganRepo) GetDaftarAturan(ctx context.Context) (aturan []models.AturanPemantauan, err error) {
+	err = t.db.SelectContext(ctx, &aturan, "SELECT * FROM aturan_pemantauan ORDER BY rowid")
+	if err != nil {
+		t.log.WithContext(ctx).WithField("error", err.Error()).Error("query aturan pemantauan error")
+	}
+	return
+}
+
+func (t *TabunganRepo) GetAturan(ctx context.Context, aturanID string) (aturan models.AturanPemantauan, err error) {
+	err = t.db.GetContext(ctx, &aturan, "SELECT * FROM aturan_pemantauan WHERE aturan_id = $1", aturanID)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"aturan_id": aturanID,
+			"error":     err.Error(),
+		}).Error("get aturan pemantauan error")
+	}
+	return
+}
+
+func (t *TabunganRepo) UpdateAturan(ctx context.Context, aturan models.AturanPemantauan) (err error) {
+	SQL := `UPDATE aturan_pemantauan SET aktif = :aktif, severity = :severity, ambang = :ambang, jumlah = :jumlah,
+		jendela = :jendela, toleransi = :toleransi, petugas = :petugas, waktu_diubah = :waktu_diubah
+		WHERE aturan_id = :aturan_id`
+	_, err = t.db.NamedExecContext(ctx, SQL, aturan)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"aturan_id": aturan.AturanID,
+			"error":     err.Error(),
+		}).Error("update aturan pemantauan error")
+	}
+	return
+}
+
+// GetMutasiNasabahSejak returns the nasabah's transactions on every rekening
+// from sejak on, oldest first. Mutasi timestamps carry more than
+// models.LayoutWaktu, so only their first 19 characters are compared and
+// sejak has to be given to the second.
+func (t *TabunganRepo) GetMutasiNasabahSejak(ctx context.Context, nik, sejak string) (mutasi []models.Mutasi, err error) {
+	SQL := `SELECT mutasi.* FROM mutasi JOIN rekening ON rekening.no_rekening = mutasi.no_rekening
+		WHERE rekening.nik_index = $1 AND substr(mutasi.waktu, 1, 19) >= $2 ORDER BY mutasi.rowid`
+	err = t.db.SelectContext(ctx, &mutasi, SQL, t.cipher.BlindIndex(nik), sejak)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":   nik,
+			"sejak": sejak,
+			"error": err.Error(),
+		}).Error("query mutasi nasabah error")
+	}
+	return
+}
+
+// GetMutasiSebelumnya returns the transaction on noRekening that came right
+// before transaksiID. found is false for the first transaction.
+func (t *TabunganRepo) GetMutasiSebelumnya(ctx context.Context, noRekening, transaksiID string) (mutasi models.Mutasi, found bool, err error) {
+	SQL := `SELECT * FROM mutasi WHERE no_rekening = $1
+		AND rowid < (SELECT rowid FROM mutasi WHERE transaksi_id = $2)
+		ORDER BY rowid DESC LIMIT 1`
+	err = t.db.GetContext(ctx, &mutasi, SQL, noRekening, transaksiID)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+		return
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"no_rekening":  noRekening,
+			"transaksi_id": transaksiID,
+			"error":        err.Error(),
+		}).Error("query mutasi sebelumnya error")
+		return
+	}
+	found = true
+	return
+}
+
+func (t *TabunganRepo) InsertAlert(ctx context.Context, alert models.AlertTransaksi) (err error) {
+	terkait, err := json.Marshal(alert.TransaksiTerkait)
+	if err == nil {
+		SQL := `INSERT INTO alert_transaksi (alert_id, nik_index, no_rekening, transaksi_id, transaksi_terkait, aturan_id,
+			jenis, severity, keterangan, status, petugas, catatan, waktu_dibuat, waktu_diputus)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, '', '', $11, '')`
+		_, err = t.db.ExecContext(ctx, SQL, alert.AlertID, t.cipher.BlindIndex(alert.NIK), alert.NoRekening, alert.TransaksiID, string(terkait),
+			alert.AturanID, alert.Jenis, alert.Severity, alert.Keterangan, alert.Status, alert.WaktuDibuat)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":          alert.NIK,
+			"aturan_id":    alert.AturanID,
+			"transaksi_id": alert.TransaksiID,
+			"error":        err.Error(),
+		}).Error("insert alert transaksi error")
+	}
+	return
+}
+
+// CountAlertTerbuka counts the pending alerts of aturanID on nik.
+func (t *TabunganRepo) CountAlertTerbuka(ctx context.Context, nik, aturanID string) (jumlah int, err error) {
+	SQL := "SELECT COUNT(*) FROM alert_transaksi WHERE nik_index = $1 AND aturan_id = $2 AND status = $3"
+	err = t.db.GetContext(ctx, &jumlah, SQL, t.cipher.BlindIndex(nik), aturanID, models.AlertPending)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":       nik,
+			"aturan_id": aturanID,
+			"error":     err.Error(),
+		}).Error("count alert transaksi error")
+	}
+	return
+}
+
+// GetDaftarAlert treats empty filter fields as "any". Alerts come back most
+// severe first, oldest first within a severity.
+func (t *TabunganRepo) GetDaftarAlert(ctx context.Context, filter models.FilterAlert) (daftar []models.AlertTransaksi, err error) {
+	var rows []alertRow
+	nikIndex := ""
+	if filter.NIK != "" {
+		nikIndex = t.cipher.BlindIndex(filter.NIK)
+	}
+	SQL := `SELECT * FROM alert_transaksi
+		WHERE ($1 = '' OR status = $1)
+		AND ($2 = '' OR severity = $2)
+		AND ($3 = '' OR aturan_id = $3)
+		AND ($4 = '' OR nik_index = $4)
+		ORDER BY CASE severity WHEN $5 THEN 0 WHEN $6 THEN 1 ELSE 2 END, waktu_dibuat, rowid`
+	err = t.db.SelectContext(ctx, &rows, SQL, filter.Status, filter.Severity, filter.AturanID, nikIndex,
+		models.SeverityTinggi, models.SeveritySedang)
+	for _, row := range rows {
+		var alert models.AlertTransaksi
+		if alert, err = t.bukaAlert(ctx, row); err != nil {
+			break
+		}
+		daftar = append(daftar, alert)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"status":    filter.Status,
+			"severity":  filter.Severity,
+			"aturan_id": filter.AturanID,
+			"nik":       filter.NIK,
+			"error":     err.Error(),
+		}).Error("query daftar alert error")
+	}
+	return
+}
+
+func (t *TabunganRepo) GetAlert(ctx context.Context, alertID string) (alert models.AlertTransaksi, err error) {
+	var row alertRow
+	err = t.db.GetContext(ctx, &row, "SELECT * FROM alert_transaksi WHERE alert_id = $1", alertID)
+	if err == nil {
+		alert, err = t.bukaAlert(ctx, row)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"alert_id": alertID,
+			"error":    err.Error(),
+		}).Error("get alert transaksi error")
+	}
+	return
+}
+
+func (t *TabunganRepo) bukaAlert(ctx context.Context, row alertRow) (alert models.AlertTransaksi, err error) {
+	var nasabah nasabahRow
+	err = t.db.GetContext(ctx, &nasabah, "SELECT * FROM nasabah WHERE nik_index = $1", row.NIKIndex)
+	if err != nil {
+		return
+	}
+	if err = t.dekripsiRow(&nasabah); err != nil {
+		return
+	}
+	alert = row.AlertTransaksi
+	alert.NIK = nasabah.NIK
+	err = json.Unmarshal([]byte(row.TransaksiTerkait), &alert.TransaksiTerkait)
+	return
+}
+
+func (t *TabunganRepo) UpdateStatusAlert(ctx context.Context, alertID, statusSebelum string, alert models.AlertTransaksi) (updated bool, err error) {
+	SQL := `UPDATE alert_transaksi SET status = $1, petugas = $2, catatan = $3, waktu_diputus = $4
+		WHERE alert_id = $5 AND status = $6`
+	result, err := t.db.ExecContext(ctx, SQL, alert.Status, alert.Petugas, alert.Catatan, alert.WaktuDiputus, alertID, statusSebelum)
+	if err == nil {
+		var affected int64
+		affected, err = result.RowsAffected()
+		updated = affected > 0
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"alert_id": alertID,
+			"status":   alert.Status,
+			"error":    err.Error(),
+		}).Error("update status alert error")
+	}
+	return
+}
@@ -1,32 +1,108 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"tabungan-api/encryption"
 	"tabungan-api/models"
+	"time"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 	"github.com/sirupsen/logrus"
 )
 
 type TabunganRepoInterface interface {
-	StartTransaction() (tx *sqlx.Tx, err error)
-	InsertNasabah(tx *sqlx.Tx, nasabah models.Nasabah) (err error)
-	GetNasabah(nik string) (nasabah models.Nasabah, err error)
-	UpdateNasabah(nasabah models.RequestUpdateNasabah) (err error)
-	SaveFoto(nik string, fotoID string) (err error)
-	SaveDokumen(nik string, dokumenID string) (err error)
-	InsertRekening(tx *sqlx.Tx, rekening models.Rekening) (err error)
-	GetDaftarRekening(nik string) (rekening []string, err error)
-	GetRekening(nik, noRekening string) (rekening models.Rekening, err error)
-	InsertMutasi(tx *sqlx.Tx, mutasi models.Mutasi) (err error)
-	GetMutasi(noRekening string, limit, offset int) (mutasi []models.Mutasi, err error)
-	UpdateSaldo(tx *sqlx.Tx, noRekening string, nominal float64) (err error)
+	Ping(ctx context.Context) (err error)
+	StartTransaction(ctx context.Context) (tx *sqlx.Tx, err error)
+	InsertNasabah(ctx context.Context, tx *sqlx.Tx, nasabah models.Nasabah) (err error)
+	GetNasabah(ctx context.Context, nik string) (nasabah models.Nasabah, err error)
+	UpdateNasabah(ctx context.Context, nasabah models.RequestUpdateNasabah) (err error)
+	SaveFoto(ctx context.Context, nik string, fotoID string) (err error)
+	SaveDokumen(ctx context.Context, nik string, dokumenID string) (err error)
+	SaveFotoDokumen(ctx context.Context, nik, fotoID, dokumenID string) (err error)
+	InsertRekening(ctx context.Context, tx *sqlx.Tx, rekening models.Rekening) (err error)
+	GetDaftarRekening(ctx context.Context, nik string) (rekening []string, err error)
+	GetRekening(ctx context.Context, nik, noRekening string) (rekening models.Rekening, err error)
+	GetRekeningByNomor(ctx context.Context, noRekening string) (rekening models.Rekening, err error)
+	InsertMutasi(ctx context.Context, tx *sqlx.Tx, mutasi models.Mutasi) (err error)
+	GetMutasi(ctx context.Context, noRekening string, limit, offset int) (mutasi []models.Mutasi, err error)
+	UpdateSaldo(ctx context.Context, tx *sqlx.Tx, noRekening string, nominal float64) (err error)
+	GetTransaksi(ctx context.Context, transaksiID string) (mutasi models.Mutasi, err error)
+	InsertOperasi(ctx context.Context, operasi models.Operasi) (err error)
+	GetOperasi(ctx context.Context, operasiID string) (operasi models.Operasi, err error)
+	GetDaftarOperasi(ctx context.Context, status string) (operasi []models.Operasi, err error)
+	CountOperasiAktif(ctx context.Context, jenisOperasi, target string) (jumlah int, err error)
+	UpdateStatusOperasi(ctx context.Context, operasiID, statusAwal string, operasi models.Operasi) (updated bool, err error)
+	InsertRiwayatOperasi(ctx context.Context, riwayat models.RiwayatOperasi) (err error)
+	GetRiwayatOperasi(ctx context.Context, operasiID string) (riwayat []models.RiwayatOperasi, err error)
+	InsertAudit(ctx context.Context, audit models.Audit) (err error)
+	GetDaftarAudit(ctx context.Context, filter models.FilterAudit) (audit []models.Audit, err error)
+	GetRiwayatNasabah(ctx context.Context, nik string) (riwayat []models.VersiNasabah, err error)
+	GetNasabahPerWaktu(ctx context.Context, nik, waktu string) (nasabah models.VersiNasabah, err error)
+	GetRiwayatFile(ctx context.Context, nik, jenis string) (file []models.FileNasabah, err error)
+	FileTerdaftar(ctx context.Context, jenis, fileID string) (terdaftar bool, err error)
+	UpdateStatusKYC(ctx context.Context, nik, statusSebelum string, kyc models.KYC) (updated bool, err error)
+	GetKYC(ctx context.Context, nik string) (kyc models.KYC, err error)
+	GetRiwayatKYC(ctx context.Context, nik string) (riwayat []models.KYC, err error)
+	GetDaftarKYC(ctx context.Context, status string) (daftar []models.KYC, err error)
+	UpdateHashFoto(ctx context.Context, nik, hash string) (err error)
+	GetKandidatDuplikat(ctx context.Context, nasabah models.Nasabah) (kandidat []models.Nasabah, err error)
+	GetFotoSerupa(ctx context.Context, nik, hash string, jarakMaks int) (serupa map[string]int, err error)
+	InsertDuplikat(ctx context.Context, duplikat models.Duplikat) (inserted bool, err error)
+	GetDaftarDuplikat(ctx context.Context, status, nik string) (daftar []models.Duplikat, err error)
+	GetDuplikat(ctx context.Context, duplikatID string) (duplikat models.Duplikat, err error)
+	UpdateStatusDuplikat(ctx context.Context, duplikatID, statusSebelum string, duplikat models.Duplikat) (updated bool, err error)
+	CountDuplikatTerbuka(ctx context.Context, nik string) (jumlah int, err error)
+	InsertHasilScreening(ctx context.Context, hasil models.HasilScreening) (inserted bool, err error)
+	GetDaftarScreening(ctx context.Context, status, nik string) (daftar []models.HasilScreening, err error)
+	GetHasilScreening(ctx context.Context, screeningID string) (hasil models.HasilScreening, err error)
+	UpdateStatusScreening(ctx context.Context, screeningID, statusSebelum string, hasil models.HasilScreening) (updated bool, err error)
+	CountScreeningTerbuka(ctx context.Context, nik string) (blokir, pending int, err error)
+	GetDaftarNasabah(ctx context.Context, limit, offset int) (daftar []models.Nasabah, err error)
+	GetDaftarAturan(ctx context.Context) (aturan []models.AturanPemantauan, err error)
+	GetAturan(ctx context.Context, aturanID string) (aturan models.AturanPemantauan, err error)
+	UpdateAturan(ctx context.Context, aturan models.AturanPemantauan) (err error)
+	GetMutasiNasabahSejak(ctx context.Context, nik, sejak string) (mutasi []models.Mutasi, err error)
+	GetMutasiSebelumnya(ctx context.Context, noRekening, transaksiID string) (mutasi models.Mutasi, found bool, err error)
+	InsertAlert(ctx context.Context, alert models.AlertTransaksi) (err error)
+	CountAlertTerbuka(ctx context.Context, nik, aturanID string) (jumlah int, err error)
+	GetDaftarAlert(ctx context.Context, filter models.FilterAlert) (daftar []models.AlertTransaksi, err error)
+	GetAlert(ctx context.Context, alertID string) (alert models.AlertTransaksi, err error)
+	UpdateStatusAlert(ctx context.Context, alertID, statusSebelum string, alert models.AlertTransaksi) (updated bool, err error)
+	GetTransaksiTunaiHarian(ctx context.Context, dari, sampai string, ambang float64) (daftar []models.TransaksiTunaiHarian, err error)
+	GetVersiLaporanTunai(ctx context.Context, dari, sampai string) (versi int, err error)
+	InsertLaporanTunai(ctx context.Context, laporan models.LaporanTunai) (err error)
+	GetDaftarLaporanTunai(ctx context.Context, status, dari, sampai string) (daftar []models.LaporanTunai, err error)
+	GetLaporanTunai(ctx context.Context, laporanID string) (laporan models.LaporanTunai, err error)
+	UpdateStatusLaporanTunai(ctx context.Context, laporanID, statusSebelum string, laporan models.LaporanTunai) (updated bool, err error)
+	InsertOutbox(ctx context.Context, tx *sqlx.Tx, event models.Outbox) (err error)
+	GetOutboxTertunda(ctx context.Context, limit int) (daftar []models.Outbox, err error)
+	TandaiOutboxTerkirim(ctx context.Context, eventID, waktu string) (err error)
+	CatatGagalOutbox(ctx context.Context, eventID, pesan string) (err error)
+	InsertLanggananWebhook(ctx context.Context, langganan models.LanggananWebhook) (err error)
+	UpdateLanggananWebhook(ctx context.Context, langganan models.LanggananWebhook) (err error)
+	GetLanggananWebhook(ctx context.Context, langgananID string) (langganan models.LanggananWebhook, err error)
+	GetDaftarLanggananWebhook(ctx context.Context) (daftar []models.LanggananWebhook, err error)
+	AntreWebhook(ctx context.Context, pengiriman models.PengirimanWebhook) (err error)
+	GetWebhookSiapKirim(ctx context.Context, sekarang string, limit int) (daftar []models.PengirimanWebhook, err error)
+	UpdatePengirimanWebhook(ctx context.Context, pengiriman models.PengirimanWebhook) (err error)
+	GetPengirimanWebhook(ctx context.Context, pengirimanID string) (pengiriman models.PengirimanWebhook, err error)
+	GetDaftarPengirimanWebhook(ctx context.Context, langgananID, status string) (daftar []models.PengirimanWebhook, err error)
+	UlangiPengirimanWebhook(ctx context.Context, pengirimanID, langgananID, status, sekarang string) (jumlah int, err error)
+	SetPreferensiNotifikasi(ctx context.Context, preferensi models.PreferensiNotifikasi) (err error)
+	GetPreferensiNotifikasi(ctx context.Context, nik string) (preferensi models.PreferensiNotifikasi, found bool, err error)
+	InsertNotifikasi(ctx context.Context, nik string, notifikasi models.Notifikasi) (err error)
+	GetNotifikasiSiapKirim(ctx context.Context, sekarang string, limit int) (daftar []models.Notifikasi, err error)
+	UpdateNotifikasi(ctx context.Context, notifikasi models.Notifikasi) (err error)
+	GetDaftarNotifikasi(ctx context.Context, nik string) (daftar []models.Notifikasi, err error)
 }
 
 type TabunganRepo struct {
-	db  *sqlx.DB
-	log *logrus.Logger
+	db     *sqlx.DB
+	cipher *encryption.Cipher
+	log    *logrus.Logger
 }
 
 func (t *TabunganRepo) initDatabase() {
@@ -41,12 +117,137 @@ func (t *TabunganRepo) initDatabase() {
 		dokumen_id text);`
 	t.db.MustExec(SQL)
 
-	SQL = `CREATE TABLE IF NOT EXISTS rekening (
+	SQL = `CREATE TABLE IF NOT EXISTS nasabah_versi (
 		nik text,
+		versi integer,
+		berlaku_sejak text,
+		nama text,
+		alamat_ktp text,
+		alamat_domisili text,
+		jenis_kelamin text,
+		tanggal_lahir text,
+		foto_id text,
+		dokumen_id text,
+		PRIMARY KEY (nik, versi));`
+	t.db.MustExec(SQL)
+
+	t.migrateEnkripsi()
+
+	SQL = `CREATE TABLE IF NOT EXISTS riwayat_file (
+		file_id text,
+		jenis text,
+		nik_index text,
+		waktu_unggah text,
+		PRIMARY KEY (jenis, file_id));`
+	t.db.MustExec(SQL)
+
+	t.db.MustExec("CREATE INDEX IF NOT EXISTS riwayat_file_nik_index ON riwayat_file (nik_index)")
+
+	// Nasabah registered before versioning existed get a first version that
+	// has been valid since forever.
+	SQL = `INSERT INTO nasabah_versi (nik, versi, berlaku_sejak, nama, alamat_ktp, alamat_domisili,
+		jenis_kelamin, tanggal_lahir, foto_id, dokumen_id, nik_index, kunci_id, kunci_data)
+		SELECT nik, 1, '', nama, alamat_ktp, alamat_domisili, jenis_kelamin, tanggal_lahir,
+		foto_id, dokumen_id, nik_index, kunci_id, kunci_data
+		FROM nasabah WHERE nik_index NOT IN (SELECT nik_index FROM nasabah_versi WHERE nik_index IS NOT NULL);`
+	t.db.MustExec(SQL)
+
+	// Every file a nasabah version ever pointed to belongs in the file history,
+	// otherwise the orphan cleanup would delete it.
+	SQL = `INSERT OR IGNORE INTO riwayat_file
+		SELECT foto_id, 'foto', nik_index, MIN(berlaku_sejak) FROM nasabah_versi
+		WHERE foto_id IS NOT NULL AND foto_id != '' GROUP BY foto_id, nik_index
+		UNION ALL
+		SELECT dokumen_id, 'dokumen', nik_index, MIN(berlaku_sejak) FROM nasabah_versi
+		WHERE dokumen_id IS NOT NULL AND dokumen_id != '' GROUP BY dokumen_id, nik_index;`
+	t.db.MustExec(SQL)
+
+	SQL = `CREATE TABLE IF NOT EXISTS kyc (
+		nik_index text PRIMARY KEY,
+		status text,
+		dokumen_id text,
+		alasan text,
+		petugas text,
+		waktu text);`
+	t.db.MustExec(SQL)
+
+	SQL = `CREATE TABLE IF NOT EXISTS riwayat_kyc (
+		nik_index text,
+		status text,
+		dokumen_id text,
+		alasan text,
+		petugas text,
+		waktu text);`
+	t.db.MustExec(SQL)
+
+	t.db.MustExec("CREATE INDEX IF NOT EXISTS riwayat_kyc_nik_index ON riwayat_kyc (nik_index)")
+
+	t.migrateKYC()
+
+	SQL = `CREATE TABLE IF NOT EXISTS sidik_nasabah (
+		nik_index text PRIMARY KEY,
+		tanggal_lahir_index text,
+		foto_hash text);`
+	t.db.MustExec(SQL)
+
+	t.db.MustExec("CREATE INDEX IF NOT EXISTS sidik_nasabah_tanggal_lahir ON sidik_nasabah (tanggal_lahir_index)")
+
+	t.tambahKolom("sidik_nasabah", "versi")
+
+	SQL = `CREATE TABLE IF NOT EXISTS blok_alamat (
+		kunci_index text,
+		nik_index text,
+		PRIMARY KEY (kunci_index, nik_index));`
+	t.db.MustExec(SQL)
+
+	t.db.MustExec("CREATE INDEX IF NOT EXISTS blok_alamat_nik_index ON blok_alamat (nik_index)")
+
+	t.migrateSidik()
+
+	SQL = `CREATE TABLE IF NOT EXISTS duplikat (
+		duplikat_id text PRIMARY KEY,
+		nik_index text,
+		nik_index_lain text,
+		jenis text,
+		skor real,
+		status text,
+		petugas text,
+		catatan text,
+		waktu_dibuat text,
+		waktu_diputus text);`
+	t.db.MustExec(SQL)
+
+	t.db.MustExec("CREATE INDEX IF NOT EXISTS duplikat_nik_index ON duplikat (nik_index)")
+	t.db.MustExec("CREATE INDEX IF NOT EXISTS duplikat_nik_index_lain ON duplikat (nik_index_lain)")
+
+	SQL = `CREATE TABLE IF NOT EXISTS hasil_screening (
+		screening_id text PRIMARY KEY,
+		nik_index text,
+		nama_index text,
+		entri_id text,
+		nama_entri text,
+		sumber text,
+		skor real,
+		tindakan text,
+		pemicu text,
+		versi_daftar text,
+		status text,
+		petugas text,
+		catatan text,
+		waktu_dibuat text,
+		waktu_diputus text);`
+	t.db.MustExec(SQL)
+
+	t.db.MustExec("CREATE INDEX IF NOT EXISTS hasil_screening_nik_index ON hasil_screening (nik_index)")
+
+	SQL = `CREATE TABLE IF NOT EXISTS rekening (
+		nik_index text,
 		no_rekening text PRIMARY KEY,
 		saldo real);`
 	t.db.MustExec(SQL)
 
+	t.migrateRekening()
+
 	SQL = `CREATE TABLE IF NOT EXISTS mutasi (
 		transaksi_id text PRIMARY KEY,
 		waktu text,
@@ -56,21 +257,233 @@ func (t *TabunganRepo) initDatabase() {
 		saldo_awal real,
 		saldo_akhir text);`
 	t.db.MustExec(SQL)
+
+	t.db.MustExec("CREATE INDEX IF NOT EXISTS mutasi_no_rekening ON mutasi (no_rekening)")
+
+	SQL = `CREATE TABLE IF NOT EXISTS aturan_pemantauan (
+		aturan_id text PRIMARY KEY,
+		jenis text,
+		aktif boolean,
+		severity text,
+		ambang real,
+		jumlah integer,
+		jendela text,
+		toleransi real,
+		petugas text,
+		waktu_diubah text);`
+	t.db.MustExec(SQL)
+
+	// Default rules; once created they are only changed through the admin API.
+	SQL = `INSERT OR IGNORE INTO aturan_pemantauan VALUES
+		('velocity', 'velocity', true, 'medium', 0, 10, '1h', 0, '', $1),
+		('structuring', 'structuring', true, 'high', 500000000, 3, '168h', 0.1, '', $1),
+		('setoran_tunai_besar', 'setoran_tunai_besar', true, 'medium', 100000000, 0, '', 0, '', $1),
+		('reaktivasi_dormant', 'reaktivasi_dormant', true, 'medium', 0, 0, '4320h', 0, '', $1);`
+	t.db.MustExec(SQL, time.Now().Format(models.LayoutWaktu))
+
+	SQL = `CREATE TABLE IF NOT EXISTS alert_transaksi (
+		alert_id text PRIMARY KEY,
+		nik_index text,
+		no_rekening text,
+		transaksi_id text,
+		transaksi_terkait text,
+		aturan_id text,
+		jenis text,
+		severity text,
+		keterangan text,
+		status text,
+		petugas text,
+		catatan text,
+		waktu_dibuat text,
+		waktu_diputus text);`
+	t.db.MustExec(SQL)
+
+	t.db.MustExec("CREATE INDEX IF NOT EXISTS alert_transaksi_nik_index ON alert_transaksi (nik_index)")
+
+	SQL = `CREATE TABLE IF NOT EXISTS laporan_tunai (
+		laporan_id text PRIMARY KEY,
+		periode_dari text,
+		periode_sampai text,
+		versi integer,
+		ambang real,
+		jumlah_baris integer,
+		total_nominal real,
+		file_id text,
+		checksum text,
+		status text,
+		referensi text,
+		catatan text,
+		pembuat text,
+		petugas text,
+		waktu_dibuat text,
+		waktu_diubah text);`
+	t.db.MustExec(SQL)
+
+	t.db.MustExec("CREATE UNIQUE INDEX IF NOT EXISTS laporan_tunai_versi ON laporan_tunai (periode_dari, periode_sampai, versi)")
+
+	SQL = `CREATE TABLE IF NOT EXISTS outbox (
+		event_id text PRIMARY KEY,
+		jenis text,
+		aggregate_id text,
+		payload text,
+		waktu_dibuat text,
+		waktu_terkirim text,
+		percobaan integer,
+		error_terakhir text);`
+	t.db.MustExec(SQL)
+
+	t.db.MustExec("CREATE INDEX IF NOT EXISTS outbox_tertunda ON outbox (waktu_terkirim)")
+	t.migrateOutbox()
+
+	SQL = `CREATE TABLE IF NOT EXISTS langganan_webhook (
+		langganan_id text PRIMARY KEY,
+		partner text,
+		url text,
+		rahasia text,
+		kunci_id text,
+		kunci_data text,
+		jenis_event text,
+		no_rekening text,
+		aktif boolean,
+		petugas text,
+		waktu_dibuat text,
+		waktu_diubah text);`
+	t.db.MustExec(SQL)
+
+	SQL = `CREATE TABLE IF NOT EXISTS pengiriman_webhook (
+		pengiriman_id text PRIMARY KEY,
+		langganan_id text,
+		event_id text,
+		jenis_event text,
+		payload text,
+		status text,
+		percobaan integer,
+		kirim_berikutnya text,
+		status_http integer,
+		error_terakhir text,
+		waktu_dibuat text,
+		waktu_terkirim text,
+		UNIQUE (langganan_id, event_id));`
+	t.db.MustExec(SQL)
+
+	t.db.MustExec("CREATE INDEX IF NOT EXISTS pengiriman_webhook_antrean ON pengiriman_webhook (status, kirim_berikutnya)")
+
+	SQL = `CREATE TABLE IF NOT EXISTS preferensi_notifikasi (
+		nik_index text PRIMARY KEY,
+		bahasa text,
+		email text,
+		no_hp text,
+		kunci_id text,
+		kunci_data text,
+		kanal text,
+		transaksi boolean,
+		perubahan_profil boolean,
+		waktu_diubah text);`
+	t.db.MustExec(SQL)
+
+	SQL = `CREATE TABLE IF NOT EXISTS notifikasi (
+		notifikasi_id text PRIMARY KEY,
+		nik_index text,
+		jenis text,
+		kanal text,
+		tujuan text,
+		subjek text,
+		isi text,
+		kunci_id text,
+		kunci_data text,
+		status text,
+		percobaan integer,
+		kirim_berikutnya text,
+		error_terakhir text,
+		waktu_dibuat text,
+		waktu_terkirim text);`
+	t.db.MustExec(SQL)
+
+	t.db.MustExec("CREATE INDEX IF NOT EXISTS notifikasi_antrean ON notifikasi (status, kirim_berikutnya)")
+	t.db.MustExec("CREATE INDEX IF NOT EXISTS notifikasi_nasabah ON notifikasi (nik_index)")
+
+	SQL = `CREATE TABLE IF NOT EXISTS operasi (
+		operasi_id text PRIMARY KEY,
+		jenis_operasi text,
+		target text,
+		payload text,
+		status text,
+		maker text,
+		checker text,
+		remark text,
+		waktu_dibuat text,
+		waktu_diputus text,
+		target_index text,
+		kunci_id text,
+		kunci_data text);`
+	t.db.MustExec(SQL)
+
+	t.migrateOperasi()
+
+	SQL = `CREATE TABLE IF NOT EXISTS riwayat_operasi (
+		operasi_id text,
+		waktu text,
+		status text,
+		petugas text,
+		remark text);`
+	t.db.MustExec(SQL)
+
+	SQL = `CREATE TABLE IF NOT EXISTS audit (
+		audit_id text PRIMARY KEY,
+		waktu text,
+		aktor text,
+		role text,
+		ip text,
+		user_agent text,
+		request_id text,
+		aksi text,
+		target text,
+		perubahan text,
+		aktor_index text,
+		target_index text,
+		kunci_id text,
+		kunci_data text);`
+	t.db.MustExec(SQL)
+
+	t.migrateAudit()
 }
 
-func (t *TabunganRepo) StartTransaction() (tx *sqlx.Tx, err error) {
-	tx, err = t.db.Beginx()
+// Ping checks that the database still answers queries.
+func (t *TabunganRepo) Ping(ctx context.Context) (err error) {
+	var satu int
+	err = t.db.GetContext(ctx, &satu, "SELECT 1")
 	if err != nil {
-		t.log.WithField("error", err.Error()).Error("begin transaction error")
+		t.log.WithContext(ctx).WithField("error", err.Error()).Error("ping database error")
 	}
 	return
 }
 
-func (t *TabunganRepo) InsertNasabah(tx *sqlx.Tx, nasabah models.Nasabah) (err error) {
-	SQL := "INSERT INTO nasabah VALUES (:nik, :nama, :alamat_ktp, :alamat_domisili, :jenis_kelamin, :tanggal_lahir, :foto_id, :dokumen_id)"
-	_, err = tx.NamedExec(SQL, nasabah)
+// Close closes the database once every query in flight has finished.
+func (t *TabunganRepo) Close() (err error) {
+	err = t.db.Close()
 	if err != nil {
-		t.log.WithFields(logrus.Fields{
+		t.log.WithField("error", err.Error()).Error("close database error")
+	}
+	return
+}
+
+func (t *TabunganRepo) StartTransaction(ctx context.Context) (tx *sqlx.Tx, err error) {
+	tx, err = t.db.BeginTxx(ctx, nil)
+	if err != nil {
+		t.log.WithContext(ctx).WithField("error", err.Error()).Error("begin transaction error")
+	}
+	return
+}
+
+func (t *TabunganRepo) InsertNasabah(ctx context.Context, tx *sqlx.Tx, nasabah models.Nasabah) (err error) {
+	SQL := `INSERT INTO nasabah (nik, nama, alamat_ktp, alamat_domisili, jenis_kelamin, tanggal_lahir, foto_id, dokumen_id, nik_index, kunci_id, kunci_data)
+		VALUES (:nik, :nama, :alamat_ktp, :alamat_domisili, :jenis_kelamin, :tanggal_lahir, :foto_id, :dokumen_id, :nik_index, :kunci_id, :kunci_data)`
+	row, err := t.enkripsiNasabah(nasabah)
+	if err == nil {
+		_, err = tx.NamedExecContext(ctx, SQL, row)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"nik":             nasabah.NIK,
 			"nama":            nasabah.Nama,
 			"alamat_ktp":      nasabah.AlamatKTP,
@@ -79,15 +492,30 @@ func (t *TabunganRepo) InsertNasabah(tx *sqlx.Tx, nasabah models.Nasabah) (err e
 			"tanggal_lahir":   nasabah.TanggalLahir,
 			"error":           err.Error(),
 		}).Error("insert data nasabah error")
+		return
+	}
+	err = t.setSidik(ctx, tx, nasabah)
+	if err == nil {
+		err = t.setKYC(ctx, tx, nasabah.NIK, models.KYC{
+			Status: models.KYCPending,
+			Waktu:  time.Now().Format(models.LayoutWaktu),
+		})
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":   nasabah.NIK,
+			"error": err.Error(),
+		}).Error("insert sidik dan kyc nasabah error")
+		return
 	}
+	err = t.snapshotNasabah(ctx, tx, nasabah.NIK)
 	return
 }
 
-func (t *TabunganRepo) GetNasabah(nik string) (nasabah models.Nasabah, err error) {
-	SQL := "SELECT * FROM nasabah WHERE nik = $1"
-	err = t.db.Get(&nasabah, SQL, nik)
+func (t *TabunganRepo) GetNasabah(ctx context.Context, nik string) (nasabah models.Nasabah, err error) {
+	nasabah, err = t.getNasabahByIndex(ctx, t.cipher.BlindIndex(nik))
 	if err != nil {
-		t.log.WithFields(logrus.Fields{
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"nik":   nik,
 			"error": err.Error(),
 		}).Error("get nasabah error")
@@ -95,11 +523,41 @@ func (t *TabunganRepo) GetNasabah(nik string) (nasabah models.Nasabah, err error
 	return
 }
 
-func (t *TabunganRepo) UpdateNasabah(nasabah models.RequestUpdateNasabah) (err error) {
-	SQL := "UPDATE nasabah SET nama = :nama, alamat_ktp = :alamat_ktp, alamat_domisili = :alamat_domisili WHERE nik = :nik"
-	_, err = t.db.NamedExec(SQL, nasabah)
+func (t *TabunganRepo) getNasabahByIndex(ctx context.Context, nikIndex string) (nasabah models.Nasabah, err error) {
+	var row nasabahRow
+	err = t.db.GetContext(ctx, &row, "SELECT * FROM nasabah WHERE nik_index = $1", nikIndex)
+	if err == nil {
+		nasabah, err = t.dekripsiNasabah(row)
+	}
+	return
+}
+
+func (t *TabunganRepo) UpdateNasabah(ctx context.Context, nasabah models.RequestUpdateNasabah) (err error) {
+	SQL := `UPDATE nasabah SET nik = :nik, nama = :nama, alamat_ktp = :alamat_ktp, alamat_domisili = :alamat_domisili,
+		tanggal_lahir = :tanggal_lahir, kunci_id = :kunci_id, kunci_data = :kunci_data WHERE nik_index = :nik_index`
+	err = t.updateNasabahVersi(ctx, nasabah.NIK, func(tx *sqlx.Tx) (err error) {
+		var row nasabahRow
+		err = tx.GetContext(ctx, &row, "SELECT * FROM nasabah WHERE nik_index = $1", t.cipher.BlindIndex(nasabah.NIK))
+		if err != nil {
+			return
+		}
+		if err = t.dekripsiRow(&row); err != nil {
+			return
+		}
+		row.Nama = nasabah.Nama
+		row.AlamatKTP = nasabah.AlamatKTP
+		row.AlamatDomisili = nasabah.AlamatDomisili
+		if err = t.setBlokAlamat(ctx, tx, row.NIK, row.AlamatKTP, row.AlamatDomisili); err != nil {
+			return
+		}
+		if err = t.enkripsiRow(&row); err != nil {
+			return
+		}
+		_, err = tx.NamedExecContext(ctx, SQL, row)
+		return
+	})
 	if err != nil {
-		t.log.WithFields(logrus.Fields{
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"nik":             nasabah.NIK,
 			"nama":            nasabah.Nama,
 			"alamat_ktp":      nasabah.AlamatKTP,
@@ -110,11 +568,12 @@ func (t *TabunganRepo) UpdateNasabah(nasabah models.RequestUpdateNasabah) (err e
 	return
 }
 
-func (t *TabunganRepo) SaveFoto(nik string, fotoID string) (err error) {
-	SQL := "UPDATE nasabah SET foto_id = $1 WHERE nik = $2"
-	_, err = t.db.Exec(SQL, fotoID, nik)
+func (t *TabunganRepo) SaveFoto(ctx context.Context, nik string, fotoID string) (err error) {
+	err = t.updateNasabahVersi(ctx, nik, func(tx *sqlx.Tx) (err error) {
+		return t.gantiFoto(ctx, tx, nik, fotoID)
+	})
 	if err != nil {
-		t.log.WithFields(logrus.Fields{
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"nik":     nik,
 			"foto_id": fotoID,
 			"error":   err.Error(),
@@ -123,12 +582,13 @@ func (t *TabunganRepo) SaveFoto(nik string, fotoID string) (err error) {
 	return
 }
 
-func (t *TabunganRepo) SaveDokumen(nik string, dokumenID string) (err error) {
-	SQL := "UPDATE nasabah SET dokumen_id = $1 WHERE nik = $2"
-	_, err = t.db.Exec(SQL, dokumenID, nik)
+func (t *TabunganRepo) SaveDokumen(ctx context.Context, nik string, dokumenID string) (err error) {
+	err = t.updateNasabahVersi(ctx, nik, func(tx *sqlx.Tx) (err error) {
+		return t.gantiDokumen(ctx, tx, nik, dokumenID)
+	})
 	if err != nil {
 		err = fmt.Errorf("update nasabah dokumen_id error")
-		t.log.WithFields(logrus.Fields{
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"nik":        nik,
 			"dokumen_id": dokumenID,
 		}).Error(err.Error())
@@ -136,11 +596,57 @@ func (t *TabunganRepo) SaveDokumen(nik string, dokumenID string) (err error) {
 	return
 }
 
-func (t *TabunganRepo) InsertRekening(tx *sqlx.Tx, rekening models.Rekening) (err error) {
-	SQL := "INSERT INTO rekening VALUES (:nik, :no_rekening, :saldo)"
-	_, err = tx.NamedExec(SQL, rekening)
+// SaveFotoDokumen replaces the photo and the document of a nasabah in one
+// transaction, so either both are kept or neither is.
+func (t *TabunganRepo) SaveFotoDokumen(ctx context.Context, nik, fotoID, dokumenID string) (err error) {
+	err = t.updateNasabahVersi(ctx, nik, func(tx *sqlx.Tx) (err error) {
+		err = t.gantiFoto(ctx, tx, nik, fotoID)
+		if err != nil {
+			return
+		}
+		return t.gantiDokumen(ctx, tx, nik, dokumenID)
+	})
 	if err != nil {
-		t.log.WithFields(logrus.Fields{
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":        nik,
+			"foto_id":    fotoID,
+			"dokumen_id": dokumenID,
+			"error":      err.Error(),
+		}).Error("update nasabah foto_id and dokumen_id error")
+	}
+	return
+}
+
+func (t *TabunganRepo) gantiFoto(ctx context.Context, tx *sqlx.Tx, nik, fotoID string) (err error) {
+	SQL := "UPDATE nasabah SET foto_id = $1 WHERE nik_index = $2"
+	return t.gantiFile(ctx, tx, SQL, nik, models.FileFoto, fotoID)
+}
+
+func (t *TabunganRepo) gantiDokumen(ctx context.Context, tx *sqlx.Tx, nik, dokumenID string) (err error) {
+	SQL := "UPDATE nasabah SET dokumen_id = $1 WHERE nik_index = $2"
+	err = t.gantiFile(ctx, tx, SQL, nik, models.FileDokumen, dokumenID)
+	if err != nil {
+		return
+	}
+	// A new KTP document always goes back to the review queue, whatever the
+	// outcome of the previous review was.
+	return t.setKYC(ctx, tx, nik, models.KYC{
+		Status:    models.KYCSubmitted,
+		DokumenID: dokumenID,
+		Waktu:     time.Now().Format(models.LayoutWaktu),
+	})
+}
+
+type rekeningRow struct {
+	NIKIndex string `db:"nik_index"`
+	models.Rekening
+}
+
+func (t *TabunganRepo) InsertRekening(ctx context.Context, tx *sqlx.Tx, rekening models.Rekening) (err error) {
+	SQL := "INSERT INTO rekening VALUES (:nik_index, :no_rekening, :saldo)"
+	_, err = tx.NamedExecContext(ctx, SQL, rekeningRow{NIKIndex: t.cipher.BlindIndex(rekening.NIK), Rekening: rekening})
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"nik":         rekening.NIK,
 			"no_rekening": rekening.NoRekening,
 			"saldo":       rekening.Saldo,
@@ -150,11 +656,11 @@ func (t *TabunganRepo) InsertRekening(tx *sqlx.Tx, rekening models.Rekening) (er
 	return
 }
 
-func (t *TabunganRepo) GetDaftarRekening(nik string) (rekening []string, err error) {
-	SQL := "SELECT no_rekening FROM rekening WHERE nik = $1"
-	err = t.db.Select(&rekening, SQL, nik)
+func (t *TabunganRepo) GetDaftarRekening(ctx context.Context, nik string) (rekening []string, err error) {
+	SQL := "SELECT no_rekening FROM rekening WHERE nik_index = $1"
+	err = t.db.SelectContext(ctx, &rekening, SQL, t.cipher.BlindIndex(nik))
 	if err != nil {
-		t.log.WithFields(logrus.Fields{
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"nik":   nik,
 			"error": err.Error(),
 		}).Error("get rekening error")
@@ -162,11 +668,12 @@ func (t *TabunganRepo) GetDaftarRekening(nik string) (rekening []string, err err
 	return
 }
 
-func (t *TabunganRepo) GetRekening(nik, noRekening string) (rekening models.Rekening, err error) {
-	SQL := "SELECT * FROM rekening WHERE nik = $1 AND no_rekening = $2"
-	err = t.db.Get(&rekening, SQL, nik, noRekening)
+func (t *TabunganRepo) GetRekening(ctx context.Context, nik, noRekening string) (rekening models.Rekening, err error) {
+	SQL := "SELECT no_rekening, saldo FROM rekening WHERE nik_index = $1 AND no_rekening = $2"
+	err = t.db.GetContext(ctx, &rekening, SQL, t.cipher.BlindIndex(nik), noRekening)
+	rekening.NIK = nik
 	if err != nil {
-		t.log.WithFields(logrus.Fields{
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"nik":         nik,
 			"no_rekening": noRekening,
 			"error":       err.Error(),
@@ -175,11 +682,32 @@ func (t *TabunganRepo) GetRekening(nik, noRekening string) (rekening models.Reke
 	return
 }
 
-func (t *TabunganRepo) InsertMutasi(tx *sqlx.Tx, mutasi models.Mutasi) (err error) {
+// GetRekeningByNomor recovers the owner's NIK from the nasabah row, since
+// rekening only keeps its blind index.
+func (t *TabunganRepo) GetRekeningByNomor(ctx context.Context, noRekening string) (rekening models.Rekening, err error) {
+	var row rekeningRow
+	SQL := "SELECT * FROM rekening WHERE no_rekening = $1"
+	err = t.db.GetContext(ctx, &row, SQL, noRekening)
+	if err == nil {
+		var nasabah models.Nasabah
+		nasabah, err = t.getNasabahByIndex(ctx, row.NIKIndex)
+		rekening = row.Rekening
+		rekening.NIK = nasabah.NIK
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"no_rekening": noRekening,
+			"error":       err.Error(),
+		}).Error("get rekening error")
+	}
+	return
+}
+
+func (t *TabunganRepo) InsertMutasi(ctx context.Context, tx *sqlx.Tx, mutasi models.Mutasi) (err error) {
 	SQL := "INSERT INTO mutasi VALUES (:transaksi_id, :waktu, :jenis_mutasi, :no_rekening, :nominal, :saldo_awal, :saldo_akhir)"
-	_, err = t.db.NamedExec(SQL, mutasi)
+	_, err = tx.NamedExecContext(ctx, SQL, mutasi)
 	if err != nil {
-		t.log.WithFields(logrus.Fields{
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"transaksi_id": mutasi.TransaksiID,
 			"waktu":        mutasi.Waktu,
 			"jenis_mutasi": mutasi.JenisMutasi,
@@ -193,11 +721,11 @@ func (t *TabunganRepo) InsertMutasi(tx *sqlx.Tx, mutasi models.Mutasi) (err erro
 	return
 }
 
-func (t *TabunganRepo) GetMutasi(noRekening string, limit, offset int) (mutasi []models.Mutasi, err error) {
+func (t *TabunganRepo) GetMutasi(ctx context.Context, noRekening string, limit, offset int) (mutasi []models.Mutasi, err error) {
 	SQL := "SELECT * FROM mutasi WHERE no_rekening = $1 LIMIT $2 OFFSET $3"
-	err = t.db.Select(&mutasi, SQL, noRekening, limit, offset)
+	err = t.db.SelectContext(ctx, &mutasi, SQL, noRekening, limit, offset)
 	if err != nil {
-		t.log.WithFields(logrus.Fields{
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"no_rekening": noRekening,
 			"limit":       limit,
 			"offset":      offset,
@@ -207,11 +735,11 @@ func (t *TabunganRepo) GetMutasi(noRekening string, limit, offset int) (mutasi [
 	return
 }
 
-func (t *TabunganRepo) UpdateSaldo(tx *sqlx.Tx, noRekening string, nominal float64) (err error) {
+func (t *TabunganRepo) UpdateSaldo(ctx context.Context, tx *sqlx.Tx, noRekening string, nominal float64) (err error) {
 	SQL := "UPDATE rekening SET saldo = saldo + $1 WHERE no_rekening = $2"
-	_, err = t.db.Exec(SQL, nominal, noRekening)
+	_, err = tx.ExecContext(ctx, SQL, nominal, noRekening)
 	if err != nil {
-		t.log.WithFields(logrus.Fields{
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
 			"no_rekening": noRekening,
 			"error":       err.Error(),
 		}).Error("tarik dana rekening error")
@@ -219,15 +747,16 @@ func (t *TabunganRepo) UpdateSaldo(tx *sqlx.Tx, noRekening string, nominal float
 	return
 }
 
-func InitDatabase(database string, logger *logrus.Logger) (repo *TabunganRepo) {
-	db, err := sqlx.Connect("sqlite3", database)
-	if err != nil {
+func InitDatabase(database string, keys encryption.KeyProvider, logger *logrus.Logger) (repo *TabunganRepo) {
+	db := sqlx.NewDb(sql.OpenDB(connectorUkur{dsn: database, driver: &sqlite3.SQLiteDriver{}}), "sqlite3")
+	if err := db.Ping(); err != nil {
 		panic(err)
 	}
 
 	repo = &TabunganRepo{
-		db:  db,
-		log: logger,
+		db:     db,
+		cipher: encryption.NewCipher(keys),
+		log:    logger,
 	}
 	repo.initDatabase()
 	return
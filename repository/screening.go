@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"tabungan-api/models"
+	"tabungan-api/similarity"
+
+	"github.com/sirupsen/logrus"
+)
+
+type screeningRow struct {
+	NIKIndex  string `db:"nik_index"`
+	NamaIndex string `db:"nama_index"`
+	models.HasilScreening
+}
+
+// indeksNama fingerprints the name a hit was made on, so that a hit already
+// decided is not raised again until the nasabah's name changes.
+func (t *TabunganRepo) indeksNama(nama string) string {
+	return t.cipher.BlindIndex("nama:" + similarity.Normalize(nama))
+}
+
+func (t *TabunganRepo) InsertHasilScreening(ctx context.Context, hasil models.HasilScreening) (inserted bool, err error) {
+	SQL := `INSERT INTO hasil_screening (screening_id, nik_index, nama_index, entri_id, nama_entri, sumber, skor,
+		tindakan, pemicu, versi_daftar, status, petugas, catatan, waktu_dibuat, waktu_diputus)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, '', '', $12, ''
+		WHERE NOT EXISTS (SELECT 1 FROM hasil_screening WHERE nik_index = $2 AND nama_index = $3 AND entri_id = $4)`
+	result, err := t.db.ExecContext(ctx, SQL, hasil.ScreeningID, t.cipher.BlindIndex(hasil.NIK), t.indeksNama(hasil.Nama), hasil.EntriID,
+		hasil.NamaEntri, hasil.Sumber, hasil.Skor, hasil.Tindakan, hasil.Pemicu, hasil.VersiDaftar, hasil.Status, hasil.WaktuDibuat)
+	if err == nil {
+		var affected int64
+		affected, err = result.RowsAffected()
+		inserted = affected > 0
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":      hasil.NIK,
+			"entri_id": hasil.EntriID,
+			"error":    err.Error(),
+		}).Error("insert hasil screening error")
+	}
+	return
+}
+
+// GetDaftarScreening lists hits by status, by nasabah, or both. Empty
+// filters match everything.
+func (t *TabunganRepo) GetDaftarScreening(ctx context.Context, status, nik string) (daftar []models.HasilScreening, err error) {
+	var rows []screeningRow
+	nikIndex := ""
+	if nik != "" {
+		nikIndex = t.cipher.BlindIndex(nik)
+	}
+	SQL := `SELECT * FROM hasil_screening WHERE ($1 = '' OR status = $1) AND ($2 = '' OR nik_index = $2)
+		ORDER BY waktu_dibuat, rowid`
+	err = t.db.SelectContext(ctx, &rows, SQL, status, nikIndex)
+	for _, row := range rows {
+		var hasil models.HasilScreening
+		if hasil, err = t.bukaHasilScreening(ctx, row); err != nil {
+			break
+		}
+		daftar = append(daftar, hasil)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"status": status,
+			"nik":    nik,
+			"error":  err.Error(),
+		}).Error("query daftar screening error")
+	}
+	return
+}
+
+func (t *TabunganRepo) GetHasilScreening(ctx context.Context, screeningID string) (hasil models.HasilScreening, err error) {
+	var row screeningRow
+	err = t.db.GetContext(ctx, &row, "SELECT * FROM hasil_screening WHERE screening_id = $1", screeningID)
+	if err == nil {
+		hasil, err = t.bukaHasilScreening(ctx, row)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"screening_id": screeningID,
+			"error":        err.Error(),
+		}).Error("get hasil screening error")
+	}
+	return
+}
+
+func (t *TabunganRepo) bukaHasilScreening(ctx context.Context, row screeningRow) (hasil models.HasilScreening, err error) {
+	var nasabah nasabahRow
+	err = t.db.GetContext(ctx, &nasabah, "SELECT * FROM nasabah WHERE nik_index = $1", row.NIKIndex)
+	if err != nil {
+		return
+	}
+	if err = t.dekripsiRow(&nasabah); err != nil {
+		return
+	}
+	hasil = row.HasilScreening
+	hasil.NIK = nasabah.NIK
+	hasil.Nama = nasabah.Nama
+	return
+}
+
+func (t *TabunganRepo) UpdateStatusScreening(ctx context.Context, screeningID, statusSebelum string, hasil models.HasilScreening) (updated bool, err error) {
+	SQL := `UPDATE hasil_screening SET status = $1, petugas = $2, catatan = $3, waktu_diputus = $4
+		WHERE screening_id = $5 AND status = $6`
+	result, err := t.db.ExecContext(ctx, SQL, hasil.Status, hasil.Petugas, hasil.Catatan, hasil.WaktuDiputus, screeningID, statusSebelum)
+	if err == nil {
+		var affected int64
+		affected, err = result.RowsAffected()
+		updated = affected > 0
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"screening_id": screeningID,
+			"status":       hasil.Status,
+			"error":        err.Error(),
+		}).Error("update status screening error")
+	}
+	return
+}
+
+// CountScreeningTerbuka counts the hits on nik that freeze it, which are
+// confirmed hits and pending blokir hits, and the hits still awaiting review.
+func (t *TabunganRepo) CountScreeningTerbuka(ctx context.Context, nik string) (blokir, pending int, err error) {
+	SQL := `SELECT
+		COUNT(CASE WHEN status = $1 OR (status = $2 AND tindakan = $3) THEN 1 END) AS blokir,
+		COUNT(CASE WHEN status = $2 THEN 1 END) AS pending
+		FROM hasil_screening WHERE nik_index = $4`
+	row := t.db.QueryRowxContext(ctx, SQL, models.ScreeningTerkonfirmasi, models.ScreeningPending, models.ScreeningBlokir, t.cipher.BlindIndex(nik))
+	err = row.Scan(&blokir, &pending)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"nik":   nik,
+			"error": err.Error(),
+		}).Error("count screening error")
+	}
+	return
+}
+
+// GetDaftarNasabah pages through every nasabah in registration order.
+func (t *TabunganRepo) GetDaftarNasabah(ctx context.Context, limit, offset int) (daftar []models.Nasabah, err error) {
+	var rows []nasabahRow
+	err = t.db.SelectContext(ctx, &rows, "SELECT * FROM nasabah ORDER BY rowid LIMIT $1 OFFSET $2", limit, offset)
+	for _, row := range rows {
+		var nasabah models.Nasabah
+		if nasabah, err = t.dekripsiNasabah(row); err != nil {
+			break
+		}
+		daftar = append(daftar, nasabah)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"limit":  limit,
+			"offset": offset,
+			"error":  err.Error(),
+		}).Error("query daftar nasabah error")
+	}
+	return
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"tabungan-api/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+type langgananRow struct {
+	Rahasia    string `db:"rahasia"`
+	KunciID    string `db:"kunci_id"`
+	KunciData  string `db:"kunci_data"`
+	JenisEvent string `db:"jenis_event"`
+	NoRekening string `db:"no_rekening"`
+	models.LanggananWebhook
+}
+
+// barisLangganan seals the subscription secret under a fresh data key, the
+// same way nasabah columns are protected.
+func (t *TabunganRepo) barisLangganan(langganan models.LanggananWebhook) (row langgananRow, err error) {
+	row.LanggananWebhook = langganan
+	jenisEvent, err := json.Marshal(langganan.JenisEvent)
+	if err != nil {
+		return
+	}
+	noRekening, err := json.Marshal(langganan.NoRekening)
+	if err != nil {
+		return
+	}
+	row.JenisEvent = string(jenisEvent)
+	row.NoRekening = string(noRekening)
+	dek, err := t.cipher.NewDataKey()
+	if err != nil {
+		return
+	}
+	row.Rahasia, err = dek.Encrypt("rahasia", langganan.Rahasia)
+	row.KunciID = dek.KeyID
+	row.KunciData = dek.Wrapped
+	return
+}
+
+func (t *TabunganRepo) bukaLangganan(row langgananRow) (langganan models.LanggananWebhook, err error) {
+	langganan = row.LanggananWebhook
+	if err = json.Unmarshal([]byte(row.JenisEvent), &langganan.JenisEvent); err != nil {
+		return
+	}
+	if err = json.Unmarshal([]byte(row.NoRekening), &langganan.NoRekening); err != nil {
+		return
+	}
+	dek, err := t.cipher.OpenDataKey(row.KunciID, row.KunciData)
+	if err != nil {
+		return
+	}
+	langganan.Rahasia, err = dek.Decrypt("rahasia", row.Rahasia)
+	return
+}
+
+func (t *TabunganRepo) InsertLanggananWebhook(ctx context.Context, langganan models.LanggananWebhook) (err error) {
+	row, err := t.barisLangganan(langganan)
+	if err == nil {
+		SQL := `INSERT INTO langganan_webhook VALUES (:langganan_id, :partner, :url, :rahasia, :kunci_id, :kunci_data,
+			:jenis_event, :no_rekening, :aktif, :petugas, :waktu_dibuat, :waktu_diubah)`
+		_, err = t.db.NamedExecContext(ctx, SQL, row)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"langganan_id": langganan.LanggananID,
+			"partner":      langganan.Partner,
+			"error":        err.Error(),
+		}).Error("insert langganan webhook error")
+	}
+	return
+}
+
+func (t *TabunganRepo) UpdateLanggananWebhook(ctx context.Context, langganan models.LanggananWebhook) (err error) {
+	row, err := t.barisLangganan(langganan)
+	if err == nil {
+		SQL := `UPDATE langganan_webhook SET partner = :partner, url = :url, rahasia = :rahasia, kunci_id = :kunci_id,
+			kunci_data = :kunci_data, jenis_event = :jenis_event, no_rekening = :no_rekening, aktif = :aktif,
+			petugas = :petugas, waktu_diubah = :waktu_diubah WHERE langganan_id = :langganan_id`
+		_, err = t.db.NamedExecContext(ctx, SQL, row)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"langganan_id": langganan.LanggananID,
+			"error":        err.Error(),
+		}).Error("update langganan webhook error")
+	}
+	return
+}
+
+func (t *TabunganRepo) GetLanggananWebhook(ctx context.Context, langgananID string) (langganan models.LanggananWebhook, err error) {
+	var row langgananRow
+	err = t.db.GetContext(ctx, &row, "SELECT * FROM langganan_webhook WHERE langganan_id = $1", langgananID)
+	if err == nil {
+		langganan, err = t.bukaLangganan(row)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"langganan_id": langgananID,
+			"error":        err.Error(),
+		}).Error("get langganan webhook error")
+	}
+	return
+}
+
+func (t *TabunganRepo) GetDaftarLanggananWebhook(ctx context.Context) (daftar []models.LanggananWebhook, err error) {
+	var rows []langgananRow
+	err = t.db.SelectContext(ctx, &rows, "SELECT * FROM langganan_webhook ORDER BY rowid")
+	for _, row := range rows {
+		var langganan models.LanggananWebhook
+		if langganan, err = t.bukaLangganan(row); err != nil {
+			break
+		}
+		daftar = append(daftar, langganan)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithField("error", err.Error()).Error("query langganan webhook error")
+	}
+	return
+}
+
+// AntreWebhook queues a delivery. Queuing the same event for the same
+// subscription again is a no-op, so the outbox relay may retry freely.
+func (t *TabunganRepo) AntreWebhook(ctx context.Context, pengiriman models.PengirimanWebhook) (err error) {
+	SQL := `INSERT OR IGNORE INTO pengiriman_webhook VALUES (:pengiriman_id, :langganan_id, :event_id, :jenis_event,
+		:payload, :status, 0, :kirim_berikutnya, 0, '', :waktu_dibuat, '')`
+	_, err = t.db.NamedExecContext(ctx, SQL, pengiriman)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"langganan_id": pengiriman.LanggananID,
+			"event_id":     pengiriman.EventID,
+			"error":        err.Error(),
+		}).Error("insert pengiriman webhook error")
+	}
+	return
+}
+
+// GetWebhookSiapKirim returns pending deliveries that are due at sekarang.
+func (t *TabunganRepo) GetWebhookSiapKirim(ctx context.Context, sekarang string, limit int) (daftar []models.PengirimanWebhook, err error) {
+	SQL := `SELECT * FROM pengiriman_webhook WHERE status = $1 AND kirim_berikutnya <= $2
+		ORDER BY kirim_berikutnya, rowid LIMIT $3`
+	err = t.db.SelectContext(ctx, &daftar, SQL, models.WebhookPending, sekarang, limit)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"sekarang": sekarang,
+			"error":    err.Error(),
+		}).Error("query pengiriman webhook error")
+	}
+	return
+}
+
+func (t *TabunganRepo) UpdatePengirimanWebhook(ctx context.Context, pengiriman models.PengirimanWebhook) (err error) {
+	SQL := `UPDATE pengiriman_webhook SET status = :status, percobaan = :percobaan, kirim_berikutnya = :kirim_berikutnya,
+		status_http = :status_http, error_terakhir = :error_terakhir, waktu_terkirim = :waktu_terkirim
+		WHERE pengiriman_id = :pengiriman_id`
+	_, err = t.db.NamedExecContext(ctx, SQL, pengiriman)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"pengiriman_id": pengiriman.PengirimanID,
+			"error":         err.Error(),
+		}).Error("update pengiriman webhook error")
+	}
+	return
+}
+
+func (t *TabunganRepo) GetPengirimanWebhook(ctx context.Context, pengirimanID string) (pengiriman models.PengirimanWebhook, err error) {
+	err = t.db.GetContext(ctx, &pengiriman, "SELECT * FROM pengiriman_webhook WHERE pengiriman_id = $1", pengirimanID)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"pengiriman_id": pengirimanID,
+			"error":         err.Error(),
+		}).Error("get pengiriman webhook error")
+	}
+	return
+}
+
+// GetDaftarPengirimanWebhook treats empty filters as "any", newest first.
+func (t *TabunganRepo) GetDaftarPengirimanWebhook(ctx context.Context, langgananID, status string) (daftar []models.PengirimanWebhook, err error) {
+	SQL := `SELECT * FROM pengiriman_webhook WHERE ($1 = '' OR langganan_id = $1) AND ($2 = '' OR status = $2)
+		ORDER BY waktu_dibuat DESC, rowid DESC`
+	err = t.db.SelectContext(ctx, &daftar, SQL, langgananID, status)
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"langganan_id": langgananID,
+			"status":       status,
+			"error":        err.Error(),
+		}).Error("query daftar pengiriman webhook error")
+	}
+	return
+}
+
+// UlangiPengirimanWebhook puts deliveries back in the queue with a fresh
+// retry budget: the one delivery pengirimanID if given, otherwise every
+// delivery of langgananID in status.
+func (t *TabunganRepo) UlangiPengirimanWebhook(ctx context.Context, pengirimanID, langgananID, status, sekarang string) (jumlah int, err error) {
+	SQL := `UPDATE pengiriman_webhook SET status = $1, percobaan = 0, kirim_berikutnya = $2
+		WHERE CASE WHEN $3 != '' THEN pengiriman_id = $3 ELSE langganan_id = $4 AND status = $5 END`
+	result, err := t.db.ExecContext(ctx, SQL, models.WebhookPending, sekarang, pengirimanID, langgananID, status)
+	if err == nil {
+		var affected int64
+		affected, err = result.RowsAffected()
+		jumlah = int(affected)
+	}
+	if err != nil {
+		t.log.WithContext(ctx).WithFields(logrus.Fields{
+			"pengiriman_id": pengirimanID,
+			"langganan_id":  langgananID,
+			"error":         err.Error(),
+		}).Error("ulangi pengiriman webhook error")
+	}
+	return
+}
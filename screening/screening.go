@@ -0,0 +1,251 @@
+// Package screening matches customer names against a sanctions or watchlist
+// file kept on local disk.
+//
+// Two file formats are understood, chosen by extension. A .csv file has a
+// header row naming the columns id, nama, alias, tanggal_lahir and sumber;
+// several aliases are separated by ';'. A .xml file looks like
+//
+//	<watchlist>
+//	  <entry id="UN-001">
+//	    <nama>...</nama>
+//	    <alias>...</alias>
+//	    <tanggal_lahir>1970-01-31</tanggal_lahir>
+//	    <sumber>UN</sumber>
+//	  </entry>
+//	</watchlist>
+//
+// with any number of alias elements.
+package screening
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"tabungan-api/similarity"
+	"time"
+)
+
+// birthDatePenalty scales the score of a name match whose birth date is
+// known on both sides and differs.
+const birthDatePenalty = 0.85
+
+type Entry struct {
+	ID        string   `json:"id" xml:"id,attr"`
+	Name      string   `json:"nama" xml:"nama"`
+	Aliases   []string `json:"alias" xml:"alias"`
+	BirthDate string   `json:"tanggal_lahir" xml:"tanggal_lahir"`
+	Source    string   `json:"sumber" xml:"sumber"`
+}
+
+type Match struct {
+	Entry Entry
+	// MatchedName is the entry name or alias that scored best.
+	MatchedName string
+	Score       float64
+}
+
+type entry struct {
+	Entry
+	names []string
+}
+
+// Watchlist holds the parsed list file. It is safe for concurrent use and
+// can be reloaded while screening is in progress.
+type Watchlist struct {
+	path    string
+	mu      sync.RWMutex
+	entries []entry
+	modTime time.Time
+	version string
+}
+
+// Reload parses the file again if it changed since it was last loaded.
+func (w *Watchlist) Reload() (reloaded bool, err error) {
+	stat, err := os.Stat(w.path)
+	if err != nil {
+		return
+	}
+	w.mu.RLock()
+	unchanged := stat.ModTime().Equal(w.modTime)
+	w.mu.RUnlock()
+	if unchanged {
+		return
+	}
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return
+	}
+	var entries []Entry
+	switch strings.ToLower(filepath.Ext(w.path)) {
+	case ".csv":
+		entries, err = parseCSV(data)
+	case ".xml":
+		entries, err = parseXML(data)
+	default:
+		err = fmt.Errorf("unsupported watchlist format %q", filepath.Ext(w.path))
+	}
+	if err != nil {
+		return
+	}
+	parsed := make([]entry, 0, len(entries))
+	for _, e := range entries {
+		parsed = append(parsed, newEntry(e))
+	}
+	sum := sha256.Sum256(data)
+	w.mu.Lock()
+	w.entries = parsed
+	w.modTime = stat.ModTime()
+	w.version = hex.EncodeToString(sum[:8])
+	w.mu.Unlock()
+	reloaded = true
+	return
+}
+
+// Version identifies the list content a screening was made against.
+func (w *Watchlist) Version() string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.version
+}
+
+func (w *Watchlist) Len() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return len(w.entries)
+}
+
+// Screen returns every entry whose name or alias scores at least threshold
+// against name, best match first.
+func (w *Watchlist) Screen(name, birthDate string, threshold float64) (matches []Match) {
+	name = similarity.Normalize(name)
+	if name == "" {
+		return
+	}
+	birthDate = strings.TrimSpace(birthDate)
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	for _, e := range w.entries {
+		match := Match{Entry: e.Entry}
+		for i, candidate := range e.names {
+			score := similarity.Ratio(name, candidate)
+			if sorted := similarity.TokenSortRatio(name, candidate); sorted > score {
+				score = sorted
+			}
+			if score > match.Score {
+				match.Score = score
+				match.MatchedName = e.Name
+				if i > 0 {
+					match.MatchedName = e.Aliases[i-1]
+				}
+			}
+		}
+		if e.BirthDate != "" && birthDate != "" && strings.TrimSpace(e.BirthDate) != birthDate {
+			match.Score *= birthDatePenalty
+		}
+		if match.Score >= threshold {
+			matches = append(matches, match)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return
+}
+
+func newEntry(e Entry) entry {
+	parsed := entry{Entry: e, names: []string{similarity.Normalize(e.Name)}}
+	for _, alias := range e.Aliases {
+		parsed.names = append(parsed.names, similarity.Normalize(alias))
+	}
+	return parsed
+}
+
+func parseCSV(data []byte) (entries []Entry, err error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return
+	}
+	column := make(map[string]int)
+	for i, name := range header {
+		column[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := column["nama"]; !ok {
+		err = fmt.Errorf("watchlist csv has no nama column")
+		return
+	}
+	field := func(record []string, name string) string {
+		if i, ok := column[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	for line := 2; ; line++ {
+		var record []string
+		record, err = reader.Read()
+		if err == io.EOF {
+			err = nil
+			return
+		}
+		if err != nil {
+			return
+		}
+		e := Entry{
+			ID:        field(record, "id"),
+			Name:      field(record, "nama"),
+			BirthDate: field(record, "tanggal_lahir"),
+			Source:    field(record, "sumber"),
+		}
+		if e.Name == "" {
+			continue
+		}
+		if e.ID == "" {
+			e.ID = fmt.Sprintf("baris-%d", line)
+		}
+		for _, alias := range strings.Split(field(record, "alias"), ";") {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				e.Aliases = append(e.Aliases, alias)
+			}
+		}
+		entries = append(entries, e)
+	}
+}
+
+func parseXML(data []byte) (entries []Entry, err error) {
+	var list struct {
+		Entries []Entry `xml:"entry"`
+	}
+	err = xml.Unmarshal(data, &list)
+	if err != nil {
+		return
+	}
+	for i, e := range list.Entries {
+		if strings.TrimSpace(e.Name) == "" {
+			continue
+		}
+		if e.ID == "" {
+			e.ID = fmt.Sprintf("entry-%d", i+1)
+		}
+		entries = append(entries, e)
+	}
+	return
+}
+
+// Load reads the watchlist at path. Later changes to the file are picked up
+// by Reload.
+func Load(path string) (w *Watchlist, err error) {
+	w = &Watchlist{path: path}
+	_, err = w.Reload()
+	return
+}
@@ -0,0 +1,152 @@
+package screening
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const daftarUji = `id,nama,alias,tanggal_lahir,sumber
+UN-001,Ahmad Yusuf Hakim,Abu Hakim; A. Y. Hakim,1970-01-31,UN
+UN-002,Siti Nurhaliza Putri,,,UN
+OFAC-003,Maria Gonzales,,1980-05-05,OFAC
+OFAC-004,Mario Gonzales,,,OFAC
+`
+
+func tulisDaftar(t *testing.T, path, isi string) {
+	if err := os.WriteFile(path, []byte(isi), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScreen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watchlist.csv")
+	tulisDaftar(t, path, daftarUji)
+	w, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		nama        string
+		name, lahir string
+		threshold   float64
+		// hits are "<id>:<matched name>", best match first; empty when
+		// nothing may match.
+		hits string
+	}{
+		{"exact name", "Ahmad Yusuf Hakim", "1970-01-31", 0.85, "UN-001:Ahmad Yusuf Hakim"},
+		{"case, spacing and punctuation", "  AHMAD-YUSUF  hakim. ", "", 0.85, "UN-001:Ahmad Yusuf Hakim"},
+		{"words reordered", "Hakim Ahmad Yusuf", "", 0.85, "UN-001:Ahmad Yusuf Hakim"},
+		{"alias", "Abu Hakim", "", 0.85, "UN-001:Abu Hakim"},
+		{"alias with one typo", "Abu Hakin", "", 0.85, "UN-001:Abu Hakim"},
+		{"alias with two typos", "Abi Hakin", "", 0.85, ""},
+		{"name with one typo", "Ahmad Yusuf Hakin", "", 0.85, "UN-001:Ahmad Yusuf Hakim"},
+		{"name with three typos", "Ahmat Yusup Hakin", "", 0.85, ""},
+		{"score at the threshold", "Sita Nurhalisa Putra", "", 0.85, "UN-002:Siti Nurhaliza Putri"},
+		{"score one edit below the threshold", "Sita Nurhalisa Potra", "", 0.85, ""},
+		{"birth date differs", "Ahmad Yusuf Hakim", "1971-01-31", 0.85, "UN-001:Ahmad Yusuf Hakim"},
+		{"birth date differs just above the penalized score", "Ahmad Yusuf Hakim", "1971-01-31", 0.851, ""},
+		{"birth date differs at the block threshold", "Ahmad Yusuf Hakim", "1971-01-31", 0.95, ""},
+		{"birth date differs and one typo", "Ahmad Yusuf Hakin", "1971-01-31", 0.85, ""},
+		{"birth date of the nasabah unknown", "Ahmad Yusuf Hakim", "", 0.95, "UN-001:Ahmad Yusuf Hakim"},
+		{"birth date of the entry unknown", "Siti Nurhaliza Putri", "1990-01-01", 0.95, "UN-002:Siti Nurhaliza Putri"},
+		{"best match first", "Maria Gonzales", "1981-01-01", 0.85, "OFAC-004:Mario Gonzales,OFAC-003:Maria Gonzales"},
+		{"unrelated name", "Budi Santoso", "", 0.85, ""},
+		{"no letters", " -- ", "", 0, ""},
+	}
+	for _, c := range cases {
+		var hits []string
+		for _, match := range w.Screen(c.name, c.lahir, c.threshold) {
+			hits = append(hits, match.Entry.ID+":"+match.MatchedName)
+		}
+		if got := strings.Join(hits, ","); got != c.hits {
+			t.Errorf("%s: Screen(%q, %q, %v) = %q, want %q", c.nama, c.name, c.lahir, c.threshold, got, c.hits)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		file, isi string
+		// entries are "<id>|<name>|<aliases>|<birth date>|<source>".
+		entries []string
+		valid   bool
+	}{
+		{"kolom.csv", "Sumber, NAMA ,alias\nUN,Ahmad Yusuf Hakim, Abu Hakim ;;A. Y. Hakim\nUN,,Tanpa Nama\nOFAC,Maria Gonzales\n",
+			[]string{"baris-2|Ahmad Yusuf Hakim|Abu Hakim;A. Y. Hakim||UN", "baris-4|Maria Gonzales|||OFAC"}, true},
+		{"tanpa_nama.csv", "id,alias\nUN-001,Abu Hakim\n", nil, false},
+		{"daftar.xml", `<watchlist>
+			<entry id="UN-001"><nama>Ahmad Yusuf Hakim</nama><alias>Abu Hakim</alias><alias>A. Y. Hakim</alias>
+				<tanggal_lahir>1970-01-31</tanggal_lahir><sumber>UN</sumber></entry>
+			<entry id="UN-002"><nama> </nama></entry>
+			<entry><nama>Maria Gonzales</nama></entry>
+		</watchlist>`,
+			[]string{"UN-001|Ahmad Yusuf Hakim|Abu Hakim;A. Y. Hakim|1970-01-31|UN", "entry-3|Maria Gonzales|||"}, true},
+		{"rusak.xml", "<watchlist><entry>", nil, false},
+		{"daftar.json", "[]", nil, false},
+	}
+	for _, c := range cases {
+		path := filepath.Join(dir, c.file)
+		tulisDaftar(t, path, c.isi)
+		w, err := Load(path)
+		if (err == nil) != c.valid {
+			t.Errorf("%s: Load error = %v, want valid %v", c.file, err, c.valid)
+			continue
+		}
+		if !c.valid {
+			continue
+		}
+		var entries []string
+		for _, e := range w.entries {
+			entries = append(entries, strings.Join([]string{e.ID, e.Name, strings.Join(e.Aliases, ";"), e.BirthDate, e.Source}, "|"))
+		}
+		if strings.Join(entries, "\n") != strings.Join(c.entries, "\n") {
+			t.Errorf("%s: entries = %q, want %q", c.file, entries, c.entries)
+		}
+	}
+}
+
+// TestReload checks that the list is only parsed again when the file
+// changed, and that a broken file keeps the list last loaded.
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watchlist.csv")
+	tulisDaftar(t, path, daftarUji)
+	w, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versi := w.Version()
+	if w.Len() != 4 || versi == "" {
+		t.Fatalf("loaded %d entries, version %q", w.Len(), versi)
+	}
+	if reloaded, err := w.Reload(); reloaded || err != nil {
+		t.Errorf("Reload of an unchanged file = %v, %v", reloaded, err)
+	}
+
+	ubah := func(isi string, waktu time.Time) {
+		tulisDaftar(t, path, isi)
+		if err := os.Chtimes(path, waktu, waktu); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ubah(daftarUji+"UN-005,Budi Santoso,,,UN\n", time.Now().Add(time.Minute))
+	if reloaded, err := w.Reload(); !reloaded || err != nil {
+		t.Fatalf("Reload of a changed file = %v, %v", reloaded, err)
+	}
+	if w.Len() != 5 || w.Version() == versi || len(w.Screen("Budi Santoso", "", 0.85)) != 1 {
+		t.Errorf("after reload: %d entries, version %q", w.Len(), w.Version())
+	}
+
+	versi = w.Version()
+	ubah("id,alias\n", time.Now().Add(2*time.Minute))
+	if _, err := w.Reload(); err == nil {
+		t.Error("Reload accepted a file without a nama column")
+	}
+	if w.Len() != 5 || w.Version() != versi {
+		t.Errorf("a failed reload replaced the list: %d entries, version %q", w.Len(), w.Version())
+	}
+}
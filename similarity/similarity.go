@@ -0,0 +1,148 @@
+// Package similarity scores how alike two names or addresses are.
+package similarity
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Normalize lowercases s and reduces it to words of letters and digits.
+func Normalize(s string) string {
+	return strings.Join(Words(s), " ")
+}
+
+// Words splits s into lowercase words of letters and digits.
+func Words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Ratio is the Levenshtein similarity of a and b, from 0 for nothing in
+// common to 1 for equal strings.
+func Ratio(a, b string) float64 {
+	x, y := []rune(a), []rune(b)
+	longest := len(x)
+	if len(y) > longest {
+		longest = len(y)
+	}
+	if longest == 0 {
+		return 0
+	}
+	prev := make([]int, len(y)+1)
+	curr := make([]int, len(y)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(x); i++ {
+		curr[0] = i
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return 1 - float64(prev[len(y)])/float64(longest)
+}
+
+// TokenSortRatio compares a and b with their words sorted, so that
+// "Santoso Budi" matches "Budi Santoso".
+func TokenSortRatio(a, b string) float64 {
+	x, y := Words(a), Words(b)
+	sort.Strings(x)
+	sort.Strings(y)
+	return Ratio(strings.Join(x, " "), strings.Join(y, " "))
+}
+
+// Jaccard is the share of distinct words a and b have in common.
+func Jaccard(a, b []string) float64 {
+	seen := make(map[string]bool)
+	for _, word := range a {
+		seen[word] = true
+	}
+	common, union := 0, len(seen)
+	for _, word := range b {
+		if seen[word] {
+			common++
+			delete(seen, word)
+		} else {
+			union++
+		}
+	}
+	if union == 0 {
+		return 0
+	}
+	return float64(common) / float64(union)
+}
+
+// addressAbbreviations expands the abbreviations commonly used in Indonesian
+// addresses so "Jl. Merdeka No. 5" matches "Jalan Merdeka Nomor 5".
+var addressAbbreviations = map[string]string{
+	"jl":   "jalan",
+	"jln":  "jalan",
+	"gg":   "gang",
+	"no":   "nomor",
+	"kel":  "kelurahan",
+	"kec":  "kecamatan",
+	"kab":  "kabupaten",
+	"prov": "provinsi",
+}
+
+// addressGeneric are words shared by too many addresses to tell them apart.
+var addressGeneric = map[string]bool{
+	"jalan": true, "gang": true, "nomor": true, "kelurahan": true, "kecamatan": true,
+	"kabupaten": true, "provinsi": true, "kota": true, "desa": true, "rt": true, "rw": true,
+}
+
+// AddressWords splits an address into words with abbreviations expanded.
+func AddressWords(s string) (words []string) {
+	for _, word := range Words(s) {
+		if full, ok := addressAbbreviations[word]; ok {
+			word = full
+		}
+		words = append(words, word)
+	}
+	return
+}
+
+// AddressKeys are blocking keys for finding similar addresses without
+// comparing every pair: each two neighbouring words once generic words such
+// as "jalan" and "nomor" are dropped, in either order. Addresses alike
+// enough to match nearly always share one. An address with a single
+// distinctive word is keyed by it alone.
+func AddressKeys(s string) (keys []string) {
+	var words []string
+	for _, word := range AddressWords(s) {
+		if !addressGeneric[word] {
+			words = append(words, word)
+		}
+	}
+	if len(words) == 1 {
+		return words
+	}
+	seen := make(map[string]bool)
+	for i := 1; i < len(words); i++ {
+		pair := []string{words[i-1], words[i]}
+		sort.Strings(pair)
+		key := pair[0] + " " + pair[1]
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return
+}
+
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
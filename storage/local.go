@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage keeps blobs as files under a single directory.
+type LocalStorage struct {
+	dir string
+}
+
+func (l *LocalStorage) path(key string) (path string, err error) {
+	path = filepath.Join(l.dir, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, filepath.Clean(l.dir)+string(os.PathSeparator)) {
+		err = fmt.Errorf("invalid blob key %q", key)
+	}
+	return
+}
+
+// Put writes to a temporary file first so a reader never sees a partial blob.
+func (l *LocalStorage) Put(key string, data []byte, contentType string) (err error) {
+	path, err := l.path(key)
+	if err != nil {
+		return
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0750)
+	if err != nil {
+		return
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(data)
+	if errClose := f.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return
+	}
+	err = os.Chmod(f.Name(), 0640)
+	if err != nil {
+		return
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func (l *LocalStorage) Get(key string) (blob io.ReadCloser, info Info, err error) {
+	info, err = l.Stat(key)
+	if err != nil {
+		return
+	}
+	path, _ := l.path(key)
+	blob, err = os.Open(path)
+	return
+}
+
+func (l *LocalStorage) GetRange(key string, offset, length int64) (blob io.ReadCloser, err error) {
+	path, err := l.path(key)
+	if err != nil {
+		return
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		err = ErrNotFound
+	}
+	if err != nil {
+		return
+	}
+	_, err = f.Seek(offset, io.SeekStart)
+	if err != nil {
+		f.Close()
+		return
+	}
+	blob = struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(f, length), f}
+	return
+}
+
+func (l *LocalStorage) Stat(key string) (info Info, err error) {
+	path, err := l.path(key)
+	if err != nil {
+		return
+	}
+	stat, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		err = ErrNotFound
+	}
+	if err != nil {
+		return
+	}
+	info = Info{
+		Key:         key,
+		Size:        stat.Size(),
+		ContentType: mime.TypeByExtension(filepath.Ext(key)),
+		ETag:        fmt.Sprintf("\"%x-%x\"", stat.ModTime().UnixNano(), stat.Size()),
+		ModTime:     stat.ModTime(),
+	}
+	return
+}
+
+func (l *LocalStorage) Delete(key string) (err error) {
+	path, err := l.path(key)
+	if err != nil {
+		return
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		err = nil
+	}
+	return
+}
+
+func (l *LocalStorage) List() (blobs []Info, err error) {
+	err = filepath.WalkDir(l.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		stat, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		key, err := filepath.Rel(l.dir, path)
+		if err != nil {
+			return err
+		}
+		blobs = append(blobs, Info{
+			Key:     filepath.ToSlash(key),
+			Size:    stat.Size(),
+			ModTime: stat.ModTime(),
+		})
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		err = nil
+	}
+	return
+}
+
+func NewLocalStorage(dir string) *LocalStorage {
+	return &LocalStorage{dir: dir}
+}
@@ -0,0 +1,284 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// Prefix is prepended to every key, so several stores can share a bucket.
+	Prefix string
+	// PathStyle addresses the bucket as endpoint/bucket/key, which is what
+	// MinIO and most self-hosted S3-compatible servers expect.
+	PathStyle bool
+}
+
+// S3Storage talks to an S3-compatible object store using signature V4. Only
+// the handful of object operations the app needs are implemented.
+type S3Storage struct {
+	config S3Config
+	client *http.Client
+}
+
+func (s *S3Storage) Put(key string, data []byte, contentType string) (err error) {
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	resp, err := s.do(http.MethodPut, s.config.Prefix+key, nil, header, data)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+	return
+}
+
+func (s *S3Storage) Get(key string) (blob io.ReadCloser, info Info, err error) {
+	resp, err := s.do(http.MethodGet, s.config.Prefix+key, nil, nil, nil)
+	if err != nil {
+		return
+	}
+	info = s.info(key, resp)
+	blob = resp.Body
+	return
+}
+
+func (s *S3Storage) GetRange(key string, offset, length int64) (blob io.ReadCloser, err error) {
+	header := http.Header{}
+	header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	resp, err := s.do(http.MethodGet, s.config.Prefix+key, nil, header, nil)
+	if err != nil {
+		return
+	}
+	blob = resp.Body
+	return
+}
+
+func (s *S3Storage) Stat(key string) (info Info, err error) {
+	resp, err := s.do(http.MethodHead, s.config.Prefix+key, nil, nil, nil)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+	info = s.info(key, resp)
+	return
+}
+
+func (s *S3Storage) Delete(key string) (err error) {
+	resp, err := s.do(http.MethodDelete, s.config.Prefix+key, nil, nil, nil)
+	if err == ErrNotFound {
+		err = nil
+	}
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+	return
+}
+
+func (s *S3Storage) info(key string, resp *http.Response) (info Info) {
+	info.Key = key
+	info.Size, _ = strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	info.ContentType = resp.Header.Get("Content-Type")
+	info.ETag = resp.Header.Get("ETag")
+	info.ModTime, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	return
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+		ETag         string    `xml:"ETag"`
+		Size         int64     `xml:"Size"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List pages through ListObjectsV2 for everything under the configured prefix.
+func (s *S3Storage) List() (blobs []Info, err error) {
+	query := url.Values{}
+	query.Set("list-type", "2")
+	query.Set("prefix", s.config.Prefix)
+	for {
+		var resp *http.Response
+		resp, err = s.do(http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return
+		}
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return
+		}
+		for _, object := range result.Contents {
+			blobs = append(blobs, Info{
+				Key:     strings.TrimPrefix(object.Key, s.config.Prefix),
+				Size:    object.Size,
+				ETag:    object.ETag,
+				ModTime: object.LastModified,
+			})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// url addresses object, the full key including any prefix, or the bucket
+// itself when object is empty.
+func (s *S3Storage) url(object string, query url.Values) (u *url.URL, err error) {
+	u, err = url.Parse(s.config.Endpoint)
+	if err != nil {
+		return
+	}
+	if s.config.PathStyle {
+		u.Path = "/" + s.config.Bucket + "/" + object
+	} else {
+		u.Host = s.config.Bucket + "." + u.Host
+		u.Path = "/" + object
+	}
+	u.RawPath = escapePath(u.Path)
+	u.RawQuery = query.Encode()
+	return
+}
+
+func (s *S3Storage) do(method, object string, query url.Values, header http.Header, body []byte) (resp *http.Response, err error) {
+	u, err := s.url(object, query)
+	if err != nil {
+		return
+	}
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	s.sign(req, body, time.Now().UTC())
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		err = ErrNotFound
+		return
+	}
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		err = fmt.Errorf("s3 %s %s: %s: %s", method, object, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return
+}
+
+func (s *S3Storage) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	payloadHash := emptyPayloadHash
+	if len(body) > 0 {
+		payloadHash = sha256Hex(body)
+	}
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signed := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	for _, name := range []string{"content-type", "range"} {
+		if req.Header.Get(name) != "" {
+			signed = append(signed, name)
+		}
+	}
+	sort.Strings(signed)
+	scope := now.Format("20060102") + "/" + s.config.Region + "/s3/aws4_request"
+	signature := signatureV4(req, req.URL.Host, signed, s.config.SecretKey, scope)
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKey, scope, strings.Join(signed, ";"), signature))
+}
+
+// signatureV4 computes the signature V4 of req over the given signed headers,
+// which must be sorted. The payload hash and date are taken from the
+// X-Amz-Content-Sha256 and X-Amz-Date headers. Both the client and
+// S3StandIn use it, so the stand-in checks signatures the way S3 does.
+func signatureV4(req *http.Request, host string, signed []string, secretKey, scope string) string {
+	var canonicalHeaders strings.Builder
+	for _, name := range signed {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		strings.Join(signed, ";"),
+		req.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+	amzDate := req.Header.Get("X-Amz-Date")
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	// scope is date/region/service/aws4_request.
+	key := []byte("AWS4" + secretKey)
+	for _, part := range strings.Split(scope, "/") {
+		key = hmacSHA256(key, part)
+	}
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// escapePath encodes a path the way S3 canonicalises it: every byte except
+// unreserved characters and '/' is percent-encoded.
+func escapePath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' ||
+			('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func NewS3Storage(config S3Config) *S3Storage {
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	return &S3Storage{
+		config: config,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// S3StandIn is an in-memory, path-style S3-compatible server for local
+// development and tests. It verifies signature V4 against a single access key
+// like S3 does, but it is not meant to be exposed to a network.
+type S3StandIn struct {
+	mu        sync.RWMutex
+	objects   map[string]standInObject
+	accessKey string
+	secretKey string
+}
+
+type standInObject struct {
+	data        []byte
+	contentType string
+	etag        string
+	modTime     time.Time
+}
+
+func (s *S3StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if status, code := s.verify(r, body); status != 0 {
+		http.Error(w, code, status)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	if r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2" {
+		s.list(w, strings.TrimSuffix(key, "/")+"/", r.URL.Query().Get("prefix"))
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		object := standInObject{
+			data:        body,
+			contentType: r.Header.Get("Content-Type"),
+			etag:        fmt.Sprintf("\"%x\"", md5.Sum(body)),
+			modTime:     time.Now().UTC(),
+		}
+		s.mu.Lock()
+		s.objects[key] = object
+		s.mu.Unlock()
+		w.Header().Set("ETag", object.etag)
+	case http.MethodGet, http.MethodHead:
+		s.mu.RLock()
+		object, ok := s.objects[key]
+		s.mu.RUnlock()
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		data := object.data
+		status := http.StatusOK
+		var start, end int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); err == nil {
+			if start >= len(data) || end < start {
+				http.Error(w, "InvalidRange", http.StatusRequestedRangeNotSatisfiable)
+				return
+			}
+			if end >= len(data) {
+				end = len(data) - 1
+			}
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+			data = data[start : end+1]
+			status = http.StatusPartialContent
+		}
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("ETag", object.etag)
+		w.Header().Set("Last-Modified", object.modTime.Format(http.TimeFormat))
+		w.WriteHeader(status)
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	case http.MethodDelete:
+		s.mu.Lock()
+		delete(s.objects, key)
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "MethodNotAllowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// verify checks the signature V4 of r, returning the status and S3 error
+// code to answer with when it does not hold.
+func (s *S3StandIn) verify(r *http.Request, body []byte) (status int, code string) {
+	auth := strings.TrimPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ")
+	fields := make(map[string]string)
+	for _, field := range strings.Split(auth, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+		fields[name] = value
+	}
+	accessKey, scope, _ := strings.Cut(fields["Credential"], "/")
+	if auth == r.Header.Get("Authorization") || accessKey != s.accessKey || fields["SignedHeaders"] == "" {
+		return http.StatusForbidden, "AccessDenied"
+	}
+	amzDate, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	if err != nil || !strings.HasPrefix(scope, amzDate.Format("20060102")+"/") {
+		return http.StatusForbidden, "AccessDenied"
+	}
+	if skew := time.Since(amzDate); skew > 15*time.Minute || skew < -15*time.Minute {
+		return http.StatusForbidden, "RequestTimeTooSkewed"
+	}
+	if sha256Hex(body) != r.Header.Get("X-Amz-Content-Sha256") {
+		return http.StatusBadRequest, "XAmzContentSHA256Mismatch"
+	}
+	signed := strings.Split(fields["SignedHeaders"], ";")
+	if !sort.StringsAreSorted(signed) {
+		return http.StatusForbidden, "AccessDenied"
+	}
+	expected := signatureV4(r, r.Host, signed, s.secretKey, scope)
+	if !hmac.Equal([]byte(expected), []byte(fields["Signature"])) {
+		return http.StatusForbidden, "SignatureDoesNotMatch"
+	}
+	return
+}
+
+// list answers ListObjectsV2 in a single page.
+func (s *S3StandIn) list(w http.ResponseWriter, bucket, prefix string) {
+	var result listBucketResult
+	s.mu.RLock()
+	for key, object := range s.objects {
+		if !strings.HasPrefix(key, bucket+prefix) {
+			continue
+		}
+		result.Contents = append(result.Contents, struct {
+			Key          string    `xml:"Key"`
+			LastModified time.Time `xml:"LastModified"`
+			ETag         string    `xml:"ETag"`
+			Size         int64     `xml:"Size"`
+		}{strings.TrimPrefix(key, bucket), object.modTime, object.etag, int64(len(object.data))})
+	}
+	s.mu.RUnlock()
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"ListBucketResult"`
+		listBucketResult
+	}{listBucketResult: result})
+}
+
+// NewS3StandIn accepts only requests signed with accessKey and secretKey.
+func NewS3StandIn(accessKey, secretKey string) *S3StandIn {
+	return &S3StandIn{
+		objects:   make(map[string]standInObject),
+		accessKey: accessKey,
+		secretKey: secretKey,
+	}
+}
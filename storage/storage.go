@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+type Info struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ETag        string
+	ModTime     time.Time
+}
+
+// Storage stores customer files as opaque blobs addressed by key.
+type Storage interface {
+	Put(key string, data []byte, contentType string) (err error)
+	Get(key string) (blob io.ReadCloser, info Info, err error)
+	// GetRange reads length bytes starting at offset.
+	GetRange(key string, offset, length int64) (blob io.ReadCloser, err error)
+	Stat(key string) (info Info, err error)
+	Delete(key string) (err error)
+	// List returns every blob in the store.
+	List() (blobs []Info, err error)
+}
+
+// Blob is a stored file whose metadata has been looked up but whose content
+// has not been read yet, so callers can answer conditional and range requests
+// before touching the data.
+type Blob struct {
+	Info
+	store Storage
+}
+
+func (b Blob) Open(offset, length int64) (io.ReadCloser, error) {
+	return b.store.GetRange(b.Key, offset, length)
+}
+
+func Open(store Storage, key string) (blob Blob, err error) {
+	blob.store = store
+	blob.Info, err = store.Stat(key)
+	return
+}
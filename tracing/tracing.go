@@ -0,0 +1,114 @@
+// Package tracing sets up OpenTelemetry tracing for the service and ties log
+// entries to the span they were written in.
+package tracing
+
+import (
+	"context"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Config chooses where spans are exported. With neither OTLPEndpoint nor
+// File set spans are still created, so that logs carry trace IDs, but they
+// are dropped when they end.
+type Config struct {
+	ServiceName string
+	// OTLPEndpoint is the host:port of a collector accepting OTLP over HTTP.
+	OTLPEndpoint string
+	// OTLPInsecure sends to OTLPEndpoint over plain HTTP.
+	OTLPInsecure bool
+	// File receives every span as one JSON object per line, as soon as the
+	// span ends.
+	File string
+}
+
+// Setup installs the global tracer provider and the W3C trace context
+// propagator. shutdown exports the spans still buffered and closes File.
+func Setup(cfg Config) (shutdown func(context.Context) error, err error) {
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName))),
+	}
+	var file *os.File
+	if cfg.File != "" {
+		file, err = os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return
+		}
+		var exporter sdktrace.SpanExporter
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return
+		}
+		opts = append(opts, sdktrace.WithSyncer(exporter))
+	}
+	if cfg.OTLPEndpoint != "" {
+		otlpOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			otlpOpts = append(otlpOpts, otlptracehttp.WithInsecure())
+		}
+		var exporter sdktrace.SpanExporter
+		// The exporter connects lazily, so an unreachable collector does
+		// not stop the service from starting.
+		exporter, err = otlptracehttp.New(context.Background(), otlpOpts...)
+		if err != nil {
+			if file != nil {
+				file.Close()
+			}
+			return
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	shutdown = func(ctx context.Context) (err error) {
+		err = provider.Shutdown(ctx)
+		if file != nil {
+			if errClose := file.Close(); err == nil {
+				err = errClose
+			}
+		}
+		return
+	}
+	return
+}
+
+// End ends span, marking it failed with err when err is not nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// LogHook adds the trace_id and span_id of the span in an entry's context,
+// set with WithContext, to the entry.
+type LogHook struct{}
+
+func (LogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (LogHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	span := trace.SpanContextFromContext(entry.Context)
+	if span.IsValid() {
+		entry.Data["trace_id"] = span.TraceID().String()
+		entry.Data["span_id"] = span.SpanID().String()
+	}
+	return nil
+}